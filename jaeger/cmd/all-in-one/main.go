@@ -53,6 +53,7 @@ import (
 	"github.com/jaegertracing/jaeger/cmd/flags"
 	queryApp "github.com/jaegertracing/jaeger/cmd/query/app"
 	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+	"github.com/jaegertracing/jaeger/model/requesttype"
 	"github.com/jaegertracing/jaeger/pkg/config"
 	"github.com/jaegertracing/jaeger/pkg/healthcheck"
 	"github.com/jaegertracing/jaeger/pkg/recoveryhandler"
@@ -127,16 +128,23 @@ by default uses only in-memory database.`,
 			cOpts := new(collector.CollectorOptions).InitFromViper(v)
 			qOpts := new(queryApp.QueryOptions).InitFromViper(v)
 
-			collectorSrv := startCollector(cOpts, spanWriter, logger, metricsFactory, strategyStore, svc.HC())
+			structureIndexer := startStructureIndexer(cOpts, storageFactory, logger, metricsFactory)
+			collectorSrv, spanBuilder := startCollector(cOpts, spanWriter, structureIndexer, logger, metricsFactory, strategyStore, svc.HC())
 			startAgent(aOpts, repOpts, tchanBuilder, grpcBuilder, cOpts, logger, metricsFactory)
 			querySrv := startQuery(
-				svc, qOpts, archiveOptions(storageFactory, logger),
+				svc, qOpts, storageOptions(storageFactory, structureIndexer != nil, logger),
 				spanReader, dependencyReader,
 				rootMetricsFactory, metricsFactory,
 			)
 
 			svc.RunAndThen(func() {
 				collectorSrv.GracefulStop()
+				if err := spanBuilder.Close(); err != nil {
+					logger.Error("Failed to close span handler builder", zap.Error(err))
+				}
+				if structureIndexer != nil {
+					structureIndexer.Close()
+				}
 				querySrv.Close()
 				if closer, ok := spanWriter.(io.Closer); ok {
 					err := closer.Close()
@@ -144,6 +152,9 @@ by default uses only in-memory database.`,
 						logger.Error("Failed to close span writer", zap.Error(err))
 					}
 				}
+				if err := storageFactory.Close(); err != nil {
+					logger.Error("Failed to close storage factory", zap.Error(err))
+				}
 				tracerCloser.Close()
 			})
 			return nil
@@ -165,6 +176,7 @@ by default uses only in-memory database.`,
 		agentGrpcRep.AddFlags,
 		collector.AddFlags,
 		queryApp.AddFlags,
+		requesttype.AddFlags,
 		strategyStoreFactory.AddFlags,
 	)
 
@@ -202,14 +214,36 @@ func startAgent(
 	}
 }
 
+func startStructureIndexer(
+	cOpts *collector.CollectorOptions,
+	storageFactory istorage.Factory,
+	logger *zap.Logger,
+	baseFactory metrics.Factory,
+) *collectorApp.StructureIndexer {
+	structureIndexer, err := collector.NewStructureIndexer(
+		cOpts,
+		storageFactory,
+		basic.Options.LoggerOption(logger),
+		basic.Options.MetricsFactoryOption(baseFactory.Namespace(metrics.NSOptions{Name: "collector", Tags: nil})),
+	)
+	if err != nil {
+		logger.Fatal("Unable to set up structure indexer", zap.Error(err))
+	}
+	if structureIndexer != nil {
+		structureIndexer.Start()
+	}
+	return structureIndexer
+}
+
 func startCollector(
 	cOpts *collector.CollectorOptions,
 	spanWriter spanstore.Writer,
+	structureIndexer *collectorApp.StructureIndexer,
 	logger *zap.Logger,
 	baseFactory metrics.Factory,
 	strategyStore strategystore.StrategyStore,
 	hc *healthcheck.HealthCheck,
-) *grpc.Server {
+) (*grpc.Server, *collector.SpanHandlerBuilder) {
 	metricsFactory := baseFactory.Namespace(metrics.NSOptions{Name: "collector", Tags: nil})
 
 	spanBuilder, err := collector.NewSpanHandlerBuilder(
@@ -222,7 +256,11 @@ func startCollector(
 		logger.Fatal("Unable to set up builder", zap.Error(err))
 	}
 
-	zipkinSpansHandler, jaegerBatchesHandler, grpcHandler := spanBuilder.BuildHandlers()
+	var processorOpts []collectorApp.Option
+	if structureIndexer != nil {
+		processorOpts = append(processorOpts, collectorApp.Options.PreSave(structureIndexer.ProcessSpan))
+	}
+	zipkinSpansHandler, jaegerBatchesHandler, grpcHandler := spanBuilder.BuildHandlers(processorOpts...)
 
 	{
 		ch, err := tchannel.NewChannel("jaeger-collector", &tchannel.ChannelOptions{})
@@ -265,7 +303,7 @@ func startCollector(
 			hc.Set(healthcheck.Unavailable)
 		}()
 	}
-	return server
+	return server, spanBuilder
 }
 
 func startGRPCServer(
@@ -313,6 +351,12 @@ func startQuery(
 	baseFactory metrics.Factory,
 ) *queryApp.Server {
 	spanReader = storageMetrics.NewReadMetricsDecorator(spanReader, baseFactory.Namespace(metrics.NSOptions{Name: "query"}))
+	requestTypes, err := qOpts.RequestTypes.NewClassifier()
+	if err != nil {
+		svc.Logger.Fatal("Failed to load request type rules", zap.Error(err))
+	}
+	queryOpts.RequestTypes = requestTypes
+	queryOpts.MaxTraceIDsPerRequest = qOpts.MaxTraceIDsPerRequest
 	qs := querysvc.NewQueryService(spanReader, depReader, *queryOpts)
 	server := queryApp.NewServer(svc, qs, qOpts, opentracing.GlobalTracer())
 	if err := server.Start(); err != nil {
@@ -336,11 +380,18 @@ func initSamplingStrategyStore(
 	return strategyStore
 }
 
-func archiveOptions(storageFactory istorage.Factory, logger *zap.Logger) *querysvc.QueryServiceOptions {
+// storageOptions initializes the archive storage and, when the collector indexes the structures
+// of the traces, the structure index.
+func storageOptions(storageFactory istorage.Factory, structureIndex bool, logger *zap.Logger) *querysvc.QueryServiceOptions {
 	opts := &querysvc.QueryServiceOptions{}
 	if !opts.InitArchiveStorage(storageFactory, logger) {
 		logger.Info("Archive storage not initialized")
 	}
+	if !structureIndex {
+		logger.Info("Structure index not enabled, the structural hashes are computed by the query service")
+	} else if !opts.InitStructureIndex(storageFactory, logger) {
+		logger.Info("Structure index not initialized")
+	}
 	return opts
 }
 
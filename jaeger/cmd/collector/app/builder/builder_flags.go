@@ -17,10 +17,12 @@ package builder
 
 import (
 	"flag"
+	"time"
 
 	"github.com/spf13/viper"
 
 	"github.com/jaegertracing/jaeger/cmd/collector/app"
+	"github.com/jaegertracing/jaeger/model/requesttype"
 	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
 	"github.com/jaegertracing/jaeger/ports"
 )
@@ -34,6 +36,9 @@ const (
 	collectorZipkinHTTPort        = "collector.zipkin.http-port"
 	collectorZipkinAllowedOrigins = "collector.zipkin.allowed-origins"
 	collectorZipkinAllowedHeaders = "collector.zipkin.allowed-headers"
+	collectorNameRulesFile        = "collector.name-rules-file"
+	collectorStructureTimeout     = "collector.structure-index.completion-timeout"
+	collectorStructureMaxPending  = "collector.structure-index.max-pending-traces"
 )
 
 var tlsFlagsConfig = tlscfg.ServerFlagsConfig{
@@ -62,6 +67,15 @@ type CollectorOptions struct {
 	CollectorZipkinAllowedOrigins string
 	// CollectorZipkinAllowedHeaders is a list of headers that the Zipkin collector service allowes the client to use with cross-domain requests
 	CollectorZipkinAllowedHeaders string
+	// NameRulesFile is the path of the service and operation name rules file, see sanitizer.NameRule
+	NameRulesFile string
+	// RequestTypes configures the request type stamped onto root spans, see requesttype.AddFlags
+	RequestTypes requesttype.Options
+	// StructureCompletionTimeout is how long a trace must go without new spans before its
+	// structural hash is indexed, zero disabling the structure index
+	StructureCompletionTimeout time.Duration
+	// StructureMaxPendingTraces is the maximum number of traces awaiting completion to be indexed
+	StructureMaxPendingTraces int
 }
 
 // AddFlags adds flags for CollectorOptions
@@ -74,6 +88,9 @@ func AddFlags(flags *flag.FlagSet) {
 	flags.Int(collectorZipkinHTTPort, 0, "The HTTP port for the Zipkin collector service e.g. 9411")
 	flags.String(collectorZipkinAllowedOrigins, "*", "Comma separated list of allowed origins for the Zipkin collector service, default accepts all")
 	flags.String(collectorZipkinAllowedHeaders, "content-type", "Comma separated list of allowed headers for the Zipkin collector service, default content-type")
+	flags.String(collectorNameRulesFile, "", "The path of the YAML or JSON file of rules rewriting the service and operation names of spans, reloaded when it changes")
+	flags.Duration(collectorStructureTimeout, app.DefaultStructureCompletionTimeout, "How long a trace must go without new spans to be deemed complete and have its structural hash indexed, when the span storage supports it. Zero disables the structure index")
+	flags.Int(collectorStructureMaxPending, app.DefaultMaxPendingStructures, "The maximum number of traces awaiting completion to have their structural hash indexed, the traces beyond it are not indexed")
 	tlsFlagsConfig.AddFlags(flags)
 }
 
@@ -87,6 +104,10 @@ func (cOpts *CollectorOptions) InitFromViper(v *viper.Viper) *CollectorOptions {
 	cOpts.CollectorZipkinHTTPPort = v.GetInt(collectorZipkinHTTPort)
 	cOpts.CollectorZipkinAllowedOrigins = v.GetString(collectorZipkinAllowedOrigins)
 	cOpts.CollectorZipkinAllowedHeaders = v.GetString(collectorZipkinAllowedHeaders)
+	cOpts.NameRulesFile = v.GetString(collectorNameRulesFile)
+	cOpts.StructureCompletionTimeout = v.GetDuration(collectorStructureTimeout)
+	cOpts.StructureMaxPendingTraces = v.GetInt(collectorStructureMaxPending)
 	cOpts.TLS = tlsFlagsConfig.InitFromViper(v)
+	cOpts.RequestTypes.InitFromViper(v)
 	return cOpts
 }
@@ -23,8 +23,12 @@ import (
 
 	basicB "github.com/jaegertracing/jaeger/cmd/builder"
 	"github.com/jaegertracing/jaeger/cmd/collector/app"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/sanitizer"
 	zs "github.com/jaegertracing/jaeger/cmd/collector/app/sanitizer/zipkin"
 	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/model/adjuster"
+	"github.com/jaegertracing/jaeger/model/requesttype"
+	"github.com/jaegertracing/jaeger/storage"
 	"github.com/jaegertracing/jaeger/storage/spanstore"
 )
 
@@ -34,24 +38,47 @@ type SpanHandlerBuilder struct {
 	metricsFactory metrics.Factory
 	collectorOpts  *CollectorOptions
 	spanWriter     spanstore.Writer
+	requestTypes   *requesttype.Classifier
+	nameSanitizer  *sanitizer.NameSanitizer
 }
 
 // NewSpanHandlerBuilder returns new SpanHandlerBuilder with configured span storage.
 func NewSpanHandlerBuilder(cOpts *CollectorOptions, spanWriter spanstore.Writer, opts ...basicB.Option) (*SpanHandlerBuilder, error) {
 	options := basicB.ApplyOptions(opts...)
 
+	requestTypes, err := cOpts.RequestTypes.NewClassifier()
+	if err != nil {
+		return nil, err
+	}
+
 	spanHb := &SpanHandlerBuilder{
 		collectorOpts:  cOpts,
 		logger:         options.Logger,
 		metricsFactory: options.MetricsFactory,
 		spanWriter:     spanWriter,
+		requestTypes:   requestTypes,
+	}
+
+	if cOpts.NameRulesFile != "" {
+		spanHb.nameSanitizer, err = sanitizer.NewNameSanitizer(
+			cOpts.NameRulesFile,
+			options.MetricsFactory.Namespace(metrics.NSOptions{Name: "sanitizer"}),
+			options.Logger,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if err := spanHb.nameSanitizer.Watch(); err != nil {
+			options.Logger.Warn("Cannot watch the name rules file, changes will not be reloaded", zap.Error(err))
+		}
 	}
 
 	return spanHb, nil
 }
 
-// BuildHandlers builds span handlers (Zipkin, Jaeger)
-func (spanHb *SpanHandlerBuilder) BuildHandlers() (
+// BuildHandlers builds span handlers (Zipkin, Jaeger). The options are appended to the ones
+// of the span processor, e.g. to set its PreSave function.
+func (spanHb *SpanHandlerBuilder) BuildHandlers(opts ...app.Option) (
 	app.ZipkinSpansHandler,
 	app.JaegerBatchesHandler,
 	*app.GRPCHandler,
@@ -61,12 +88,15 @@ func (spanHb *SpanHandlerBuilder) BuildHandlers() (
 
 	spanProcessor := app.NewSpanProcessor(
 		spanHb.spanWriter,
-		app.Options.ServiceMetrics(spanHb.metricsFactory),
-		app.Options.HostMetrics(hostMetrics),
-		app.Options.Logger(spanHb.logger),
-		app.Options.SpanFilter(defaultSpanFilter),
-		app.Options.NumWorkers(spanHb.collectorOpts.NumWorkers),
-		app.Options.QueueSize(spanHb.collectorOpts.QueueSize),
+		append([]app.Option{
+			app.Options.ServiceMetrics(spanHb.metricsFactory),
+			app.Options.HostMetrics(hostMetrics),
+			app.Options.Logger(spanHb.logger),
+			app.Options.SpanFilter(defaultSpanFilter),
+			app.Options.NumWorkers(spanHb.collectorOpts.NumWorkers),
+			app.Options.QueueSize(spanHb.collectorOpts.QueueSize),
+			app.Options.Sanitizer(spanHb.sanitizer()),
+		}, opts...)...,
 	)
 
 	return app.NewZipkinSpanHandler(spanHb.logger, spanProcessor, zs.NewChainedSanitizer(zs.StandardSanitizers...)),
@@ -74,6 +104,69 @@ func (spanHb *SpanHandlerBuilder) BuildHandlers() (
 		app.NewGRPCHandler(spanHb.logger, spanProcessor)
 }
 
+// Close stops watching the name rules file, if any.
+func (spanHb *SpanHandlerBuilder) Close() error {
+	if spanHb.nameSanitizer == nil {
+		return nil
+	}
+	return spanHb.nameSanitizer.Close()
+}
+
+// NewStructureIndexer creates the app.StructureIndexer of the structural hashes of the traces
+// written to the span storage. It returns nil when the structure index is disabled or not
+// supported by the storage.
+func NewStructureIndexer(cOpts *CollectorOptions, storageFactory storage.Factory, opts ...basicB.Option) (*app.StructureIndexer, error) {
+	options := basicB.ApplyOptions(opts...)
+	if cOpts.StructureCompletionTimeout <= 0 {
+		return nil, nil
+	}
+	indexFactory, ok := storageFactory.(storage.StructureIndexFactory)
+	if !ok {
+		options.Logger.Info("Structure index not supported by the factory")
+		return nil, nil
+	}
+	writer, err := indexFactory.CreateStructureWriter()
+	if err == storage.ErrStructureIndexNotSupported {
+		options.Logger.Info("Structure index not created", zap.String("reason", err.Error()))
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	reader, err := storageFactory.CreateSpanReader()
+	if err != nil {
+		return nil, err
+	}
+	return app.NewStructureIndexer(
+		reader,
+		writer,
+		app.StructureIndexerOptions{
+			CompletionTimeout: cOpts.StructureCompletionTimeout,
+			MaxPendingTraces:  cOpts.StructureMaxPendingTraces,
+			// hash the traces as the query service sees them
+			Adjuster: adjuster.Sequence(adjuster.StandardAdjusters...),
+		},
+		options.MetricsFactory,
+		options.Logger,
+	), nil
+}
+
+// sanitizer rewrites the service and operation names, then stamps the request type onto
+// root spans, when the respective rules are configured.
+func (spanHb *SpanHandlerBuilder) sanitizer() sanitizer.SanitizeSpan {
+	var sanitizers []sanitizer.SanitizeSpan
+	if spanHb.nameSanitizer != nil {
+		sanitizers = append(sanitizers, spanHb.nameSanitizer.Sanitize)
+	}
+	if spanHb.requestTypes != nil {
+		sanitizers = append(sanitizers, sanitizer.NewRequestTypeSanitizer(spanHb.requestTypes))
+	}
+	if len(sanitizers) == 0 {
+		return nil
+	}
+	return sanitizer.NewChainedSanitizer(sanitizers...)
+}
+
 func defaultSpanFilter(*model.Span) bool {
 	return true
 }
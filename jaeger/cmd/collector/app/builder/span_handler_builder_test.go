@@ -16,6 +16,10 @@
 package builder
 
 import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -24,9 +28,14 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/jaegertracing/jaeger/cmd/builder"
+	"github.com/jaegertracing/jaeger/cmd/collector/app"
 	"github.com/jaegertracing/jaeger/cmd/flags"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/model/requesttype"
 	"github.com/jaegertracing/jaeger/pkg/config"
 	"github.com/jaegertracing/jaeger/plugin/storage/memory"
+	"github.com/jaegertracing/jaeger/storage"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
 )
 
 func TestNewSpanHandlerBuilder(t *testing.T) {
@@ -49,6 +58,91 @@ func TestNewSpanHandlerBuilder(t *testing.T) {
 	assert.NotNil(t, zipkin)
 	assert.NotNil(t, jaeger)
 	assert.NotNil(t, grpc)
+	assert.NoError(t, handler.Close())
+}
+
+func TestNewSpanHandlerBuilderRequestTypes(t *testing.T) {
+	v, command := config.Viperize(flags.AddFlags, AddFlags, requesttype.AddFlags)
+
+	command.ParseFlags([]string{"--request-types.rules-file=fixtures/missing.yaml"})
+	cOpts := new(CollectorOptions).InitFromViper(v)
+	_, err := NewSpanHandlerBuilder(cOpts, memory.NewStore())
+	assert.Error(t, err)
+
+	spanHb := &SpanHandlerBuilder{}
+	assert.Nil(t, spanHb.sanitizer())
+	spanHb.requestTypes, err = requesttype.NewClassifier(nil)
+	require.NoError(t, err)
+	span := spanHb.sanitizer()(&model.Span{OperationName: "GET", Process: &model.Process{ServiceName: "frontend"}})
+	assert.Equal(t, []model.KeyValue{model.String(requesttype.Tag, "frontend:GET")}, span.Tags)
+}
+
+func TestNewSpanHandlerBuilderNameRules(t *testing.T) {
+	dir, err := ioutil.TempDir("", "name-rules")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "rules.yaml")
+	require.NoError(t, ioutil.WriteFile(file, []byte("rules: [{name: abbrev, field: service, map: {fe: frontend}}]"), 0600))
+
+	v, command := config.Viperize(flags.AddFlags, AddFlags, requesttype.AddFlags)
+	command.ParseFlags([]string{"--collector.name-rules-file=" + file})
+	cOpts := new(CollectorOptions).InitFromViper(v)
+	assert.Equal(t, file, cOpts.NameRulesFile)
+
+	spanHb, err := NewSpanHandlerBuilder(cOpts, memory.NewStore())
+	require.NoError(t, err)
+	defer func() { assert.NoError(t, spanHb.Close()) }()
+	spanHb.requestTypes, err = requesttype.NewClassifier(nil)
+	require.NoError(t, err)
+	span := spanHb.sanitizer()(&model.Span{OperationName: "GET", Process: &model.Process{ServiceName: "fe"}})
+	assert.Equal(t, "frontend", span.Process.ServiceName)
+	assert.Equal(t, []model.KeyValue{model.String(requesttype.Tag, "frontend:GET")}, span.Tags, "names are rewritten first")
+
+	require.NoError(t, ioutil.WriteFile(file, []byte("rules: [{field: service}]"), 0600))
+	_, err = NewSpanHandlerBuilder(cOpts, memory.NewStore())
+	assert.EqualError(t, err, "name rule #0 has no name")
+}
+
+type structureIndexFactory struct {
+	*memory.Factory
+	err error
+}
+
+func (f structureIndexFactory) CreateStructureWriter() (spanstore.StructureWriter, error) {
+	return nil, f.err
+}
+
+func TestNewStructureIndexer(t *testing.T) {
+	v, command := config.Viperize(flags.AddFlags, AddFlags)
+	command.ParseFlags([]string{"--collector.structure-index.max-pending-traces=10"})
+	cOpts := new(CollectorOptions).InitFromViper(v)
+	assert.Equal(t, app.DefaultStructureCompletionTimeout, cOpts.StructureCompletionTimeout)
+	assert.Equal(t, 10, cOpts.StructureMaxPendingTraces)
+
+	storageFactory := memory.NewFactory()
+	require.NoError(t, storageFactory.Initialize(metrics.NullFactory, zap.NewNop()))
+	indexer, err := NewStructureIndexer(cOpts, storageFactory)
+	require.NoError(t, err)
+	require.NotNil(t, indexer)
+	indexer.Start()
+	defer indexer.Close()
+
+	handler, err := NewSpanHandlerBuilder(cOpts, memory.NewStore())
+	require.NoError(t, err)
+	_, jaeger, _ := handler.BuildHandlers(app.Options.PreSave(indexer.ProcessSpan))
+	assert.NotNil(t, jaeger)
+
+	indexer, err = NewStructureIndexer(cOpts, structureIndexFactory{Factory: storageFactory, err: storage.ErrStructureIndexNotSupported})
+	assert.NoError(t, err)
+	assert.Nil(t, indexer)
+
+	_, err = NewStructureIndexer(cOpts, structureIndexFactory{Factory: storageFactory, err: errors.New("index error")})
+	assert.EqualError(t, err, "index error")
+
+	cOpts.StructureCompletionTimeout = 0
+	indexer, err = NewStructureIndexer(cOpts, storageFactory)
+	assert.NoError(t, err)
+	assert.Nil(t, indexer)
 }
 
 func TestDefaultSpanFilter(t *testing.T) {
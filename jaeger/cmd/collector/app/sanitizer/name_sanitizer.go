@@ -0,0 +1,308 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sanitizer
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+	"github.com/uber/jaeger-lib/metrics"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+const (
+	// ServiceField is the NameRule.Field rewriting service names.
+	ServiceField = "service"
+	// OperationField is the NameRule.Field rewriting operation names.
+	OperationField = "operation"
+
+	nameRulesKey = "rules"
+)
+
+// NameRule rewrites the service or operation names of spans. Exactly one of Match, PathTemplates
+// and Map must be set.
+type NameRule struct {
+	// Name identifies the rule in the metrics.
+	Name string `mapstructure:"name" json:"name"`
+	// Field is the name rewritten, ServiceField or OperationField.
+	Field string `mapstructure:"field" json:"field"`
+	// Service restricts the rule to the spans of a service when not empty.
+	Service string `mapstructure:"service" json:"service"`
+	// Match is a regular expression whose matches are replaced by Replace,
+	// which can refer to capture groups as $1 or ${name}.
+	Match   string `mapstructure:"match" json:"match"`
+	Replace string `mapstructure:"replace" json:"replace"`
+	// PathTemplates are URL path templates such as /users/{id}. The URL paths of the name
+	// matching a template, where a {param} segment matches any segment, are replaced by it.
+	PathTemplates []string `mapstructure:"pathTemplates" json:"pathTemplates"`
+	// Map replaces the names equal to one of its keys by the associated value.
+	Map map[string]string `mapstructure:"map" json:"map"`
+}
+
+type nameRule struct {
+	NameRule
+	match     *regexp.Regexp
+	templates [][]string
+	rewrites  metrics.Counter
+}
+
+// LoadNameRules reads the list of rules under the "rules" key of a YAML or JSON file,
+// the format being inferred from the file extension.
+func LoadNameRules(file string) ([]NameRule, error) {
+	v := viper.New()
+	v.SetConfigFile(file)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, errors.Wrap(err, "failed to read name rules")
+	}
+	var rules []NameRule
+	if err := v.UnmarshalKey(nameRulesKey, &rules); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal name rules")
+	}
+	return rules, nil
+}
+
+func compileNameRules(rules []NameRule, metricsFactory metrics.Factory) ([]*nameRule, error) {
+	compiled := make([]*nameRule, len(rules))
+	for i, rule := range rules {
+		if rule.Name == "" {
+			return nil, fmt.Errorf("name rule #%d has no name", i)
+		}
+		if rule.Field != ServiceField && rule.Field != OperationField {
+			return nil, fmt.Errorf("name rule %s: field must be %s or %s", rule.Name, ServiceField, OperationField)
+		}
+		kinds := 0
+		for _, set := range []bool{rule.Match != "", len(rule.PathTemplates) > 0, len(rule.Map) > 0} {
+			if set {
+				kinds++
+			}
+		}
+		if kinds != 1 {
+			return nil, fmt.Errorf("name rule %s: exactly one of match, pathTemplates and map must be set", rule.Name)
+		}
+		c := &nameRule{
+			NameRule: rule,
+			rewrites: metricsFactory.Counter(metrics.Options{Name: "rewrites", Tags: map[string]string{"rule": rule.Name}}),
+		}
+		if rule.Match != "" {
+			match, err := regexp.Compile(rule.Match)
+			if err != nil {
+				return nil, fmt.Errorf("name rule %s: invalid match: %v", rule.Name, err)
+			}
+			c.match = match
+		}
+		for _, template := range rule.PathTemplates {
+			if !strings.HasPrefix(template, "/") {
+				return nil, fmt.Errorf("name rule %s: path template %s must start with /", rule.Name, template)
+			}
+			c.templates = append(c.templates, strings.Split(template, "/"))
+		}
+		compiled[i] = c
+	}
+	return compiled, nil
+}
+
+func (r *nameRule) rewrite(name string) string {
+	switch {
+	case r.match != nil:
+		return r.match.ReplaceAllString(name, r.Replace)
+	case r.templates != nil:
+		return r.templatePaths(name)
+	}
+	if replacement, ok := r.Map[name]; ok {
+		return replacement
+	}
+	return name
+}
+
+// templatePaths replaces the space separated words of the name which are URL paths
+// matching one of the templates, ignoring their query string.
+func (r *nameRule) templatePaths(name string) string {
+	words := strings.Split(name, " ")
+	for i, word := range words {
+		if !strings.HasPrefix(word, "/") {
+			continue
+		}
+		path := word
+		if q := strings.IndexByte(path, '?'); q >= 0 {
+			path = path[:q]
+		}
+		segments := strings.Split(path, "/")
+		for _, template := range r.templates {
+			if matchesTemplate(segments, template) {
+				words[i] = strings.Join(template, "/")
+				break
+			}
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+func matchesTemplate(segments, template []string) bool {
+	if len(segments) != len(template) {
+		return false
+	}
+	for i, segment := range segments {
+		t := template[i]
+		if strings.HasPrefix(t, "{") && strings.HasSuffix(t, "}") {
+			if segment == "" {
+				return false
+			}
+		} else if segment != t {
+			return false
+		}
+	}
+	return true
+}
+
+// NameSanitizer rewrites the service and operation names of spans with an ordered list of
+// rules, each rule being applied to the names rewritten by the previous ones. The rules are
+// loaded from a file, which can be watched for changes.
+type NameSanitizer struct {
+	file           string
+	metricsFactory metrics.Factory
+	logger         *zap.Logger
+	rules          atomic.Value // []*nameRule
+	watcher        *fsnotify.Watcher
+	// the file with the symlinks resolved, which changes when a symlink to it is swapped
+	realFile string
+}
+
+// NewNameSanitizer creates a NameSanitizer with the rules of the file, see LoadNameRules.
+// The number of spans rewritten by each rule is reported by a rewrites counter tagged with the rule name.
+func NewNameSanitizer(file string, metricsFactory metrics.Factory, logger *zap.Logger) (*NameSanitizer, error) {
+	s := &NameSanitizer{
+		file:           file,
+		metricsFactory: metricsFactory,
+		logger:         logger,
+	}
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Reload loads the rules file again. The current rules are kept when the file is invalid.
+func (s *NameSanitizer) Reload() error {
+	rules, err := LoadNameRules(s.file)
+	if err != nil {
+		return err
+	}
+	compiled, err := compileNameRules(rules, s.metricsFactory)
+	if err != nil {
+		return err
+	}
+	s.rules.Store(compiled)
+	return nil
+}
+
+// Watch reloads the rules whenever the file changes, until Close is called. The directory of
+// the file is watched, so that the file being replaced by a rename is caught, as well as a
+// symlink to it being swapped, e.g. the ..data symlink of an updated Kubernetes ConfigMap volume.
+func (s *NameSanitizer) Watch() error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	if err := watcher.Add(filepath.Dir(s.file)); err != nil {
+		watcher.Close()
+		return err
+	}
+	s.watcher = watcher
+	s.realFile, _ = filepath.EvalSymlinks(s.file)
+	go func() {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if !s.fileChanged(event) {
+					continue
+				}
+				s.logger.Info("Reloading name rules", zap.String("filename", s.file))
+				if err := s.Reload(); err != nil {
+					s.logger.Error("Failed to reload name rules, using the last known version", zap.Error(err))
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				s.logger.Error("Name rules watcher error", zap.Error(err))
+			}
+		}
+	}()
+	return nil
+}
+
+// fileChanged tells whether the event of the watched directory changes the rules file.
+func (s *NameSanitizer) fileChanged(event fsnotify.Event) bool {
+	if event.Op&fsnotify.Remove == fsnotify.Remove {
+		return false
+	}
+	realFile, err := filepath.EvalSymlinks(s.file)
+	if err != nil {
+		return false
+	}
+	if filepath.Clean(event.Name) == filepath.Clean(s.file) {
+		s.realFile = realFile
+		return true
+	}
+	if event.Op&(fsnotify.Create|fsnotify.Rename) == 0 || realFile == s.realFile {
+		return false
+	}
+	s.realFile = realFile
+	return true
+}
+
+// Close stops watching the rules file.
+func (s *NameSanitizer) Close() error {
+	if s.watcher == nil {
+		return nil
+	}
+	return s.watcher.Close()
+}
+
+// Sanitize rewrites the service and operation names of the span.
+func (s *NameSanitizer) Sanitize(span *model.Span) *model.Span {
+	for _, rule := range s.rules.Load().([]*nameRule) {
+		if rule.Service != "" && rule.Service != span.Process.ServiceName {
+			continue
+		}
+		switch rule.Field {
+		case ServiceField:
+			if name := rule.rewrite(span.Process.ServiceName); name != span.Process.ServiceName {
+				// the process may be shared with the other spans of the batch
+				process := *span.Process
+				process.ServiceName = name
+				span.Process = &process
+				rule.rewrites.Inc(1)
+			}
+		case OperationField:
+			if name := rule.rewrite(span.OperationName); name != span.OperationName {
+				span.OperationName = name
+				rule.rewrites.Inc(1)
+			}
+		}
+	}
+	return span
+}
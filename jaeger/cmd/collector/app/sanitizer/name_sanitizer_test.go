@@ -0,0 +1,197 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sanitizer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber/jaeger-lib/metrics"
+	"github.com/uber/jaeger-lib/metrics/metricstest"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+const testNameRules = `
+rules:
+  - name: abbrev
+    field: service
+    map:
+      fe: frontend
+      db: mysql
+  - name: users
+    field: operation
+    service: frontend
+    pathTemplates:
+      - /users/{id}
+      - /users/{id}/orders/{order}
+  - name: ids
+    field: operation
+    match: "/[0-9]+"
+    replace: "/{id}"
+  - name: prefix
+    field: operation
+    service: mysql
+    match: "^(SELECT|INSERT) .*$"
+    replace: "$1"
+`
+
+func writeNameRules(t *testing.T, dir, content string) string {
+	file := filepath.Join(dir, "rules.yaml")
+	require.NoError(t, ioutil.WriteFile(file, []byte(content), 0600))
+	return file
+}
+
+func TestNameSanitizer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "name-rules")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	metricsFactory := metricstest.NewFactory(time.Hour)
+	s, err := NewNameSanitizer(writeNameRules(t, dir, testNameRules), metricsFactory, zap.NewNop())
+	require.NoError(t, err)
+
+	testCases := []struct {
+		service, operation                 string
+		expectedService, expectedOperation string
+	}{
+		{"fe", "GET /users/12345", "frontend", "GET /users/{id}"},
+		{"frontend", "GET /users/12/orders/abc?x=1", "frontend", "GET /users/{id}/orders/{order}"},
+		{"frontend", "GET /users", "frontend", "GET /users"},
+		{"backend", "GET /items/42/parts/7", "backend", "GET /items/{id}/parts/{id}"},
+		{"db", "SELECT * FROM users", "mysql", "SELECT"},
+		{"backend", "SELECT * FROM users", "backend", "SELECT * FROM users"},
+	}
+	for _, testCase := range testCases {
+		process := &model.Process{ServiceName: testCase.service}
+		span := s.Sanitize(&model.Span{OperationName: testCase.operation, Process: process})
+		assert.Equal(t, testCase.expectedService, span.Process.ServiceName)
+		assert.Equal(t, testCase.expectedOperation, span.OperationName)
+		assert.Equal(t, testCase.service, process.ServiceName, "the shared process is left untouched")
+	}
+
+	metricsFactory.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "rewrites", Tags: map[string]string{"rule": "abbrev"}, Value: 2},
+		metricstest.ExpectedMetric{Name: "rewrites", Tags: map[string]string{"rule": "users"}, Value: 2},
+		metricstest.ExpectedMetric{Name: "rewrites", Tags: map[string]string{"rule": "ids"}, Value: 1},
+		metricstest.ExpectedMetric{Name: "rewrites", Tags: map[string]string{"rule": "prefix"}, Value: 1},
+	)
+}
+
+func TestNameSanitizerReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "name-rules")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	file := writeNameRules(t, dir, testNameRules)
+	s, err := NewNameSanitizer(file, metrics.NullFactory, zap.NewNop())
+	require.NoError(t, err)
+	require.NoError(t, s.Watch())
+	defer s.Close()
+
+	sanitize := func() string {
+		return s.Sanitize(&model.Span{OperationName: "op", Process: &model.Process{ServiceName: "fe"}}).Process.ServiceName
+	}
+	assert.Equal(t, "frontend", sanitize())
+
+	writeNameRules(t, dir, "rules: [{name: broken, field: service}]")
+	assert.Error(t, s.Reload())
+	assert.Equal(t, "frontend", sanitize(), "invalid rules are not applied")
+
+	writeNameRules(t, dir, "rules: [{name: abbrev, field: service, map: {fe: web}}]")
+	assert.Eventually(t, func() bool { return sanitize() == "web" }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestNameSanitizerReloadReplaced(t *testing.T) {
+	dir, err := ioutil.TempDir("", "name-rules")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	file := writeNameRules(t, dir, testNameRules)
+	s, err := NewNameSanitizer(file, metrics.NullFactory, zap.NewNop())
+	require.NoError(t, err)
+	require.NoError(t, s.Watch())
+	defer s.Close()
+
+	sanitize := func() string {
+		return s.Sanitize(&model.Span{OperationName: "op", Process: &model.Process{ServiceName: "fe"}}).Process.ServiceName
+	}
+	replaced := filepath.Join(dir, "rules.yaml.tmp")
+	require.NoError(t, ioutil.WriteFile(replaced, []byte("rules: [{name: abbrev, field: service, map: {fe: web}}]"), 0600))
+	require.NoError(t, os.Rename(replaced, file))
+	assert.Eventually(t, func() bool { return sanitize() == "web" }, 5*time.Second, 10*time.Millisecond)
+}
+
+// The files of a Kubernetes ConfigMap volume are symlinks to the ..data symlink, which is
+// swapped with a rename when the ConfigMap is updated.
+func TestNameSanitizerReloadConfigMap(t *testing.T) {
+	dir, err := ioutil.TempDir("", "name-rules")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	writeVersion := func(version, content string) {
+		require.NoError(t, os.Mkdir(filepath.Join(dir, version), 0700))
+		writeNameRules(t, filepath.Join(dir, version), content)
+		require.NoError(t, os.Symlink(version, filepath.Join(dir, "..data_tmp")))
+		require.NoError(t, os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")))
+	}
+	writeVersion("..v1", testNameRules)
+	file := filepath.Join(dir, "rules.yaml")
+	require.NoError(t, os.Symlink(filepath.Join("..data", "rules.yaml"), file))
+
+	s, err := NewNameSanitizer(file, metrics.NullFactory, zap.NewNop())
+	require.NoError(t, err)
+	require.NoError(t, s.Watch())
+	defer s.Close()
+
+	sanitize := func() string {
+		return s.Sanitize(&model.Span{OperationName: "op", Process: &model.Process{ServiceName: "fe"}}).Process.ServiceName
+	}
+	assert.Equal(t, "frontend", sanitize())
+	writeVersion("..v2", "rules: [{name: abbrev, field: service, map: {fe: web}}]")
+	assert.Eventually(t, func() bool { return sanitize() == "web" }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestNameSanitizerErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "name-rules")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	_, err = NewNameSanitizer(filepath.Join(dir, "missing.yaml"), metrics.NullFactory, zap.NewNop())
+	assert.Error(t, err)
+
+	testCases := []struct {
+		rules  string
+		errMsg string
+	}{
+		{`rules: [{field: service, map: {a: b}}]`, "name rule #0 has no name"},
+		{`rules: [{name: r, field: tags, map: {a: b}}]`, "name rule r: field must be service or operation"},
+		{`rules: [{name: r, field: service}]`, "name rule r: exactly one of match, pathTemplates and map must be set"},
+		{`rules: [{name: r, field: service, match: a, map: {a: b}}]`, "name rule r: exactly one of match, pathTemplates and map must be set"},
+		{`rules: [{name: r, field: service, match: "("}]`, "name rule r: invalid match: error parsing regexp: missing closing ): `(`"},
+		{`rules: [{name: r, field: operation, pathTemplates: [users]}]`, "name rule r: path template users must start with /"},
+	}
+	for _, testCase := range testCases {
+		_, err := NewNameSanitizer(writeNameRules(t, dir, testCase.rules), metrics.NullFactory, zap.NewNop())
+		assert.EqualError(t, err, testCase.errMsg)
+	}
+	assert.NoError(t, (&NameSanitizer{}).Close())
+}
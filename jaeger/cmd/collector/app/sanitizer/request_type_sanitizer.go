@@ -0,0 +1,35 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sanitizer
+
+import (
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/model/requesttype"
+)
+
+// NewRequestTypeSanitizer creates a sanitizer stamping the request type assigned by the
+// classifier onto root spans, as a requesttype.Tag tag. Spans already carrying the tag are left as is.
+func NewRequestTypeSanitizer(classifier *requesttype.Classifier) SanitizeSpan {
+	return func(span *model.Span) *model.Span {
+		if len(span.References) > 0 {
+			return span
+		}
+		if _, ok := model.KeyValues(span.Tags).FindByKey(requesttype.Tag); ok {
+			return span
+		}
+		span.Tags = append(span.Tags, model.String(requesttype.Tag, classifier.ClassifySpan(span)))
+		return span
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sanitizer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/model/requesttype"
+)
+
+func TestRequestTypeSanitizer(t *testing.T) {
+	classifier, err := requesttype.NewClassifier([]requesttype.Rule{{Type: "search", Operation: "GET /search"}})
+	require.NoError(t, err)
+	sanitize := NewRequestTypeSanitizer(classifier)
+
+	root := &model.Span{OperationName: "GET /search", Process: &model.Process{ServiceName: "frontend"}}
+	assert.Equal(t, []model.KeyValue{model.String(requesttype.Tag, "search")}, sanitize(root).Tags)
+	assert.Len(t, sanitize(root).Tags, 1, "the tag is stamped once")
+
+	other := &model.Span{OperationName: "GET /", Process: &model.Process{ServiceName: "frontend"}}
+	assert.Equal(t, []model.KeyValue{model.String(requesttype.Tag, "frontend:GET /")}, sanitize(other).Tags)
+
+	child := &model.Span{
+		OperationName: "GET /search",
+		References:    []model.SpanRef{model.NewChildOfRef(model.NewTraceID(0, 1), model.NewSpanID(1))},
+		Process:       &model.Process{ServiceName: "frontend"},
+	}
+	assert.Empty(t, sanitize(child).Tags)
+}
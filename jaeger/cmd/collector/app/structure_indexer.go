@@ -0,0 +1,191 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/uber/jaeger-lib/metrics"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/model/adjuster"
+	"github.com/jaegertracing/jaeger/model/structure"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+const (
+	// DefaultStructureCompletionTimeout is the default StructureIndexerOptions.CompletionTimeout
+	DefaultStructureCompletionTimeout = 30 * time.Second
+	// DefaultMaxPendingStructures is the default StructureIndexerOptions.MaxPendingTraces
+	DefaultMaxPendingStructures = 100000
+)
+
+// StructureIndexerOptions configures a StructureIndexer.
+type StructureIndexerOptions struct {
+	// CompletionTimeout is how long a trace must go without new spans to be deemed complete.
+	CompletionTimeout time.Duration
+	// MaxPendingTraces bounds the number of traces awaiting completion. The traces
+	// received beyond it are not indexed.
+	MaxPendingTraces int
+	// Adjuster is applied to the traces before hashing them, and should be the one of the
+	// query service, so that the hashes match the ones it computes.
+	Adjuster adjuster.Adjuster
+}
+
+type structureIndexerMetrics struct {
+	// Indexed is the number of trace structures written to the index
+	Indexed metrics.Counter `metric:"structures.indexed"`
+	// Failed is the number of complete traces which could not be read or indexed
+	Failed metrics.Counter `metric:"structures.failed"`
+	// Dropped is the number of traces not indexed because too many traces were pending
+	Dropped metrics.Counter `metric:"structures.dropped"`
+	// Pending is the number of traces awaiting completion
+	Pending metrics.Gauge `metric:"structures.pending"`
+}
+
+// StructureIndexer computes the structural hash of the traces once they are deemed complete,
+// i.e. once they have not received any span for the completion timeout, and persists it with
+// a spanstore.StructureWriter. The traces are read back from the span storage to be hashed,
+// see structure.Signature. A trace receiving spans after being indexed is indexed again.
+//
+// ProcessSpan registers the spans of the traces to index, and is meant to be used as the
+// PreSave function of the span processor.
+type StructureIndexer struct {
+	reader  spanstore.Reader
+	writer  spanstore.StructureWriter
+	options StructureIndexerOptions
+	logger  *zap.Logger
+	metrics structureIndexerMetrics
+	timeNow func() time.Time
+
+	sync.Mutex
+	// pending maps the traces awaiting completion to the time of their last span
+	pending map[model.TraceID]time.Time
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewStructureIndexer creates a StructureIndexer reading the traces from reader and writing
+// their hashes to writer. Start must be called for the traces to be indexed.
+func NewStructureIndexer(
+	reader spanstore.Reader,
+	writer spanstore.StructureWriter,
+	options StructureIndexerOptions,
+	metricsFactory metrics.Factory,
+	logger *zap.Logger,
+) *StructureIndexer {
+	if options.CompletionTimeout <= 0 {
+		options.CompletionTimeout = DefaultStructureCompletionTimeout
+	}
+	if options.MaxPendingTraces <= 0 {
+		options.MaxPendingTraces = DefaultMaxPendingStructures
+	}
+	if options.Adjuster == nil {
+		options.Adjuster = adjuster.Sequence()
+	}
+	indexer := &StructureIndexer{
+		reader:  reader,
+		writer:  writer,
+		options: options,
+		logger:  logger,
+		timeNow: time.Now,
+		pending: make(map[model.TraceID]time.Time),
+		done:    make(chan struct{}),
+	}
+	metrics.Init(&indexer.metrics, metricsFactory, nil)
+	return indexer
+}
+
+// ProcessSpan records that the trace of the span is not complete yet.
+func (i *StructureIndexer) ProcessSpan(span *model.Span) {
+	i.Lock()
+	defer i.Unlock()
+	if _, ok := i.pending[span.TraceID]; !ok && len(i.pending) >= i.options.MaxPendingTraces {
+		i.metrics.Dropped.Inc(1)
+		return
+	}
+	i.pending[span.TraceID] = i.timeNow()
+}
+
+// Start periodically indexes the traces deemed complete, until Close is called.
+func (i *StructureIndexer) Start() {
+	i.wg.Add(1)
+	go func() {
+		defer i.wg.Done()
+		ticker := time.NewTicker(i.options.CompletionTimeout / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-i.done:
+				return
+			case <-ticker.C:
+				i.indexCompleteTraces()
+			}
+		}
+	}()
+}
+
+// Close stops indexing the traces. The traces still pending are not indexed.
+func (i *StructureIndexer) Close() error {
+	close(i.done)
+	i.wg.Wait()
+	return nil
+}
+
+func (i *StructureIndexer) indexCompleteTraces() {
+	deadline := i.timeNow().Add(-i.options.CompletionTimeout)
+	var complete []model.TraceID
+	i.Lock()
+	for traceID, lastSeen := range i.pending {
+		if !lastSeen.After(deadline) {
+			complete = append(complete, traceID)
+			delete(i.pending, traceID)
+		}
+	}
+	i.metrics.Pending.Update(int64(len(i.pending)))
+	i.Unlock()
+
+	for _, traceID := range complete {
+		if err := i.indexTrace(traceID); err != nil {
+			i.metrics.Failed.Inc(1)
+			i.logger.Error("Failed to index the trace structure", zap.Stringer("trace-id", traceID), zap.Error(err))
+			continue
+		}
+		i.metrics.Indexed.Inc(1)
+	}
+}
+
+func (i *StructureIndexer) indexTrace(traceID model.TraceID) error {
+	trace, err := i.reader.GetTrace(context.Background(), traceID)
+	if err == nil && (trace == nil || len(trace.Spans) == 0) {
+		err = spanstore.ErrTraceNotFound
+	}
+	if err != nil {
+		return err
+	}
+	// the trace returned by the span reader may be shared with the storage, e.g. by the memory one
+	trace = adjuster.AdjustCopy(i.options.Adjuster, trace)
+	startTime := trace.Spans[0].StartTime
+	for _, span := range trace.Spans[1:] {
+		if span.StartTime.Before(startTime) {
+			startTime = span.StartTime
+		}
+	}
+	return i.writer.WriteStructure(traceID, startTime, structure.Hash(structure.Signature(trace)))
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/uber/jaeger-lib/metrics"
+	"github.com/uber/jaeger-lib/metrics/metricstest"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/model/adjuster"
+	"github.com/jaegertracing/jaeger/model/structure"
+	spanstoremocks "github.com/jaegertracing/jaeger/storage/spanstore/mocks"
+)
+
+func newStructureIndexerTrace(traceID model.TraceID, start time.Time) *model.Trace {
+	return &model.Trace{
+		Spans: []*model.Span{
+			{
+				TraceID:       traceID,
+				SpanID:        model.NewSpanID(2),
+				OperationName: "SELECT",
+				References:    []model.SpanRef{model.NewChildOfRef(traceID, model.NewSpanID(1))},
+				StartTime:     start.Add(time.Millisecond),
+				Duration:      time.Millisecond,
+				Process:       &model.Process{ServiceName: "db"},
+			},
+			{
+				TraceID:       traceID,
+				SpanID:        model.NewSpanID(1),
+				OperationName: "GET",
+				StartTime:     start,
+				Duration:      3 * time.Millisecond,
+				Process:       &model.Process{ServiceName: "frontend"},
+			},
+		},
+	}
+}
+
+func TestStructureIndexer(t *testing.T) {
+	complete, pending, missing, failing := model.NewTraceID(0, 1), model.NewTraceID(0, 2), model.NewTraceID(0, 3), model.NewTraceID(0, 4)
+	start := time.Unix(100, 0)
+	reader := &spanstoremocks.Reader{}
+	reader.On("GetTrace", mock.Anything, complete).Return(newStructureIndexerTrace(complete, start), nil).Once()
+	reader.On("GetTrace", mock.Anything, missing).Return(nil, nil).Once()
+	reader.On("GetTrace", mock.Anything, failing).Return(newStructureIndexerTrace(failing, start), nil).Once()
+	hash := structure.Hash(structure.Signature(newStructureIndexerTrace(complete, start)))
+	writer := &spanstoremocks.StructureWriter{}
+	writer.On("WriteStructure", complete, start, hash).Return(nil).Once()
+	writer.On("WriteStructure", failing, start, hash).Return(errors.New("write error")).Once()
+
+	metricsFactory := metricstest.NewFactory(time.Hour)
+	indexer := NewStructureIndexer(reader, writer, StructureIndexerOptions{
+		CompletionTimeout: time.Minute,
+		MaxPendingTraces:  4,
+	}, metricsFactory, zap.NewNop())
+	now := time.Unix(1000, 0)
+	indexer.timeNow = func() time.Time { return now }
+
+	for _, traceID := range []model.TraceID{complete, missing, failing} {
+		indexer.ProcessSpan(&model.Span{TraceID: traceID})
+	}
+	now = now.Add(30 * time.Second)
+	indexer.ProcessSpan(&model.Span{TraceID: pending})
+	indexer.ProcessSpan(&model.Span{TraceID: model.NewTraceID(0, 5)})
+	indexer.ProcessSpan(&model.Span{TraceID: model.NewTraceID(0, 6)})
+
+	now = now.Add(30 * time.Second)
+	indexer.indexCompleteTraces()
+	reader.AssertExpectations(t)
+	writer.AssertExpectations(t)
+	metricsFactory.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "structures.indexed", Value: 1},
+		metricstest.ExpectedMetric{Name: "structures.failed", Value: 2},
+		metricstest.ExpectedMetric{Name: "structures.dropped", Value: 2},
+	)
+	metricsFactory.AssertGaugeMetrics(t, metricstest.ExpectedMetric{Name: "structures.pending", Value: 1})
+	assert.Contains(t, indexer.pending, pending)
+}
+
+func TestStructureIndexerAdjustsCopy(t *testing.T) {
+	traceID := model.NewTraceID(0, 1)
+	start := time.Unix(100, 0)
+	stored := newStructureIndexerTrace(traceID, start)
+	reader := &spanstoremocks.Reader{}
+	reader.On("GetTrace", mock.Anything, traceID).Return(stored, nil).Once()
+	writer := &spanstoremocks.StructureWriter{}
+	writer.On("WriteStructure", traceID, start.Add(-time.Second), mock.Anything).Return(nil).Once()
+
+	indexer := NewStructureIndexer(reader, writer, StructureIndexerOptions{
+		CompletionTimeout: time.Minute,
+		Adjuster: adjuster.Func(func(trace *model.Trace) (*model.Trace, error) {
+			for _, span := range trace.Spans {
+				span.StartTime = span.StartTime.Add(-time.Second)
+			}
+			return trace, nil
+		}),
+	}, metrics.NullFactory, zap.NewNop())
+	require.NoError(t, indexer.indexTrace(traceID))
+	writer.AssertExpectations(t)
+	// the trace may be shared with the span storage
+	assert.Equal(t, start, stored.Spans[1].StartTime)
+}
+
+func TestStructureIndexerStartAndClose(t *testing.T) {
+	traceID := model.NewTraceID(0, 1)
+	start := time.Unix(100, 0)
+	reader := &spanstoremocks.Reader{}
+	reader.On("GetTrace", mock.Anything, traceID).Return(newStructureIndexerTrace(traceID, start), nil)
+	indexed := make(chan struct{})
+	writer := &spanstoremocks.StructureWriter{}
+	writer.On("WriteStructure", traceID, start, mock.AnythingOfType("string")).Return(nil).Once().Run(func(mock.Arguments) {
+		close(indexed)
+	})
+
+	indexer := NewStructureIndexer(reader, writer, StructureIndexerOptions{CompletionTimeout: time.Millisecond}, metricstest.NewFactory(time.Hour), zap.NewNop())
+	assert.Equal(t, DefaultMaxPendingStructures, indexer.options.MaxPendingTraces)
+	indexer.ProcessSpan(&model.Span{TraceID: traceID})
+	indexer.Start()
+	select {
+	case <-indexed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the trace was not indexed")
+	}
+	assert.NoError(t, indexer.Close())
+}
@@ -47,6 +47,7 @@ import (
 	"github.com/jaegertracing/jaeger/cmd/docs"
 	"github.com/jaegertracing/jaeger/cmd/env"
 	"github.com/jaegertracing/jaeger/cmd/flags"
+	"github.com/jaegertracing/jaeger/model/requesttype"
 	"github.com/jaegertracing/jaeger/pkg/config"
 	"github.com/jaegertracing/jaeger/pkg/healthcheck"
 	"github.com/jaegertracing/jaeger/pkg/recoveryhandler"
@@ -106,7 +107,22 @@ func main() {
 				logger.Fatal("Unable to set up builder", zap.Error(err))
 			}
 
-			zipkinSpansHandler, jaegerBatchesHandler, grpcHandler := handlerBuilder.BuildHandlers()
+			structureIndexer, err := builder.NewStructureIndexer(
+				builderOpts,
+				storageFactory,
+				basicB.Options.LoggerOption(logger),
+				basicB.Options.MetricsFactoryOption(metricsFactory),
+			)
+			if err != nil {
+				logger.Fatal("Unable to set up structure indexer", zap.Error(err))
+			}
+			var processorOpts []app.Option
+			if structureIndexer != nil {
+				structureIndexer.Start()
+				processorOpts = append(processorOpts, app.Options.PreSave(structureIndexer.ProcessSpan))
+			}
+
+			zipkinSpansHandler, jaegerBatchesHandler, grpcHandler := handlerBuilder.BuildHandlers(processorOpts...)
 			strategyStoreFactory.InitFromViper(v)
 			strategyStore := initSamplingStrategyStore(strategyStoreFactory, metricsFactory, logger)
 
@@ -154,6 +170,12 @@ func main() {
 			}
 
 			svc.RunAndThen(func() {
+				if structureIndexer != nil {
+					structureIndexer.Close()
+				}
+				if err := handlerBuilder.Close(); err != nil {
+					logger.Error("Failed to close span handler builder", zap.Error(err))
+				}
 				if closer, ok := spanWriter.(io.Closer); ok {
 					server.GracefulStop()
 					err := closer.Close()
@@ -161,6 +183,9 @@ func main() {
 						logger.Error("Failed to close span writer", zap.Error(err))
 					}
 				}
+				if err := storageFactory.Close(); err != nil {
+					logger.Error("Failed to close storage factory", zap.Error(err))
+				}
 			})
 			return nil
 		},
@@ -175,6 +200,7 @@ func main() {
 		command,
 		svc.AddFlags,
 		builder.AddFlags,
+		requesttype.AddFlags,
 		storageFactory.AddFlags,
 		strategyStoreFactory.AddFlags,
 	)
@@ -0,0 +1,82 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"context"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/plugin/storage/file"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+// ErrTruncated is returned by Exporter.Export, once all the traces are written, when the
+// traces of some services reached the limit, since some of their traces were left out.
+var ErrTruncated = errors.New("the traces of some services reached the limit")
+
+// Exporter copies the traces of a span reader to a trace file, which can be loaded by the
+// file storage for offline analysis.
+type Exporter struct {
+	reader  spanstore.Reader
+	logger  *zap.Logger
+	options Options
+	now     func() time.Time
+}
+
+// NewExporter creates a new Exporter.
+func NewExporter(reader spanstore.Reader, options Options, logger *zap.Logger) *Exporter {
+	return &Exporter{
+		reader:  reader,
+		logger:  logger,
+		options: options,
+		now:     time.Now,
+	}
+}
+
+// Export fetches the traces of the configured service, or of every service known to the
+// reader when none is configured, and writes them to w as they are stored, without adjusting
+// them. It returns the number of exported traces, and ErrTruncated, see errors.Cause, when
+// the traces of some services may be incomplete.
+func (e *Exporter) Export(ctx context.Context, w io.Writer) (int, error) {
+	encoder, err := file.NewEncoder(w, e.options.Format)
+	if err != nil {
+		return 0, err
+	}
+	end := e.now()
+	exported := 0
+	truncated, err := e.options.FindTraces(ctx, e.reader, end.Add(-e.options.Lookback), end, func(trace *model.Trace) error {
+		if err := encoder.Encode(trace); err != nil {
+			return errors.Wrapf(err, "cannot write trace %s", trace.Spans[0].TraceID)
+		}
+		exported++
+		return nil
+	})
+	if err != nil {
+		return exported, err
+	}
+	if len(truncated) > 0 {
+		for _, service := range truncated {
+			e.logger.Warn("The traces of the service reached the limit, some were not exported", zap.String("service", service), zap.Int("limit", e.options.Limit))
+		}
+		return exported, errors.Wrapf(ErrTruncated, "services %s", strings.Join(truncated, ", "))
+	}
+	return exported, nil
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/cmd/offline"
+	"github.com/jaegertracing/jaeger/cmd/offline/offlinetest"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/plugin/storage/file"
+	"github.com/jaegertracing/jaeger/plugin/storage/memory"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+var exportEnd = time.Date(2020, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestExporter(reader spanstore.Reader, options Options) *Exporter {
+	e := NewExporter(reader, options, zap.NewNop())
+	e.now = func() time.Time { return exportEnd }
+	return e
+}
+
+func TestExport(t *testing.T) {
+	store := memory.NewStore()
+	offlinetest.WriteTrace(t, store, 1, exportEnd.Add(-time.Minute), time.Second, "GET", "query")
+	offlinetest.WriteTrace(t, store, 2, exportEnd.Add(-time.Minute), time.Second, "POST", "query")
+	offlinetest.WriteTrace(t, store, 3, exportEnd.Add(-2*time.Hour), time.Second, "GET", "query")
+
+	testCases := []struct {
+		name     string
+		options  Options
+		output   string
+		expected []model.TraceID
+	}{
+		{
+			name:     "all services",
+			options:  Options{QueryOptions: offline.QueryOptions{Lookback: time.Hour, Limit: 10}, Format: file.FormatJSON},
+			output:   "traces.json",
+			expected: []model.TraceID{model.NewTraceID(0, 1), model.NewTraceID(0, 2)},
+		},
+		{
+			name:     "operation",
+			options:  Options{QueryOptions: offline.QueryOptions{Service: "frontend", Operation: "GET", Lookback: 24 * time.Hour, Limit: 10}, Format: file.FormatProtobuf},
+			output:   "traces.pb",
+			expected: []model.TraceID{model.NewTraceID(0, 3), model.NewTraceID(0, 1)},
+		},
+	}
+	for _, testCase := range testCases {
+		testCase := testCase // capture loop var
+		t.Run(testCase.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "export")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+			out, err := os.Create(filepath.Join(dir, testCase.output))
+			require.NoError(t, err)
+
+			count, err := newTestExporter(store, testCase.options).Export(context.Background(), out)
+			require.NoError(t, err)
+			require.NoError(t, out.Close())
+			assert.Equal(t, len(testCase.expected), count)
+
+			// the exported traces can be loaded by the file storage
+			loaded, err := file.NewStore(dir, file.FormatJSON)
+			require.NoError(t, err)
+			defer loaded.Close()
+			ids, err := loaded.FindTraceIDs(context.Background(), &spanstore.TraceQueryParameters{})
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expected, ids)
+			trace, err := loaded.GetTrace(context.Background(), testCase.expected[0])
+			require.NoError(t, err)
+			assert.Len(t, trace.Spans, 2)
+		})
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestExportErrors(t *testing.T) {
+	testCases := []struct {
+		name    string
+		options Options
+		output  io.Writer
+		err     string
+	}{
+		{
+			name:    "unknown format",
+			options: Options{Format: "csv"},
+			output:  ioutil.Discard,
+			err:     `unknown format "csv", expected json or protobuf`,
+		},
+		{
+			name:    "write failure",
+			options: Options{QueryOptions: offline.QueryOptions{Service: "frontend", Lookback: time.Hour}, Format: file.FormatJSON},
+			output:  failingWriter{},
+			err:     "cannot write trace 1: disk full",
+		},
+	}
+	store := memory.NewStore()
+	offlinetest.WriteTrace(t, store, 1, exportEnd.Add(-time.Minute), time.Second, "GET", "query")
+	for _, testCase := range testCases {
+		testCase := testCase // capture loop var
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := newTestExporter(store, testCase.options).Export(context.Background(), testCase.output)
+			assert.EqualError(t, err, testCase.err)
+		})
+	}
+}
+
+func TestExportTruncated(t *testing.T) {
+	store := memory.NewStore()
+	offlinetest.WriteTrace(t, store, 1, exportEnd.Add(-time.Minute), time.Second, "GET", "query")
+	offlinetest.WriteTrace(t, store, 2, exportEnd.Add(-2*time.Minute), time.Second, "GET", "query")
+
+	options := Options{QueryOptions: offline.QueryOptions{Service: "frontend", Lookback: time.Hour, Limit: 2}, Format: file.FormatJSON}
+	count, err := newTestExporter(store, options).Export(context.Background(), ioutil.Discard)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, ErrTruncated, errors.Cause(err))
+	assert.EqualError(t, err, "services frontend: the traces of some services reached the limit")
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"flag"
+
+	"github.com/spf13/viper"
+
+	"github.com/jaegertracing/jaeger/cmd/offline"
+	"github.com/jaegertracing/jaeger/plugin/storage/file"
+)
+
+const (
+	exportPrefix = "export"
+	exportFormat = exportPrefix + ".format"
+)
+
+// Options holds configuration for the export
+type Options struct {
+	// QueryOptions selects the exported traces, and the path of the trace file
+	offline.QueryOptions
+	// Format is the format of the trace file, file.FormatJSON or file.FormatProtobuf
+	Format string
+}
+
+// AddFlags adds flags for Options
+func AddFlags(flagSet *flag.FlagSet) {
+	offline.AddFlags(flagSet, exportPrefix)
+	flagSet.String(exportFormat, file.FormatJSON, "The format of the trace file, to be named with a .json or .pb extension to be loaded by the file storage: "+file.FormatJSON+" for newline-delimited traces of the /api/traces endpoint, or "+file.FormatProtobuf+" for length-prefixed spans")
+}
+
+// InitFromViper initializes Options with properties from viper
+func (opts *Options) InitFromViper(v *viper.Viper) *Options {
+	opts.QueryOptions.InitFromViper(v, exportPrefix)
+	opts.Format = v.GetString(exportFormat)
+	return opts
+}
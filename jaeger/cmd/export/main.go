@@ -0,0 +1,95 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/cmd/docs"
+	"github.com/jaegertracing/jaeger/cmd/env"
+	"github.com/jaegertracing/jaeger/cmd/export/app"
+	"github.com/jaegertracing/jaeger/cmd/flags"
+	"github.com/jaegertracing/jaeger/cmd/offline"
+	"github.com/jaegertracing/jaeger/pkg/config"
+	"github.com/jaegertracing/jaeger/pkg/version"
+	"github.com/jaegertracing/jaeger/plugin/storage"
+)
+
+func main() {
+	storageFactory, err := storage.NewFactory(storage.FactoryConfigFromEnvAndCLI(os.Args, os.Stderr))
+	if err != nil {
+		log.Fatalf("Cannot initialize storage factory: %v", err)
+	}
+
+	v := viper.New()
+	var command = &cobra.Command{
+		Use:   "jaeger-export",
+		Short: "Jaeger export dumps the traces of a storage backend to a file.",
+		Long: `Jaeger export reads the traces from the configured storage backend and writes them to a trace file,
+either newline-delimited JSON in the format of the /api/traces endpoint or length-prefixed protobuf spans.
+The file can be loaded by the file storage, e.g. SPAN_STORAGE_TYPE=file, to analyze the traces offline.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			spanReader, logger, err := offline.NewSpanReader(v, storageFactory)
+			if err != nil {
+				return err
+			}
+
+			opts := new(app.Options).InitFromViper(v)
+			exporter := app.NewExporter(spanReader, *opts, logger)
+			var count int
+			var exportErr error
+			err = offline.WriteOutput(opts.Output, func(w io.Writer) error {
+				count, exportErr = exporter.Export(context.Background(), w)
+				if errors.Cause(exportErr) == app.ErrTruncated {
+					// the trace file is kept, the exit status reports the traces left out
+					return nil
+				}
+				return exportErr
+			})
+			if err != nil {
+				return err
+			}
+			logger.Info("Exported traces", zap.Int("count", count))
+			return exportErr
+		},
+	}
+
+	command.AddCommand(version.Command())
+	command.AddCommand(env.Command())
+	command.AddCommand(docs.Command(v))
+
+	config.AddFlags(
+		v,
+		command,
+		flags.AddConfigFileFlag,
+		flags.AddLoggingFlag,
+		storageFactory.AddFlags,
+		app.AddFlags,
+	)
+
+	if err := command.Execute(); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+}
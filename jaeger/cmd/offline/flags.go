@@ -0,0 +1,66 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package offline
+
+import (
+	"flag"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	suffixService   = ".service"
+	suffixOperation = ".operation"
+	suffixLookback  = ".lookback"
+	suffixLimit     = ".limit"
+	suffixOutput    = ".output"
+
+	defaultLookback = time.Hour
+	defaultLimit    = 1000
+)
+
+// QueryOptions selects the traces read by a tool, and where its output is written.
+type QueryOptions struct {
+	// Service restricts the query to the traces of a service; all services are queried when empty
+	Service string
+	// Operation restricts the query to the traces of an operation of Service
+	Operation string
+	// Lookback is how far back in time the traces are searched
+	Lookback time.Duration
+	// Limit is the maximum number of traces fetched for each service
+	Limit int
+	// Output is the path of the output file; the output is written to stdout when empty
+	Output string
+}
+
+// AddFlags adds flags for QueryOptions, named after the tool, e.g. tprof.service.
+func AddFlags(flagSet *flag.FlagSet, tool string) {
+	flagSet.String(tool+suffixService, "", "The service whose traces are read; all services are read when empty")
+	flagSet.String(tool+suffixOperation, "", "The operation whose traces are read; requires --"+tool+suffixService)
+	flagSet.Duration(tool+suffixLookback, defaultLookback, "How far back in time the traces are searched")
+	flagSet.Int(tool+suffixLimit, defaultLimit, "The maximum number of traces fetched for each service")
+	flagSet.String(tool+suffixOutput, "", "The path of the output file; the output is written to stdout when empty")
+}
+
+// InitFromViper initializes QueryOptions with the properties of the tool from viper
+func (opts *QueryOptions) InitFromViper(v *viper.Viper, tool string) *QueryOptions {
+	opts.Service = v.GetString(tool + suffixService)
+	opts.Operation = v.GetString(tool + suffixOperation)
+	opts.Lookback = v.GetDuration(tool + suffixLookback)
+	opts.Limit = v.GetInt(tool + suffixLimit)
+	opts.Output = v.GetString(tool + suffixOutput)
+	return opts
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package offline
+
+import (
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jaegertracing/jaeger/pkg/config"
+)
+
+func addTestFlags(flagSet *flag.FlagSet) {
+	AddFlags(flagSet, "tool")
+}
+
+func TestQueryFlags(t *testing.T) {
+	v, command := config.Viperize(addTestFlags)
+	command.ParseFlags([]string{
+		"--tool.service=frontend",
+		"--tool.operation=GET",
+		"--tool.lookback=10m",
+		"--tool.limit=50",
+		"--tool.output=out.json",
+	})
+	opts := new(QueryOptions).InitFromViper(v, "tool")
+	assert.Equal(t, QueryOptions{
+		Service:   "frontend",
+		Operation: "GET",
+		Lookback:  10 * time.Minute,
+		Limit:     50,
+		Output:    "out.json",
+	}, *opts)
+}
+
+func TestQueryFlagsDefaults(t *testing.T) {
+	v, _ := config.Viperize(addTestFlags)
+	opts := new(QueryOptions).InitFromViper(v, "tool")
+	assert.Equal(t, QueryOptions{
+		Lookback: time.Hour,
+		Limit:    1000,
+	}, *opts)
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package offlinetest provides the test fixtures of the tools reading the stored traces offline.
+package offlinetest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+var (
+	frontend = &model.Process{ServiceName: "frontend"}
+	backend  = &model.Process{ServiceName: "backend"}
+)
+
+// WriteTrace writes a trace whose frontend root span calls the backend once per child
+// operation, each call lasting a millisecond and starting a millisecond after the previous one.
+func WriteTrace(t *testing.T, writer spanstore.Writer, traceID uint64, startTime time.Time, duration time.Duration, rootOperation string, children ...string) {
+	id := model.NewTraceID(0, traceID)
+	root := &model.Span{
+		TraceID:       id,
+		SpanID:        model.NewSpanID(1),
+		OperationName: rootOperation,
+		StartTime:     startTime,
+		Duration:      duration,
+		Process:       frontend,
+	}
+	require.NoError(t, writer.WriteSpan(root))
+	for i, operation := range children {
+		require.NoError(t, writer.WriteSpan(&model.Span{
+			TraceID:       id,
+			SpanID:        model.NewSpanID(uint64(i + 2)),
+			OperationName: operation,
+			References:    []model.SpanRef{model.NewChildOfRef(id, root.SpanID)},
+			StartTime:     startTime.Add(time.Duration(i) * time.Millisecond),
+			Duration:      time.Millisecond,
+			Process:       backend,
+		}))
+	}
+}
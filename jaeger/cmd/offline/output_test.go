@@ -0,0 +1,70 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package offline
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteOutput(t *testing.T) {
+	dir, err := ioutil.TempDir("", "offline")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "out.txt")
+
+	err = WriteOutput(path, func(w io.Writer) error {
+		_, err := io.WriteString(w, "output")
+		return err
+	})
+	require.NoError(t, err)
+	content, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "output", string(content))
+}
+
+func TestWriteOutputStdout(t *testing.T) {
+	var out io.Writer
+	err := WriteOutput("", func(w io.Writer) error {
+		out = w
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, os.Stdout, out)
+}
+
+func TestWriteOutputErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "offline")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	err = WriteOutput(filepath.Join(dir, "out.txt"), func(w io.Writer) error {
+		return errors.New("write error")
+	})
+	assert.EqualError(t, err, "write error")
+
+	err = WriteOutput(filepath.Join(dir, "missing", "out.txt"), func(w io.Writer) error {
+		t.Fatal("write must not be called")
+		return nil
+	})
+	assert.Error(t, err)
+}
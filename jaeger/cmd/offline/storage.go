@@ -0,0 +1,48 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package offline
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+	"github.com/uber/jaeger-lib/metrics"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/cmd/flags"
+	"github.com/jaegertracing/jaeger/plugin/storage"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+// NewSpanReader loads the configuration file, if any, then creates the logger and the span
+// reader of the storage backend configured in v. It expects the flags of
+// flags.AddConfigFileFlag, flags.AddLoggingFlag and storageFactory.AddFlags.
+func NewSpanReader(v *viper.Viper, storageFactory *storage.Factory) (spanstore.Reader, *zap.Logger, error) {
+	if err := flags.TryLoadConfigFile(v); err != nil {
+		return nil, nil, err
+	}
+	logger, err := new(flags.SharedFlags).InitFromViper(v).NewLogger(zap.NewProductionConfig())
+	if err != nil {
+		return nil, nil, err
+	}
+	storageFactory.InitFromViper(v)
+	if err := storageFactory.Initialize(metrics.NullFactory, logger); err != nil {
+		return nil, nil, errors.Wrap(err, "cannot init storage factory")
+	}
+	spanReader, err := storageFactory.CreateSpanReader()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "cannot create span reader")
+	}
+	return spanReader, logger, nil
+}
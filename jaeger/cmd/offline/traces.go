@@ -0,0 +1,79 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package offline
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+// FindTraces fetches the traces of the configured service, or of every service known to the
+// reader when none is configured, which started between start and end, and passes each trace
+// to fn once, even when it crosses several services. It returns the services whose traces
+// reached the limit, since some of their traces may have been left out.
+func (opts QueryOptions) FindTraces(
+	ctx context.Context,
+	reader spanstore.Reader,
+	start, end time.Time,
+	fn func(trace *model.Trace) error,
+) (truncated []string, err error) {
+	services := []string{opts.Service}
+	if opts.Service == "" {
+		if opts.Operation != "" {
+			return nil, errors.New("an operation requires a service")
+		}
+		if services, err = reader.GetServices(ctx); err != nil {
+			return nil, errors.Wrap(err, "cannot get services")
+		}
+		sort.Strings(services)
+	}
+	found := make(map[model.TraceID]struct{})
+	for _, service := range services {
+		traces, err := reader.FindTraces(ctx, &spanstore.TraceQueryParameters{
+			ServiceName:   service,
+			OperationName: opts.Operation,
+			StartTimeMin:  start,
+			StartTimeMax:  end,
+			NumTraces:     opts.Limit,
+		})
+		if err != nil {
+			return truncated, errors.Wrapf(err, "cannot find traces of service %s", service)
+		}
+		if opts.Limit > 0 && len(traces) >= opts.Limit {
+			truncated = append(truncated, service)
+		}
+		for _, trace := range traces {
+			if len(trace.Spans) == 0 {
+				continue
+			}
+			// a trace crossing several services is returned once per service
+			traceID := trace.Spans[0].TraceID
+			if _, ok := found[traceID]; ok {
+				continue
+			}
+			found[traceID] = struct{}{}
+			if err := fn(trace); err != nil {
+				return truncated, err
+			}
+		}
+	}
+	return truncated, nil
+}
@@ -0,0 +1,148 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package offline
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/cmd/offline/offlinetest"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/plugin/storage/memory"
+	spanstoremocks "github.com/jaegertracing/jaeger/storage/spanstore/mocks"
+)
+
+var queryEnd = time.Date(2020, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+// findTraceIDs returns the IDs of the traces found with opts, in the order they are found.
+func findTraceIDs(opts QueryOptions, store *memory.Store) ([]model.TraceID, []string, error) {
+	var ids []model.TraceID
+	truncated, err := opts.FindTraces(context.Background(), store, queryEnd.Add(-time.Hour), queryEnd, func(trace *model.Trace) error {
+		ids = append(ids, trace.Spans[0].TraceID)
+		return nil
+	})
+	return ids, truncated, err
+}
+
+func TestFindTraces(t *testing.T) {
+	store := memory.NewStore()
+	offlinetest.WriteTrace(t, store, 1, queryEnd.Add(-time.Minute), time.Second, "GET", "query")
+	offlinetest.WriteTrace(t, store, 2, queryEnd.Add(-2*time.Minute), time.Second, "POST", "query")
+	offlinetest.WriteTrace(t, store, 3, queryEnd.Add(-2*time.Hour), time.Second, "GET", "query")
+
+	testCases := []struct {
+		name      string
+		options   QueryOptions
+		expected  []model.TraceID
+		truncated []string
+	}{
+		{
+			name:     "all services",
+			options:  QueryOptions{Limit: 10},
+			expected: []model.TraceID{model.NewTraceID(0, 1), model.NewTraceID(0, 2)},
+		},
+		{
+			name:     "operation",
+			options:  QueryOptions{Service: "frontend", Operation: "POST", Limit: 10},
+			expected: []model.TraceID{model.NewTraceID(0, 2)},
+		},
+		{
+			name:      "limit",
+			options:   QueryOptions{Limit: 2},
+			expected:  []model.TraceID{model.NewTraceID(0, 1), model.NewTraceID(0, 2)},
+			truncated: []string{"backend", "frontend"},
+		},
+	}
+	for _, testCase := range testCases {
+		testCase := testCase // capture loop var
+		t.Run(testCase.name, func(t *testing.T) {
+			ids, truncated, err := findTraceIDs(testCase.options, store)
+			require.NoError(t, err)
+			assert.ElementsMatch(t, testCase.expected, ids)
+			assert.Equal(t, testCase.truncated, truncated)
+		})
+	}
+}
+
+func TestFindTracesSkipsEmptyTraces(t *testing.T) {
+	reader := &spanstoremocks.Reader{}
+	reader.On("FindTraces", mock.Anything, mock.Anything).Return([]*model.Trace{{}}, nil)
+	truncated, err := QueryOptions{Service: "frontend"}.FindTraces(context.Background(), reader, queryEnd.Add(-time.Hour), queryEnd, func(trace *model.Trace) error {
+		t.Fatal("empty traces must be skipped")
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Empty(t, truncated)
+}
+
+func TestFindTracesErrors(t *testing.T) {
+	store := memory.NewStore()
+	offlinetest.WriteTrace(t, store, 1, queryEnd.Add(-time.Minute), time.Second, "GET", "query")
+
+	testCases := []struct {
+		name    string
+		options QueryOptions
+		reader  func() *spanstoremocks.Reader
+		err     string
+	}{
+		{
+			name:    "operation without service",
+			options: QueryOptions{Operation: "GET"},
+			reader:  func() *spanstoremocks.Reader { return &spanstoremocks.Reader{} },
+			err:     "an operation requires a service",
+		},
+		{
+			name: "services failure",
+			reader: func() *spanstoremocks.Reader {
+				r := &spanstoremocks.Reader{}
+				r.On("GetServices", mock.Anything).Return(nil, errors.New("storage error"))
+				return r
+			},
+			err: "cannot get services: storage error",
+		},
+		{
+			name:    "traces failure",
+			options: QueryOptions{Service: "frontend"},
+			reader: func() *spanstoremocks.Reader {
+				r := &spanstoremocks.Reader{}
+				r.On("FindTraces", mock.Anything, mock.Anything).Return(nil, errors.New("storage error"))
+				return r
+			},
+			err: "cannot find traces of service frontend: storage error",
+		},
+	}
+	for _, testCase := range testCases {
+		testCase := testCase // capture loop var
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := testCase.options.FindTraces(context.Background(), testCase.reader(), queryEnd.Add(-time.Hour), queryEnd, func(trace *model.Trace) error {
+				return nil
+			})
+			assert.EqualError(t, err, testCase.err)
+		})
+	}
+
+	t.Run("callback failure", func(t *testing.T) {
+		_, err := QueryOptions{}.FindTraces(context.Background(), store, queryEnd.Add(-time.Hour), queryEnd, func(trace *model.Trace) error {
+			return errors.New("callback error")
+		})
+		assert.EqualError(t, err, "callback error")
+	})
+}
@@ -20,6 +20,7 @@ import (
 
 	"github.com/spf13/viper"
 
+	"github.com/jaegertracing/jaeger/model/requesttype"
 	"github.com/jaegertracing/jaeger/ports"
 )
 
@@ -29,6 +30,10 @@ const (
 	queryStaticFiles      = "query.static-files"
 	queryUIConfig         = "query.ui-config"
 	queryTokenPropagation = "query.bearer-token-propagation"
+	queryMaxTraceIDs      = "query.max-trace-ids-per-request"
+	queryStructureIndex   = "query.structure-index"
+
+	defaultMaxTraceIDs = 1000
 )
 
 // QueryOptions holds configuration for query service
@@ -43,6 +48,12 @@ type QueryOptions struct {
 	UIConfig string
 	// BearerTokenPropagation activate/deactivate bearer token propagation to storage
 	BearerTokenPropagation bool
+	// MaxTraceIDsPerRequest bounds the number of traces requested by ID at once
+	MaxTraceIDsPerRequest int
+	// StructureIndex tells whether the collectors index the structural hashes of the traces
+	StructureIndex bool
+	// RequestTypes configures the classification of traces into request types, see requesttype.AddFlags
+	RequestTypes requesttype.Options
 }
 
 // AddFlags adds flags for QueryOptions
@@ -52,6 +63,8 @@ func AddFlags(flagSet *flag.FlagSet) {
 	flagSet.String(queryStaticFiles, "", "The directory path override for the static assets for the UI")
 	flagSet.String(queryUIConfig, "", "The path to the UI configuration file in JSON format")
 	flagSet.Bool(queryTokenPropagation, false, "Allow propagation of bearer token to be used by storage plugins")
+	flagSet.Int(queryMaxTraceIDs, defaultMaxTraceIDs, "The maximum number of traces requested by ID at once, e.g. by the GetTraces gRPC endpoint")
+	flagSet.Bool(queryStructureIndex, false, "Whether the collectors index the structural hashes of the traces (see collector.structure-index.completion-timeout), so that they are read from the span storage rather than computed by the query service. Ignored by all-in-one")
 
 }
 
@@ -62,5 +75,8 @@ func (qOpts *QueryOptions) InitFromViper(v *viper.Viper) *QueryOptions {
 	qOpts.StaticAssets = v.GetString(queryStaticFiles)
 	qOpts.UIConfig = v.GetString(queryUIConfig)
 	qOpts.BearerTokenPropagation = v.GetBool(queryTokenPropagation)
+	qOpts.MaxTraceIDsPerRequest = v.GetInt(queryMaxTraceIDs)
+	qOpts.StructureIndex = v.GetBool(queryStructureIndex)
+	qOpts.RequestTypes.InitFromViper(v)
 	return qOpts
 }
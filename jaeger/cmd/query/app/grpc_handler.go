@@ -19,14 +19,22 @@ import (
 
 	"github.com/opentracing/opentracing-go"
 	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
 	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/model/structure/pattern"
+	"github.com/jaegertracing/jaeger/pkg/stats"
 	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
 	"github.com/jaegertracing/jaeger/storage/spanstore"
 )
 
-const maxSpanCountInChunk = 10
+const (
+	defaultSpanCountInChunk = 10
+	maxSpanCountInChunk     = 1000
+	maxTraceIDCountInChunk  = 100
+)
 
 // GRPCHandler implements the GRPC endpoint of the query service.
 type GRPCHandler struct {
@@ -46,7 +54,8 @@ func NewGRPCHandler(queryService *querysvc.QueryService, logger *zap.Logger, tra
 	return gH
 }
 
-// GetTrace is the GRPC handler to fetch traces based on trace-id.
+// GetTrace is the GRPC handler to fetch traces based on trace-id. The adjusters
+// are applied to the trace if r.Adjust is set.
 func (g *GRPCHandler) GetTrace(r *api_v2.GetTraceRequest, stream api_v2.QueryService_GetTraceServer) error {
 	trace, err := g.queryService.GetTrace(stream.Context(), r.TraceID)
 	if err == spanstore.ErrTraceNotFound {
@@ -57,7 +66,45 @@ func (g *GRPCHandler) GetTrace(r *api_v2.GetTraceRequest, stream api_v2.QuerySer
 		g.logger.Error("Could not fetch spans from backend", zap.Error(err))
 		return err
 	}
-	return g.sendSpanChunks(trace.Spans, stream.Send)
+	if r.Adjust {
+		trace = g.adjust(trace)
+	}
+	return g.sendSpanChunks(r.TraceID, trace, defaultSpanCountInChunk, r.Adjust, stream.Send)
+}
+
+// GetTraces is the GRPC handler to fetch several traces based on their trace-ids.
+// Each trace is sent as soon as it is fetched, in the order of r.TraceIDs.
+func (g *GRPCHandler) GetTraces(r *api_v2.GetTracesRequest, stream api_v2.QueryService_GetTracesServer) error {
+	chunkSize := spanCountInChunk(r.ChunkSize)
+	i := 0
+	var sendErr error
+	err := g.queryService.StreamTraces(stream.Context(), r.TraceIDs, func(trace *model.Trace) error {
+		traceID := r.TraceIDs[i]
+		i++
+		if trace == nil {
+			notFound := &api_v2.SpansResponseChunk{
+				TraceID:  traceID,
+				TraceEnd: true,
+				Error:    spanstore.ErrTraceNotFound.Error(),
+			}
+			if sendErr = stream.Send(notFound); sendErr != nil {
+				g.logger.Error("failed to send response to client", zap.Error(sendErr))
+			}
+			return sendErr
+		}
+		if r.Adjust {
+			trace = g.adjust(trace)
+		}
+		sendErr = g.sendSpanChunks(traceID, trace, chunkSize, true, stream.Send)
+		return sendErr
+	})
+	if err == querysvc.ErrTooManyTraceIDs {
+		return status.Errorf(codes.InvalidArgument, "%v: %d requested", err, len(r.TraceIDs))
+	}
+	if err != nil && err != sendErr {
+		g.logger.Error("Could not fetch spans from backend", zap.Error(err))
+	}
+	return err
 }
 
 // ArchiveTrace is the GRPC handler to archive traces.
@@ -76,9 +123,108 @@ func (g *GRPCHandler) ArchiveTrace(ctx context.Context, r *api_v2.ArchiveTraceRe
 }
 
 // FindTraces is the GRPC handler to fetch traces based on TraceQueryParameters.
+// The spans of each trace are sent in chunks of at most r.ChunkSize spans, after
+// applying the adjusters if r.Adjust is set.
 func (g *GRPCHandler) FindTraces(r *api_v2.FindTracesRequest, stream api_v2.QueryService_FindTracesServer) error {
-	query := r.GetQuery()
-	queryParams := spanstore.TraceQueryParameters{
+	queryParams, err := toSpanstoreQuery(r.GetQuery())
+	if err != nil {
+		return err
+	}
+	traces, err := g.queryService.FindTraces(stream.Context(), queryParams)
+	if err != nil {
+		g.logger.Error("Error fetching traces", zap.Error(err))
+		return err
+	}
+	chunkSize := spanCountInChunk(r.ChunkSize)
+	for _, trace := range traces {
+		if r.Adjust {
+			trace = g.adjust(trace)
+		}
+		if err := g.sendSpanChunks(model.TraceID{}, trace, chunkSize, r.TraceDelimited || r.Adjust, stream.Send); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FindTraceIDs is the GRPC handler to fetch trace IDs based on TraceQueryParameters,
+// without loading the spans of the matching traces.
+func (g *GRPCHandler) FindTraceIDs(r *api_v2.FindTraceIDsRequest, stream api_v2.QueryService_FindTraceIDsServer) error {
+	queryParams, err := toSpanstoreQuery(r.GetQuery())
+	if err != nil {
+		return err
+	}
+	traceIDs, err := g.queryService.FindTraceIDs(stream.Context(), queryParams)
+	if err != nil {
+		g.logger.Error("Error fetching trace IDs", zap.Error(err))
+		return err
+	}
+	for i := 0; i < len(traceIDs); i += maxTraceIDCountInChunk {
+		end := i + maxTraceIDCountInChunk
+		if end > len(traceIDs) {
+			end = len(traceIDs)
+		}
+		if err := stream.Send(&api_v2.TraceIDsResponseChunk{TraceIDs: traceIDs[i:end]}); err != nil {
+			g.logger.Error("failed to send response to client", zap.Error(err))
+			return err
+		}
+	}
+	return nil
+}
+
+// GetStructureGroups is the GRPC handler to group the traces matching TraceQueryParameters by call structure.
+func (g *GRPCHandler) GetStructureGroups(ctx context.Context, r *api_v2.GetStructureGroupsRequest) (*api_v2.GetStructureGroupsResponse, error) {
+	queryParams, err := toSpanstoreQuery(r.GetQuery())
+	if err != nil {
+		return nil, err
+	}
+	groups, err := g.queryService.GetStructureGroups(ctx, queryParams)
+	if err != nil {
+		g.logger.Error("Error grouping traces by structure", zap.Error(err))
+		return nil, err
+	}
+	response := &api_v2.GetStructureGroupsResponse{
+		Groups: make([]api_v2.StructureGroup, len(groups)),
+	}
+	for i, group := range groups {
+		response.Groups[i] = api_v2.StructureGroup{
+			Signature: group.Signature,
+			Hash:      group.Hash,
+			TraceIDs:  group.TraceIDs,
+			Latency:   toLatencyStats(group.Latency),
+		}
+	}
+	return response, nil
+}
+
+func toLatencyStats(summary stats.Summary) api_v2.LatencyStats {
+	return api_v2.LatencyStats{
+		Count:  int64(summary.Count),
+		Mean:   summary.Mean,
+		StdDev: summary.StdDev,
+		Min:    summary.Min,
+		Max:    summary.Max,
+		P50:    summary.P50,
+		P99:    summary.P99,
+	}
+}
+
+// toSpanstoreQuery converts the query, failing with InvalidArgument when it is missing, when its
+// pattern cannot be parsed or when its integrity statuses are unknown.
+func toSpanstoreQuery(query *api_v2.TraceQueryParameters) (*spanstore.TraceQueryParameters, error) {
+	if query == nil {
+		return nil, status.Error(codes.InvalidArgument, "missing query")
+	}
+	if query.GetPattern() != "" {
+		if _, err := pattern.Parse(query.Pattern); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "cannot parse pattern: %v", err)
+		}
+	}
+	integrity, err := toIntegrityStatuses(query.Integrity)
+	if err != nil {
+		return nil, err
+	}
+	return &spanstore.TraceQueryParameters{
 		ServiceName:   query.ServiceName,
 		OperationName: query.OperationName,
 		Tags:          query.Tags,
@@ -87,28 +233,97 @@ func (g *GRPCHandler) FindTraces(r *api_v2.FindTracesRequest, stream api_v2.Quer
 		DurationMin:   query.DurationMin,
 		DurationMax:   query.DurationMax,
 		NumTraces:     int(query.SearchDepth),
+		Integrity:     integrity,
+		Pattern:       query.Pattern,
+		StructureHash: query.StructureHash,
+	}, nil
+}
+
+// toIntegrityStatuses converts the statuses, failing with InvalidArgument on unknown values.
+func toIntegrityStatuses(integrity []api_v2.TraceIntegrity) ([]model.IntegrityStatus, error) {
+	if len(integrity) == 0 {
+		return nil, nil
 	}
-	traces, err := g.queryService.FindTraces(stream.Context(), &queryParams)
-	if err != nil {
-		g.logger.Error("Error fetching traces", zap.Error(err))
-		return err
-	}
-	for _, trace := range traces {
-		if err := g.sendSpanChunks(trace.Spans, stream.Send); err != nil {
-			return err
+	retMe := make([]model.IntegrityStatus, len(integrity))
+	for i, value := range integrity {
+		switch value {
+		case api_v2.TraceIntegrity_DROPPED_SPAN:
+			retMe[i] = model.IntegrityDroppedSpan
+		case api_v2.TraceIntegrity_ORPHANED:
+			retMe[i] = model.IntegrityOrphaned
+		case api_v2.TraceIntegrity_MULTI_PARENT:
+			retMe[i] = model.IntegrityMultiParent
+		case api_v2.TraceIntegrity_CROSS_TRACE_REFERENCE:
+			retMe[i] = model.IntegrityCrossTraceReference
+		case api_v2.TraceIntegrity_COMPLETE:
+			retMe[i] = model.IntegrityComplete
+		default:
+			return nil, status.Errorf(codes.InvalidArgument, "unknown trace integrity %d", value)
 		}
 	}
-	return nil
+	return retMe, nil
+}
+
+// spanCountInChunk returns the requested number of spans per chunk, or the default if none,
+// capped to maxSpanCountInChunk to bound the size of the messages.
+func spanCountInChunk(requested uint32) int {
+	if requested == 0 {
+		return defaultSpanCountInChunk
+	}
+	if requested > maxSpanCountInChunk {
+		return maxSpanCountInChunk
+	}
+	return int(requested)
+}
+
+// adjust applies the query service adjusters to the trace. Adjuster errors are
+// reported as trace warnings, since the adjusted trace remains usable.
+func (g *GRPCHandler) adjust(trace *model.Trace) *model.Trace {
+	adjusted, err := g.queryService.Adjust(trace)
+	if err != nil {
+		g.logger.Warn("Failed to adjust trace", zap.Error(err))
+		adjusted.Warnings = append(adjusted.Warnings, err.Error())
+	}
+	return adjusted
 }
 
-func (g *GRPCHandler) sendSpanChunks(spans []*model.Span, sendFn func(*api_v2.SpansResponseChunk) error) error {
+// sendSpanChunks sends the spans of the trace in chunks of at most chunkSize spans.
+// When delimited, the last chunk is marked as the end of the trace and carries the
+// trace warnings; a trace without spans is sent as a single empty chunk with traceID.
+func (g *GRPCHandler) sendSpanChunks(
+	traceID model.TraceID,
+	trace *model.Trace,
+	chunkSize int,
+	delimited bool,
+	sendFn func(*api_v2.SpansResponseChunk) error,
+) error {
+	spans := trace.Spans
+	if len(spans) == 0 && delimited {
+		err := sendFn(&api_v2.SpansResponseChunk{
+			TraceID:  traceID,
+			TraceEnd: true,
+			Warnings: trace.Warnings,
+		})
+		if err != nil {
+			g.logger.Error("failed to send response to client", zap.Error(err))
+		}
+		return err
+	}
 	chunk := make([]model.Span, 0, len(spans))
-	for i := 0; i < len(spans); i += maxSpanCountInChunk {
+	for i := 0; i < len(spans); i += chunkSize {
 		chunk = chunk[:0]
-		for j := i; j < len(spans) && j < i+maxSpanCountInChunk; j++ {
+		for j := i; j < len(spans) && j < i+chunkSize; j++ {
 			chunk = append(chunk, *spans[j])
 		}
-		if err := sendFn(&api_v2.SpansResponseChunk{Spans: chunk}); err != nil {
+		response := &api_v2.SpansResponseChunk{Spans: chunk}
+		if delimited {
+			response.TraceID = spans[i].TraceID
+			if i+chunkSize >= len(spans) {
+				response.TraceEnd = true
+				response.Warnings = trace.Warnings
+			}
+		}
+		if err := sendFn(response); err != nil {
 			g.logger.Error("failed to send response to client", zap.Error(err))
 			return err
 		}
@@ -19,6 +19,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"math"
 	"net"
 	"testing"
 	"time"
@@ -29,10 +31,13 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
 	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
 	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/model/adjuster"
+	"github.com/jaegertracing/jaeger/model/structure"
 	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
 	depsmocks "github.com/jaegertracing/jaeger/storage/dependencystore/mocks"
 	"github.com/jaegertracing/jaeger/storage/spanstore"
@@ -218,6 +223,50 @@ func TestGetTraceSuccessGRPC(t *testing.T) {
 	})
 }
 
+// newOrphanedTraceGRPC returns a trace with a span whose parent is missing.
+func newOrphanedTraceGRPC() *model.Trace {
+	return &model.Trace{
+		Spans: []*model.Span{
+			{
+				TraceID:    mockTraceID,
+				SpanID:     model.NewSpanID(2),
+				References: []model.SpanRef{model.NewChildOfRef(mockTraceID, model.NewSpanID(1))},
+				Process:    &model.Process{},
+			},
+		},
+	}
+}
+
+func TestGetTraceAdjustedGRPC(t *testing.T) {
+	withServerAndClient(t, func(server *grpcServer, client *grpcClient) {
+		server.spanReader.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("model.TraceID")).
+			Return(newOrphanedTraceGRPC(), nil).Once()
+		server.spanReader.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("model.TraceID")).
+			Return(newOrphanedTraceGRPC(), nil).Once()
+
+		res, err := client.GetTrace(context.Background(), &api_v2.GetTraceRequest{
+			TraceID: mockTraceIDgrpc,
+			Adjust:  true,
+		})
+		require.NoError(t, err)
+		spanResChunk, err := res.Recv()
+		require.NoError(t, err)
+		assert.True(t, spanResChunk.TraceEnd)
+		assert.Equal(t, []string{"integrity: dropped-span,orphaned"}, spanResChunk.Warnings)
+		assert.NotEmpty(t, spanResChunk.Spans[0].Warnings)
+
+		res, err = client.GetTrace(context.Background(), &api_v2.GetTraceRequest{
+			TraceID: mockTraceIDgrpc,
+		})
+		require.NoError(t, err)
+		spanResChunk, err = res.Recv()
+		require.NoError(t, err)
+		assert.False(t, spanResChunk.TraceEnd)
+		assert.Empty(t, spanResChunk.Warnings)
+		assert.Empty(t, spanResChunk.Spans[0].Warnings)
+	})
+}
+
 func TestGetTraceDBFailureGRPC(t *testing.T) {
 	withServerAndClient(t, func(server *grpcServer, client *grpcClient) {
 
@@ -259,6 +308,73 @@ func TestGetTraceNotFoundGRPC(t *testing.T) {
 	})
 }
 
+func TestGetTracesSuccessGRPC(t *testing.T) {
+	withServerAndClient(t, func(server *grpcServer, client *grpcClient) {
+		missingTraceID := model.NewTraceID(0, 1)
+		server.spanReader.On("GetTrace", mock.Anything, mockTraceIDgrpc).
+			Return(mockLargeTraceGRPC, nil).Once()
+		server.spanReader.On("GetTrace", mock.Anything, missingTraceID).
+			Return(nil, spanstore.ErrTraceNotFound).Once()
+		server.archiveSpanReader.On("GetTrace", mock.Anything, missingTraceID).
+			Return(nil, spanstore.ErrTraceNotFound).Once()
+
+		res, err := client.GetTraces(context.Background(), &api_v2.GetTracesRequest{
+			TraceIDs:  []model.TraceID{missingTraceID, mockTraceIDgrpc},
+			ChunkSize: 6,
+		})
+		require.NoError(t, err)
+
+		spanResChunk, err := res.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, missingTraceID, spanResChunk.TraceID)
+		assert.True(t, spanResChunk.TraceEnd)
+		assert.Equal(t, spanstore.ErrTraceNotFound.Error(), spanResChunk.Error)
+		assert.Empty(t, spanResChunk.Spans)
+
+		spanResChunk, err = res.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, mockTraceID, spanResChunk.TraceID)
+		assert.False(t, spanResChunk.TraceEnd)
+		assert.Len(t, spanResChunk.Spans, 6)
+
+		spanResChunk, err = res.Recv()
+		require.NoError(t, err)
+		assert.True(t, spanResChunk.TraceEnd)
+		assert.Len(t, spanResChunk.Spans, 5)
+
+		_, err = res.Recv()
+		assert.Equal(t, io.EOF, err)
+	})
+}
+
+func TestGetTracesFailureGRPC(t *testing.T) {
+	withServerAndClient(t, func(server *grpcServer, client *grpcClient) {
+		server.spanReader.On("GetTrace", mock.Anything, mock.AnythingOfType("model.TraceID")).
+			Return(nil, errStorageGRPC).Once()
+
+		res, err := client.GetTraces(context.Background(), &api_v2.GetTracesRequest{
+			TraceIDs: []model.TraceID{mockTraceIDgrpc},
+		})
+		require.NoError(t, err)
+
+		_, err = res.Recv()
+		assert.EqualError(t, err, errStatusStorageGRPC.Error())
+	})
+}
+
+func TestGetTracesTooManyGRPC(t *testing.T) {
+	withServerAndClient(t, func(server *grpcServer, client *grpcClient) {
+		res, err := client.GetTraces(context.Background(), &api_v2.GetTracesRequest{
+			TraceIDs: make([]model.TraceID, 1001),
+		})
+		require.NoError(t, err)
+
+		_, err = res.Recv()
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Contains(t, err.Error(), "too many trace IDs in the request: 1001 requested")
+	})
+}
+
 func TestArchiveTraceSuccessGRPC(t *testing.T) {
 	withServerAndClient(t, func(server *grpcServer, client *grpcClient) {
 		server.spanReader.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("model.TraceID")).
@@ -365,6 +481,85 @@ func TestSearchSuccess_SpanStreamingGRPC(t *testing.T) {
 	})
 }
 
+func TestSearchSuccess_TraceDelimitedGRPC(t *testing.T) {
+	withServerAndClient(t, func(server *grpcServer, client *grpcClient) {
+		warnedTrace := &model.Trace{
+			Spans:    mockTraceGRPC.Spans,
+			Warnings: []string{"clock skew adjustment disabled"},
+		}
+		server.spanReader.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+			Return([]*model.Trace{mockLargeTraceGRPC, warnedTrace}, nil).Once()
+
+		res, err := client.FindTraces(context.Background(), &api_v2.FindTracesRequest{
+			Query: &api_v2.TraceQueryParameters{
+				ServiceName:  "service",
+				StartTimeMin: time.Now().Add(time.Duration(-10) * time.Minute),
+				StartTimeMax: time.Now(),
+			},
+			ChunkSize:      4,
+			TraceDelimited: true,
+		})
+		require.NoError(t, err)
+
+		type chunk struct {
+			spans    int
+			traceEnd bool
+			warnings []string
+		}
+		var chunks []chunk
+		for {
+			spanResChunk, err := res.Recv()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			chunks = append(chunks, chunk{
+				spans:    len(spanResChunk.Spans),
+				traceEnd: spanResChunk.TraceEnd,
+				warnings: spanResChunk.Warnings,
+			})
+		}
+		assert.Equal(t, []chunk{
+			{spans: 4},
+			{spans: 4},
+			{spans: 3, traceEnd: true},
+			{spans: 2, traceEnd: true, warnings: []string{"clock skew adjustment disabled"}},
+		}, chunks)
+	})
+}
+
+func TestSearchSuccess_AdjustedGRPC(t *testing.T) {
+	withServerAndClient(t, func(server *grpcServer, client *grpcClient) {
+		server.spanReader.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+			Return([]*model.Trace{newOrphanedTraceGRPC()}, nil).Once()
+
+		res, err := client.FindTraces(context.Background(), &api_v2.FindTracesRequest{
+			Query: &api_v2.TraceQueryParameters{
+				ServiceName:  "service",
+				StartTimeMin: time.Now().Add(time.Duration(-10) * time.Minute),
+				StartTimeMax: time.Now(),
+			},
+			Adjust: true,
+		})
+		require.NoError(t, err)
+		spanResChunk, err := res.Recv()
+		require.NoError(t, err)
+		assert.True(t, spanResChunk.TraceEnd)
+		assert.Equal(t, []string{"integrity: dropped-span,orphaned"}, spanResChunk.Warnings)
+	})
+}
+
+func TestAdjustErrorGRPC(t *testing.T) {
+	q := querysvc.NewQueryService(&spanstoremocks.Reader{}, &depsmocks.Reader{}, querysvc.QueryServiceOptions{
+		Adjuster: adjuster.Func(func(trace *model.Trace) (*model.Trace, error) {
+			return trace, errAdjustment
+		}),
+	})
+	g := NewGRPCHandler(q, zap.NewNop(), opentracing.NoopTracer{})
+	trace := g.adjust(&model.Trace{Warnings: []string{"existing"}})
+	assert.Equal(t, []string{"existing", errAdjustment.Error()}, trace.Warnings)
+}
+
 func TestSearchFailure_GRPC(t *testing.T) {
 	withServerAndClient(t, func(server *grpcServer, client *grpcClient) {
 		mockErrorGRPC := fmt.Errorf("whatsamattayou")
@@ -391,6 +586,188 @@ func TestSearchFailure_GRPC(t *testing.T) {
 	})
 }
 
+func TestFindTraceIDsSuccessGRPC(t *testing.T) {
+	withServerAndClient(t, func(server *grpcServer, client *grpcClient) {
+		traceIDs := make([]model.TraceID, maxTraceIDCountInChunk+1)
+		for i := range traceIDs {
+			traceIDs[i] = model.NewTraceID(0, uint64(i+1))
+		}
+		server.spanReader.On("FindTraceIDs", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+			Return(traceIDs, nil).Once()
+
+		queryParams := &api_v2.TraceQueryParameters{
+			ServiceName:   "service",
+			OperationName: "operation",
+			StartTimeMin:  time.Now().Add(time.Duration(-10) * time.Minute),
+			StartTimeMax:  time.Now(),
+		}
+		res, err := client.FindTraceIDs(context.Background(), &api_v2.FindTraceIDsRequest{
+			Query: queryParams,
+		})
+		assert.NoError(t, err)
+
+		chunk, err := res.Recv()
+		assert.NoError(t, err)
+		assert.Equal(t, traceIDs[:maxTraceIDCountInChunk], chunk.TraceIDs)
+
+		chunk, err = res.Recv()
+		assert.NoError(t, err)
+		assert.Equal(t, traceIDs[maxTraceIDCountInChunk:], chunk.TraceIDs)
+	})
+}
+
+func TestFindTraceIDsFailureGRPC(t *testing.T) {
+	withServerAndClient(t, func(server *grpcServer, client *grpcClient) {
+		server.spanReader.On("FindTraceIDs", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+			Return(nil, errStorageGRPC).Once()
+
+		res, err := client.FindTraceIDs(context.Background(), &api_v2.FindTraceIDsRequest{
+			Query: &api_v2.TraceQueryParameters{ServiceName: "service"},
+		})
+		assert.NoError(t, err)
+
+		chunk, err := res.Recv()
+		assert.EqualError(t, err, errStatusStorageGRPC.Error())
+		assert.Nil(t, chunk)
+	})
+}
+
+func TestSearchByPatternGRPC(t *testing.T) {
+	withServerAndClient(t, func(server *grpcServer, client *grpcClient) {
+		server.spanReader.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+			Return(func(context.Context, *spanstore.TraceQueryParameters) []*model.Trace {
+				return []*model.Trace{mockTraceGRPC}
+			}, nil).Twice()
+
+		// mockTraceGRPC has two root spans
+		res, err := client.FindTraces(context.Background(), &api_v2.FindTracesRequest{
+			Query: &api_v2.TraceQueryParameters{ServiceName: "service", Pattern: "* > *"},
+		})
+		require.NoError(t, err)
+		_, err = res.Recv()
+		assert.Equal(t, io.EOF, err)
+
+		idsRes, err := client.FindTraceIDs(context.Background(), &api_v2.FindTraceIDsRequest{
+			Query: &api_v2.TraceQueryParameters{ServiceName: "service", Pattern: "* #=2"},
+		})
+		require.NoError(t, err)
+		chunk, err := idsRes.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, []model.TraceID{mockTraceID}, chunk.TraceIDs)
+	})
+}
+
+func TestSearchByStructureHashGRPC(t *testing.T) {
+	withServerAndClient(t, func(server *grpcServer, client *grpcClient) {
+		// the query service adjusts the traces to hash them, so each call gets its own copy
+		newTrace := func() *model.Trace {
+			return &model.Trace{Spans: []*model.Span{
+				{TraceID: mockTraceID, SpanID: model.NewSpanID(1), Process: &model.Process{ServiceName: "service"}},
+				{TraceID: mockTraceID, SpanID: model.NewSpanID(2), Process: &model.Process{ServiceName: "service"}},
+			}}
+		}
+		server.spanReader.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+			Return(func(context.Context, *spanstore.TraceQueryParameters) []*model.Trace {
+				return []*model.Trace{newTrace()}
+			}, nil).Twice()
+
+		idsRes, err := client.FindTraceIDs(context.Background(), &api_v2.FindTraceIDsRequest{
+			Query: &api_v2.TraceQueryParameters{
+				ServiceName:   "service",
+				StructureHash: structure.Hash(structure.Signature(newTrace())),
+			},
+		})
+		require.NoError(t, err)
+		chunk, err := idsRes.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, []model.TraceID{mockTraceID}, chunk.TraceIDs)
+
+		idsRes, err = client.FindTraceIDs(context.Background(), &api_v2.FindTraceIDsRequest{
+			Query: &api_v2.TraceQueryParameters{ServiceName: "service", StructureHash: "0123456789abcdef"},
+		})
+		require.NoError(t, err)
+		_, err = idsRes.Recv()
+		assert.Equal(t, io.EOF, err)
+	})
+}
+
+func TestInvalidPatternGRPC(t *testing.T) {
+	withServerAndClient(t, func(server *grpcServer, client *grpcClient) {
+		query := &api_v2.TraceQueryParameters{ServiceName: "service", Pattern: "frontend >"}
+		expectInvalid := func(err error) {
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+			assert.Contains(t, err.Error(), "cannot parse pattern: expected a span selector")
+		}
+
+		res, err := client.FindTraces(context.Background(), &api_v2.FindTracesRequest{Query: query})
+		require.NoError(t, err)
+		_, err = res.Recv()
+		expectInvalid(err)
+
+		idsRes, err := client.FindTraceIDs(context.Background(), &api_v2.FindTraceIDsRequest{Query: query})
+		require.NoError(t, err)
+		_, err = idsRes.Recv()
+		expectInvalid(err)
+
+		_, err = client.GetStructureGroups(context.Background(), &api_v2.GetStructureGroupsRequest{Query: query})
+		expectInvalid(err)
+	})
+}
+
+func TestFindTracesMissingQueryGRPC(t *testing.T) {
+	withServerAndClient(t, func(server *grpcServer, client *grpcClient) {
+		res, err := client.FindTraces(context.Background(), &api_v2.FindTracesRequest{})
+		require.NoError(t, err)
+		_, err = res.Recv()
+		assert.Equal(t, status.Error(codes.InvalidArgument, "missing query"), err)
+	})
+}
+
+func TestFindTraceIDsMissingQueryGRPC(t *testing.T) {
+	withServerAndClient(t, func(server *grpcServer, client *grpcClient) {
+		res, err := client.FindTraceIDs(context.Background(), &api_v2.FindTraceIDsRequest{})
+		require.NoError(t, err)
+		_, err = res.Recv()
+		assert.Equal(t, status.Error(codes.InvalidArgument, "missing query"), err)
+	})
+}
+
+func TestGetStructureGroupsMissingQueryGRPC(t *testing.T) {
+	withServerAndClient(t, func(server *grpcServer, client *grpcClient) {
+		_, err := client.GetStructureGroups(context.Background(), &api_v2.GetStructureGroupsRequest{})
+		assert.Equal(t, status.Error(codes.InvalidArgument, "missing query"), err)
+	})
+}
+
+func TestGetStructureGroupsSuccessGRPC(t *testing.T) {
+	withServerAndClient(t, func(server *grpcServer, client *grpcClient) {
+		server.spanReader.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+			Return([]*model.Trace{mockTraceGRPC}, nil).Once()
+
+		res, err := client.GetStructureGroups(context.Background(), &api_v2.GetStructureGroupsRequest{
+			Query: &api_v2.TraceQueryParameters{ServiceName: "service"},
+		})
+		assert.NoError(t, err)
+		assert.Len(t, res.Groups, 1)
+		assert.Equal(t, []model.TraceID{mockTraceID}, res.Groups[0].TraceIDs)
+		assert.NotEmpty(t, res.Groups[0].Signature)
+		assert.NotEmpty(t, res.Groups[0].Hash)
+		assert.EqualValues(t, 1, res.Groups[0].Latency.Count)
+	})
+}
+
+func TestGetStructureGroupsFailureGRPC(t *testing.T) {
+	withServerAndClient(t, func(server *grpcServer, client *grpcClient) {
+		server.spanReader.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+			Return(nil, errStorageGRPC).Once()
+
+		_, err := client.GetStructureGroups(context.Background(), &api_v2.GetStructureGroupsRequest{
+			Query: &api_v2.TraceQueryParameters{ServiceName: "service"},
+		})
+		assert.EqualError(t, err, errStatusStorageGRPC.Error())
+	})
+}
+
 func TestGetServicesSuccessGRPC(t *testing.T) {
 	withServerAndClient(t, func(server *grpcServer, client *grpcClient) {
 		expectedServices := []string{"trifle", "bling"}
@@ -475,12 +852,83 @@ func TestSendSpanChunksError(t *testing.T) {
 		logger: zap.NewNop(),
 	}
 	expectedErr := assert.AnError
-	err := g.sendSpanChunks([]*model.Span{
-		{
-			OperationName: "blah",
+	err := g.sendSpanChunks(mockTraceID, &model.Trace{
+		Spans: []*model.Span{
+			{
+				OperationName: "blah",
+			},
 		},
-	}, func(*api_v2.SpansResponseChunk) error {
+	}, defaultSpanCountInChunk, false, func(*api_v2.SpansResponseChunk) error {
 		return expectedErr
 	})
 	assert.EqualError(t, err, expectedErr.Error())
+
+	err = g.sendSpanChunks(mockTraceID, &model.Trace{}, defaultSpanCountInChunk, true, func(*api_v2.SpansResponseChunk) error {
+		return expectedErr
+	})
+	assert.EqualError(t, err, expectedErr.Error())
+}
+
+func TestSendSpanChunksEmptyTrace(t *testing.T) {
+	g := &GRPCHandler{
+		logger: zap.NewNop(),
+	}
+	var chunks []*api_v2.SpansResponseChunk
+	send := func(chunk *api_v2.SpansResponseChunk) error {
+		chunks = append(chunks, chunk)
+		return nil
+	}
+	trace := &model.Trace{Warnings: []string{"warning"}}
+	assert.NoError(t, g.sendSpanChunks(mockTraceID, trace, defaultSpanCountInChunk, false, send))
+	assert.Empty(t, chunks)
+
+	// the end of a delimited trace is always sent
+	assert.NoError(t, g.sendSpanChunks(mockTraceID, trace, defaultSpanCountInChunk, true, send))
+	assert.Equal(t, []*api_v2.SpansResponseChunk{
+		{TraceID: mockTraceID, TraceEnd: true, Warnings: []string{"warning"}},
+	}, chunks)
+}
+
+func TestSpanCountInChunk(t *testing.T) {
+	assert.Equal(t, defaultSpanCountInChunk, spanCountInChunk(0))
+	assert.Equal(t, 5, spanCountInChunk(5))
+	assert.Equal(t, maxSpanCountInChunk, spanCountInChunk(maxSpanCountInChunk+1))
+	assert.Equal(t, maxSpanCountInChunk, spanCountInChunk(math.MaxUint32))
+}
+
+func TestToIntegrityStatuses(t *testing.T) {
+	statuses, err := toIntegrityStatuses(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, statuses)
+	statuses, err = toIntegrityStatuses([]api_v2.TraceIntegrity{
+		api_v2.TraceIntegrity_COMPLETE,
+		api_v2.TraceIntegrity_DROPPED_SPAN,
+		api_v2.TraceIntegrity_ORPHANED,
+		api_v2.TraceIntegrity_MULTI_PARENT,
+		api_v2.TraceIntegrity_CROSS_TRACE_REFERENCE,
+	})
+	assert.Equal(t, []model.IntegrityStatus{
+		model.IntegrityComplete,
+		model.IntegrityDroppedSpan,
+		model.IntegrityOrphaned,
+		model.IntegrityMultiParent,
+		model.IntegrityCrossTraceReference,
+	}, statuses)
+	assert.NoError(t, err)
+
+	_, err = toIntegrityStatuses([]api_v2.TraceIntegrity{api_v2.TraceIntegrity_ORPHANED, api_v2.TraceIntegrity(42)})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Contains(t, err.Error(), "unknown trace integrity 42")
+}
+
+func TestInvalidIntegrityGRPC(t *testing.T) {
+	withServerAndClient(t, func(server *grpcServer, client *grpcClient) {
+		res, err := client.FindTraces(context.Background(), &api_v2.FindTracesRequest{
+			Query: &api_v2.TraceQueryParameters{ServiceName: "service", Integrity: []api_v2.TraceIntegrity{42}},
+		})
+		require.NoError(t, err)
+		_, err = res.Recv()
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Contains(t, err.Error(), "unknown trace integrity 42")
+	})
 }
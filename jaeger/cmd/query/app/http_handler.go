@@ -32,16 +32,34 @@ import (
 
 	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
 	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/model/converter/chrome"
+	"github.com/jaegertracing/jaeger/model/converter/folded"
 	uiconv "github.com/jaegertracing/jaeger/model/converter/json"
+	"github.com/jaegertracing/jaeger/model/converter/pprof"
 	ui "github.com/jaegertracing/jaeger/model/json"
+	"github.com/jaegertracing/jaeger/model/latency"
 	"github.com/jaegertracing/jaeger/pkg/multierror"
+	"github.com/jaegertracing/jaeger/storage"
 	"github.com/jaegertracing/jaeger/storage/spanstore"
 )
 
 const (
-	traceIDParam  = "traceID"
-	endTsParam    = "endTs"
-	lookbackParam = "lookback"
+	traceIDParam   = "traceID"
+	endTsParam     = "endTs"
+	lookbackParam  = "lookback"
+	breakdownParam = "breakdown"
+	cutoffParam    = "cutoff"
+	structureParam = "structure"
+
+	baselineStartParam = "baselineStart"
+	baselineEndParam   = "baselineEnd"
+	significanceParam  = "significance"
+	requestTypeParam   = "requestType"
+	formatParam        = "format"
+	baselineTraceParam = "baselineTraceID"
+
+	formatFolded = "folded"
+	formatChrome = "chrome"
 
 	defaultDependencyLookbackDuration = time.Hour * 24
 	defaultTraceQueryLookbackDuration = time.Hour * 24 * 2
@@ -118,6 +136,16 @@ func (aH *APIHandler) RegisterRoutes(router *mux.Router) {
 	// TODO - remove this when UI catches up
 	aH.handleFunc(router, aH.getOperationsLegacy, "/services/{%s}/operations", serviceParam).Methods(http.MethodGet)
 	aH.handleFunc(router, aH.dependencies, "/dependencies").Methods(http.MethodGet)
+	aH.handleFunc(router, aH.requestTypes, "/request-types").Methods(http.MethodGet)
+	aH.handleFunc(router, aH.tailProfile, "/tail-profile").Methods(http.MethodGet)
+	aH.handleFunc(router, aH.structureCounts, "/structures").Methods(http.MethodGet)
+	aH.handleFunc(router, aH.aggregateTrace, "/structures/{%s}/aggregate", structureParam).Methods(http.MethodGet)
+	aH.handleFunc(router, aH.childDiffs, "/structures/{%s}/child-diffs", structureParam).Methods(http.MethodGet)
+	aH.handleFunc(router, aH.criticalPath, "/traces/{%s}/critical-path", traceIDParam).Methods(http.MethodGet)
+	aH.handleFunc(router, aH.diffTraces, "/traces/{%s}/diff/{%s}", traceIDParam, baselineTraceParam).Methods(http.MethodGet)
+	aH.handleFunc(router, aH.criticalPathContributions, "/critical-path").Methods(http.MethodGet)
+	aH.handleFunc(router, aH.regression, "/regression").Methods(http.MethodGet)
+	aH.handleFunc(router, aH.profile, "/profile").Methods(http.MethodGet)
 }
 
 func (aH *APIHandler) handleFunc(
@@ -196,6 +224,10 @@ func (aH *APIHandler) search(w http.ResponseWriter, r *http.Request) {
 	var tracesFromStorage []*model.Trace
 	if len(tQuery.traceIDs) > 0 {
 		tracesFromStorage, uiErrors, err = aH.tracesByIDs(r.Context(), tQuery.traceIDs)
+		if err == querysvc.ErrTooManyTraceIDs {
+			aH.handleError(w, err, http.StatusBadRequest)
+			return
+		}
 		if aH.handleError(w, err, http.StatusInternalServerError) {
 			return
 		}
@@ -208,7 +240,7 @@ func (aH *APIHandler) search(w http.ResponseWriter, r *http.Request) {
 
 	uiTraces := make([]*ui.Trace, len(tracesFromStorage))
 	for i, v := range tracesFromStorage {
-		uiTrace, uiErr := aH.convertModelToUI(v, true)
+		uiTrace, uiErr := aH.convertModelToUI(v, true, false)
 		if uiErr != nil {
 			uiErrors = append(uiErrors, *uiErr)
 		}
@@ -222,17 +254,330 @@ func (aH *APIHandler) search(w http.ResponseWriter, r *http.Request) {
 	aH.writeJSON(w, r, &structuredRes)
 }
 
+// requestTypes implements the REST API /request-types.
+// It accepts the same parameters as the search, and groups the matching traces by request type.
+func (aH *APIHandler) requestTypes(w http.ResponseWriter, r *http.Request) {
+	tQuery, err := aH.queryParser.parse(r)
+	if err == nil && tQuery.ServiceName == "" {
+		err = ErrServiceParameterRequired
+	}
+	if aH.handleError(w, err, http.StatusBadRequest) {
+		return
+	}
+	groups, err := aH.queryService.GetRequestTypeGroups(r.Context(), &tQuery.TraceQueryParameters)
+	if aH.handleError(w, err, http.StatusInternalServerError) {
+		return
+	}
+	uiGroups := make([]ui.RequestTypeGroup, len(groups))
+	for i, group := range groups {
+		traceIDs := make([]ui.TraceID, len(group.TraceIDs))
+		for j, traceID := range group.TraceIDs {
+			traceIDs[j] = ui.TraceID(traceID.String())
+		}
+		uiGroups[i] = ui.RequestTypeGroup{
+			RequestType: group.RequestType,
+			TraceIDs:    traceIDs,
+			Latency:     uiconv.LatencyStatsFromDomain(group.Latency),
+		}
+	}
+	structuredRes := structuredResponse{
+		Data:  uiGroups,
+		Total: len(uiGroups),
+	}
+	aH.writeJSON(w, r, &structuredRes)
+}
+
+// tailProfile implements the REST API /tail-profile.
+// It accepts the same parameters as the search, plus the cutoff percentile separating
+// the norm from the tail traces and an optional structure hash to profile a single group.
+func (aH *APIHandler) tailProfile(w http.ResponseWriter, r *http.Request) {
+	tQuery, params, ok := aH.parseTailProfileQuery(w, r)
+	if !ok {
+		return
+	}
+	params.StructureHash = r.FormValue(structureParam)
+
+	profile, err := aH.queryService.GetTailProfile(r.Context(), &tQuery.TraceQueryParameters, params)
+	if err == querysvc.ErrStructureGroupNotFound {
+		aH.handleError(w, err, http.StatusNotFound)
+		return
+	}
+	if aH.handleError(w, err, http.StatusInternalServerError) {
+		return
+	}
+	structuredRes := structuredResponse{
+		Data: uiconv.TailProfileFromDomain(profile),
+	}
+	aH.writeJSON(w, r, &structuredRes)
+}
+
+// structureCounts implements the REST API /structures.
+// It responds with the number of traces of each structure, by structural hash, among the traces
+// which started between the start and end parameters, as counted by the structure index of the
+// storage.
+func (aH *APIHandler) structureCounts(w http.ResponseWriter, r *http.Request) {
+	startTime, err := aH.queryParser.parseTime(startTimeParam, r)
+	if aH.handleError(w, errors.Wrapf(err, "unable to parse %s", startTimeParam), http.StatusBadRequest) {
+		return
+	}
+	endTime, err := aH.queryParser.parseTime(endTimeParam, r)
+	if aH.handleError(w, errors.Wrapf(err, "unable to parse %s", endTimeParam), http.StatusBadRequest) {
+		return
+	}
+	counts, err := aH.queryService.GetStructureCounts(r.Context(), startTime, endTime)
+	if err == storage.ErrStructureIndexNotSupported {
+		aH.handleError(w, err, http.StatusNotImplemented)
+		return
+	}
+	if aH.handleError(w, err, http.StatusInternalServerError) {
+		return
+	}
+	structuredRes := structuredResponse{
+		Data: uiconv.StructureCountsFromIndex(counts),
+	}
+	aH.writeJSON(w, r, &structuredRes)
+}
+
+// aggregateTrace implements the REST API /structures/{structure}/aggregate.
+// It accepts the same parameters as the tail profile, and responds with a synthetic trace
+// standing for the traces of the structural group, which remains available for a while
+// under /traces/{trace-id} so that the UI can display it.
+func (aH *APIHandler) aggregateTrace(w http.ResponseWriter, r *http.Request) {
+	tQuery, params, ok := aH.parseTailProfileQuery(w, r)
+	if !ok {
+		return
+	}
+	params.StructureHash = mux.Vars(r)[structureParam]
+
+	trace, err := aH.queryService.GetAggregateTrace(r.Context(), &tQuery.TraceQueryParameters, params)
+	if err == querysvc.ErrStructureGroupNotFound {
+		aH.handleError(w, err, http.StatusNotFound)
+		return
+	}
+	if aH.handleError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	var uiErrors []structuredError
+	uiTrace, uiErr := aH.convertModelToUI(trace, shouldAdjust(r), shouldBreakdown(r))
+	if uiErr != nil {
+		uiErrors = append(uiErrors, *uiErr)
+	}
+	structuredRes := structuredResponse{
+		Data: []*ui.Trace{
+			uiTrace,
+		},
+		Errors: uiErrors,
+	}
+	aH.writeJSON(w, r, &structuredRes)
+}
+
+// childDiffs implements the REST API /structures/{structure}/child-diffs.
+// It accepts the same parameters as the tail profile, and compares how the children of
+// the spans of the structural group are spaced out in the norm and the tail traces.
+func (aH *APIHandler) childDiffs(w http.ResponseWriter, r *http.Request) {
+	tQuery, params, ok := aH.parseTailProfileQuery(w, r)
+	if !ok {
+		return
+	}
+	params.StructureHash = mux.Vars(r)[structureParam]
+
+	profiles, err := aH.queryService.GetChildDiffProfiles(r.Context(), &tQuery.TraceQueryParameters, params)
+	if err == querysvc.ErrStructureGroupNotFound {
+		aH.handleError(w, err, http.StatusNotFound)
+		return
+	}
+	if aH.handleError(w, err, http.StatusInternalServerError) {
+		return
+	}
+	structuredRes := structuredResponse{
+		Data: uiconv.ChildDiffProfilesFromDomain(profiles),
+	}
+	aH.writeJSON(w, r, &structuredRes)
+}
+
+// criticalPath implements the REST API /traces/{trace-id}/critical-path.
+// It responds with the segments of the critical path of the adjusted trace, in time order.
+func (aH *APIHandler) criticalPath(w http.ResponseWriter, r *http.Request) {
+	traceID, ok := aH.parseTraceID(w, r)
+	if !ok {
+		return
+	}
+	path, err := aH.queryService.GetCriticalPath(r.Context(), traceID)
+	if err == spanstore.ErrTraceNotFound {
+		aH.handleError(w, err, http.StatusNotFound)
+		return
+	}
+	if aH.handleError(w, err, http.StatusInternalServerError) {
+		return
+	}
+	structuredRes := structuredResponse{
+		Data: uiconv.CriticalPathFromDomain(path),
+	}
+	aH.writeJSON(w, r, &structuredRes)
+}
+
+// diffTraces implements the REST API /traces/{trace-id}/diff/{baseline-trace-id}.
+// It aligns the spans of the trace with those of the baseline trace by structural path,
+// and responds with the missing and extra spans and the differences between the others.
+func (aH *APIHandler) diffTraces(w http.ResponseWriter, r *http.Request) {
+	traceID, ok := aH.parseTraceID(w, r)
+	if !ok {
+		return
+	}
+	baselineID, err := model.TraceIDFromString(mux.Vars(r)[baselineTraceParam])
+	if aH.handleError(w, err, http.StatusBadRequest) {
+		return
+	}
+	diff, err := aH.queryService.DiffTraces(r.Context(), baselineID, traceID)
+	if err == spanstore.ErrTraceNotFound {
+		aH.handleError(w, err, http.StatusNotFound)
+		return
+	}
+	if aH.handleError(w, err, http.StatusInternalServerError) {
+		return
+	}
+	structuredRes := structuredResponse{
+		Data: uiconv.TraceDiffFromDomain(diff),
+	}
+	aH.writeJSON(w, r, &structuredRes)
+}
+
+// criticalPathContributions implements the REST API /critical-path.
+// It accepts the same parameters as the search, which must include a service, and responds
+// with the time each operation spends on the critical paths of the matching traces.
+func (aH *APIHandler) criticalPathContributions(w http.ResponseWriter, r *http.Request) {
+	tQuery, err := aH.queryParser.parse(r)
+	if err == nil && tQuery.ServiceName == "" {
+		err = ErrServiceParameterRequired
+	}
+	if aH.handleError(w, err, http.StatusBadRequest) {
+		return
+	}
+	contributions, err := aH.queryService.GetCriticalPathContributions(r.Context(), &tQuery.TraceQueryParameters)
+	if aH.handleError(w, err, http.StatusInternalServerError) {
+		return
+	}
+	structuredRes := structuredResponse{
+		Data: uiconv.CriticalPathContributionsFromDomain(contributions),
+	}
+	aH.writeJSON(w, r, &structuredRes)
+}
+
+// regression implements the REST API /regression. The current traces are found by the search
+// parameters, the baseline traces by the same parameters over the [baselineStart, baselineEnd]
+// window, which defaults to the window of equal length preceding the current one.
+func (aH *APIHandler) regression(w http.ResponseWriter, r *http.Request) {
+	tQuery, err := aH.queryParser.parse(r)
+	if err == nil && tQuery.ServiceName == "" {
+		err = ErrServiceParameterRequired
+	}
+	if aH.handleError(w, err, http.StatusBadRequest) {
+		return
+	}
+	current := tQuery.TraceQueryParameters
+	baseline := current
+	baseline.StartTimeMax = current.StartTimeMin
+	baseline.StartTimeMin = current.StartTimeMin.Add(-current.StartTimeMax.Sub(current.StartTimeMin))
+	for _, window := range []struct {
+		param string
+		value *time.Time
+	}{
+		{param: baselineStartParam, value: &baseline.StartTimeMin},
+		{param: baselineEndParam, value: &baseline.StartTimeMax},
+	} {
+		if r.FormValue(window.param) == "" {
+			continue
+		}
+		*window.value, err = aH.queryParser.parseTime(window.param, r)
+		if aH.handleError(w, errors.Wrapf(err, "unable to parse %s", window.param), http.StatusBadRequest) {
+			return
+		}
+	}
+	var params querysvc.RegressionParameters
+	if value := r.FormValue(significanceParam); value != "" {
+		params.Significance, err = strconv.ParseFloat(value, 64)
+		if err == nil && (params.Significance <= 0 || params.Significance >= 1) {
+			err = fmt.Errorf("'%s' must be between 0 and 1, exclusive", significanceParam)
+		}
+		if aH.handleError(w, errors.Wrapf(err, "unable to parse %s", significanceParam), http.StatusBadRequest) {
+			return
+		}
+	}
+	regression, err := aH.queryService.GetRegression(r.Context(), &baseline, &current, params)
+	if aH.handleError(w, err, http.StatusInternalServerError) {
+		return
+	}
+	structuredRes := structuredResponse{
+		Data: uiconv.RegressionFromDomain(regression),
+	}
+	aH.writeJSON(w, r, &structuredRes)
+}
+
+// profile implements the REST API /profile.
+// It accepts the same parameters as the tail profile, and responds with the self-time of the
+// spans of the matching traces aggregated by service:operation stack, in the gzipped pprof format.
+func (aH *APIHandler) profile(w http.ResponseWriter, r *http.Request) {
+	tQuery, params, ok := aH.parseTailProfileQuery(w, r)
+	if !ok {
+		return
+	}
+	params.StructureHash = r.FormValue(structureParam)
+
+	traces, err := aH.queryService.FindProfiledTraces(r.Context(), &tQuery.TraceQueryParameters, params)
+	if err == querysvc.ErrStructureGroupNotFound {
+		aH.handleError(w, err, http.StatusNotFound)
+		return
+	}
+	if aH.handleError(w, err, http.StatusInternalServerError) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", `attachment; filename="profile.pb.gz"`)
+	if err := pprof.FromDomain(traces).Write(w); err != nil {
+		aH.logger.Error("Failed to write the profile", zap.Error(err))
+	}
+}
+
+// parseTailProfileQuery parses the search parameters, which must include a service, the cutoff
+// percentile and the optional request type.
+func (aH *APIHandler) parseTailProfileQuery(w http.ResponseWriter, r *http.Request) (*traceQueryParameters, querysvc.TailProfileParameters, bool) {
+	params := querysvc.TailProfileParameters{
+		Cutoff:      latency.DefaultTailCutoff,
+		RequestType: r.FormValue(requestTypeParam),
+	}
+	tQuery, err := aH.queryParser.parse(r)
+	if err == nil && tQuery.ServiceName == "" {
+		err = ErrServiceParameterRequired
+	}
+	if aH.handleError(w, err, http.StatusBadRequest) {
+		return nil, params, false
+	}
+	if value := r.FormValue(cutoffParam); value != "" {
+		cutoff, err := strconv.ParseFloat(value, 64)
+		if err == nil && (cutoff <= 0 || cutoff >= 100) {
+			err = fmt.Errorf("'%s' must be between 0 and 100, exclusive", cutoffParam)
+		}
+		if aH.handleError(w, errors.Wrapf(err, "unable to parse %s", cutoffParam), http.StatusBadRequest) {
+			return nil, params, false
+		}
+		params.Cutoff = cutoff
+	}
+	return tQuery, params, true
+}
+
 func (aH *APIHandler) tracesByIDs(ctx context.Context, traceIDs []model.TraceID) ([]*model.Trace, []structuredError, error) {
+	traces, err := aH.queryService.GetTraces(ctx, traceIDs)
+	if err != nil {
+		return nil, nil, err
+	}
 	var errors []structuredError
 	retMe := make([]*model.Trace, 0, len(traceIDs))
-	for _, traceID := range traceIDs {
-		if trace, err := aH.queryService.GetTrace(ctx, traceID); err != nil {
-			if err != spanstore.ErrTraceNotFound {
-				return nil, nil, err
-			}
+	for i, trace := range traces {
+		if trace == nil {
 			errors = append(errors, structuredError{
-				Msg:     err.Error(),
-				TraceID: ui.TraceID(traceID.String()),
+				Msg:     spanstore.ErrTraceNotFound.Error(),
+				TraceID: ui.TraceID(traceIDs[i].String()),
 			})
 		} else {
 			retMe = append(retMe, trace)
@@ -272,7 +617,7 @@ func (aH *APIHandler) dependencies(w http.ResponseWriter, r *http.Request) {
 	aH.writeJSON(w, r, &structuredRes)
 }
 
-func (aH *APIHandler) convertModelToUI(trace *model.Trace, adjust bool) (*ui.Trace, *structuredError) {
+func (aH *APIHandler) convertModelToUI(trace *model.Trace, adjust bool, breakdown bool) (*ui.Trace, *structuredError) {
 	var errors []error
 	if adjust {
 		var err error
@@ -282,6 +627,15 @@ func (aH *APIHandler) convertModelToUI(trace *model.Trace, adjust bool) (*ui.Tra
 		}
 	}
 	uiTrace := uiconv.FromDomain(trace)
+	if breakdown {
+		tree := model.NewTraceTree(trace)
+		for i, span := range trace.Spans {
+			// spans with a duplicate ID are left out of the tree
+			if node := tree.FindNode(span.SpanID); node != nil && node.Span == span {
+				uiTrace.Spans[i].Breakdown = uiconv.BreakdownFromDomain(node.Breakdown())
+			}
+		}
+	}
 	var uiError *structuredError
 	if err := multierror.Wrap(errors); err != nil {
 		uiError = &structuredError{
@@ -342,11 +696,21 @@ func (aH *APIHandler) parseTraceID(w http.ResponseWriter, r *http.Request) (mode
 // getTrace implements the REST API /traces/{trace-id}
 // It parses trace ID from the path, fetches the trace from QueryService,
 // formats it in the UI JSON format, and responds to the client.
+// With breakdown=true, each span also reports its self-time and the gaps between its children.
+// With format=folded the trace is exported as folded stacks for flame graph tools, and with
+// format=chrome in the Chrome Trace Event format for chrome://tracing and Perfetto.
 func (aH *APIHandler) getTrace(w http.ResponseWriter, r *http.Request) {
 	traceID, ok := aH.parseTraceID(w, r)
 	if !ok {
 		return
 	}
+	format := r.FormValue(formatParam)
+	switch format {
+	case "", formatFolded, formatChrome:
+	default:
+		aH.handleError(w, fmt.Errorf("unsupported format %q", format), http.StatusBadRequest)
+		return
+	}
 	trace, err := aH.queryService.GetTrace(r.Context(), traceID)
 	if err == spanstore.ErrTraceNotFound {
 		aH.handleError(w, err, http.StatusNotFound)
@@ -356,8 +720,13 @@ func (aH *APIHandler) getTrace(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if format != "" {
+		aH.exportTrace(w, r, trace, format)
+		return
+	}
+
 	var uiErrors []structuredError
-	uiTrace, uiErr := aH.convertModelToUI(trace, shouldAdjust(r))
+	uiTrace, uiErr := aH.convertModelToUI(trace, shouldAdjust(r), shouldBreakdown(r))
 	if uiErr != nil {
 		uiErrors = append(uiErrors, *uiErr)
 	}
@@ -371,12 +740,37 @@ func (aH *APIHandler) getTrace(w http.ResponseWriter, r *http.Request) {
 	aH.writeJSON(w, r, &structuredRes)
 }
 
+// exportTrace writes the trace in one of the export formats. These formats have no room for
+// the adjuster warnings, so the trace is exported as far as it could be adjusted.
+func (aH *APIHandler) exportTrace(w http.ResponseWriter, r *http.Request, trace *model.Trace, format string) {
+	if shouldAdjust(r) {
+		var err error
+		if trace, err = aH.queryService.Adjust(trace); err != nil {
+			aH.logger.Debug("Exporting a partially adjusted trace", zap.Error(err))
+		}
+	}
+	switch format {
+	case formatFolded:
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if err := folded.Write(w, trace); err != nil {
+			aH.logger.Error("Failed to write the folded stacks", zap.Error(err))
+		}
+	case formatChrome:
+		aH.writeJSON(w, r, chrome.FromDomain(trace))
+	}
+}
+
 func shouldAdjust(r *http.Request) bool {
 	raw := r.FormValue("raw")
 	isRaw, _ := strconv.ParseBool(raw)
 	return !isRaw
 }
 
+func shouldBreakdown(r *http.Request) bool {
+	breakdown, _ := strconv.ParseBool(r.FormValue(breakdownParam))
+	return breakdown
+}
+
 // archiveTrace implements the REST API POST:/archive/{trace-id}.
 // It passes the traceID to queryService.ArchiveTrace for writing.
 func (aH *APIHandler) archiveTrace(w http.ResponseWriter, r *http.Request) {
@@ -17,6 +17,8 @@ package app
 
 import (
 	"bytes"
+	"compress/gzip"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -39,7 +41,10 @@ import (
 	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
 	"github.com/jaegertracing/jaeger/model"
 	"github.com/jaegertracing/jaeger/model/adjuster"
+	"github.com/jaegertracing/jaeger/model/converter/chrome"
 	ui "github.com/jaegertracing/jaeger/model/json"
+	"github.com/jaegertracing/jaeger/model/latency"
+	"github.com/jaegertracing/jaeger/model/structure"
 	depsmocks "github.com/jaegertracing/jaeger/storage/dependencystore/mocks"
 	"github.com/jaegertracing/jaeger/storage/spanstore"
 	spanstoremocks "github.com/jaegertracing/jaeger/storage/spanstore/mocks"
@@ -277,6 +282,99 @@ func TestGetTrace(t *testing.T) {
 	}
 }
 
+func TestGetTraceBreakdown(t *testing.T) {
+	start := time.Unix(100, 0)
+	trace := &model.Trace{
+		Spans: []*model.Span{
+			{
+				TraceID:   mockTraceID,
+				SpanID:    model.NewSpanID(1),
+				StartTime: start,
+				Duration:  10 * time.Millisecond,
+				Process:   &model.Process{},
+			},
+			{
+				TraceID:    mockTraceID,
+				SpanID:     model.NewSpanID(2),
+				References: []model.SpanRef{model.NewChildOfRef(mockTraceID, model.NewSpanID(1))},
+				StartTime:  start.Add(2 * time.Millisecond),
+				Duration:   5 * time.Millisecond,
+				Process:    &model.Process{},
+			},
+		},
+	}
+	testCases := []struct {
+		suffix    string
+		breakdown bool
+	}{
+		{suffix: "", breakdown: false},
+		{suffix: "?breakdown=false", breakdown: false},
+		{suffix: "?breakdown=true", breakdown: true},
+	}
+	for _, tc := range testCases {
+		testCase := tc // capture loop var
+		t.Run(testCase.suffix, func(t *testing.T) {
+			server, readMock, _ := initializeTestServer()
+			defer server.Close()
+			readMock.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("model.TraceID")).
+				Return(trace, nil).Once()
+
+			var response structuredTraceResponse
+			err := getJSON(server.URL+`/api/traces/123456`+testCase.suffix, &response)
+			require.NoError(t, err)
+			require.Len(t, response.Traces, 1)
+			spans := response.Traces[0].Spans
+			require.Len(t, spans, 2)
+			if !testCase.breakdown {
+				assert.Nil(t, spans[0].Breakdown)
+				assert.Nil(t, spans[1].Breakdown)
+				return
+			}
+			require.NotNil(t, spans[0].Breakdown)
+			assert.EqualValues(t, 5000, spans[0].Breakdown.SelfTime)
+			assert.EqualValues(t, 5000, spans[0].Breakdown.ChildTime)
+			assert.Equal(t, []ui.Interval{
+				{StartTime: 100000000, Duration: 2000},
+				{StartTime: 100007000, Duration: 3000},
+			}, spans[0].Breakdown.Gaps)
+			require.NotNil(t, spans[1].Breakdown)
+			assert.EqualValues(t, 5000, spans[1].Breakdown.SelfTime)
+		})
+	}
+}
+
+func TestGetTraceFormats(t *testing.T) {
+	server, readMock, _ := initializeTestServer()
+	defer server.Close()
+	readMock.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("model.TraceID")).
+		Return(newParentChildTrace(), nil).Twice()
+
+	res, err := http.Get(server.URL + `/api/traces/123456?format=folded`)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "text/plain; charset=utf-8", res.Header.Get("Content-Type"))
+	body, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "frontend: 9000\nfrontend:;db: 1000\n", string(body))
+
+	var events chrome.Trace
+	require.NoError(t, getJSON(server.URL+`/api/traces/123456?format=chrome`, &events))
+	assert.Equal(t, "ms", events.DisplayTimeUnit)
+	var names []string
+	for _, event := range events.TraceEvents {
+		if event.Phase == chrome.PhaseComplete {
+			names = append(names, event.Category+":"+event.Name)
+		}
+	}
+	assert.Equal(t, []string{"db:", "frontend:"}, names)
+
+	var response structuredResponse
+	err = getJSON(server.URL+`/api/traces/123456?format=svg`, &response)
+	assert.EqualError(t, err, parsedError(400, `unsupported format \"svg\"`))
+	readMock.AssertExpectations(t)
+}
+
 func TestGetTraceDBFailure(t *testing.T) {
 	server, readMock, _ := initializeTestServer()
 	defer server.Close()
@@ -339,10 +437,52 @@ func TestSearchSuccess(t *testing.T) {
 	assert.Len(t, response.Errors, 0)
 }
 
+func TestSearchByPattern(t *testing.T) {
+	server, readMock, _ := initializeTestServer()
+	defer server.Close()
+	readMock.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+		Return(func(context.Context, *spanstore.TraceQueryParameters) []*model.Trace {
+			return []*model.Trace{newParentChildTrace()}
+		}, nil).Twice()
+
+	var response structuredResponse
+	err := getJSON(server.URL+`/api/traces?service=frontend&pattern=frontend+%3E+db`, &response)
+	assert.NoError(t, err)
+	assert.Len(t, response.Data, 1)
+
+	err = getJSON(server.URL+`/api/traces?service=frontend&pattern=db+%3E+frontend`, &response)
+	assert.NoError(t, err)
+	assert.Len(t, response.Data, 0)
+
+	err = getJSON(server.URL+`/api/traces?service=frontend&pattern=frontend+%3E`, &response)
+	assert.EqualError(t, err, parsedError(400, "cannot parse pattern param: expected a span selector, found end of pattern at offset 10"))
+	readMock.AssertExpectations(t)
+}
+
+func TestSearchByStructureHash(t *testing.T) {
+	server, readMock, _ := initializeTestServer()
+	defer server.Close()
+	readMock.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+		Return(func(context.Context, *spanstore.TraceQueryParameters) []*model.Trace {
+			return []*model.Trace{newParentChildTrace()}
+		}, nil).Twice()
+
+	hash := structure.Hash(structure.Signature(newParentChildTrace()))
+	var response structuredResponse
+	err := getJSON(server.URL+`/api/traces?service=frontend&structureHash=`+hash, &response)
+	assert.NoError(t, err)
+	assert.Len(t, response.Data, 1)
+
+	err = getJSON(server.URL+`/api/traces?service=frontend&structureHash=0123456789abcdef`, &response)
+	assert.NoError(t, err)
+	assert.Len(t, response.Data, 0)
+	readMock.AssertExpectations(t)
+}
+
 func TestSearchByTraceIDSuccess(t *testing.T) {
 	server, readMock, _ := initializeTestServer()
 	defer server.Close()
-	readMock.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("model.TraceID")).
+	readMock.On("GetTrace", mock.Anything, mock.AnythingOfType("model.TraceID")).
 		Return(mockTrace, nil).Twice()
 
 	var response structuredResponse
@@ -358,9 +498,9 @@ func TestSearchByTraceIDSuccessWithArchive(t *testing.T) {
 		ArchiveSpanReader: archiveReadMock,
 	})
 	defer server.Close()
-	readMock.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("model.TraceID")).
+	readMock.On("GetTrace", mock.Anything, mock.AnythingOfType("model.TraceID")).
 		Return(nil, spanstore.ErrTraceNotFound).Twice()
-	archiveReadMock.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("model.TraceID")).
+	archiveReadMock.On("GetTrace", mock.Anything, mock.AnythingOfType("model.TraceID")).
 		Return(mockTrace, nil).Twice()
 
 	var response structuredResponse
@@ -373,7 +513,7 @@ func TestSearchByTraceIDSuccessWithArchive(t *testing.T) {
 func TestSearchByTraceIDNotFound(t *testing.T) {
 	server, readMock, _ := initializeTestServer()
 	defer server.Close()
-	readMock.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("model.TraceID")).
+	readMock.On("GetTrace", mock.Anything, mock.AnythingOfType("model.TraceID")).
 		Return(nil, spanstore.ErrTraceNotFound).Once()
 
 	var response structuredResponse
@@ -387,7 +527,7 @@ func TestSearchByTraceIDFailure(t *testing.T) {
 	server, readMock, _ := initializeTestServer()
 	defer server.Close()
 	whatsamattayou := "https://youtu.be/WrKFOCg13QQ"
-	readMock.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("model.TraceID")).
+	readMock.On("GetTrace", mock.Anything, mock.AnythingOfType("model.TraceID")).
 		Return(nil, fmt.Errorf(whatsamattayou)).Once()
 
 	var response structuredResponse
@@ -395,6 +535,15 @@ func TestSearchByTraceIDFailure(t *testing.T) {
 	assert.EqualError(t, err, parsedError(500, whatsamattayou))
 }
 
+func TestSearchByTraceIDTooMany(t *testing.T) {
+	server, _, _, _ := initializeTestServerWithOptions(querysvc.QueryServiceOptions{MaxTraceIDsPerRequest: 1})
+	defer server.Close()
+
+	var response structuredResponse
+	err := getJSON(server.URL+`/api/traces?traceID=1&traceID=2`, &response)
+	assert.EqualError(t, err, parsedError(400, querysvc.ErrTooManyTraceIDs.Error()))
+}
+
 func TestSearchModelConversionFailure(t *testing.T) {
 	server, readMock, _, _ := initializeTestServerWithOptions(
 		querysvc.QueryServiceOptions{
@@ -424,6 +573,428 @@ func TestSearchDBFailure(t *testing.T) {
 	assert.EqualError(t, err, parsedError(500, "whatsamattayou"))
 }
 
+func TestTailProfile(t *testing.T) {
+	testCases := []struct {
+		suffix     string
+		cutoff     float64
+		tailTraces int
+	}{
+		{suffix: "", cutoff: 90, tailTraces: 1},
+		{suffix: "&cutoff=99.5", cutoff: 99.5, tailTraces: 1},
+		{suffix: "&structure=" + structure.Hash(structure.Signature(mockTrace)), cutoff: 90, tailTraces: 1},
+		{suffix: "&requestType=unknown", cutoff: 90, tailTraces: 0},
+	}
+	for _, tc := range testCases {
+		testCase := tc // capture loop var
+		t.Run(testCase.suffix, func(t *testing.T) {
+			server, readMock, _ := initializeTestServer()
+			defer server.Close()
+			readMock.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+				Return([]*model.Trace{mockTrace}, nil).Once()
+
+			var response struct {
+				Data   ui.TailProfile    `json:"data"`
+				Errors []structuredError `json:"errors"`
+			}
+			err := getJSON(server.URL+`/api/tail-profile?service=service`+testCase.suffix, &response)
+			require.NoError(t, err)
+			assert.Empty(t, response.Errors)
+			assert.Equal(t, testCase.cutoff, response.Data.Cutoff)
+			assert.Equal(t, testCase.tailTraces, response.Data.Traces.Tail.Count)
+		})
+	}
+}
+
+func TestRequestTypes(t *testing.T) {
+	server, readMock, _ := initializeTestServer()
+	defer server.Close()
+	readMock.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+		Return([]*model.Trace{newParentChildTrace()}, nil).Once()
+
+	var response struct {
+		Data   []ui.RequestTypeGroup `json:"data"`
+		Total  int                   `json:"total"`
+		Errors []structuredError     `json:"errors"`
+	}
+	err := getJSON(server.URL+`/api/request-types?service=service`, &response)
+	require.NoError(t, err)
+	assert.Empty(t, response.Errors)
+	assert.Equal(t, 1, response.Total)
+	require.Len(t, response.Data, 1)
+	assert.Equal(t, "frontend:", response.Data[0].RequestType)
+	assert.Len(t, response.Data[0].TraceIDs, 1)
+	assert.Equal(t, int64(10000), response.Data[0].Latency.Mean)
+
+	err = getJSON(server.URL+`/api/request-types?traceID=1`, &response)
+	assert.EqualError(t, err, parsedError(400, "parameter 'service' is required"))
+}
+
+func TestRequestTypesDBFailure(t *testing.T) {
+	server, readMock, _ := initializeTestServer()
+	defer server.Close()
+	readMock.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+		Return(nil, errStorage).Once()
+
+	var response structuredResponse
+	err := getJSON(server.URL+`/api/request-types?service=service`, &response)
+	assert.EqualError(t, err, parsedError(500, errStorage.Error()))
+}
+
+func TestTailProfileFailures(t *testing.T) {
+	tests := []struct {
+		urlStr string
+		errMsg string
+	}{
+		{
+			`/api/tail-profile?traceID=1`,
+			parsedError(400, "parameter 'service' is required"),
+		},
+		{
+			`/api/tail-profile?service=service&cutoff=0`,
+			parsedError(400, "unable to parse cutoff: 'cutoff' must be between 0 and 100, exclusive"),
+		},
+		{
+			`/api/tail-profile?service=service&cutoff=100`,
+			parsedError(400, "unable to parse cutoff: 'cutoff' must be between 0 and 100, exclusive"),
+		},
+	}
+	for _, test := range tests {
+		server, _, _ := initializeTestServer()
+		var response structuredResponse
+		err := getJSON(server.URL+test.urlStr, &response)
+		assert.EqualError(t, err, test.errMsg)
+		server.Close()
+	}
+}
+
+func TestTailProfileUnknownStructure(t *testing.T) {
+	server, readMock, _ := initializeTestServer()
+	defer server.Close()
+	readMock.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+		Return([]*model.Trace{mockTrace}, nil).Once()
+
+	var response structuredResponse
+	err := getJSON(server.URL+`/api/tail-profile?service=service&structure=0000000000000000`, &response)
+	assert.EqualError(t, err, parsedError(404, querysvc.ErrStructureGroupNotFound.Error()))
+}
+
+func TestTailProfileDBFailure(t *testing.T) {
+	server, readMock, _ := initializeTestServer()
+	defer server.Close()
+	readMock.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+		Return(nil, errStorage).Once()
+
+	var response structuredResponse
+	err := getJSON(server.URL+`/api/tail-profile?service=service`, &response)
+	assert.EqualError(t, err, parsedError(500, errStorage.Error()))
+}
+
+func TestAggregateTrace(t *testing.T) {
+	server, readMock, _ := initializeTestServer()
+	defer server.Close()
+	readMock.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+		Return([]*model.Trace{mockTrace}, nil)
+
+	var response structuredTraceResponse
+	hash := structure.Hash(structure.Signature(mockTrace))
+	err := getJSON(server.URL+`/api/structures/`+hash+`/aggregate?service=service`, &response)
+	require.NoError(t, err)
+	assert.Empty(t, response.Errors)
+	require.Len(t, response.Traces, 1)
+	aggregate := response.Traces[0]
+	assert.Len(t, aggregate.Spans, 2)
+
+	// the synthetic trace is served from the cache, without reaching the span reader
+	var cached structuredTraceResponse
+	err = getJSON(server.URL+`/api/traces/`+string(aggregate.TraceID), &cached)
+	require.NoError(t, err)
+	require.Len(t, cached.Traces, 1)
+	assert.Equal(t, aggregate.TraceID, cached.Traces[0].TraceID)
+	assert.Len(t, cached.Traces[0].Spans, 2)
+
+	err = getJSON(server.URL+`/api/structures/0000000000000000/aggregate?service=service`, &response)
+	assert.EqualError(t, err, parsedError(404, querysvc.ErrStructureGroupNotFound.Error()))
+}
+
+// newParentChildTrace returns a frontend span of 10ms calling the db 2ms after it starts, for 1ms.
+func newParentChildTrace() *model.Trace {
+	return &model.Trace{
+		Spans: []*model.Span{
+			{
+				TraceID:   mockTraceID,
+				SpanID:    model.NewSpanID(1),
+				StartTime: time.Unix(0, 0),
+				Duration:  10 * time.Millisecond,
+				Process:   &model.Process{ServiceName: "frontend"},
+			},
+			{
+				TraceID:    mockTraceID,
+				SpanID:     model.NewSpanID(2),
+				References: []model.SpanRef{model.NewChildOfRef(mockTraceID, model.NewSpanID(1))},
+				StartTime:  time.Unix(0, 0).Add(2 * time.Millisecond),
+				Duration:   time.Millisecond,
+				Process:    &model.Process{ServiceName: "db"},
+			},
+		},
+	}
+}
+
+func TestChildDiffs(t *testing.T) {
+	server, readMock, _ := initializeTestServer()
+	defer server.Close()
+	trace := newParentChildTrace()
+	readMock.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+		Return([]*model.Trace{trace}, nil)
+
+	var response struct {
+		Data   []ui.ChildDiffProfile `json:"data"`
+		Errors []structuredError     `json:"errors"`
+	}
+	hash := structure.Hash(structure.Signature(trace))
+	err := getJSON(server.URL+`/api/structures/`+hash+`/child-diffs?service=service`, &response)
+	require.NoError(t, err)
+	assert.Empty(t, response.Errors)
+	require.Len(t, response.Data, 1)
+	assert.Equal(t, "frontend#0", response.Data[0].Path)
+	require.Len(t, response.Data[0].ChildDiffs, 1)
+	assert.Equal(t, int64(2000), response.Data[0].ChildDiffs[0].Tail.Mean)
+	assert.Equal(t, int64(7000), response.Data[0].EndDiff.Tail.Mean)
+
+	err = getJSON(server.URL+`/api/structures/0000000000000000/child-diffs?service=service`, &response)
+	assert.EqualError(t, err, parsedError(404, querysvc.ErrStructureGroupNotFound.Error()))
+}
+
+func TestCriticalPath(t *testing.T) {
+	server, readMock, _ := initializeTestServer()
+	defer server.Close()
+	readMock.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("model.TraceID")).
+		Return(newParentChildTrace(), nil).Once()
+
+	var response struct {
+		Data   []ui.CriticalPathSegment `json:"data"`
+		Errors []structuredError        `json:"errors"`
+	}
+	err := getJSON(server.URL+`/api/traces/123456/critical-path`, &response)
+	require.NoError(t, err)
+	assert.Empty(t, response.Errors)
+	require.Len(t, response.Data, 3)
+	assert.Equal(t, ui.SpanID("1"), response.Data[0].SpanID)
+	assert.Equal(t, ui.SpanID("2"), response.Data[1].SpanID)
+	assert.Equal(t, "db", response.Data[1].ServiceName)
+	assert.Equal(t, uint64(1000), response.Data[1].Duration)
+}
+
+func TestCriticalPathFailures(t *testing.T) {
+	server, readMock, _ := initializeTestServer()
+	defer server.Close()
+	readMock.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("model.TraceID")).
+		Return(nil, spanstore.ErrTraceNotFound).Once()
+
+	var response structuredResponse
+	err := getJSON(server.URL+`/api/traces/123456/critical-path`, &response)
+	assert.EqualError(t, err, parsedError(404, spanstore.ErrTraceNotFound.Error()))
+
+	err = getJSON(server.URL+`/api/traces/xyz/critical-path`, &response)
+	assert.Error(t, err)
+}
+
+func TestDiffTraces(t *testing.T) {
+	server, readMock, _ := initializeTestServer()
+	defer server.Close()
+	slower := newParentChildTrace()
+	slower.Spans[1].Duration = 3 * time.Millisecond
+	readMock.On("GetTrace", mock.Anything, model.NewTraceID(0, 1)).
+		Return(newParentChildTrace(), nil).Once()
+	readMock.On("GetTrace", mock.Anything, model.NewTraceID(0, 2)).
+		Return(slower, nil).Once()
+
+	var response struct {
+		Data   ui.TraceDiff      `json:"data"`
+		Errors []structuredError `json:"errors"`
+	}
+	err := getJSON(server.URL+`/api/traces/2/diff/1`, &response)
+	require.NoError(t, err)
+	assert.Empty(t, response.Errors)
+	assert.Zero(t, response.Data.Missing)
+	assert.Zero(t, response.Data.Extra)
+	require.Len(t, response.Data.Spans, 2)
+	assert.Equal(t, "frontend#0~db#0", response.Data.Spans[1].Path)
+	assert.Equal(t, int64(2000), response.Data.Spans[1].DurationDelta)
+	assert.Equal(t, uint64(1000), response.Data.Spans[1].Baseline.Duration)
+	assert.Equal(t, uint64(3000), response.Data.Spans[1].Current.Duration)
+}
+
+func TestDiffTracesFailures(t *testing.T) {
+	server, readMock, _ := initializeTestServer()
+	defer server.Close()
+	readMock.On("GetTrace", mock.Anything, model.NewTraceID(0, 1)).
+		Return(newParentChildTrace(), nil)
+	readMock.On("GetTrace", mock.Anything, model.NewTraceID(0, 2)).
+		Return(nil, spanstore.ErrTraceNotFound)
+	readMock.On("GetTrace", mock.Anything, model.NewTraceID(0, 3)).
+		Return(nil, errStorage)
+
+	var response structuredResponse
+	err := getJSON(server.URL+`/api/traces/1/diff/2`, &response)
+	assert.EqualError(t, err, parsedError(404, spanstore.ErrTraceNotFound.Error()))
+
+	err = getJSON(server.URL+`/api/traces/1/diff/3`, &response)
+	assert.EqualError(t, err, parsedError(500, errStorage.Error()))
+
+	err = getJSON(server.URL+`/api/traces/1/diff/xyz`, &response)
+	assert.EqualError(t, err, parsedError(400, `strconv.ParseUint: parsing \"xyz\": invalid syntax`))
+
+	err = getJSON(server.URL+`/api/traces/xyz/diff/1`, &response)
+	assert.Error(t, err)
+}
+
+func TestCriticalPathContributions(t *testing.T) {
+	server, readMock, _ := initializeTestServer()
+	defer server.Close()
+	readMock.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+		Return([]*model.Trace{newParentChildTrace()}, nil).Once()
+
+	var response struct {
+		Data   []ui.CriticalPathContribution `json:"data"`
+		Errors []structuredError             `json:"errors"`
+	}
+	err := getJSON(server.URL+`/api/critical-path?service=service`, &response)
+	require.NoError(t, err)
+	assert.Empty(t, response.Errors)
+	require.Len(t, response.Data, 2)
+	assert.Equal(t, "frontend", response.Data[0].Service)
+	assert.Equal(t, int64(9000), response.Data[0].Total)
+	assert.Equal(t, 1, response.Data[0].Traces)
+
+	err = getJSON(server.URL+`/api/critical-path?traceID=1`, &response)
+	assert.EqualError(t, err, parsedError(400, "parameter 'service' is required"))
+}
+
+func TestCriticalPathContributionsDBFailure(t *testing.T) {
+	server, readMock, _ := initializeTestServer()
+	defer server.Close()
+	readMock.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+		Return(nil, errStorage).Once()
+
+	var response structuredResponse
+	err := getJSON(server.URL+`/api/critical-path?service=service`, &response)
+	assert.EqualError(t, err, parsedError(500, errStorage.Error()))
+}
+
+func TestRegression(t *testing.T) {
+	server, readMock, _ := initializeTestServer()
+	defer server.Close()
+	matchWindow := func(start, end int64) interface{} {
+		return mock.MatchedBy(func(query *spanstore.TraceQueryParameters) bool {
+			return query.ServiceName == "service" &&
+				query.StartTimeMin.Equal(time.Unix(start, 0)) && query.StartTimeMax.Equal(time.Unix(end, 0))
+		})
+	}
+	readMock.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), matchWindow(1000, 2000)).
+		Return([]*model.Trace{newParentChildTrace()}, nil).Once()
+	readMock.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), matchWindow(2000, 3000)).
+		Return([]*model.Trace{mockTrace}, nil).Once()
+	readMock.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), matchWindow(500, 600)).
+		Return([]*model.Trace{}, nil).Once()
+	readMock.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), matchWindow(2000, 3000)).
+		Return([]*model.Trace{mockTrace}, nil).Once()
+
+	var response struct {
+		Data   ui.Regression     `json:"data"`
+		Errors []structuredError `json:"errors"`
+	}
+	err := getJSON(server.URL+`/api/regression?service=service&start=2000000000&end=3000000000&significance=0.01`, &response)
+	require.NoError(t, err)
+	assert.Empty(t, response.Errors)
+	assert.Equal(t, 0.01, response.Data.Significance)
+	assert.Equal(t, 1, response.Data.Traces.Baseline.Count)
+	assert.Equal(t, 1, response.Data.Traces.Current.Count)
+	assert.Len(t, response.Data.Appeared, 1)
+	assert.Len(t, response.Data.Disappeared, 1)
+
+	err = getJSON(server.URL+`/api/regression?service=service&start=2000000000&end=3000000000&baselineStart=500000000&baselineEnd=600000000`, &response)
+	require.NoError(t, err)
+	assert.Equal(t, latency.DefaultSignificance, response.Data.Significance)
+	assert.Equal(t, 0, response.Data.Traces.Baseline.Count)
+	assert.Len(t, response.Data.Appeared, 1)
+	assert.Empty(t, response.Data.Disappeared)
+	readMock.AssertExpectations(t)
+}
+
+func TestProfile(t *testing.T) {
+	server, readMock, _ := initializeTestServer()
+	defer server.Close()
+	readMock.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+		Return([]*model.Trace{newParentChildTrace()}, nil).Twice()
+
+	res, err := http.Get(server.URL + `/api/profile?service=service`)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "application/octet-stream", res.Header.Get("Content-Type"))
+	gz, err := gzip.NewReader(res.Body)
+	require.NoError(t, err)
+	body, err := ioutil.ReadAll(gz)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), "frontend:")
+
+	var response structuredResponse
+	err = getJSON(server.URL+`/api/profile?service=service&structure=unknown`, &response)
+	assert.EqualError(t, err, parsedError(404, querysvc.ErrStructureGroupNotFound.Error()))
+}
+
+func TestProfileFailures(t *testing.T) {
+	server, readMock, _ := initializeTestServer()
+	defer server.Close()
+	readMock.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+		Return(nil, errStorage).Once()
+
+	var response structuredResponse
+	err := getJSON(server.URL+`/api/profile?service=service`, &response)
+	assert.EqualError(t, err, parsedError(500, errStorage.Error()))
+
+	err = getJSON(server.URL+`/api/profile?traceID=1`, &response)
+	assert.EqualError(t, err, parsedError(400, "parameter 'service' is required"))
+}
+
+func TestRegressionFailures(t *testing.T) {
+	tests := []struct {
+		urlStr string
+		errMsg string
+	}{
+		{
+			`/api/regression?traceID=1`,
+			parsedError(400, "parameter 'service' is required"),
+		},
+		{
+			`/api/regression?service=service&baselineStart=abc`,
+			parsedError(400, `unable to parse baselineStart: strconv.ParseInt: parsing \"abc\": invalid syntax`),
+		},
+		{
+			`/api/regression?service=service&significance=1`,
+			parsedError(400, "unable to parse significance: 'significance' must be between 0 and 1, exclusive"),
+		},
+	}
+	for _, test := range tests {
+		server, _, _ := initializeTestServer()
+		var response structuredResponse
+		err := getJSON(server.URL+test.urlStr, &response)
+		assert.EqualError(t, err, test.errMsg)
+		server.Close()
+	}
+}
+
+func TestRegressionDBFailure(t *testing.T) {
+	server, readMock, _ := initializeTestServer()
+	defer server.Close()
+	readMock.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+		Return(nil, errStorage).Once()
+
+	var response structuredResponse
+	err := getJSON(server.URL+`/api/regression?service=service`, &response)
+	assert.EqualError(t, err, parsedError(500, errStorage.Error()))
+}
+
 func TestSearchFailures(t *testing.T) {
 	tests := []struct {
 		urlStr string
@@ -596,3 +1167,40 @@ func execJSON(req *http.Request, out interface{}) error {
 func parsedError(code int, err string) string {
 	return fmt.Sprintf(`%d error from server: {"data":null,"total":0,"limit":0,"offset":0,"errors":[{"code":%d,"msg":"%s"}]}`+"\n", code, code, err)
 }
+
+func TestStructureCounts(t *testing.T) {
+	structureReader := &spanstoremocks.StructureReader{}
+	server, _, _, _ := initializeTestServerWithHandler(querysvc.QueryServiceOptions{StructureReader: structureReader})
+	defer server.Close()
+	structureReader.On("CountStructures", mock.AnythingOfType("*context.valueCtx"), time.Unix(1, 0), time.Unix(2, 0)).
+		Return(map[string]int{"0123456789abcdef": 1, "fedcba9876543210": 3}, nil).Once()
+	structureReader.On("CountStructures", mock.AnythingOfType("*context.valueCtx"), time.Unix(2, 0), time.Unix(3, 0)).
+		Return(nil, errStorage).Once()
+
+	var response struct {
+		Data   []ui.StructureCount `json:"data"`
+		Errors []structuredError   `json:"errors"`
+	}
+	err := getJSON(server.URL+`/api/structures?start=1000000&end=2000000`, &response)
+	require.NoError(t, err)
+	assert.Equal(t, []ui.StructureCount{
+		{Hash: "fedcba9876543210", Traces: 3},
+		{Hash: "0123456789abcdef", Traces: 1},
+	}, response.Data)
+
+	err = getJSON(server.URL+`/api/structures?start=2000000&end=3000000`, &response)
+	assert.EqualError(t, err, parsedError(500, errStorageMsg))
+
+	err = getJSON(server.URL+`/api/structures?start=yesterday`, &response)
+	assert.EqualError(t, err, parsedError(400, `unable to parse start: strconv.ParseInt: parsing \"yesterday\": invalid syntax`))
+	structureReader.AssertExpectations(t)
+}
+
+func TestStructureCountsNotSupported(t *testing.T) {
+	server, _, _ := initializeTestServer()
+	defer server.Close()
+
+	var response structuredResponse
+	err := getJSON(server.URL+`/api/structures`, &response)
+	assert.EqualError(t, err, parsedError(501, "structure index not supported"))
+}
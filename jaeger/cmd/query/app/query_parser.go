@@ -26,22 +26,26 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/model/structure/pattern"
 	"github.com/jaegertracing/jaeger/storage/spanstore"
 )
 
 const (
 	defaultQueryLimit = 100
 
-	operationParam   = "operation"
-	tagParam         = "tag"
-	tagsParam        = "tags"
-	startTimeParam   = "start"
-	limitParam       = "limit"
-	minDurationParam = "minDuration"
-	maxDurationParam = "maxDuration"
-	serviceParam     = "service"
-	endTimeParam     = "end"
-	prettyPrintParam = "prettyPrint"
+	operationParam     = "operation"
+	tagParam           = "tag"
+	tagsParam          = "tags"
+	startTimeParam     = "start"
+	limitParam         = "limit"
+	minDurationParam   = "minDuration"
+	maxDurationParam   = "maxDuration"
+	serviceParam       = "service"
+	endTimeParam       = "end"
+	prettyPrintParam   = "prettyPrint"
+	integrityParam     = "integrity"
+	patternParam       = "pattern"
+	structureHashParam = "structureHash"
 )
 
 var (
@@ -65,7 +69,7 @@ type traceQueryParameters struct {
 // parse takes a request and constructs a model of parameters
 // Trace query syntax:
 //     query ::= param | param '&' query
-//     param ::= service | operation | limit | start | end | minDuration | maxDuration | tag | tags
+//     param ::= service | operation | limit | start | end | minDuration | maxDuration | tag | tags | integrity | pattern | structureHash
 //     service ::= 'service=' strValue
 //     operation ::= 'operation=' strValue
 //     limit ::= 'limit=' intValue
@@ -77,6 +81,9 @@ type traceQueryParameters struct {
 //     key := strValue
 //     keyValue := strValue ':' strValue
 //     tags :== 'tags=' jsonMap
+//     integrity ::= 'integrity=' strValue ("complete", or comma separated "dropped-span", "orphaned", "multi-parent", "cross-trace-reference")
+//     pattern ::= 'pattern=' strValue (span tree pattern, see package model/structure/pattern)
+//     structureHash ::= 'structureHash=' strValue (structural hash, see structure.Hash)
 func (p *queryParser) parse(r *http.Request) (*traceQueryParameters, error) {
 	service := r.FormValue(serviceParam)
 	operation := r.FormValue(operationParam)
@@ -115,6 +122,22 @@ func (p *queryParser) parse(r *http.Request) (*traceQueryParameters, error) {
 		return nil, err
 	}
 
+	var integrity []model.IntegrityStatus
+	for _, value := range r.Form[integrityParam] {
+		status, err := model.IntegrityStatusFromString(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot parse %s param", integrityParam)
+		}
+		integrity = append(integrity, status)
+	}
+
+	structure := r.FormValue(patternParam)
+	if structure != "" {
+		if _, err := pattern.Parse(structure); err != nil {
+			return nil, errors.Wrapf(err, "cannot parse %s param", patternParam)
+		}
+	}
+
 	var traceIDs []model.TraceID
 	for _, id := range r.Form[traceIDParam] {
 		if traceID, err := model.TraceIDFromString(id); err == nil {
@@ -134,6 +157,9 @@ func (p *queryParser) parse(r *http.Request) (*traceQueryParameters, error) {
 			NumTraces:     limit,
 			DurationMin:   minDuration,
 			DurationMax:   maxDuration,
+			Integrity:     integrity,
+			Pattern:       structure,
+			StructureHash: r.FormValue(structureHashParam),
 		},
 		traceIDs: traceIDs,
 	}
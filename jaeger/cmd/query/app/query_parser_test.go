@@ -112,6 +112,47 @@ func TestParseTraceQuery(t *testing.T) {
 				},
 			},
 		},
+		{"x?service=service&start=0&end=0&integrity=complete&integrity=dropped-span,orphaned", noErr,
+			&traceQueryParameters{
+				TraceQueryParameters: spanstore.TraceQueryParameters{
+					ServiceName:  "service",
+					StartTimeMin: time.Unix(0, 0),
+					StartTimeMax: time.Unix(0, 0),
+					NumTraces:    100,
+					Tags:         make(map[string]string),
+					Integrity: []model.IntegrityStatus{
+						model.IntegrityComplete,
+						model.IntegrityDroppedSpan | model.IntegrityOrphaned,
+					},
+				},
+			},
+		},
+		{"x?service=service&integrity=broken", `cannot parse integrity param: unknown trace integrity status: "broken"`, nil},
+		{"x?service=service&start=0&end=0&pattern=frontend+%3E+db", noErr,
+			&traceQueryParameters{
+				TraceQueryParameters: spanstore.TraceQueryParameters{
+					ServiceName:  "service",
+					StartTimeMin: time.Unix(0, 0),
+					StartTimeMax: time.Unix(0, 0),
+					NumTraces:    100,
+					Tags:         make(map[string]string),
+					Pattern:      "frontend > db",
+				},
+			},
+		},
+		{"x?service=service&start=0&end=0&structureHash=0123456789abcdef", noErr,
+			&traceQueryParameters{
+				TraceQueryParameters: spanstore.TraceQueryParameters{
+					ServiceName:   "service",
+					StartTimeMin:  time.Unix(0, 0),
+					StartTimeMax:  time.Unix(0, 0),
+					NumTraces:     100,
+					Tags:          make(map[string]string),
+					StructureHash: "0123456789abcdef",
+				},
+			},
+		},
+		{"x?service=service&pattern=frontend+%3E", "cannot parse pattern param: expected a span selector, found end of pattern at offset 10", nil},
 		// trace ID in upper/lower case
 		{"x?traceID=1f00&traceID=1E00", noErr,
 			&traceQueryParameters{
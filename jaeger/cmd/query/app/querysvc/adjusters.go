@@ -20,11 +20,5 @@ import (
 )
 
 // StandardAdjusters is a list of model adjusters applied by the query service
-// before returning the data to the API clients.
-var StandardAdjusters = []adjuster.Adjuster{
-	adjuster.SpanIDDeduper(),
-	adjuster.ClockSkew(),
-	adjuster.IPTagAdjuster(),
-	adjuster.SortLogFields(),
-	adjuster.SpanReferences(),
-}
+// before returning the data to the API clients, see adjuster.StandardAdjusters.
+var StandardAdjusters = adjuster.StandardAdjusters
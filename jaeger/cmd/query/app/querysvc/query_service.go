@@ -17,20 +17,48 @@ package querysvc
 import (
 	"context"
 	"errors"
+	"hash/fnv"
+	"sort"
 	"time"
 
 	"go.uber.org/zap"
 
 	"github.com/jaegertracing/jaeger/model"
 	"github.com/jaegertracing/jaeger/model/adjuster"
+	"github.com/jaegertracing/jaeger/model/latency"
+	"github.com/jaegertracing/jaeger/model/requesttype"
+	"github.com/jaegertracing/jaeger/model/structure"
+	"github.com/jaegertracing/jaeger/model/structure/pattern"
+	"github.com/jaegertracing/jaeger/pkg/cache"
 	"github.com/jaegertracing/jaeger/pkg/multierror"
+	"github.com/jaegertracing/jaeger/pkg/stats"
 	"github.com/jaegertracing/jaeger/storage"
 	"github.com/jaegertracing/jaeger/storage/dependencystore"
 	"github.com/jaegertracing/jaeger/storage/spanstore"
 )
 
+const (
+	// syntheticTraceIDHigh is the high part of the IDs of the synthetic traces built by GetAggregateTrace.
+	syntheticTraceIDHigh = 0x5e5e5e5e5e5e5e5e
+	maxSyntheticTraces   = 100
+
+	defaultMaxConcurrentTraceFetches = 8
+	defaultMaxTraceIDsPerRequest     = 1000
+	defaultMaxFilteredTraces         = 2000
+
+	// filteredTracesGrowth is the factor by which FindTraces widens its reads while filtering
+	filteredTracesGrowth = 4
+)
+
 var (
 	errNoArchiveSpanStorage = errors.New("archive span storage was not configured")
+
+	// ErrStructureGroupNotFound occurs when no trace matching the query has the requested structure
+	ErrStructureGroupNotFound = errors.New("structure group not found")
+
+	// ErrTooManyTraceIDs occurs when more traces are requested at once than
+	// QueryServiceOptions.MaxTraceIDsPerRequest allows
+	ErrTooManyTraceIDs = errors.New("too many trace IDs in the request")
 )
 
 // QueryServiceOptions has optional members of QueryService
@@ -38,6 +66,52 @@ type QueryServiceOptions struct {
 	ArchiveSpanReader spanstore.Reader
 	ArchiveSpanWriter spanstore.Writer
 	Adjuster          adjuster.Adjuster
+	// MaxConcurrentTraceFetches bounds the number of traces fetched concurrently by GetTraces.
+	MaxConcurrentTraceFetches int
+	// MaxTraceIDsPerRequest bounds the number of traces requested at once from GetTraces.
+	MaxTraceIDsPerRequest int
+	// MaxFilteredTraces bounds the number of traces read by FindTraces to find the
+	// spanstore.TraceQueryParameters.NumTraces ones matching the filters of the query service.
+	MaxFilteredTraces int
+	// RequestTypes classifies the traces into request types, by the service and operation
+	// of their root span when nil.
+	RequestTypes *requesttype.Classifier
+	// StructureReader counts the traces by structural hash. It must only be set when the
+	// structures are indexed, e.g. by the collector, since the span reader is then expected
+	// to filter the traces by spanstore.TraceQueryParameters.StructureHash itself.
+	StructureReader spanstore.StructureReader
+}
+
+// StructureGroup is a set of traces sharing the same call structure, see structure.Signature.
+type StructureGroup struct {
+	Signature string
+	Hash      string
+	TraceIDs  []model.TraceID
+	Latency   stats.Summary
+}
+
+// RequestTypeGroup is a set of traces of the same request type, see requesttype.Classifier.
+type RequestTypeGroup struct {
+	RequestType string
+	TraceIDs    []model.TraceID
+	Latency     stats.Summary
+}
+
+// TailProfileParameters configure QueryService.GetTailProfile.
+type TailProfileParameters struct {
+	// Cutoff is the percentile of the trace durations separating the norm from the tail traces.
+	Cutoff float64
+	// StructureHash restricts the profile to one structural group when not empty.
+	StructureHash string
+	// RequestType restricts the profile to the traces of one request type when not empty.
+	RequestType string
+}
+
+// RegressionParameters configure QueryService.GetRegression.
+type RegressionParameters struct {
+	// Significance is the p-value below which a latency shift is reported,
+	// latency.DefaultSignificance when zero.
+	Significance float64
 }
 
 // QueryService contains span utils required by the query-service.
@@ -45,6 +119,7 @@ type QueryService struct {
 	spanReader       spanstore.Reader
 	dependencyReader dependencystore.Reader
 	options          QueryServiceOptions
+	syntheticTraces  cache.Cache
 }
 
 // NewQueryService returns a new QueryService.
@@ -53,26 +128,119 @@ func NewQueryService(spanReader spanstore.Reader, dependencyReader dependencysto
 		spanReader:       spanReader,
 		dependencyReader: dependencyReader,
 		options:          options,
+		syntheticTraces:  cache.NewLRU(maxSyntheticTraces),
 	}
 
 	if qsvc.options.Adjuster == nil {
-		qsvc.options.Adjuster = adjuster.Sequence(StandardAdjusters...)
+		qsvc.options.Adjuster = adjuster.Sequence(adjuster.StandardAdjusters...)
+	}
+	if qsvc.options.MaxConcurrentTraceFetches <= 0 {
+		qsvc.options.MaxConcurrentTraceFetches = defaultMaxConcurrentTraceFetches
+	}
+	if qsvc.options.MaxTraceIDsPerRequest <= 0 {
+		qsvc.options.MaxTraceIDsPerRequest = defaultMaxTraceIDsPerRequest
+	}
+	if qsvc.options.MaxFilteredTraces <= 0 {
+		qsvc.options.MaxFilteredTraces = defaultMaxFilteredTraces
 	}
 	return qsvc
 }
 
 // GetTrace is the queryService implementation of spanstore.Reader.GetTrace
 func (qs QueryService) GetTrace(ctx context.Context, traceID model.TraceID) (*model.Trace, error) {
-	trace, err := qs.spanReader.GetTrace(ctx, traceID)
+	if traceID.High == syntheticTraceIDHigh {
+		if trace, ok := qs.syntheticTraces.Get(traceID.String()).(*model.Trace); ok {
+			// the callers adjust the traces in place
+			return trace.DeepCopy(), nil
+		}
+	}
+	trace, err := getTrace(ctx, qs.spanReader, traceID)
 	if err == spanstore.ErrTraceNotFound {
 		if qs.options.ArchiveSpanReader == nil {
 			return nil, err
 		}
-		trace, err = qs.options.ArchiveSpanReader.GetTrace(ctx, traceID)
+		trace, err = getTrace(ctx, qs.options.ArchiveSpanReader, traceID)
 	}
 	return trace, err
 }
 
+// getTrace reports missing traces as spanstore.ErrTraceNotFound, which is not what all span
+// readers do, e.g. badger returns a nil trace.
+func getTrace(ctx context.Context, reader spanstore.Reader, traceID model.TraceID) (*model.Trace, error) {
+	trace, err := reader.GetTrace(ctx, traceID)
+	if err == nil && trace == nil {
+		return nil, spanstore.ErrTraceNotFound
+	}
+	return trace, err
+}
+
+// GetTraces fetches the traces with the given IDs like StreamTraces, and returns them in the
+// order of traceIDs, with a nil trace for each ID that is not found.
+func (qs QueryService) GetTraces(ctx context.Context, traceIDs []model.TraceID) ([]*model.Trace, error) {
+	traces := make([]*model.Trace, 0, len(traceIDs))
+	err := qs.StreamTraces(ctx, traceIDs, func(trace *model.Trace) error {
+		traces = append(traces, trace)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return traces, nil
+}
+
+type fetchResult struct {
+	trace *model.Trace
+	err   error
+}
+
+// StreamTraces fetches the traces with the given IDs concurrently, like GetTrace, and passes
+// them to fn in the order of traceIDs, with a nil trace for each ID that is not found. A trace
+// is passed as soon as it and the ones before it are fetched, and at most
+// MaxConcurrentTraceFetches traces are fetched and not yet passed at any time. Any other
+// failure, or an error returned by fn, cancels the fetches and is returned. It fails with
+// ErrTooManyTraceIDs when there are more than MaxTraceIDsPerRequest IDs.
+func (qs QueryService) StreamTraces(ctx context.Context, traceIDs []model.TraceID, fn func(trace *model.Trace) error) error {
+	if len(traceIDs) > qs.options.MaxTraceIDsPerRequest {
+		return ErrTooManyTraceIDs
+	}
+	// stops queuing fetches, and cancels the ones in flight, when returning early
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	done := make(chan struct{})
+	defer close(done)
+
+	// the results are queued in request order, each one being filled by its own fetch
+	queue := make(chan chan fetchResult, qs.options.MaxConcurrentTraceFetches-1)
+	go func() {
+		defer close(queue)
+		for _, traceID := range traceIDs {
+			result := make(chan fetchResult, 1)
+			select {
+			case queue <- result:
+			case <-done:
+				return
+			}
+			go func(traceID model.TraceID) {
+				trace, err := qs.GetTrace(ctx, traceID)
+				result <- fetchResult{trace: trace, err: err}
+			}(traceID)
+		}
+	}()
+	for result := range queue {
+		fetched := <-result
+		if fetched.err == spanstore.ErrTraceNotFound {
+			fetched.trace, fetched.err = nil, nil
+		}
+		if fetched.err != nil {
+			return fetched.err
+		}
+		if err := fn(fetched.trace); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // GetServices is the queryService implementation of spanstore.Reader.GetServices
 func (qs QueryService) GetServices(ctx context.Context) ([]string, error) {
 	return qs.spanReader.GetServices(ctx)
@@ -84,8 +252,372 @@ func (qs QueryService) GetOperations(ctx context.Context, service string) ([]str
 }
 
 // FindTraces is the queryService implementation of spanstore.Reader.FindTraces
+// The results are filtered by query.Integrity and query.Pattern, if set, and by
+// query.StructureHash when the structures are not indexed by the storage. Since the span
+// reader limits the traces to query.NumTraces before they are filtered, more traces are read
+// until query.NumTraces of them match, or the reader has no more traces, or MaxFilteredTraces
+// traces are read, in which case fewer traces than matching ones may be returned. The newest
+// traces are returned when more than query.NumTraces match.
 func (qs QueryService) FindTraces(ctx context.Context, query *spanstore.TraceQueryParameters) ([]*model.Trace, error) {
-	return qs.spanReader.FindTraces(ctx, query)
+	var structurePattern *pattern.Pattern
+	if query.Pattern != "" {
+		var err error
+		if structurePattern, err = pattern.Parse(query.Pattern); err != nil {
+			return nil, err
+		}
+	}
+	if !qs.filtersTraces(query) {
+		return qs.spanReader.FindTraces(ctx, query)
+	}
+	candidates := *query
+	for {
+		traces, err := qs.spanReader.FindTraces(ctx, &candidates)
+		if err != nil {
+			return nil, err
+		}
+		filtered := make([]*model.Trace, 0, len(traces))
+		for _, trace := range traces {
+			if matchesIntegrity(trace, query.Integrity) &&
+				(structurePattern == nil || structurePattern.Match(trace)) &&
+				qs.matchesStructureHash(trace, query.StructureHash) {
+				filtered = append(filtered, trace)
+			}
+		}
+		if query.NumTraces > 0 && len(filtered) >= query.NumTraces {
+			return newestTraces(filtered, query.NumTraces), nil
+		}
+		if query.NumTraces <= 0 || len(traces) < candidates.NumTraces || candidates.NumTraces >= qs.options.MaxFilteredTraces {
+			return filtered, nil
+		}
+		candidates.NumTraces *= filteredTracesGrowth
+		if candidates.NumTraces > qs.options.MaxFilteredTraces {
+			candidates.NumTraces = qs.options.MaxFilteredTraces
+		}
+	}
+}
+
+// newestTraces returns the n traces which started last, if there are more.
+func newestTraces(traces []*model.Trace, n int) []*model.Trace {
+	if len(traces) <= n {
+		return traces
+	}
+	startTimes := make(map[*model.Trace]time.Time, len(traces))
+	for _, trace := range traces {
+		var startTime time.Time
+		for i, span := range trace.Spans {
+			if i == 0 || span.StartTime.Before(startTime) {
+				startTime = span.StartTime
+			}
+		}
+		startTimes[trace] = startTime
+	}
+	sort.SliceStable(traces, func(i, j int) bool {
+		return startTimes[traces[i]].After(startTimes[traces[j]])
+	})
+	return traces[:n]
+}
+
+// filtersTraces tells whether FindTraces filters the traces read from the span reader.
+func (qs QueryService) filtersTraces(query *spanstore.TraceQueryParameters) bool {
+	return len(query.Integrity) > 0 || query.Pattern != "" ||
+		(query.StructureHash != "" && qs.options.StructureReader == nil)
+}
+
+// matchesStructureHash hashes a copy of the trace once adjusted, like GetStructureGroups does,
+// unless the span reader has already filtered the traces by hash.
+func (qs QueryService) matchesStructureHash(trace *model.Trace, hash string) bool {
+	if hash == "" || qs.options.StructureReader != nil {
+		return true
+	}
+	adjusted := adjuster.AdjustCopy(qs.options.Adjuster, trace)
+	return structure.Hash(structure.Signature(adjusted)) == hash
+}
+
+func matchesIntegrity(trace *model.Trace, integrity []model.IntegrityStatus) bool {
+	if len(integrity) == 0 {
+		return true
+	}
+	status := adjuster.ClassifyIntegrity(trace)
+	for _, i := range integrity {
+		if status.Matches(i) {
+			return true
+		}
+	}
+	return false
+}
+
+// FindTraceIDs is the queryService implementation of spanstore.Reader.FindTraceIDs
+// When FindTraces filters the traces, they are read in full to be filtered.
+func (qs QueryService) FindTraceIDs(ctx context.Context, query *spanstore.TraceQueryParameters) ([]model.TraceID, error) {
+	if !qs.filtersTraces(query) {
+		return qs.spanReader.FindTraceIDs(ctx, query)
+	}
+	traces, err := qs.FindTraces(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	traceIDs := make([]model.TraceID, 0, len(traces))
+	for _, trace := range traces {
+		if len(trace.Spans) > 0 {
+			traceIDs = append(traceIDs, trace.Spans[0].TraceID)
+		}
+	}
+	return traceIDs, nil
+}
+
+// GetStructureGroups finds the traces matching the query, adjusts them, and groups them
+// by call structure, largest groups first. The latency of each group is computed from the
+// end-to-end duration of its traces.
+func (qs QueryService) GetStructureGroups(ctx context.Context, query *spanstore.TraceQueryParameters) ([]StructureGroup, error) {
+	traces, err := qs.findAdjustedTraces(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	groups := structure.GroupTraces(traces)
+	retMe := make([]StructureGroup, len(groups))
+	for i, group := range groups {
+		traceIDs := make([]model.TraceID, len(group.Traces))
+		durations := make([]time.Duration, len(group.Traces))
+		for j, trace := range group.Traces {
+			traceIDs[j] = trace.Spans[0].TraceID
+			durations[j] = trace.Duration()
+		}
+		retMe[i] = StructureGroup{
+			Signature: group.Signature,
+			Hash:      group.Hash,
+			TraceIDs:  traceIDs,
+			Latency:   stats.Summarize(durations),
+		}
+	}
+	return retMe, nil
+}
+
+// GetRequestTypeGroups finds the traces matching the query, adjusts them, and groups them by
+// request type, largest groups first. The latency of each group is computed from the end-to-end
+// duration of its traces.
+func (qs QueryService) GetRequestTypeGroups(ctx context.Context, query *spanstore.TraceQueryParameters) ([]RequestTypeGroup, error) {
+	traces, err := qs.findAdjustedTraces(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	byType := make(map[string][]*model.Trace)
+	for _, trace := range traces {
+		requestType := qs.options.RequestTypes.Classify(trace)
+		byType[requestType] = append(byType[requestType], trace)
+	}
+	retMe := make([]RequestTypeGroup, 0, len(byType))
+	for requestType, members := range byType {
+		traceIDs := make([]model.TraceID, len(members))
+		durations := make([]time.Duration, len(members))
+		for i, trace := range members {
+			traceIDs[i] = trace.Spans[0].TraceID
+			durations[i] = trace.Duration()
+		}
+		retMe = append(retMe, RequestTypeGroup{
+			RequestType: requestType,
+			TraceIDs:    traceIDs,
+			Latency:     stats.Summarize(durations),
+		})
+	}
+	sort.Slice(retMe, func(i, j int) bool {
+		if len(retMe[i].TraceIDs) != len(retMe[j].TraceIDs) {
+			return len(retMe[i].TraceIDs) > len(retMe[j].TraceIDs)
+		}
+		return retMe[i].RequestType < retMe[j].RequestType
+	})
+	return retMe, nil
+}
+
+// GetTailProfile finds the traces matching the query, adjusts them, and compares
+// the operations and subspans of the norm and tail traces, see latency.NewTailProfile.
+// When params.StructureHash is set, only the traces with that structure are profiled, and
+// ErrStructureGroupNotFound is returned when none of the traces has it.
+func (qs QueryService) GetTailProfile(ctx context.Context, query *spanstore.TraceQueryParameters, params TailProfileParameters) (*latency.TailProfile, error) {
+	traces, err := qs.findAdjustedTraces(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	traces = qs.filterRequestType(traces, params.RequestType)
+	if params.StructureHash != "" {
+		group := findStructureGroup(traces, params.StructureHash)
+		if group == nil {
+			return nil, ErrStructureGroupNotFound
+		}
+		traces = group.Traces
+	}
+	return latency.NewTailProfile(traces, params.Cutoff), nil
+}
+
+// GetAggregateTrace finds the traces matching the query, adjusts them, and synthesizes a trace
+// standing for the ones with the params.StructureHash structure, see structure.Aggregate. The
+// subspan contributing the most to the tail, as defined by params.Cutoff, is highlighted.
+// The synthetic trace can later be retrieved with GetTrace, until it falls out of the cache.
+func (qs QueryService) GetAggregateTrace(ctx context.Context, query *spanstore.TraceQueryParameters, params TailProfileParameters) (*model.Trace, error) {
+	traces, err := qs.findAdjustedTraces(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	traces = qs.filterRequestType(traces, params.RequestType)
+	group := findStructureGroup(traces, params.StructureHash)
+	if group == nil {
+		return nil, ErrStructureGroupNotFound
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(group.Signature))
+	for _, trace := range group.Traces {
+		h.Write([]byte(trace.Spans[0].TraceID.String()))
+	}
+	traceID := model.NewTraceID(syntheticTraceIDHigh, h.Sum64())
+
+	trace := structure.Aggregate(group.Traces, traceID)
+	profile := latency.NewTailProfile(group.Traces, params.Cutoff)
+	if len(profile.Subspans) > 0 && profile.Subspans[0].Contribution > 0 {
+		structure.Highlight(trace, profile.Subspans[0].Path, profile.Subspans[0].Index)
+	}
+	qs.syntheticTraces.Put(traceID.String(), trace.DeepCopy())
+	return trace, nil
+}
+
+// GetChildDiffProfiles finds the traces matching the query, adjusts them, and compares how the
+// children of the spans are spaced out in the norm and tail traces with the params.StructureHash
+// structure, see latency.NewChildDiffProfiles.
+func (qs QueryService) GetChildDiffProfiles(ctx context.Context, query *spanstore.TraceQueryParameters, params TailProfileParameters) ([]latency.ChildDiffProfile, error) {
+	traces, err := qs.findAdjustedTraces(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	traces = qs.filterRequestType(traces, params.RequestType)
+	group := findStructureGroup(traces, params.StructureHash)
+	if group == nil {
+		return nil, ErrStructureGroupNotFound
+	}
+	return latency.NewChildDiffProfiles(group.Traces, params.Cutoff), nil
+}
+
+// FindProfiledTraces finds the traces matching the query, adjusts them, and returns the ones of
+// params.RequestType, and of the params.StructureHash structure when not empty. The cutoff is ignored.
+func (qs QueryService) FindProfiledTraces(ctx context.Context, query *spanstore.TraceQueryParameters, params TailProfileParameters) ([]*model.Trace, error) {
+	traces, err := qs.findAdjustedTraces(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	traces = qs.filterRequestType(traces, params.RequestType)
+	if params.StructureHash == "" {
+		return traces, nil
+	}
+	group := findStructureGroup(traces, params.StructureHash)
+	if group == nil {
+		return nil, ErrStructureGroupNotFound
+	}
+	return group.Traces, nil
+}
+
+// GetCriticalPath fetches the trace, adjusts it, and returns its critical path,
+// see model.TraceTree.CriticalPath.
+func (qs QueryService) GetCriticalPath(ctx context.Context, traceID model.TraceID) ([]model.CriticalPathSegment, error) {
+	trace, err := qs.GetTrace(ctx, traceID)
+	if err != nil {
+		return nil, err
+	}
+	trace = adjuster.AdjustCopy(qs.options.Adjuster, trace)
+	return model.NewTraceTree(trace).CriticalPath(), nil
+}
+
+// DiffTraces fetches the current and the baseline traces like GetTraces, adjusts them, and
+// compares them span by span, see structure.Diff. It fails with spanstore.ErrTraceNotFound
+// when either trace is not found.
+func (qs QueryService) DiffTraces(ctx context.Context, baselineID, currentID model.TraceID) (*structure.TraceDiff, error) {
+	traces, err := qs.GetTraces(ctx, []model.TraceID{baselineID, currentID})
+	if err != nil {
+		return nil, err
+	}
+	for i, trace := range traces {
+		if trace == nil {
+			return nil, spanstore.ErrTraceNotFound
+		}
+		traces[i] = adjuster.AdjustCopy(qs.options.Adjuster, trace)
+	}
+	return structure.Diff(traces[0], traces[1]), nil
+}
+
+// GetCriticalPathContributions finds the traces matching the query, adjusts them, and aggregates
+// their critical paths per operation, see latency.CriticalPathContributions.
+func (qs QueryService) GetCriticalPathContributions(ctx context.Context, query *spanstore.TraceQueryParameters) ([]latency.CriticalPathContribution, error) {
+	traces, err := qs.findAdjustedTraces(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return latency.CriticalPathContributions(traces), nil
+}
+
+// GetRegression finds and adjusts the traces matching the baseline and the current queries, and
+// compares them by service/operation and by structural group, see latency.NewRegression.
+func (qs QueryService) GetRegression(
+	ctx context.Context,
+	baseline, current *spanstore.TraceQueryParameters,
+	params RegressionParameters,
+) (*latency.Regression, error) {
+	baselineTraces, err := qs.findAdjustedTraces(ctx, baseline)
+	if err != nil {
+		return nil, err
+	}
+	currentTraces, err := qs.findAdjustedTraces(ctx, current)
+	if err != nil {
+		return nil, err
+	}
+	significance := params.Significance
+	if significance == 0 {
+		significance = latency.DefaultSignificance
+	}
+	return latency.NewRegression(baselineTraces, currentTraces, significance), nil
+}
+
+// filterRequestType returns the traces of the given request type, or all of them when requestType is empty.
+func (qs QueryService) filterRequestType(traces []*model.Trace, requestType string) []*model.Trace {
+	if requestType == "" {
+		return traces
+	}
+	var retMe []*model.Trace
+	for _, trace := range traces {
+		if qs.options.RequestTypes.Classify(trace) == requestType {
+			retMe = append(retMe, trace)
+		}
+	}
+	return retMe
+}
+
+// findStructureGroup groups the traces by structure and returns the group with the given hash, if any.
+func findStructureGroup(traces []*model.Trace, hash string) *structure.Group {
+	for _, group := range structure.GroupTraces(traces) {
+		if group.Hash == hash {
+			return group
+		}
+	}
+	return nil
+}
+
+// findAdjustedTraces finds the traces matching the query, and adjusts copies of them, since
+// the traces returned by the span reader may be shared with the storage, e.g. by the memory one.
+func (qs QueryService) findAdjustedTraces(ctx context.Context, query *spanstore.TraceQueryParameters) ([]*model.Trace, error) {
+	traces, err := qs.FindTraces(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	for i, trace := range traces {
+		traces[i] = adjuster.AdjustCopy(qs.options.Adjuster, trace)
+	}
+	return traces, nil
+}
+
+// GetStructureCounts counts the traces which started between startTimeMin and startTimeMax
+// by structural hash, from the structure index of the storage. It fails with
+// storage.ErrStructureIndexNotSupported when the storage does not index the structures.
+func (qs QueryService) GetStructureCounts(ctx context.Context, startTimeMin, startTimeMax time.Time) (map[string]int, error) {
+	if qs.options.StructureReader == nil {
+		return nil, storage.ErrStructureIndexNotSupported
+	}
+	return qs.options.StructureReader.CountStructures(ctx, startTimeMin, startTimeMax)
 }
 
 // ArchiveTrace is the queryService utility to archive traces.
@@ -147,3 +679,23 @@ func (opts *QueryServiceOptions) InitArchiveStorage(storageFactory storage.Facto
 	opts.ArchiveSpanWriter = writer
 	return true
 }
+
+// InitStructureIndex tries to initialize the structure reader if storage factory supports it.
+func (opts *QueryServiceOptions) InitStructureIndex(storageFactory storage.Factory, logger *zap.Logger) bool {
+	indexFactory, ok := storageFactory.(storage.StructureIndexFactory)
+	if !ok {
+		logger.Info("Structure index not supported by the factory")
+		return false
+	}
+	reader, err := indexFactory.CreateStructureReader()
+	if err == storage.ErrStructureIndexNotSupported {
+		logger.Info("Structure index not created", zap.String("reason", err.Error()))
+		return false
+	}
+	if err != nil {
+		logger.Error("Cannot init structure index reader", zap.Error(err))
+		return false
+	}
+	opts.StructureReader = reader
+	return true
+}
@@ -17,16 +17,21 @@ package querysvc
 import (
 	"context"
 	"errors"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"github.com/uber/jaeger-lib/metrics"
 	"go.uber.org/zap"
 
 	"github.com/jaegertracing/jaeger/model"
 	"github.com/jaegertracing/jaeger/model/adjuster"
+	"github.com/jaegertracing/jaeger/model/latency"
+	"github.com/jaegertracing/jaeger/model/requesttype"
+	"github.com/jaegertracing/jaeger/model/structure"
 	"github.com/jaegertracing/jaeger/storage"
 	"github.com/jaegertracing/jaeger/storage/dependencystore"
 	depsmocks "github.com/jaegertracing/jaeger/storage/dependencystore/mocks"
@@ -131,6 +136,134 @@ func TestGetTraceFromArchiveStorage(t *testing.T) {
 	assert.Equal(t, res, mockTrace)
 }
 
+// Test QueryService.GetTrace() with a span reader returning nil for missing traces
+func TestGetTraceNilFromArchiveStorage(t *testing.T) {
+	qs, readMock, _, readArchiveMock, _ := initializeTestServiceWithArchiveOptions()
+	readMock.On("GetTrace", mock.Anything, mockTraceID).Return(nil, nil).Once()
+	readArchiveMock.On("GetTrace", mock.Anything, mockTraceID).Return(mockTrace, nil).Once()
+
+	res, err := qs.GetTrace(context.Background(), mockTraceID)
+	assert.NoError(t, err)
+	assert.Equal(t, mockTrace, res)
+
+	readMock.On("GetTrace", mock.Anything, mockTraceID).Return(nil, nil).Once()
+	readArchiveMock.On("GetTrace", mock.Anything, mockTraceID).Return(nil, nil).Once()
+	_, err = qs.GetTrace(context.Background(), mockTraceID)
+	assert.Equal(t, spanstore.ErrTraceNotFound, err)
+}
+
+func TestGetTraces(t *testing.T) {
+	qs, readMock, _ := initializeTestService()
+	missingTraceID := model.NewTraceID(0, 1)
+	readMock.On("GetTrace", mock.Anything, mockTraceID).Return(mockTrace, nil).Twice()
+	readMock.On("GetTrace", mock.Anything, missingTraceID).Return(nil, spanstore.ErrTraceNotFound).Once()
+
+	traces, err := qs.GetTraces(context.Background(), []model.TraceID{mockTraceID, missingTraceID, mockTraceID})
+	require.NoError(t, err)
+	assert.Equal(t, []*model.Trace{mockTrace, nil, mockTrace}, traces)
+	readMock.AssertExpectations(t)
+}
+
+func TestGetTracesFailure(t *testing.T) {
+	qs, readMock, _ := initializeTestService()
+	readMock.On("GetTrace", mock.Anything, mock.AnythingOfType("model.TraceID")).Return(nil, errors.New("storage error"))
+
+	traceIDs := make([]model.TraceID, 20)
+	for i := range traceIDs {
+		traceIDs[i] = model.NewTraceID(0, uint64(i+1))
+	}
+	_, err := qs.GetTraces(context.Background(), traceIDs)
+	assert.EqualError(t, err, "storage error")
+}
+
+func TestGetTracesConcurrency(t *testing.T) {
+	readMock := &spanstoremocks.Reader{}
+	qs := NewQueryService(readMock, &depsmocks.Reader{}, QueryServiceOptions{MaxConcurrentTraceFetches: 3})
+	var inFlight, maxInFlight int32
+	readMock.On("GetTrace", mock.Anything, mock.AnythingOfType("model.TraceID")).
+		Run(func(mock.Arguments) {
+			n := atomic.AddInt32(&inFlight, 1)
+			for {
+				max := atomic.LoadInt32(&maxInFlight)
+				if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&inFlight, -1)
+		}).
+		Return(mockTrace, nil)
+
+	traceIDs := make([]model.TraceID, 30)
+	traces, err := qs.GetTraces(context.Background(), traceIDs)
+	require.NoError(t, err)
+	assert.Len(t, traces, 30)
+	assert.True(t, atomic.LoadInt32(&maxInFlight) <= 3)
+}
+
+func TestStreamTraces(t *testing.T) {
+	readMock := &spanstoremocks.Reader{}
+	qs := NewQueryService(readMock, &depsmocks.Reader{}, QueryServiceOptions{MaxConcurrentTraceFetches: 2})
+	firstID, secondID := model.NewTraceID(0, 1), model.NewTraceID(0, 2)
+	firstTrace := &model.Trace{Spans: []*model.Span{{TraceID: firstID}}}
+	secondTrace := &model.Trace{Spans: []*model.Span{{TraceID: secondID}}}
+	firstSent := make(chan struct{})
+	readMock.On("GetTrace", mock.Anything, firstID).Return(firstTrace, nil)
+	// the second trace is only found once the first one is sent, which requires streaming
+	readMock.On("GetTrace", mock.Anything, secondID).Run(func(mock.Arguments) { <-firstSent }).Return(secondTrace, nil)
+
+	var traces []*model.Trace
+	err := qs.StreamTraces(context.Background(), []model.TraceID{firstID, secondID}, func(trace *model.Trace) error {
+		if len(traces) == 0 {
+			close(firstSent)
+		}
+		traces = append(traces, trace)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []*model.Trace{firstTrace, secondTrace}, traces)
+
+	err = qs.StreamTraces(context.Background(), []model.TraceID{firstID, secondID}, func(trace *model.Trace) error {
+		return assert.AnError
+	})
+	assert.Equal(t, assert.AnError, err)
+}
+
+func TestStreamTracesCancelsFetches(t *testing.T) {
+	readMock := &spanstoremocks.Reader{}
+	qs := NewQueryService(readMock, &depsmocks.Reader{}, QueryServiceOptions{MaxConcurrentTraceFetches: 2})
+	firstID, secondID := model.NewTraceID(0, 1), model.NewTraceID(0, 2)
+	readMock.On("GetTrace", mock.Anything, firstID).Return(&model.Trace{Spans: []*model.Span{{TraceID: firstID}}}, nil)
+	// the second fetch only completes when it is canceled
+	canceled := make(chan struct{})
+	readMock.On("GetTrace", mock.Anything, secondID).Run(func(args mock.Arguments) {
+		<-args.Get(0).(context.Context).Done()
+		close(canceled)
+	}).Return(nil, context.Canceled)
+
+	err := qs.StreamTraces(context.Background(), []model.TraceID{firstID, secondID}, func(trace *model.Trace) error {
+		return assert.AnError
+	})
+	assert.Equal(t, assert.AnError, err)
+	select {
+	case <-canceled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the fetch in flight is not canceled")
+	}
+}
+
+func TestGetTracesTooMany(t *testing.T) {
+	readMock := &spanstoremocks.Reader{}
+	qs := NewQueryService(readMock, &depsmocks.Reader{}, QueryServiceOptions{MaxTraceIDsPerRequest: 2})
+	readMock.On("GetTrace", mock.Anything, mock.AnythingOfType("model.TraceID")).Return(mockTrace, nil)
+
+	traces, err := qs.GetTraces(context.Background(), make([]model.TraceID, 2))
+	require.NoError(t, err)
+	assert.Len(t, traces, 2)
+	_, err = qs.GetTraces(context.Background(), make([]model.TraceID, 3))
+	assert.Equal(t, ErrTooManyTraceIDs, err)
+}
+
 // Test QueryService.GetServices() for success.
 func TestGetServices(t *testing.T) {
 	qs, readMock, _ := initializeTestService()
@@ -178,6 +311,712 @@ func TestFindTraces(t *testing.T) {
 	assert.Len(t, traces, 1)
 }
 
+// Test QueryService.FindTraces() filtering traces by integrity.
+func TestFindTracesByIntegrity(t *testing.T) {
+	complete := makeStructureTestTrace(1, 10*time.Millisecond, "query")
+	orphaned := makeStructureTestTrace(2, 10*time.Millisecond, "query")
+	orphaned.Spans[1].References = nil
+	testCases := []struct {
+		name      string
+		integrity []model.IntegrityStatus
+		expected  []*model.Trace
+	}{
+		{name: "no filter", expected: []*model.Trace{complete, orphaned}},
+		{name: "complete", integrity: []model.IntegrityStatus{model.IntegrityComplete}, expected: []*model.Trace{complete}},
+		{name: "orphaned", integrity: []model.IntegrityStatus{model.IntegrityOrphaned}, expected: []*model.Trace{orphaned}},
+		{name: "dropped span", integrity: []model.IntegrityStatus{model.IntegrityDroppedSpan}, expected: []*model.Trace{}},
+	}
+	for _, tc := range testCases {
+		testCase := tc // capture loop var
+		t.Run(testCase.name, func(t *testing.T) {
+			qs, readMock, _ := initializeTestService()
+			readMock.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+				Return([]*model.Trace{complete, orphaned}, nil).Once()
+
+			type contextKey string
+			ctx := context.Background()
+			params := &spanstore.TraceQueryParameters{
+				ServiceName: "frontend",
+				Integrity:   testCase.integrity,
+			}
+			traces, err := qs.FindTraces(context.WithValue(ctx, contextKey("foo"), "bar"), params)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expected, traces)
+		})
+	}
+}
+
+// Test QueryService.FindTraces() and FindTraceIDs() filtering traces by structure.
+func TestFindTracesByStructure(t *testing.T) {
+	reads := makeStructureTestTrace(1, 10*time.Millisecond, "SELECT", "SELECT")
+	writes := makeStructureTestTrace(2, 10*time.Millisecond, "INSERT")
+	qs, readMock, _ := initializeTestService()
+	readMock.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+		Return(func(context.Context, *spanstore.TraceQueryParameters) []*model.Trace {
+			return []*model.Trace{reads, writes}
+		}, nil).Twice()
+
+	type contextKey string
+	ctx := context.WithValue(context.Background(), contextKey("foo"), "bar")
+	params := &spanstore.TraceQueryParameters{
+		ServiceName: "frontend",
+		Pattern:     "frontend:GET > db:SELECT #>1",
+	}
+	traces, err := qs.FindTraces(ctx, params)
+	assert.NoError(t, err)
+	assert.Equal(t, []*model.Trace{reads}, traces)
+
+	traceIDs, err := qs.FindTraceIDs(ctx, params)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.TraceID{model.NewTraceID(0, 1)}, traceIDs)
+	readMock.AssertExpectations(t)
+}
+
+// Test QueryService.FindTraces() reading more traces than query.NumTraces to filter them.
+func TestFindTracesFilteredBeyondNumTraces(t *testing.T) {
+	// the reader returns the newest traces first, the matching ones being the oldest
+	var stored []*model.Trace
+	for i := 1; i <= 10; i++ {
+		trace := makeStructureTestTrace(uint64(i), 10*time.Millisecond, "INSERT")
+		if i > 8 {
+			trace = makeStructureTestTrace(uint64(i), 10*time.Millisecond, "SELECT", "SELECT")
+		}
+		for _, span := range trace.Spans {
+			span.StartTime = span.StartTime.Add(-time.Duration(i) * time.Second)
+		}
+		stored = append(stored, trace)
+	}
+	testCases := []struct {
+		name              string
+		maxFilteredTraces int
+		reads             []int
+		expected          []model.TraceID
+	}{
+		{
+			name:     "until the reader has no more traces",
+			reads:    []int{2, 8, 32},
+			expected: []model.TraceID{model.NewTraceID(0, 9), model.NewTraceID(0, 10)},
+		},
+		{
+			name:              "until MaxFilteredTraces",
+			maxFilteredTraces: 8,
+			reads:             []int{2, 8},
+			expected:          []model.TraceID{},
+		},
+	}
+	for _, testCase := range testCases {
+		testCase := testCase // capture loop var
+		t.Run(testCase.name, func(t *testing.T) {
+			var reads []int
+			readMock := &spanstoremocks.Reader{}
+			readMock.On("FindTraces", mock.Anything, mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+				Return(func(_ context.Context, query *spanstore.TraceQueryParameters) []*model.Trace {
+					reads = append(reads, query.NumTraces)
+					if query.NumTraces < len(stored) {
+						return stored[:query.NumTraces]
+					}
+					return stored
+				}, nil)
+			qs := NewQueryService(readMock, &depsmocks.Reader{}, QueryServiceOptions{MaxFilteredTraces: testCase.maxFilteredTraces})
+
+			traceIDs, err := qs.FindTraceIDs(context.Background(), &spanstore.TraceQueryParameters{
+				ServiceName: "frontend",
+				Pattern:     "frontend:GET > db:SELECT #>1",
+				NumTraces:   2,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expected, traceIDs)
+			assert.Equal(t, testCase.reads, reads)
+		})
+	}
+}
+
+// Test QueryService.FindTraces() returning the newest of the filtered traces.
+func TestFindTracesFilteredNewest(t *testing.T) {
+	var stored []*model.Trace
+	for i := 1; i <= 3; i++ {
+		trace := makeStructureTestTrace(uint64(i), 10*time.Millisecond, "SELECT", "SELECT")
+		for _, span := range trace.Spans {
+			span.StartTime = span.StartTime.Add(time.Duration(i) * time.Second)
+		}
+		stored = append(stored, trace)
+	}
+	stored = append(stored, makeStructureTestTrace(4, 10*time.Millisecond, "INSERT"))
+	qs, readMock, _ := initializeTestService()
+	readMock.On("FindTraces", mock.Anything, mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+		Return(func(_ context.Context, query *spanstore.TraceQueryParameters) []*model.Trace {
+			if query.NumTraces < len(stored) {
+				return stored[len(stored)-query.NumTraces:]
+			}
+			return stored
+		}, nil)
+
+	traceIDs, err := qs.FindTraceIDs(context.Background(), &spanstore.TraceQueryParameters{
+		Pattern:   "frontend:GET > db:SELECT #>1",
+		NumTraces: 2,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []model.TraceID{model.NewTraceID(0, 3), model.NewTraceID(0, 2)}, traceIDs)
+}
+
+// Test QueryService.FindTraceIDs() when the filtered traces cannot be read.
+func TestFindTraceIDsByStructureFailure(t *testing.T) {
+	qs, readMock, _ := initializeTestService()
+	readMock.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+		Return(nil, errAdjustment).Once()
+
+	type contextKey string
+	_, err := qs.FindTraceIDs(context.WithValue(context.Background(), contextKey("foo"), "bar"), &spanstore.TraceQueryParameters{Pattern: "db"})
+	assert.EqualError(t, err, errAdjustment.Error())
+}
+
+// Test QueryService.FindTraces() with a pattern that cannot be parsed.
+func TestFindTracesInvalidPattern(t *testing.T) {
+	qs, _, _ := initializeTestService()
+	_, err := qs.FindTraces(context.Background(), &spanstore.TraceQueryParameters{Pattern: "db >"})
+	assert.EqualError(t, err, "expected a span selector, found end of pattern at offset 4")
+}
+
+// Test QueryService.FindTraces() and FindTraceIDs() filtering traces by structural hash.
+func TestFindTracesByStructureHash(t *testing.T) {
+	reads := makeStructureTestTrace(1, 10*time.Millisecond, "SELECT")
+	writes := makeStructureTestTrace(2, 10*time.Millisecond, "INSERT")
+	hash := structure.Hash(structure.Signature(reads))
+	qs, readMock, _ := initializeTestService()
+	readMock.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+		Return(func(context.Context, *spanstore.TraceQueryParameters) []*model.Trace {
+			return []*model.Trace{reads, writes}
+		}, nil).Twice()
+
+	type contextKey string
+	ctx := context.WithValue(context.Background(), contextKey("foo"), "bar")
+	params := &spanstore.TraceQueryParameters{
+		ServiceName:   "frontend",
+		StructureHash: hash,
+	}
+	traces, err := qs.FindTraces(ctx, params)
+	assert.NoError(t, err)
+	assert.Equal(t, []*model.Trace{reads}, traces)
+
+	traceIDs, err := qs.FindTraceIDs(ctx, params)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.TraceID{model.NewTraceID(0, 1)}, traceIDs)
+	readMock.AssertExpectations(t)
+}
+
+// Test QueryService.FindTraceIDs() leaving the structural hash to a span reader indexing the structures.
+func TestFindTraceIDsByIndexedStructureHash(t *testing.T) {
+	readMock := &spanstoremocks.Reader{}
+	qs := NewQueryService(readMock, &depsmocks.Reader{}, QueryServiceOptions{
+		StructureReader: &spanstoremocks.StructureReader{},
+	})
+	readMock.On("FindTraceIDs", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+		Return([]model.TraceID{mockTraceID}, nil).Once()
+
+	type contextKey string
+	params := &spanstore.TraceQueryParameters{
+		ServiceName:   "frontend",
+		StructureHash: "0123456789abcdef",
+	}
+	traceIDs, err := qs.FindTraceIDs(context.WithValue(context.Background(), contextKey("foo"), "bar"), params)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.TraceID{mockTraceID}, traceIDs)
+	readMock.AssertExpectations(t)
+}
+
+// Test QueryService.FindTraceIDs() for success.
+func TestFindTraceIDs(t *testing.T) {
+	qs, readMock, _ := initializeTestService()
+	readMock.On("FindTraceIDs", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+		Return([]model.TraceID{mockTraceID}, nil).Once()
+
+	type contextKey string
+	ctx := context.Background()
+	params := &spanstore.TraceQueryParameters{
+		ServiceName:  "service",
+		StartTimeMax: time.Now(),
+		NumTraces:    200,
+	}
+	traceIDs, err := qs.FindTraceIDs(context.WithValue(ctx, contextKey("foo"), "bar"), params)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.TraceID{mockTraceID}, traceIDs)
+}
+
+func makeStructureTestTrace(traceID uint64, duration time.Duration, childOps ...string) *model.Trace {
+	start := time.Unix(0, 0)
+	id := model.NewTraceID(0, traceID)
+	trace := &model.Trace{
+		Spans: []*model.Span{
+			{
+				TraceID:       id,
+				SpanID:        model.NewSpanID(1),
+				OperationName: "GET",
+				StartTime:     start,
+				Duration:      duration,
+				Process:       &model.Process{ServiceName: "frontend"},
+			},
+		},
+	}
+	for i, op := range childOps {
+		trace.Spans = append(trace.Spans, &model.Span{
+			TraceID:       id,
+			SpanID:        model.NewSpanID(uint64(i + 2)),
+			OperationName: op,
+			References:    []model.SpanRef{model.NewChildOfRef(id, model.NewSpanID(1))},
+			StartTime:     start.Add(time.Duration(i+1) * time.Millisecond),
+			Duration:      time.Millisecond,
+			Process:       &model.Process{ServiceName: "db"},
+		})
+	}
+	return trace
+}
+
+// Test QueryService.GetStructureGroups() for success.
+func TestGetStructureGroups(t *testing.T) {
+	qs, readMock, _ := initializeTestService()
+	readMock.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+		Return([]*model.Trace{
+			makeStructureTestTrace(1, 10*time.Millisecond, "query"),
+			makeStructureTestTrace(2, 30*time.Millisecond, "insert"),
+			makeStructureTestTrace(3, 20*time.Millisecond, "query"),
+		}, nil).Once()
+
+	type contextKey string
+	ctx := context.Background()
+	params := &spanstore.TraceQueryParameters{
+		ServiceName:  "frontend",
+		StartTimeMax: time.Now(),
+		NumTraces:    200,
+	}
+	groups, err := qs.GetStructureGroups(context.WithValue(ctx, contextKey("foo"), "bar"), params)
+	assert.NoError(t, err)
+	assert.Len(t, groups, 2)
+
+	assert.Equal(t, `{>"frontend:GET"#0{>"db:query"#0{}<"db:query"#0}<"frontend:GET"#0}`, groups[0].Signature)
+	assert.Equal(t, []model.TraceID{model.NewTraceID(0, 1), model.NewTraceID(0, 3)}, groups[0].TraceIDs)
+	assert.Equal(t, 2, groups[0].Latency.Count)
+	assert.Equal(t, 15*time.Millisecond, groups[0].Latency.Mean)
+	assert.Equal(t, 10*time.Millisecond, groups[0].Latency.Min)
+	assert.Equal(t, 20*time.Millisecond, groups[0].Latency.Max)
+
+	assert.Equal(t, []model.TraceID{model.NewTraceID(0, 2)}, groups[1].TraceIDs)
+	assert.Equal(t, 30*time.Millisecond, groups[1].Latency.P50)
+}
+
+// Test QueryService.GetStructureCounts() with and without a structure index.
+func TestGetStructureCounts(t *testing.T) {
+	qs, _, _ := initializeTestService()
+	_, err := qs.GetStructureCounts(context.Background(), time.Time{}, time.Time{})
+	assert.Equal(t, storage.ErrStructureIndexNotSupported, err)
+
+	structureReader := &spanstoremocks.StructureReader{}
+	qs = NewQueryService(&spanstoremocks.Reader{}, &depsmocks.Reader{}, QueryServiceOptions{
+		StructureReader: structureReader,
+	})
+	start, end := time.Unix(10, 0), time.Unix(20, 0)
+	structureReader.On("CountStructures", mock.Anything, start, end).
+		Return(map[string]int{"0123456789abcdef": 3}, nil).Once()
+	counts, err := qs.GetStructureCounts(context.Background(), start, end)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"0123456789abcdef": 3}, counts)
+}
+
+// Test QueryService.GetStructureGroups() when the span reader fails.
+func TestGetStructureGroupsAdjustsCopies(t *testing.T) {
+	stored := makeStructureTestTrace(1, 10*time.Millisecond, "query")
+	readMock := &spanstoremocks.Reader{}
+	readMock.On("FindTraces", mock.Anything, mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+		Return([]*model.Trace{stored}, nil).Once()
+	qs := NewQueryService(readMock, &depsmocks.Reader{}, QueryServiceOptions{
+		Adjuster: adjuster.Func(func(trace *model.Trace) (*model.Trace, error) {
+			trace.Spans[0].Duration += time.Millisecond
+			return trace, errAdjustment
+		}),
+	})
+
+	// the traces are adjusted once to be hashed, then once to be grouped
+	groups, err := qs.GetStructureGroups(context.Background(), &spanstore.TraceQueryParameters{
+		StructureHash: structure.Hash(structure.Signature(stored)),
+	})
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	assert.Equal(t, 11*time.Millisecond, groups[0].Latency.Max)
+	assert.Equal(t, 10*time.Millisecond, stored.Spans[0].Duration)
+	assert.Empty(t, stored.Warnings)
+}
+
+func TestGetStructureGroupsFailure(t *testing.T) {
+	qs, readMock, _ := initializeTestService()
+	readMock.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+		Return(nil, errAdjustment).Once()
+
+	type contextKey string
+	ctx := context.Background()
+	_, err := qs.GetStructureGroups(context.WithValue(ctx, contextKey("foo"), "bar"), &spanstore.TraceQueryParameters{})
+	assert.Equal(t, errAdjustment, err)
+}
+
+func TestGetRequestTypeGroups(t *testing.T) {
+	insert := makeStructureTestTrace(2, 30*time.Millisecond, "insert")
+	insert.Spans[0].Tags = []model.KeyValue{model.String("http.route", "/insert")}
+	classifier, err := requesttype.NewClassifier([]requesttype.Rule{
+		{Type: "insert", Tags: map[string]string{"http.route": "/insert"}},
+	})
+	require.NoError(t, err)
+	readMock := &spanstoremocks.Reader{}
+	qs := NewQueryService(readMock, &depsmocks.Reader{}, QueryServiceOptions{RequestTypes: classifier})
+	readMock.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+		Return([]*model.Trace{
+			makeStructureTestTrace(1, 10*time.Millisecond, "query"),
+			insert,
+			makeStructureTestTrace(3, 20*time.Millisecond, "query"),
+		}, nil).Once()
+
+	type contextKey string
+	ctx := context.WithValue(context.Background(), contextKey("foo"), "bar")
+	groups, err := qs.GetRequestTypeGroups(ctx, &spanstore.TraceQueryParameters{})
+	require.NoError(t, err)
+	require.Len(t, groups, 2)
+	assert.Equal(t, "frontend:GET", groups[0].RequestType)
+	assert.Equal(t, []model.TraceID{model.NewTraceID(0, 1), model.NewTraceID(0, 3)}, groups[0].TraceIDs)
+	assert.Equal(t, 15*time.Millisecond, groups[0].Latency.Mean)
+	assert.Equal(t, "insert", groups[1].RequestType)
+	assert.Equal(t, []model.TraceID{model.NewTraceID(0, 2)}, groups[1].TraceIDs)
+
+	readMock.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+		Return(nil, errAdjustment).Once()
+	_, err = qs.GetRequestTypeGroups(ctx, &spanstore.TraceQueryParameters{})
+	assert.Equal(t, errAdjustment, err)
+}
+
+// Test QueryService.GetTailProfile() for success.
+func TestGetTailProfile(t *testing.T) {
+	traces := []*model.Trace{
+		makeStructureTestTrace(1, 10*time.Millisecond, "query"),
+		makeStructureTestTrace(2, 30*time.Millisecond, "insert"),
+		makeStructureTestTrace(3, 20*time.Millisecond, "query"),
+	}
+	queryGroupHash := structure.Hash(structure.Signature(traces[0]))
+	testCases := []struct {
+		name       string
+		params     TailProfileParameters
+		normTraces int
+		tailTraces int
+	}{
+		{name: "all traces", params: TailProfileParameters{Cutoff: 50}, normTraces: 1, tailTraces: 2},
+		{name: "one group", params: TailProfileParameters{Cutoff: 50, StructureHash: queryGroupHash}, normTraces: 1, tailTraces: 1},
+		{name: "request type", params: TailProfileParameters{Cutoff: 50, RequestType: "frontend:GET"}, normTraces: 1, tailTraces: 2},
+		{name: "unknown request type", params: TailProfileParameters{Cutoff: 50, RequestType: "unknown"}},
+	}
+	for _, tc := range testCases {
+		testCase := tc // capture loop var
+		t.Run(testCase.name, func(t *testing.T) {
+			qs, readMock, _ := initializeTestService()
+			readMock.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+				Return(traces, nil).Once()
+
+			type contextKey string
+			ctx := context.Background()
+			profile, err := qs.GetTailProfile(context.WithValue(ctx, contextKey("foo"), "bar"), &spanstore.TraceQueryParameters{}, testCase.params)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.params.Cutoff, profile.Cutoff)
+			assert.Equal(t, testCase.normTraces, profile.Traces.Norm.Count)
+			assert.Equal(t, testCase.tailTraces, profile.Traces.Tail.Count)
+		})
+	}
+}
+
+// Test QueryService.GetTailProfile() when none of the traces has the requested structure.
+func TestGetTailProfileUnknownGroup(t *testing.T) {
+	qs, readMock, _ := initializeTestService()
+	readMock.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+		Return([]*model.Trace{makeStructureTestTrace(1, 10*time.Millisecond, "query")}, nil).Once()
+
+	type contextKey string
+	ctx := context.Background()
+	params := TailProfileParameters{Cutoff: 50, StructureHash: "unknown"}
+	_, err := qs.GetTailProfile(context.WithValue(ctx, contextKey("foo"), "bar"), &spanstore.TraceQueryParameters{}, params)
+	assert.Equal(t, ErrStructureGroupNotFound, err)
+}
+
+// Test QueryService.GetTailProfile() when the span reader fails.
+func TestGetTailProfileFailure(t *testing.T) {
+	qs, readMock, _ := initializeTestService()
+	readMock.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+		Return(nil, errAdjustment).Once()
+
+	type contextKey string
+	ctx := context.Background()
+	_, err := qs.GetTailProfile(context.WithValue(ctx, contextKey("foo"), "bar"), &spanstore.TraceQueryParameters{}, TailProfileParameters{Cutoff: 90})
+	assert.Equal(t, errAdjustment, err)
+}
+
+// Test QueryService.GetAggregateTrace() and retrieving the synthetic trace with GetTrace().
+func TestGetAggregateTrace(t *testing.T) {
+	traces := []*model.Trace{
+		makeStructureTestTrace(1, 10*time.Millisecond, "query"),
+		makeStructureTestTrace(2, 30*time.Millisecond, "insert"),
+		makeStructureTestTrace(3, 20*time.Millisecond, "query"),
+	}
+	qs, readMock, _ := initializeTestService()
+	readMock.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+		Return(traces, nil)
+
+	type contextKey string
+	ctx := context.WithValue(context.Background(), contextKey("foo"), "bar")
+	params := TailProfileParameters{
+		Cutoff:        50,
+		StructureHash: structure.Hash(structure.Signature(traces[0])),
+	}
+	trace, err := qs.GetAggregateTrace(ctx, &spanstore.TraceQueryParameters{}, params)
+	assert.NoError(t, err)
+	// the root span, its child, and the highlighted subspan after the child
+	assert.Len(t, trace.Spans, 3)
+	assert.Equal(t, 15*time.Millisecond, trace.Spans[0].Duration)
+	assert.Equal(t, "subspan #1", trace.Spans[2].OperationName)
+
+	res, err := qs.GetTrace(ctx, trace.Spans[0].TraceID)
+	assert.NoError(t, err)
+	assert.Equal(t, trace, res)
+
+	params.StructureHash = "unknown"
+	_, err = qs.GetAggregateTrace(ctx, &spanstore.TraceQueryParameters{}, params)
+	assert.Equal(t, ErrStructureGroupNotFound, err)
+}
+
+// Test that the cached synthetic traces are not modified by adjusting the retrieved ones.
+func TestGetAggregateTraceCopies(t *testing.T) {
+	traces := []*model.Trace{
+		makeStructureTestTrace(1, 10*time.Millisecond, "query"),
+		makeStructureTestTrace(2, 30*time.Millisecond, "query"),
+	}
+	qs, readMock, _ := initializeTestService()
+	readMock.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+		Return(traces, nil)
+
+	type contextKey string
+	ctx := context.WithValue(context.Background(), contextKey("foo"), "bar")
+	params := TailProfileParameters{
+		Cutoff:        50,
+		StructureHash: structure.Hash(structure.Signature(traces[0])),
+	}
+	trace, err := qs.GetAggregateTrace(ctx, &spanstore.TraceQueryParameters{}, params)
+	require.NoError(t, err)
+	traceID := trace.Spans[0].TraceID
+	cached := qs.syntheticTraces.Get(traceID.String()).(*model.Trace)
+	expected := cached.DeepCopy()
+	// the returned trace is not the cached one either
+	trace.Spans[0].Tags = append(trace.Spans[0].Tags, model.String("foo", "bar"))
+
+	for i := 0; i < 2; i++ {
+		res, err := qs.GetTrace(ctx, traceID)
+		require.NoError(t, err)
+		assert.Equal(t, expected, res)
+		res.Spans[0].Tags = append(res.Spans[0].Tags, model.String("foo", "bar"))
+		res.Spans[0].Process.Tags = append(res.Spans[0].Process.Tags, model.String("foo", "bar"))
+		res.Spans[0].Warnings = append(res.Spans[0].Warnings, "adjusted")
+		_, err = qs.Adjust(res)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, expected, qs.syntheticTraces.Get(traceID.String()))
+}
+
+func TestGetChildDiffProfiles(t *testing.T) {
+	traces := []*model.Trace{
+		makeStructureTestTrace(1, 10*time.Millisecond, "query", "insert"),
+		makeStructureTestTrace(2, 30*time.Millisecond, "insert"),
+		makeStructureTestTrace(3, 20*time.Millisecond, "query", "insert"),
+	}
+	qs, readMock, _ := initializeTestService()
+	readMock.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+		Return(traces, nil)
+
+	type contextKey string
+	ctx := context.WithValue(context.Background(), contextKey("foo"), "bar")
+	params := TailProfileParameters{
+		Cutoff:        50,
+		StructureHash: structure.Hash(structure.Signature(traces[0])),
+	}
+	profiles, err := qs.GetChildDiffProfiles(ctx, &spanstore.TraceQueryParameters{}, params)
+	require.NoError(t, err)
+	require.Len(t, profiles, 1)
+	assert.Len(t, profiles[0].ChildDiffs, 2)
+	assert.Equal(t, 1, profiles[0].EndDiff.Norm.Count)
+	assert.Equal(t, 1, profiles[0].EndDiff.Tail.Count)
+	// the tail trace ends 10ms later after its last call
+	assert.Equal(t, 10*time.Millisecond, profiles[0].EndDiff.Contribution)
+
+	params.StructureHash = "unknown"
+	_, err = qs.GetChildDiffProfiles(ctx, &spanstore.TraceQueryParameters{}, params)
+	assert.Equal(t, ErrStructureGroupNotFound, err)
+}
+
+func TestGetChildDiffProfilesFailure(t *testing.T) {
+	qs, readMock, _ := initializeTestService()
+	readMock.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+		Return(nil, errors.New("storage error"))
+
+	type contextKey string
+	ctx := context.WithValue(context.Background(), contextKey("foo"), "bar")
+	_, err := qs.GetChildDiffProfiles(ctx, &spanstore.TraceQueryParameters{}, TailProfileParameters{Cutoff: 50})
+	assert.EqualError(t, err, "storage error")
+}
+
+func TestGetCriticalPath(t *testing.T) {
+	qs, readMock, _ := initializeTestService()
+	readMock.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), model.NewTraceID(0, 1)).
+		Return(makeStructureTestTrace(1, 10*time.Millisecond, "query"), nil).Once()
+	readMock.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), model.NewTraceID(0, 2)).
+		Return(nil, spanstore.ErrTraceNotFound).Once()
+
+	type contextKey string
+	ctx := context.WithValue(context.Background(), contextKey("foo"), "bar")
+	path, err := qs.GetCriticalPath(ctx, model.NewTraceID(0, 1))
+	require.NoError(t, err)
+	require.Len(t, path, 3)
+	assert.Equal(t, "GET", path[0].Span.OperationName)
+	assert.Equal(t, "query", path[1].Span.OperationName)
+	assert.Equal(t, "GET", path[2].Span.OperationName)
+	assert.Equal(t, 8*time.Millisecond, path[2].Duration())
+
+	_, err = qs.GetCriticalPath(ctx, model.NewTraceID(0, 2))
+	assert.Equal(t, spanstore.ErrTraceNotFound, err)
+}
+
+func TestDiffTraces(t *testing.T) {
+	qs, readMock, _, archiveReadMock, _ := initializeTestServiceWithArchiveOptions()
+	readMock.On("GetTrace", mock.Anything, model.NewTraceID(0, 1)).
+		Return(makeStructureTestTrace(1, 10*time.Millisecond, "query"), nil)
+	readMock.On("GetTrace", mock.Anything, mock.AnythingOfType("model.TraceID")).
+		Return(nil, spanstore.ErrTraceNotFound)
+	archiveReadMock.On("GetTrace", mock.Anything, model.NewTraceID(0, 2)).
+		Return(makeStructureTestTrace(2, 15*time.Millisecond, "query", "insert"), nil)
+	archiveReadMock.On("GetTrace", mock.Anything, mock.AnythingOfType("model.TraceID")).
+		Return(nil, spanstore.ErrTraceNotFound)
+
+	type contextKey string
+	ctx := context.WithValue(context.Background(), contextKey("foo"), "bar")
+	diff, err := qs.DiffTraces(ctx, model.NewTraceID(0, 1), model.NewTraceID(0, 2))
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Millisecond, diff.DurationDelta)
+	assert.Equal(t, 0, diff.Missing)
+	assert.Equal(t, 1, diff.Extra)
+	require.Len(t, diff.Spans, 3)
+	assert.Equal(t, "frontend:GET#0~db:insert#0", diff.Spans[2].Path)
+	assert.Nil(t, diff.Spans[2].Baseline)
+
+	_, err = qs.DiffTraces(ctx, model.NewTraceID(0, 3), model.NewTraceID(0, 1))
+	assert.Equal(t, spanstore.ErrTraceNotFound, err)
+}
+
+func TestDiffTracesFailure(t *testing.T) {
+	qs, readMock, _ := initializeTestService()
+	readMock.On("GetTrace", mock.Anything, mock.AnythingOfType("model.TraceID")).
+		Return(nil, errAdjustment)
+
+	type contextKey string
+	_, err := qs.DiffTraces(context.WithValue(context.Background(), contextKey("foo"), "bar"), model.NewTraceID(0, 1), model.NewTraceID(0, 2))
+	assert.Equal(t, errAdjustment, err)
+}
+
+func TestGetCriticalPathContributions(t *testing.T) {
+	traces := []*model.Trace{
+		makeStructureTestTrace(1, 10*time.Millisecond, "query"),
+		makeStructureTestTrace(2, 30*time.Millisecond, "insert"),
+	}
+	qs, readMock, _ := initializeTestService()
+	readMock.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+		Return(traces, nil).Once()
+
+	type contextKey string
+	ctx := context.WithValue(context.Background(), contextKey("foo"), "bar")
+	contributions, err := qs.GetCriticalPathContributions(ctx, &spanstore.TraceQueryParameters{})
+	require.NoError(t, err)
+	require.Len(t, contributions, 3)
+	assert.Equal(t, "GET", contributions[0].Operation)
+	assert.Equal(t, 38*time.Millisecond, contributions[0].Total)
+	assert.Equal(t, 2, contributions[0].Traces)
+
+	readMock.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+		Return(nil, errors.New("storage error")).Once()
+	_, err = qs.GetCriticalPathContributions(ctx, &spanstore.TraceQueryParameters{})
+	assert.EqualError(t, err, "storage error")
+}
+
+func TestFindProfiledTraces(t *testing.T) {
+	traces := []*model.Trace{
+		makeStructureTestTrace(1, 10*time.Millisecond, "query"),
+		makeStructureTestTrace(2, 30*time.Millisecond, "insert"),
+	}
+	queryGroupHash := structure.Hash(structure.Signature(traces[0]))
+	testCases := []struct {
+		name   string
+		params TailProfileParameters
+		traces int
+		err    error
+	}{
+		{name: "all traces", traces: 2},
+		{name: "one group", params: TailProfileParameters{StructureHash: queryGroupHash}, traces: 1},
+		{name: "unknown group", params: TailProfileParameters{StructureHash: "unknown"}, err: ErrStructureGroupNotFound},
+		{name: "unknown request type", params: TailProfileParameters{RequestType: "unknown"}},
+	}
+	for _, tc := range testCases {
+		testCase := tc // capture loop var
+		t.Run(testCase.name, func(t *testing.T) {
+			qs, readMock, _ := initializeTestService()
+			readMock.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+				Return(traces, nil).Once()
+
+			type contextKey string
+			ctx := context.WithValue(context.Background(), contextKey("foo"), "bar")
+			found, err := qs.FindProfiledTraces(ctx, &spanstore.TraceQueryParameters{}, testCase.params)
+			assert.Equal(t, testCase.err, err)
+			assert.Len(t, found, testCase.traces)
+		})
+	}
+
+	qs, readMock, _ := initializeTestService()
+	readMock.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+		Return(nil, errAdjustment).Once()
+	type contextKey string
+	_, err := qs.FindProfiledTraces(context.WithValue(context.Background(), contextKey("foo"), "bar"), &spanstore.TraceQueryParameters{}, TailProfileParameters{})
+	assert.Equal(t, errAdjustment, err)
+}
+
+func TestGetRegression(t *testing.T) {
+	baseline := []*model.Trace{
+		makeStructureTestTrace(1, 10*time.Millisecond, "query"),
+		makeStructureTestTrace(2, 12*time.Millisecond, "query"),
+	}
+	current := []*model.Trace{
+		makeStructureTestTrace(3, 11*time.Millisecond, "query"),
+		makeStructureTestTrace(4, 30*time.Millisecond, "insert"),
+	}
+	qs, readMock, _ := initializeTestService()
+	readMock.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+		Return(baseline, nil).Once()
+	readMock.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+		Return(current, nil).Once()
+
+	type contextKey string
+	ctx := context.WithValue(context.Background(), contextKey("foo"), "bar")
+	regression, err := qs.GetRegression(ctx, &spanstore.TraceQueryParameters{}, &spanstore.TraceQueryParameters{}, RegressionParameters{})
+	require.NoError(t, err)
+	assert.Equal(t, latency.DefaultSignificance, regression.Significance)
+	assert.Equal(t, 2, regression.Traces.Baseline.Count)
+	assert.Equal(t, 2, regression.Traces.Current.Count)
+	require.Len(t, regression.Appeared, 1)
+	assert.Equal(t, structure.Hash(structure.Signature(current[1])), regression.Appeared[0].Hash)
+	assert.Empty(t, regression.Disappeared)
+
+	readMock.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+		Return(baseline, nil).Once()
+	readMock.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+		Return(nil, errors.New("storage error")).Once()
+	_, err = qs.GetRegression(ctx, &spanstore.TraceQueryParameters{}, &spanstore.TraceQueryParameters{}, RegressionParameters{Significance: 0.1})
+	assert.EqualError(t, err, "storage error")
+}
+
 // Test QueryService.ArchiveTrace() with no ArchiveSpanWriter.
 func TestArchiveTraceNoOptions(t *testing.T) {
 	qs, _, _ := initializeTestService()
@@ -280,8 +1119,22 @@ func (*fakeStorageFactory1) CreateDependencyReader() (dependencystore.Reader, er
 func (f *fakeStorageFactory2) CreateArchiveSpanReader() (spanstore.Reader, error) { return f.r, f.rErr }
 func (f *fakeStorageFactory2) CreateArchiveSpanWriter() (spanstore.Writer, error) { return f.w, f.wErr }
 
+type fakeStorageFactory3 struct {
+	fakeStorageFactory1
+	r    spanstore.StructureReader
+	rErr error
+}
+
+func (f *fakeStorageFactory3) CreateStructureReader() (spanstore.StructureReader, error) {
+	return f.r, f.rErr
+}
+func (f *fakeStorageFactory3) CreateStructureWriter() (spanstore.StructureWriter, error) {
+	return nil, nil
+}
+
 var _ storage.Factory = new(fakeStorageFactory1)
 var _ storage.ArchiveFactory = new(fakeStorageFactory2)
+var _ storage.StructureIndexFactory = new(fakeStorageFactory3)
 
 func TestInitArchiveStorageErrors(t *testing.T) {
 	opts := &QueryServiceOptions{}
@@ -318,3 +1171,23 @@ func TestInitArchiveStorage(t *testing.T) {
 	assert.Equal(t, reader, opts.ArchiveSpanReader)
 	assert.Equal(t, writer, opts.ArchiveSpanWriter)
 }
+
+func TestInitStructureIndex(t *testing.T) {
+	opts := &QueryServiceOptions{}
+	logger := zap.NewNop()
+
+	assert.False(t, opts.InitStructureIndex(new(fakeStorageFactory1), logger))
+	assert.False(t, opts.InitStructureIndex(
+		&fakeStorageFactory3{rErr: storage.ErrStructureIndexNotSupported},
+		logger,
+	))
+	assert.False(t, opts.InitStructureIndex(
+		&fakeStorageFactory3{rErr: errors.New("error")},
+		logger,
+	))
+	assert.Nil(t, opts.StructureReader)
+
+	reader := &spanstoremocks.StructureReader{}
+	assert.True(t, opts.InitStructureIndex(&fakeStorageFactory3{r: reader}, logger))
+	assert.Equal(t, reader, opts.StructureReader)
+}
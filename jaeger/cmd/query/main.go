@@ -34,6 +34,7 @@ import (
 	"github.com/jaegertracing/jaeger/cmd/flags"
 	"github.com/jaegertracing/jaeger/cmd/query/app"
 	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+	"github.com/jaegertracing/jaeger/model/requesttype"
 	"github.com/jaegertracing/jaeger/pkg/config"
 	"github.com/jaegertracing/jaeger/pkg/version"
 	"github.com/jaegertracing/jaeger/plugin/storage"
@@ -101,7 +102,12 @@ func main() {
 			if err != nil {
 				logger.Fatal("Failed to create dependency reader", zap.Error(err))
 			}
-			queryServiceOptions := archiveOptions(storageFactory, logger)
+			queryServiceOptions := storageOptions(storageFactory, queryOpts.StructureIndex, logger)
+			queryServiceOptions.RequestTypes, err = queryOpts.RequestTypes.NewClassifier()
+			if err != nil {
+				logger.Fatal("Failed to load request type rules", zap.Error(err))
+			}
+			queryServiceOptions.MaxTraceIDsPerRequest = queryOpts.MaxTraceIDsPerRequest
 			queryService := querysvc.NewQueryService(
 				spanReader,
 				dependencyReader,
@@ -115,6 +121,9 @@ func main() {
 
 			svc.RunAndThen(func() {
 				server.Close()
+				if err := storageFactory.Close(); err != nil {
+					logger.Error("Failed to close storage factory", zap.Error(err))
+				}
 			})
 			return nil
 		},
@@ -130,6 +139,7 @@ func main() {
 		svc.AddFlags,
 		storageFactory.AddFlags,
 		app.AddFlags,
+		requesttype.AddFlags,
 	)
 
 	if error := command.Execute(); error != nil {
@@ -138,10 +148,17 @@ func main() {
 	}
 }
 
-func archiveOptions(storageFactory istorage.Factory, logger *zap.Logger) *querysvc.QueryServiceOptions {
+// storageOptions initializes the archive storage and, when the collectors index the structures
+// of the traces, the structure index.
+func storageOptions(storageFactory istorage.Factory, structureIndex bool, logger *zap.Logger) *querysvc.QueryServiceOptions {
 	opts := &querysvc.QueryServiceOptions{}
 	if !opts.InitArchiveStorage(storageFactory, logger) {
 		logger.Info("Archive storage not initialized")
 	}
+	if !structureIndex {
+		logger.Info("Structure index not enabled, the structural hashes are computed by the query service")
+	} else if !opts.InitStructureIndex(storageFactory, logger) {
+		logger.Info("Structure index not initialized")
+	}
 	return opts
 }
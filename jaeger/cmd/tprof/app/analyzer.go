@@ -0,0 +1,204 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/model/adjuster"
+	uiconv "github.com/jaegertracing/jaeger/model/converter/json"
+	"github.com/jaegertracing/jaeger/model/converter/pprof"
+	"github.com/jaegertracing/jaeger/model/latency"
+	"github.com/jaegertracing/jaeger/model/requesttype"
+	"github.com/jaegertracing/jaeger/model/structure"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+var errInvalidCutoff = errors.New("cutoff must be between 0 and 100")
+
+// Analyzer fetches traces from a span reader and profiles them layer by layer:
+// integrity status, request type, structure and subspans.
+type Analyzer struct {
+	reader       spanstore.Reader
+	adjuster     adjuster.Adjuster
+	requestTypes *requesttype.Classifier
+	logger       *zap.Logger
+	options      Options
+	now          func() time.Time
+}
+
+// NewAnalyzer creates a new Analyzer. The traces are adjusted with the same
+// adjusters as the query service before being analyzed, and classified into
+// request types by requestTypes, which may be nil.
+func NewAnalyzer(reader spanstore.Reader, requestTypes *requesttype.Classifier, options Options, logger *zap.Logger) *Analyzer {
+	return &Analyzer{
+		reader:       reader,
+		adjuster:     adjuster.Sequence(adjuster.StandardAdjusters...),
+		requestTypes: requestTypes,
+		logger:       logger,
+		options:      options,
+		now:          time.Now,
+	}
+}
+
+// Analyze fetches the traces and builds the report.
+func (a *Analyzer) Analyze(ctx context.Context) (*Report, error) {
+	if a.options.Cutoff <= 0 || a.options.Cutoff >= 100 {
+		return nil, errInvalidCutoff
+	}
+	start, end := a.window()
+	traces, err := a.findTraces(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+	a.logger.Info("Analyzing traces", zap.Int("count", len(traces)))
+	return &Report{
+		Version:    ReportVersion,
+		Service:    a.options.Service,
+		Operation:  a.options.Operation,
+		Start:      start,
+		End:        end,
+		Cutoff:     a.options.Cutoff,
+		TraceCount: len(traces),
+		Groups:     a.statusGroups(traces),
+	}, nil
+}
+
+// Export fetches the traces and writes them to w in the configured format: the JSON report,
+// or a pprof profile of the self-time of the spans, see pprof.FromDomain.
+func (a *Analyzer) Export(ctx context.Context, w io.Writer) error {
+	switch a.options.Format {
+	case FormatReport:
+		report, err := a.Analyze(ctx)
+		if err != nil {
+			return err
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	case FormatPprof:
+		start, end := a.window()
+		traces, err := a.findTraces(ctx, start, end)
+		if err != nil {
+			return err
+		}
+		return pprof.FromDomain(traces).Write(w)
+	}
+	return fmt.Errorf("unknown format %q", a.options.Format)
+}
+
+// window returns the time range in which the traces are searched.
+func (a *Analyzer) window() (start, end time.Time) {
+	end = a.now()
+	return end.Add(-a.options.Lookback), end
+}
+
+// findTraces fetches and adjusts the traces of the configured service, or of every service
+// known to the reader when none is configured.
+func (a *Analyzer) findTraces(ctx context.Context, start, end time.Time) ([]*model.Trace, error) {
+	var traces []*model.Trace
+	truncated, err := a.options.FindTraces(ctx, a.reader, start, end, func(trace *model.Trace) error {
+		adjusted, err := a.adjuster.Adjust(trace)
+		if err != nil {
+			a.logger.Warn("Failed to adjust trace", zap.Stringer("trace-id", trace.Spans[0].TraceID), zap.Error(err))
+		}
+		traces = append(traces, adjusted)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, service := range truncated {
+		a.logger.Warn("The traces of the service reached the limit, some were left out of the analysis", zap.String("service", service), zap.Int("limit", a.options.Limit))
+	}
+	return traces, nil
+}
+
+func (a *Analyzer) statusGroups(traces []*model.Trace) []*Group {
+	return a.groupBy(StatusLayer, traces, func(trace *model.Trace) string {
+		status, err := adjuster.IntegrityFromWarnings(trace)
+		if err != nil {
+			status = adjuster.ClassifyIntegrity(trace)
+		}
+		return status.String()
+	}, a.requestTypeGroups)
+}
+
+func (a *Analyzer) requestTypeGroups(traces []*model.Trace) []*Group {
+	return a.groupBy(RequestTypeLayer, traces, a.requestTypes.Classify, a.structureGroups)
+}
+
+func (a *Analyzer) structureGroups(traces []*model.Trace) []*Group {
+	var groups []*Group
+	for _, sg := range structure.GroupTraces(traces) {
+		group := a.newGroup(StructureLayer, sg.Hash, sg.Traces)
+		group.Signature = sg.Signature
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// groupBy splits the traces by key into groups of the given layer, ordered from the largest
+// to the smallest, and breaks each group down with next.
+func (a *Analyzer) groupBy(
+	layer string,
+	traces []*model.Trace,
+	key func(trace *model.Trace) string,
+	next func(traces []*model.Trace) []*Group,
+) []*Group {
+	byKey := make(map[string][]*model.Trace)
+	for _, trace := range traces {
+		k := key(trace)
+		byKey[k] = append(byKey[k], trace)
+	}
+	groups := make([]*Group, 0, len(byKey))
+	for k, members := range byKey {
+		group := a.newGroup(layer, k, members)
+		// subspans are only comparable between traces sharing the same structure
+		group.Profile.Subspans = nil
+		group.Groups = next(members)
+		groups = append(groups, group)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].TraceCount != groups[j].TraceCount {
+			return groups[i].TraceCount > groups[j].TraceCount
+		}
+		return groups[i].Key < groups[j].Key
+	})
+	return groups
+}
+
+func (a *Analyzer) newGroup(layer, key string, traces []*model.Trace) *Group {
+	traceIDs := make([]string, len(traces))
+	for i, trace := range traces {
+		traceIDs[i] = trace.Spans[0].TraceID.String()
+	}
+	return &Group{
+		Layer:      layer,
+		Key:        key,
+		TraceCount: len(traces),
+		TraceIDs:   traceIDs,
+		Profile:    uiconv.TailProfileFromDomain(latency.NewTailProfile(traces, a.options.Cutoff)),
+	}
+}
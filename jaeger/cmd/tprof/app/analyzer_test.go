@@ -0,0 +1,237 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/cmd/offline"
+	"github.com/jaegertracing/jaeger/cmd/offline/offlinetest"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/model/requesttype"
+	"github.com/jaegertracing/jaeger/plugin/storage/memory"
+	spanstoremocks "github.com/jaegertracing/jaeger/storage/spanstore/mocks"
+)
+
+var (
+	analysisEnd   = time.Date(2020, time.January, 1, 12, 0, 0, 0, time.UTC)
+	testTraceTime = analysisEnd.Add(-time.Minute)
+)
+
+func newTestAnalyzer(reader *memory.Store, options Options) *Analyzer {
+	a := NewAnalyzer(reader, nil, options, zap.NewNop())
+	a.now = func() time.Time { return analysisEnd }
+	return a
+}
+
+func TestAnalyze(t *testing.T) {
+	store := memory.NewStore()
+	for i := uint64(1); i <= 10; i++ {
+		offlinetest.WriteTrace(t, store, i, testTraceTime, time.Duration(i)*10*time.Millisecond, "GET", "query")
+	}
+	offlinetest.WriteTrace(t, store, 11, testTraceTime, 5*time.Millisecond, "GET", "query", "query")
+	offlinetest.WriteTrace(t, store, 12, testTraceTime, 5*time.Millisecond, "POST", "insert")
+
+	report, err := newTestAnalyzer(store, Options{QueryOptions: offline.QueryOptions{Lookback: time.Hour, Limit: 100}, Cutoff: 90}).Analyze(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, ReportVersion, report.Version)
+	assert.Equal(t, analysisEnd, report.End)
+	assert.Equal(t, analysisEnd.Add(-time.Hour), report.Start)
+	// the traces are found through both services, but reported once
+	assert.Equal(t, 12, report.TraceCount)
+
+	require.Len(t, report.Groups, 1)
+	status := report.Groups[0]
+	assert.Equal(t, StatusLayer, status.Layer)
+	assert.Equal(t, "complete", status.Key)
+	assert.Equal(t, 12, status.TraceCount)
+	assert.Nil(t, status.Profile.Subspans)
+
+	require.Len(t, status.Groups, 2)
+	get, post := status.Groups[0], status.Groups[1]
+	assert.Equal(t, RequestTypeLayer, get.Layer)
+	assert.Equal(t, "frontend:GET", get.Key)
+	assert.Equal(t, 11, get.TraceCount)
+	assert.Equal(t, "frontend:POST", post.Key)
+	assert.Equal(t, 1, post.TraceCount)
+
+	require.Len(t, get.Groups, 2)
+	single, double := get.Groups[0], get.Groups[1]
+	assert.Equal(t, StructureLayer, single.Layer)
+	assert.Equal(t, 10, single.TraceCount)
+	assert.NotEmpty(t, single.Signature)
+	assert.NotEmpty(t, single.Profile.Subspans)
+	assert.Empty(t, single.Groups)
+	assert.Equal(t, 1, double.TraceCount)
+	assert.Equal(t, []string{model.NewTraceID(0, 11).String()}, double.TraceIDs)
+
+	assert.Equal(t, 90.0, single.Profile.Cutoff)
+	assert.Equal(t, int64(100000), single.Profile.Traces.Tail.Max)
+}
+
+func TestAnalyzeOperation(t *testing.T) {
+	store := memory.NewStore()
+	offlinetest.WriteTrace(t, store, 1, testTraceTime, time.Millisecond, "GET", "query")
+	offlinetest.WriteTrace(t, store, 2, testTraceTime, time.Millisecond, "POST", "insert")
+
+	report, err := newTestAnalyzer(store, Options{
+		QueryOptions: offline.QueryOptions{
+			Service:   "frontend",
+			Operation: "POST",
+			Lookback:  time.Hour,
+		},
+		Cutoff: 90,
+	}).Analyze(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "frontend", report.Service)
+	assert.Equal(t, "POST", report.Operation)
+	assert.Equal(t, 1, report.TraceCount)
+	assert.Equal(t, "frontend:POST", report.Groups[0].Groups[0].Key)
+}
+
+func TestAnalyzeIntegrity(t *testing.T) {
+	store := memory.NewStore()
+	offlinetest.WriteTrace(t, store, 1, testTraceTime, time.Millisecond, "GET", "query")
+	id := model.NewTraceID(0, 2)
+	require.NoError(t, store.WriteSpan(&model.Span{
+		TraceID:       id,
+		SpanID:        model.NewSpanID(2),
+		OperationName: "query",
+		References:    []model.SpanRef{model.NewChildOfRef(id, model.NewSpanID(1))},
+		StartTime:     testTraceTime,
+		Duration:      time.Millisecond,
+		Process:       &model.Process{ServiceName: "backend"},
+	}))
+
+	report, err := newTestAnalyzer(store, Options{QueryOptions: offline.QueryOptions{Lookback: time.Hour}, Cutoff: 90}).Analyze(context.Background())
+	require.NoError(t, err)
+	require.Len(t, report.Groups, 2)
+	assert.Equal(t, "complete", report.Groups[0].Key)
+	assert.Equal(t, "dropped-span,orphaned", report.Groups[1].Key)
+	assert.Equal(t, "backend:query", report.Groups[1].Groups[0].Key)
+}
+
+func TestAnalyzeErrors(t *testing.T) {
+	testCases := []struct {
+		name    string
+		options Options
+		reader  func() *spanstoremocks.Reader
+		err     string
+	}{
+		{
+			name:    "invalid cutoff",
+			options: Options{Cutoff: 100},
+			reader:  func() *spanstoremocks.Reader { return &spanstoremocks.Reader{} },
+			err:     "cutoff must be between 0 and 100",
+		},
+		{
+			name:    "operation without service",
+			options: Options{QueryOptions: offline.QueryOptions{Operation: "GET"}, Cutoff: 90},
+			reader:  func() *spanstoremocks.Reader { return &spanstoremocks.Reader{} },
+			err:     "an operation requires a service",
+		},
+		{
+			name:    "services failure",
+			options: Options{Cutoff: 90},
+			reader: func() *spanstoremocks.Reader {
+				r := &spanstoremocks.Reader{}
+				r.On("GetServices", mock.Anything).Return(nil, errors.New("storage error"))
+				return r
+			},
+			err: "cannot get services: storage error",
+		},
+		{
+			name:    "traces failure",
+			options: Options{QueryOptions: offline.QueryOptions{Service: "frontend"}, Cutoff: 90},
+			reader: func() *spanstoremocks.Reader {
+				r := &spanstoremocks.Reader{}
+				r.On("FindTraces", mock.Anything, mock.Anything).Return(nil, errors.New("storage error"))
+				return r
+			},
+			err: "cannot find traces of service frontend: storage error",
+		},
+	}
+	for _, tc := range testCases {
+		testCase := tc // capture loop var
+		t.Run(testCase.name, func(t *testing.T) {
+			a := NewAnalyzer(testCase.reader(), nil, testCase.options, zap.NewNop())
+			_, err := a.Analyze(context.Background())
+			assert.EqualError(t, err, testCase.err)
+		})
+	}
+}
+
+func TestAnalyzeRequestTypes(t *testing.T) {
+	store := memory.NewStore()
+	offlinetest.WriteTrace(t, store, 1, testTraceTime, time.Millisecond, "GET", "query")
+	offlinetest.WriteTrace(t, store, 2, testTraceTime, time.Millisecond, "POST", "insert")
+	offlinetest.WriteTrace(t, store, 3, testTraceTime, time.Millisecond, "PUT", "update")
+	requestTypes, err := requesttype.NewClassifier([]requesttype.Rule{{Type: "write", OperationPattern: "^(POST|PUT)$"}})
+	require.NoError(t, err)
+
+	a := NewAnalyzer(store, requestTypes, Options{QueryOptions: offline.QueryOptions{Service: "frontend", Lookback: time.Hour}, Cutoff: 90}, zap.NewNop())
+	a.now = func() time.Time { return analysisEnd }
+	report, err := a.Analyze(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, report.Groups, 1)
+	require.Len(t, report.Groups[0].Groups, 2)
+	write, get := report.Groups[0].Groups[0], report.Groups[0].Groups[1]
+	assert.Equal(t, "write", write.Key)
+	assert.Equal(t, 2, write.TraceCount)
+	assert.Len(t, write.Groups, 2, "the write request types have different structures")
+	assert.Equal(t, "frontend:GET", get.Key)
+}
+
+func TestExport(t *testing.T) {
+	store := memory.NewStore()
+	offlinetest.WriteTrace(t, store, 1, testTraceTime, 10*time.Millisecond, "GET", "query")
+
+	var out bytes.Buffer
+	err := newTestAnalyzer(store, Options{QueryOptions: offline.QueryOptions{Service: "frontend", Lookback: time.Hour}, Cutoff: 90, Format: FormatReport}).Export(context.Background(), &out)
+	require.NoError(t, err)
+	var report Report
+	require.NoError(t, json.Unmarshal(out.Bytes(), &report))
+	assert.Equal(t, 1, report.TraceCount)
+
+	out.Reset()
+	err = newTestAnalyzer(store, Options{QueryOptions: offline.QueryOptions{Service: "frontend", Lookback: time.Hour}, Format: FormatPprof}).Export(context.Background(), &out)
+	require.NoError(t, err)
+	gz, err := gzip.NewReader(&out)
+	require.NoError(t, err)
+	profile, err := ioutil.ReadAll(gz)
+	require.NoError(t, err)
+	assert.Contains(t, string(profile), "backend:query")
+
+	err = newTestAnalyzer(store, Options{QueryOptions: offline.QueryOptions{Service: "frontend"}, Format: "xml"}).Export(context.Background(), &out)
+	assert.EqualError(t, err, `unknown format "xml"`)
+	err = newTestAnalyzer(store, Options{Format: FormatReport}).Export(context.Background(), &out)
+	assert.Equal(t, errInvalidCutoff, err)
+	err = newTestAnalyzer(store, Options{QueryOptions: offline.QueryOptions{Operation: "GET"}, Format: FormatPprof}).Export(context.Background(), &out)
+	assert.EqualError(t, err, "an operation requires a service")
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"flag"
+
+	"github.com/spf13/viper"
+
+	"github.com/jaegertracing/jaeger/cmd/offline"
+	"github.com/jaegertracing/jaeger/model/latency"
+)
+
+const (
+	tprofPrefix = "tprof"
+	tprofCutoff = tprofPrefix + ".cutoff"
+	tprofFormat = tprofPrefix + ".format"
+
+	// FormatReport is the format of the JSON report, see Report.
+	FormatReport = "report"
+	// FormatPprof is the format of the gzipped pprof profiles.
+	FormatPprof = "pprof"
+)
+
+// Options holds configuration for the tprof analysis
+type Options struct {
+	// QueryOptions selects the analyzed traces, and the path of the report or the profile
+	offline.QueryOptions
+	// Cutoff is the percentile of the trace durations separating the norm from the tail
+	Cutoff float64
+	// Format is the format of the output, FormatReport or FormatPprof
+	Format string
+}
+
+// AddFlags adds flags for Options
+func AddFlags(flagSet *flag.FlagSet) {
+	offline.AddFlags(flagSet, tprofPrefix)
+	flagSet.Float64(tprofCutoff, latency.DefaultTailCutoff, "The percentile of the trace durations separating the norm from the tail, between 0 and 100")
+	flagSet.String(tprofFormat, FormatReport, "The format of the output: "+FormatReport+" for the JSON report, or "+FormatPprof+" for a pprof profile of the self-time of the spans")
+}
+
+// InitFromViper initializes Options with properties from viper
+func (opts *Options) InitFromViper(v *viper.Viper) *Options {
+	opts.QueryOptions.InitFromViper(v, tprofPrefix)
+	opts.Cutoff = v.GetFloat64(tprofCutoff)
+	opts.Format = v.GetString(tprofFormat)
+	return opts
+}
@@ -0,0 +1,43 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jaegertracing/jaeger/pkg/config"
+)
+
+func TestTprofFlags(t *testing.T) {
+	v, command := config.Viperize(AddFlags)
+	command.ParseFlags([]string{
+		"--tprof.service=frontend",
+		"--tprof.cutoff=99",
+		"--tprof.format=pprof",
+	})
+	opts := new(Options).InitFromViper(v)
+	assert.Equal(t, "frontend", opts.Service)
+	assert.Equal(t, 99.0, opts.Cutoff)
+	assert.Equal(t, "pprof", opts.Format)
+}
+
+func TestTprofFlagsDefaults(t *testing.T) {
+	v, _ := config.Viperize(AddFlags)
+	opts := new(Options).InitFromViper(v)
+	assert.Equal(t, 90.0, opts.Cutoff)
+	assert.Equal(t, FormatReport, opts.Format)
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"time"
+
+	"github.com/jaegertracing/jaeger/model/json"
+)
+
+// ReportVersion is the version of the report format, incremented on every incompatible change.
+const ReportVersion = 1
+
+// Analysis layers, from the outermost to the innermost.
+const (
+	// StatusLayer groups the traces by integrity status, see model.IntegrityStatus.
+	StatusLayer = "status"
+	// RequestTypeLayer groups the traces by request type, see requesttype.Classifier.
+	RequestTypeLayer = "request-type"
+	// StructureLayer groups the traces by structural signature, see structure.Signature.
+	// The profiles of the structure groups carry the subspan layer.
+	StructureLayer = "structure"
+)
+
+// Report is the result of a tprof analysis.
+type Report struct {
+	Version    int       `json:"version"`
+	Service    string    `json:"service,omitempty"`
+	Operation  string    `json:"operation,omitempty"`
+	Start      time.Time `json:"start"`
+	End        time.Time `json:"end"`
+	Cutoff     float64   `json:"cutoff"`
+	TraceCount int       `json:"traceCount"`
+	Groups     []*Group  `json:"groups"`
+}
+
+// Group is a set of traces sharing the same key in a layer of the analysis,
+// broken down into the groups of the next layer.
+type Group struct {
+	Layer string `json:"layer"`
+	Key   string `json:"key"`
+	// Signature is the structural signature of the traces of a structure group.
+	Signature  string            `json:"signature,omitempty"`
+	TraceCount int               `json:"traceCount"`
+	TraceIDs   []string          `json:"traceIDs"`
+	Profile    *json.TailProfile `json:"profile"`
+	Groups     []*Group          `json:"groups,omitempty"`
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/jaegertracing/jaeger/cmd/docs"
+	"github.com/jaegertracing/jaeger/cmd/env"
+	"github.com/jaegertracing/jaeger/cmd/flags"
+	"github.com/jaegertracing/jaeger/cmd/offline"
+	"github.com/jaegertracing/jaeger/cmd/tprof/app"
+	"github.com/jaegertracing/jaeger/model/requesttype"
+	"github.com/jaegertracing/jaeger/pkg/config"
+	"github.com/jaegertracing/jaeger/pkg/version"
+	"github.com/jaegertracing/jaeger/plugin/storage"
+)
+
+func main() {
+	storageFactory, err := storage.NewFactory(storage.FactoryConfigFromEnvAndCLI(os.Args, os.Stderr))
+	if err != nil {
+		log.Fatalf("Cannot initialize storage factory: %v", err)
+	}
+
+	v := viper.New()
+	var command = &cobra.Command{
+		Use:   "jaeger-tprof",
+		Short: "Jaeger tprof profiles the tail latency of the traces in a storage backend.",
+		Long: `Jaeger tprof reads the traces from the configured storage backend, groups them by integrity status,
+request type and structure, compares the norm and the tail traces of every group and writes a JSON report.
+It can also write a pprof profile of the self-time of the spans, to be explored with go tool pprof.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			spanReader, logger, err := offline.NewSpanReader(v, storageFactory)
+			if err != nil {
+				return err
+			}
+			requestTypes, err := new(requesttype.Options).InitFromViper(v).NewClassifier()
+			if err != nil {
+				return err
+			}
+
+			opts := new(app.Options).InitFromViper(v)
+			analyzer := app.NewAnalyzer(spanReader, requestTypes, *opts, logger)
+			return offline.WriteOutput(opts.Output, func(w io.Writer) error {
+				return analyzer.Export(context.Background(), w)
+			})
+		},
+	}
+
+	command.AddCommand(version.Command())
+	command.AddCommand(env.Command())
+	command.AddCommand(docs.Command(v))
+
+	config.AddFlags(
+		v,
+		command,
+		flags.AddConfigFileFlag,
+		flags.AddLoggingFlag,
+		storageFactory.AddFlags,
+		app.AddFlags,
+		requesttype.AddFlags,
+	)
+
+	if err := command.Execute(); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+}
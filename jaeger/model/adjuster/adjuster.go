@@ -16,6 +16,8 @@
 package adjuster
 
 import (
+	"fmt"
+
 	"github.com/jaegertracing/jaeger/model"
 	"github.com/jaegertracing/jaeger/pkg/multierror"
 )
@@ -69,3 +71,14 @@ func (c sequence) Adjust(trace *model.Trace) (*model.Trace, error) {
 	}
 	return trace, multierror.Wrap(errors)
 }
+
+// AdjustCopy applies the adjuster to a deep copy of the trace, leaving the trace untouched,
+// e.g. when it is shared with the span storage. Since adjusters return a usable trace even
+// when they fail, the adjusted copy is always returned, with the error added to its warnings.
+func AdjustCopy(adjuster Adjuster, trace *model.Trace) *model.Trace {
+	adjusted, err := adjuster.Adjust(trace.DeepCopy())
+	if err != nil {
+		adjusted.Warnings = append(adjusted.Warnings, fmt.Sprintf("cannot adjust the trace: %v", err))
+	}
+	return adjusted
+}
@@ -66,3 +66,24 @@ func TestSequences(t *testing.T) {
 		assert.EqualError(t, err, testCase.err)
 	}
 }
+
+func TestAdjustCopy(t *testing.T) {
+	adj := adjuster.Sequence(
+		adjuster.Func(func(trace *model.Trace) (*model.Trace, error) {
+			trace.Spans[0].SpanID++
+			return trace, nil
+		}),
+		adjuster.Func(func(trace *model.Trace) (*model.Trace, error) {
+			return trace, errors.New("mock adjuster error")
+		}),
+	)
+	span := &model.Span{}
+	trace := &model.Trace{Spans: []*model.Span{span}}
+
+	adjTrace := adjuster.AdjustCopy(adj, trace)
+	assert.False(t, span == adjTrace.Spans[0], "the trace is copied")
+	assert.EqualValues(t, 0, span.SpanID, "the trace is left untouched")
+	assert.EqualValues(t, 1, adjTrace.Spans[0].SpanID)
+	assert.Empty(t, trace.Warnings)
+	assert.Equal(t, []string{"cannot adjust the trace: mock adjuster error"}, adjTrace.Warnings)
+}
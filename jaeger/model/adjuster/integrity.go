@@ -0,0 +1,115 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adjuster
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// IntegrityWarningPrefix starts the trace warning recording the integrity status of a trace.
+const IntegrityWarningPrefix = "integrity: "
+
+// Integrity returns an adjuster that classifies the integrity of the trace, see ClassifyIntegrity.
+// Unless the trace is complete, it records the status in a trace warning made of
+// IntegrityWarningPrefix followed by the status, and describes the problem of
+// every faulty span in Span.Warnings. Adjusting a trace again does not duplicate the
+// warnings, and replaces the recorded status.
+//
+// This adjuster never returns any errors.
+func Integrity() Adjuster {
+	return Func(func(trace *model.Trace) (*model.Trace, error) {
+		status := classifyIntegrity(trace, func(span *model.Span, warning string) {
+			span.Warnings = appendMissing(span.Warnings, warning)
+		})
+		warnings := trace.Warnings[:0]
+		for _, warning := range trace.Warnings {
+			if !strings.HasPrefix(warning, IntegrityWarningPrefix) {
+				warnings = append(warnings, warning)
+			}
+		}
+		trace.Warnings = warnings
+		if status != model.IntegrityComplete {
+			trace.Warnings = append(trace.Warnings, IntegrityWarningPrefix+status.String())
+		}
+		return trace, nil
+	})
+}
+
+func appendMissing(warnings []string, warning string) []string {
+	for _, w := range warnings {
+		if w == warning {
+			return warnings
+		}
+	}
+	return append(warnings, warning)
+}
+
+// ClassifyIntegrity returns the integrity status of the trace. References with
+// a zero trace ID are ignored, as they are removed by SpanReferences.
+func ClassifyIntegrity(trace *model.Trace) model.IntegrityStatus {
+	return classifyIntegrity(trace, func(*model.Span, string) {})
+}
+
+// IntegrityFromWarnings returns the integrity status recorded by the Integrity adjuster.
+func IntegrityFromWarnings(trace *model.Trace) (model.IntegrityStatus, error) {
+	for _, warning := range trace.Warnings {
+		if strings.HasPrefix(warning, IntegrityWarningPrefix) {
+			return model.IntegrityStatusFromString(strings.TrimPrefix(warning, IntegrityWarningPrefix))
+		}
+	}
+	return model.IntegrityComplete, nil
+}
+
+func classifyIntegrity(trace *model.Trace, warn func(span *model.Span, warning string)) model.IntegrityStatus {
+	spanIDs := make(map[model.SpanID]struct{}, len(trace.Spans))
+	for _, span := range trace.Spans {
+		spanIDs[span.SpanID] = struct{}{}
+	}
+
+	status := model.IntegrityComplete
+	roots := 0
+	for _, span := range trace.Spans {
+		parents := 0
+		for _, ref := range span.References {
+			if ref.TraceID.High == 0 && ref.TraceID.Low == 0 {
+				continue
+			}
+			if ref.TraceID != span.TraceID {
+				status |= model.IntegrityCrossTraceReference
+				warn(span, fmt.Sprintf("Span references span %v of another trace %v", ref.SpanID, ref.TraceID))
+				continue
+			}
+			parents++
+			if _, ok := spanIDs[ref.SpanID]; !ok {
+				status |= model.IntegrityDroppedSpan
+				warn(span, fmt.Sprintf("Span references span %v missing from the trace", ref.SpanID))
+			}
+		}
+		switch {
+		case parents == 0:
+			roots++
+		case parents > 1:
+			status |= model.IntegrityMultiParent
+			warn(span, fmt.Sprintf("Span references %d spans of the trace", parents))
+		}
+	}
+	if roots != 1 {
+		status |= model.IntegrityOrphaned
+	}
+	return status
+}
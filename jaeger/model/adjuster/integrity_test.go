@@ -0,0 +1,137 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adjuster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestIntegrityAdjuster(t *testing.T) {
+	traceID := model.NewTraceID(0, 1)
+	otherTraceID := model.NewTraceID(0, 2)
+	newSpan := func(id uint64, refs ...model.SpanRef) *model.Span {
+		return &model.Span{TraceID: traceID, SpanID: model.NewSpanID(id), References: refs}
+	}
+	childOf := func(id uint64) model.SpanRef {
+		return model.NewChildOfRef(traceID, model.NewSpanID(id))
+	}
+	testCases := []struct {
+		name     string
+		spans    []*model.Span
+		status   model.IntegrityStatus
+		warnings map[int]string
+	}{
+		{
+			name:   "complete",
+			spans:  []*model.Span{newSpan(1), newSpan(2, childOf(1)), newSpan(3, childOf(2), model.SpanRef{})},
+			status: model.IntegrityComplete,
+		},
+		{
+			name:     "dropped span",
+			spans:    []*model.Span{newSpan(1), newSpan(2, childOf(5))},
+			status:   model.IntegrityDroppedSpan,
+			warnings: map[int]string{1: "Span references span 5 missing from the trace"},
+		},
+		{
+			name:   "no root",
+			spans:  []*model.Span{newSpan(1, childOf(2)), newSpan(2, childOf(1))},
+			status: model.IntegrityOrphaned,
+		},
+		{
+			name:   "several roots",
+			spans:  []*model.Span{newSpan(1), newSpan(2)},
+			status: model.IntegrityOrphaned,
+		},
+		{
+			name:     "multiple parents",
+			spans:    []*model.Span{newSpan(1), newSpan(2, childOf(1)), newSpan(3, childOf(1), model.NewFollowsFromRef(traceID, model.NewSpanID(2)))},
+			status:   model.IntegrityMultiParent,
+			warnings: map[int]string{2: "Span references 2 spans of the trace"},
+		},
+		{
+			name:     "cross trace reference",
+			spans:    []*model.Span{newSpan(1), newSpan(2, childOf(1), model.NewFollowsFromRef(otherTraceID, model.NewSpanID(7)))},
+			status:   model.IntegrityCrossTraceReference,
+			warnings: map[int]string{1: "Span references span 7 of another trace 2"},
+		},
+		{
+			name:   "several problems",
+			spans:  []*model.Span{newSpan(1, childOf(9)), newSpan(2, model.NewChildOfRef(otherTraceID, model.NewSpanID(1)))},
+			status: model.IntegrityDroppedSpan | model.IntegrityCrossTraceReference,
+			warnings: map[int]string{
+				0: "Span references span 9 missing from the trace",
+				1: "Span references span 1 of another trace 2",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		testCase := tc // capture loop var
+		t.Run(testCase.name, func(t *testing.T) {
+			trace := &model.Trace{Spans: testCase.spans}
+			assert.Equal(t, testCase.status, ClassifyIntegrity(trace))
+
+			// adjusting twice leaves the same warnings
+			for run := 0; run < 2; run++ {
+				trace, err := Integrity().Adjust(trace)
+				assert.NoError(t, err)
+				if testCase.status == model.IntegrityComplete {
+					assert.Empty(t, trace.Warnings)
+				} else {
+					assert.Equal(t, []string{IntegrityWarningPrefix + testCase.status.String()}, trace.Warnings)
+				}
+				for i, span := range trace.Spans {
+					if warning, ok := testCase.warnings[i]; ok {
+						assert.Equal(t, []string{warning}, span.Warnings)
+					} else {
+						assert.Empty(t, span.Warnings)
+					}
+				}
+				status, err := IntegrityFromWarnings(trace)
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.status, status)
+			}
+		})
+	}
+}
+
+func TestIntegrityAdjusterReplacesStatus(t *testing.T) {
+	traceID := model.NewTraceID(0, 1)
+	trace := &model.Trace{
+		Spans: []*model.Span{
+			{TraceID: traceID, SpanID: model.NewSpanID(1)},
+			{TraceID: traceID, SpanID: model.NewSpanID(2), References: []model.SpanRef{model.NewChildOfRef(traceID, model.NewSpanID(3))}},
+		},
+		Warnings: []string{"unrelated"},
+	}
+	trace, err := Integrity().Adjust(trace)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"unrelated", IntegrityWarningPrefix + model.IntegrityDroppedSpan.String()}, trace.Warnings)
+
+	// the missing span arrives
+	trace.Spans = append(trace.Spans, &model.Span{TraceID: traceID, SpanID: model.NewSpanID(3), References: []model.SpanRef{model.NewChildOfRef(traceID, model.NewSpanID(1))}})
+	trace, err = Integrity().Adjust(trace)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"unrelated"}, trace.Warnings)
+}
+
+func TestIntegrityFromWarnings(t *testing.T) {
+	trace := &model.Trace{Warnings: []string{"unrelated", IntegrityWarningPrefix + "bogus"}}
+	_, err := IntegrityFromWarnings(trace)
+	assert.Error(t, err)
+}
@@ -0,0 +1,26 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adjuster
+
+// StandardAdjusters is the list of adjusters applied by the query service before returning
+// the traces to the API clients, and by the collector before hashing their structure.
+var StandardAdjusters = []Adjuster{
+	SpanIDDeduper(),
+	ClockSkew(),
+	IPTagAdjuster(),
+	SortLogFields(),
+	SpanReferences(),
+	Integrity(),
+}
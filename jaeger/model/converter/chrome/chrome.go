@@ -0,0 +1,152 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package chrome converts traces into the Chrome Trace Event format, read by
+// chrome://tracing and the Perfetto UI.
+package chrome
+
+import (
+	"sort"
+	"time"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+const (
+	// PhaseComplete marks an event with both a start time and a duration.
+	PhaseComplete = "X"
+	// PhaseMetadata marks an event naming a process or a thread.
+	PhaseMetadata = "M"
+)
+
+// Event is a Chrome trace event. Timestamps and durations are in microseconds.
+type Event struct {
+	Name     string            `json:"name"`
+	Category string            `json:"cat,omitempty"`
+	Phase    string            `json:"ph"`
+	Time     uint64            `json:"ts"`
+	Duration uint64            `json:"dur,omitempty"`
+	PID      int               `json:"pid"`
+	TID      int               `json:"tid"`
+	Args     map[string]string `json:"args,omitempty"`
+}
+
+// Trace is the JSON object format of a Chrome trace.
+type Trace struct {
+	TraceEvents     []Event `json:"traceEvents"`
+	DisplayTimeUnit string  `json:"displayTimeUnit"`
+}
+
+// FromDomain converts the spans of a trace into complete events, one process per service.
+// The spans of a service are spread over threads so that the spans on a thread are either
+// nested or disjoint, which the viewers require to draw them as stacks.
+func FromDomain(trace *model.Trace) *Trace {
+	services := make(map[string][]*model.Span)
+	for _, span := range trace.Spans {
+		service := span.Process.GetServiceName()
+		services[service] = append(services[service], span)
+	}
+	names := make([]string, 0, len(services))
+	for service := range services {
+		names = append(names, service)
+	}
+	sort.Strings(names)
+
+	events := []Event{}
+	for i, service := range names {
+		pid := i + 1
+		events = append(events, Event{
+			Name:  "process_name",
+			Phase: PhaseMetadata,
+			PID:   pid,
+			Args:  map[string]string{"name": service},
+		})
+		spans := services[service]
+		lanes := assignLanes(spans)
+		threads := 0
+		for j, span := range spans {
+			tid := lanes[j] + 1
+			if tid > threads {
+				threads = tid
+			}
+			events = append(events, spanEvent(span, pid, tid))
+		}
+		for tid := 1; tid <= threads; tid++ {
+			events = append(events, Event{
+				Name:  "thread_name",
+				Phase: PhaseMetadata,
+				PID:   pid,
+				TID:   tid,
+				Args:  map[string]string{"name": service},
+			})
+		}
+	}
+	return &Trace{TraceEvents: events, DisplayTimeUnit: "ms"}
+}
+
+func spanEvent(span *model.Span, pid, tid int) Event {
+	args := map[string]string{
+		"traceID": span.TraceID.String(),
+		"spanID":  span.SpanID.String(),
+	}
+	if parent := span.ParentSpanID(); parent != 0 {
+		args["parentSpanID"] = parent.String()
+	}
+	for i := range span.Tags {
+		args[span.Tags[i].Key] = span.Tags[i].AsString()
+	}
+	return Event{
+		Name:     span.OperationName,
+		Category: span.Process.GetServiceName(),
+		Phase:    PhaseComplete,
+		Time:     model.TimeAsEpochMicroseconds(span.StartTime),
+		Duration: model.DurationAsMicroseconds(span.Duration),
+		PID:      pid,
+		TID:      tid,
+		Args:     args,
+	}
+}
+
+// assignLanes sorts the spans by start time, longest first, and returns the lane of each
+// span: the first lane where the span nests within the innermost open span or opens alone.
+func assignLanes(spans []*model.Span) []int {
+	sort.SliceStable(spans, func(i, j int) bool {
+		if !spans[i].StartTime.Equal(spans[j].StartTime) {
+			return spans[i].StartTime.Before(spans[j].StartTime)
+		}
+		return spans[i].Duration > spans[j].Duration
+	})
+	var open [][]time.Time // end times of the spans open on each lane, innermost last
+	assigned := make([]int, len(spans))
+	for i, span := range spans {
+		end := span.StartTime.Add(span.Duration)
+		lane := 0
+		for ; lane < len(open); lane++ {
+			stack := open[lane]
+			for len(stack) > 0 && !stack[len(stack)-1].After(span.StartTime) {
+				stack = stack[:len(stack)-1]
+			}
+			open[lane] = stack
+			if len(stack) == 0 || !end.After(stack[len(stack)-1]) {
+				break
+			}
+		}
+		if lane == len(open) {
+			open = append(open, nil)
+		}
+		open[lane] = append(open[lane], end)
+		assigned[i] = lane
+	}
+	return assigned
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chrome
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func makeSpan(id uint64, parent uint64, service, operation string, start, duration time.Duration) *model.Span {
+	traceID := model.NewTraceID(0, 1)
+	span := &model.Span{
+		TraceID:       traceID,
+		SpanID:        model.NewSpanID(id),
+		OperationName: operation,
+		StartTime:     time.Unix(1000, 0).Add(start),
+		Duration:      duration,
+		Process:       &model.Process{ServiceName: service},
+	}
+	if parent != 0 {
+		span.References = []model.SpanRef{model.NewChildOfRef(traceID, model.NewSpanID(parent))}
+	}
+	return span
+}
+
+func TestFromDomain(t *testing.T) {
+	root := makeSpan(1, 0, "frontend", "GET", 0, 10*time.Millisecond)
+	root.Tags = []model.KeyValue{model.String("http.method", "GET"), model.Int64("http.status_code", 200)}
+	trace := &model.Trace{Spans: []*model.Span{
+		root,
+		makeSpan(2, 1, "db", "SELECT", time.Millisecond, 2*time.Millisecond),
+	}}
+	chrome := FromDomain(trace)
+	assert.Equal(t, "ms", chrome.DisplayTimeUnit)
+	assert.Equal(t, []Event{
+		{Name: "process_name", Phase: PhaseMetadata, PID: 1, Args: map[string]string{"name": "db"}},
+		{
+			Name:     "SELECT",
+			Category: "db",
+			Phase:    PhaseComplete,
+			Time:     1000001000,
+			Duration: 2000,
+			PID:      1,
+			TID:      1,
+			Args: map[string]string{
+				"traceID":      "1",
+				"spanID":       "2",
+				"parentSpanID": "1",
+			},
+		},
+		{Name: "thread_name", Phase: PhaseMetadata, PID: 1, TID: 1, Args: map[string]string{"name": "db"}},
+		{Name: "process_name", Phase: PhaseMetadata, PID: 2, Args: map[string]string{"name": "frontend"}},
+		{
+			Name:     "GET",
+			Category: "frontend",
+			Phase:    PhaseComplete,
+			Time:     1000000000,
+			Duration: 10000,
+			PID:      2,
+			TID:      1,
+			Args: map[string]string{
+				"traceID":          "1",
+				"spanID":           "1",
+				"http.method":      "GET",
+				"http.status_code": "200",
+			},
+		},
+		{Name: "thread_name", Phase: PhaseMetadata, PID: 2, TID: 1, Args: map[string]string{"name": "frontend"}},
+	}, chrome.TraceEvents)
+
+	out, err := json.Marshal(chrome)
+	require.NoError(t, err)
+	assert.Contains(t, string(out), `{"name":"SELECT","cat":"db","ph":"X","ts":1000001000,"dur":2000,"pid":1,"tid":1,`)
+}
+
+func TestFromDomainEmpty(t *testing.T) {
+	out, err := json.Marshal(FromDomain(&model.Trace{}))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"traceEvents":[],"displayTimeUnit":"ms"}`, string(out))
+}
+
+func TestAssignLanes(t *testing.T) {
+	spans := []*model.Span{
+		makeSpan(4, 1, "svc", "overlapping", 8*time.Millisecond, 6*time.Millisecond),
+		makeSpan(1, 0, "svc", "root", 0, 10*time.Millisecond),
+		makeSpan(2, 1, "svc", "nested", time.Millisecond, 3*time.Millisecond),
+		makeSpan(3, 1, "svc", "concurrent", 2*time.Millisecond, 3*time.Millisecond),
+		makeSpan(5, 1, "svc", "after", 11*time.Millisecond, time.Millisecond),
+	}
+	lanes := assignLanes(spans)
+	assigned := make(map[string]int)
+	for i, span := range spans {
+		assigned[span.OperationName] = lanes[i]
+	}
+	assert.Equal(t, map[string]int{
+		"root":        0,
+		"nested":      0,
+		"concurrent":  1,
+		"overlapping": 1,
+		"after":       0,
+	}, assigned)
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package folded converts traces into the folded stack format of flamegraph.pl,
+// also read by speedscope and other flame graph viewers.
+package folded
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/model/converter/pprof"
+)
+
+// frameReplacer escapes the frame separator and the line terminator out of frame names.
+var frameReplacer = strings.NewReplacer(";", ":", "\n", " ")
+
+// Write writes one line per service:operation stack of the spans of the traces, with the
+// frames from the root separated by semicolons, followed by the self-time of the spans in
+// microseconds, see pprof.FromDomain.
+func Write(w io.Writer, traces ...*model.Trace) error {
+	out := bufio.NewWriter(w)
+	for _, sample := range pprof.FromDomain(traces).Samples {
+		for i, frame := range sample.Stack {
+			if i > 0 {
+				out.WriteByte(';')
+			}
+			out.WriteString(frameReplacer.Replace(frame))
+		}
+		out.WriteByte(' ')
+		out.WriteString(strconv.FormatInt(int64(sample.SelfTime/time.Microsecond), 10))
+		out.WriteByte('\n')
+	}
+	return out.Flush()
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package folded
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestWrite(t *testing.T) {
+	start := time.Unix(1000, 0)
+	traceID := model.NewTraceID(0, 1)
+	trace := &model.Trace{
+		Spans: []*model.Span{
+			{
+				TraceID:       traceID,
+				SpanID:        model.NewSpanID(1),
+				OperationName: "GET",
+				StartTime:     start,
+				Duration:      10 * time.Millisecond,
+				Process:       &model.Process{ServiceName: "frontend"},
+			},
+			{
+				TraceID:       traceID,
+				SpanID:        model.NewSpanID(2),
+				OperationName: "SELECT a;b",
+				References:    []model.SpanRef{model.NewChildOfRef(traceID, model.NewSpanID(1))},
+				StartTime:     start.Add(time.Millisecond),
+				Duration:      2500 * time.Microsecond,
+				Process:       &model.Process{ServiceName: "db"},
+			},
+		},
+	}
+	var out bytes.Buffer
+	assert.NoError(t, Write(&out, trace))
+	assert.Equal(t, "frontend:GET 7500\nfrontend:GET;db:SELECT a:b 2500\n", out.String())
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestWriteFailure(t *testing.T) {
+	trace := &model.Trace{Spans: []*model.Span{{Process: &model.Process{ServiceName: "frontend"}}}}
+	assert.EqualError(t, Write(failingWriter{}, trace), "write failed")
+}
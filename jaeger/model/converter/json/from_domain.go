@@ -175,6 +175,39 @@ func (fd fromDomain) convertProcess(process *model.Process) json.Process {
 	}
 }
 
+// BreakdownFromDomain converts model.SpanBreakdown into json.Breakdown format.
+func BreakdownFromDomain(breakdown model.SpanBreakdown) *json.Breakdown {
+	gaps := make([]json.Interval, len(breakdown.Gaps))
+	for i, gap := range breakdown.Gaps {
+		gaps[i] = json.Interval{
+			StartTime: model.TimeAsEpochMicroseconds(gap.Start),
+			Duration:  model.DurationAsMicroseconds(gap.Duration()),
+		}
+	}
+	return &json.Breakdown{
+		SelfTime:  model.DurationAsMicroseconds(breakdown.SelfTime),
+		ChildTime: model.DurationAsMicroseconds(breakdown.ChildTime),
+		Gaps:      gaps,
+	}
+}
+
+// CriticalPathFromDomain converts []model.CriticalPathSegment into []json.CriticalPathSegment format.
+func CriticalPathFromDomain(path []model.CriticalPathSegment) []json.CriticalPathSegment {
+	retMe := make([]json.CriticalPathSegment, len(path))
+	for i, segment := range path {
+		retMe[i] = json.CriticalPathSegment{
+			SpanID:        json.SpanID(segment.Span.SpanID.String()),
+			ServiceName:   segment.Span.Process.ServiceName,
+			OperationName: segment.Span.OperationName,
+			Interval: json.Interval{
+				StartTime: model.TimeAsEpochMicroseconds(segment.Start),
+				Duration:  model.DurationAsMicroseconds(segment.Duration()),
+			},
+		}
+	}
+	return retMe
+}
+
 // DependenciesFromDomain converts []model.DependencyLink into []json.DependencyLink format.
 func DependenciesFromDomain(dependencyLinks []model.DependencyLink) []json.DependencyLink {
 	retMe := make([]json.DependencyLink, 0, len(dependencyLinks))
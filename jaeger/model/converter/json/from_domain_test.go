@@ -144,6 +144,48 @@ func testJSONEncoding(t *testing.T, i int, expectedStr []byte, object interface{
 	}
 }
 
+func TestBreakdownFromDomain(t *testing.T) {
+	start := time.Unix(100, 0)
+	breakdown := model.SpanBreakdown{
+		SelfTime:  3 * time.Millisecond,
+		ChildTime: 5 * time.Millisecond,
+		Gaps: []model.Interval{
+			{Start: start, End: start.Add(time.Millisecond)},
+			{Start: start.Add(6 * time.Millisecond), End: start.Add(8 * time.Millisecond)},
+		},
+	}
+	expected := &jModel.Breakdown{
+		SelfTime:  3000,
+		ChildTime: 5000,
+		Gaps: []jModel.Interval{
+			{StartTime: 100000000, Duration: 1000},
+			{StartTime: 100006000, Duration: 2000},
+		},
+	}
+	assert.Equal(t, expected, BreakdownFromDomain(breakdown))
+}
+
+func TestCriticalPathFromDomain(t *testing.T) {
+	start := time.Unix(100, 0)
+	span := &model.Span{
+		SpanID:        model.NewSpanID(0xab),
+		OperationName: "GET",
+		Process:       &model.Process{ServiceName: "frontend"},
+	}
+	path := []model.CriticalPathSegment{
+		{Span: span, Interval: model.Interval{Start: start, End: start.Add(time.Millisecond)}},
+	}
+	expected := []jModel.CriticalPathSegment{
+		{
+			SpanID:        "ab",
+			ServiceName:   "frontend",
+			OperationName: "GET",
+			Interval:      jModel.Interval{StartTime: 100000000, Duration: 1000},
+		},
+	}
+	assert.Equal(t, expected, CriticalPathFromDomain(path))
+}
+
 func TestDependenciesFromDomain(t *testing.T) {
 	someParent := "someParent"
 	someChild := "someChild"
@@ -0,0 +1,156 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package json
+
+import (
+	"time"
+
+	"github.com/jaegertracing/jaeger/model/json"
+	"github.com/jaegertracing/jaeger/model/latency"
+	"github.com/jaegertracing/jaeger/pkg/stats"
+)
+
+// TailProfileFromDomain converts latency.TailProfile into json.TailProfile format.
+func TailProfileFromDomain(profile *latency.TailProfile) *json.TailProfile {
+	retMe := &json.TailProfile{
+		Cutoff:     profile.Cutoff,
+		Traces:     latencyComparisonFromDomain(profile.Traces),
+		Operations: make([]json.OperationTailProfile, len(profile.Operations)),
+		Subspans:   make([]json.SubspanTailProfile, len(profile.Subspans)),
+	}
+	for i, operation := range profile.Operations {
+		retMe.Operations[i] = json.OperationTailProfile{
+			Service:           operation.Service,
+			Operation:         operation.Operation,
+			LatencyComparison: latencyComparisonFromDomain(operation.Comparison),
+		}
+	}
+	for i, subspan := range profile.Subspans {
+		retMe.Subspans[i] = json.SubspanTailProfile{
+			Path:              subspan.Path,
+			Index:             subspan.Index,
+			LatencyComparison: latencyComparisonFromDomain(subspan.Comparison),
+		}
+	}
+	return retMe
+}
+
+// ChildDiffProfilesFromDomain converts []latency.ChildDiffProfile into []json.ChildDiffProfile format.
+func ChildDiffProfilesFromDomain(profiles []latency.ChildDiffProfile) []json.ChildDiffProfile {
+	retMe := make([]json.ChildDiffProfile, len(profiles))
+	for i, profile := range profiles {
+		retMe[i] = json.ChildDiffProfile{
+			Path:       profile.Path,
+			ChildDiffs: make([]json.LatencyComparison, len(profile.ChildDiffs)),
+			EndDiff:    latencyComparisonFromDomain(profile.EndDiff),
+		}
+		for j, childDiff := range profile.ChildDiffs {
+			retMe[i].ChildDiffs[j] = latencyComparisonFromDomain(childDiff)
+		}
+	}
+	return retMe
+}
+
+// CriticalPathContributionsFromDomain converts []latency.CriticalPathContribution into
+// []json.CriticalPathContribution format.
+func CriticalPathContributionsFromDomain(contributions []latency.CriticalPathContribution) []json.CriticalPathContribution {
+	retMe := make([]json.CriticalPathContribution, len(contributions))
+	for i, c := range contributions {
+		retMe[i] = json.CriticalPathContribution{
+			Service:   c.Service,
+			Operation: c.Operation,
+			Total:     signedMicroseconds(c.Total),
+			PerTrace:  signedMicroseconds(c.PerTrace),
+			Share:     c.Share,
+			Traces:    c.Traces,
+		}
+	}
+	return retMe
+}
+
+// RegressionFromDomain converts latency.Regression into json.Regression format.
+func RegressionFromDomain(regression *latency.Regression) *json.Regression {
+	retMe := &json.Regression{
+		Significance: regression.Significance,
+		Traces:       latencyShiftFromDomain(regression.Traces),
+		Operations:   make([]json.OperationShift, len(regression.Operations)),
+		Structures:   make([]json.StructureShift, len(regression.Structures)),
+		Appeared:     structureCountsFromDomain(regression.Appeared),
+		Disappeared:  structureCountsFromDomain(regression.Disappeared),
+	}
+	for i, operation := range regression.Operations {
+		retMe.Operations[i] = json.OperationShift{
+			Service:      operation.Service,
+			Operation:    operation.Operation,
+			LatencyShift: latencyShiftFromDomain(operation.Shift),
+		}
+	}
+	for i, group := range regression.Structures {
+		retMe.Structures[i] = json.StructureShift{
+			Hash:         group.Hash,
+			Signature:    group.Signature,
+			LatencyShift: latencyShiftFromDomain(group.Shift),
+		}
+	}
+	return retMe
+}
+
+func latencyShiftFromDomain(shift latency.Shift) json.LatencyShift {
+	return json.LatencyShift{
+		Baseline: LatencyStatsFromDomain(shift.Baseline),
+		Current:  LatencyStatsFromDomain(shift.Current),
+		Diff:     LatencyStatsFromDomain(shift.Diff),
+		Effect:   shift.Test.Effect,
+		PValue:   shift.Test.PValue,
+	}
+}
+
+func structureCountsFromDomain(groups []latency.StructureCount) []json.StructureCount {
+	retMe := make([]json.StructureCount, len(groups))
+	for i, group := range groups {
+		retMe[i] = json.StructureCount{
+			Hash:      group.Hash,
+			Signature: group.Signature,
+			Traces:    group.Traces,
+		}
+	}
+	return retMe
+}
+
+func latencyComparisonFromDomain(comparison latency.Comparison) json.LatencyComparison {
+	return json.LatencyComparison{
+		Norm:         LatencyStatsFromDomain(comparison.Norm),
+		Tail:         LatencyStatsFromDomain(comparison.Tail),
+		Diff:         LatencyStatsFromDomain(comparison.Diff),
+		Contribution: signedMicroseconds(comparison.Contribution),
+	}
+}
+
+// LatencyStatsFromDomain converts stats.Summary into json.LatencyStats format.
+func LatencyStatsFromDomain(summary stats.Summary) json.LatencyStats {
+	return json.LatencyStats{
+		Count:  summary.Count,
+		Mean:   signedMicroseconds(summary.Mean),
+		StdDev: signedMicroseconds(summary.StdDev),
+		Min:    signedMicroseconds(summary.Min),
+		Max:    signedMicroseconds(summary.Max),
+		P50:    signedMicroseconds(summary.P50),
+		P99:    signedMicroseconds(summary.P99),
+	}
+}
+
+func signedMicroseconds(d time.Duration) int64 {
+	return int64(d / time.Microsecond)
+}
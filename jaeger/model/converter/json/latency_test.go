@@ -0,0 +1,133 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package json
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	jModel "github.com/jaegertracing/jaeger/model/json"
+	"github.com/jaegertracing/jaeger/model/latency"
+	"github.com/jaegertracing/jaeger/pkg/stats"
+)
+
+func TestTailProfileFromDomain(t *testing.T) {
+	comparison := latency.Comparison{
+		Norm:         stats.Summary{Count: 9, Mean: 2 * time.Millisecond, P50: 2 * time.Millisecond},
+		Tail:         stats.Summary{Count: 1, Mean: time.Millisecond, P50: time.Millisecond},
+		Diff:         stats.Summary{Count: -8, Mean: -time.Millisecond, P50: -time.Millisecond},
+		Contribution: -1500 * time.Microsecond,
+	}
+	profile := &latency.TailProfile{
+		Cutoff:     90,
+		Traces:     comparison,
+		Operations: []latency.OperationProfile{{Service: "db", Operation: "query", Comparison: comparison}},
+		Subspans:   []latency.SubspanProfile{{Path: "db:query#0", Index: 1, Comparison: comparison}},
+	}
+	expectedComparison := jModel.LatencyComparison{
+		Norm:         jModel.LatencyStats{Count: 9, Mean: 2000, P50: 2000},
+		Tail:         jModel.LatencyStats{Count: 1, Mean: 1000, P50: 1000},
+		Diff:         jModel.LatencyStats{Count: -8, Mean: -1000, P50: -1000},
+		Contribution: -1500,
+	}
+	expected := &jModel.TailProfile{
+		Cutoff: 90,
+		Traces: expectedComparison,
+		Operations: []jModel.OperationTailProfile{
+			{Service: "db", Operation: "query", LatencyComparison: expectedComparison},
+		},
+		Subspans: []jModel.SubspanTailProfile{
+			{Path: "db:query#0", Index: 1, LatencyComparison: expectedComparison},
+		},
+	}
+	assert.Equal(t, expected, TailProfileFromDomain(profile))
+}
+
+func TestChildDiffProfilesFromDomain(t *testing.T) {
+	comparison := latency.Comparison{
+		Norm:         stats.Summary{Count: 9, Mean: 2 * time.Millisecond},
+		Tail:         stats.Summary{Count: 1, Mean: 5 * time.Millisecond},
+		Diff:         stats.Summary{Count: -8, Mean: 3 * time.Millisecond},
+		Contribution: 3 * time.Millisecond,
+	}
+	expectedComparison := jModel.LatencyComparison{
+		Norm:         jModel.LatencyStats{Count: 9, Mean: 2000},
+		Tail:         jModel.LatencyStats{Count: 1, Mean: 5000},
+		Diff:         jModel.LatencyStats{Count: -8, Mean: 3000},
+		Contribution: 3000,
+	}
+	profiles := []latency.ChildDiffProfile{
+		{Path: "frontend:GET#0", ChildDiffs: []latency.Comparison{comparison, {}}, EndDiff: comparison},
+	}
+	expected := []jModel.ChildDiffProfile{
+		{
+			Path:       "frontend:GET#0",
+			ChildDiffs: []jModel.LatencyComparison{expectedComparison, {}},
+			EndDiff:    expectedComparison,
+		},
+	}
+	assert.Equal(t, expected, ChildDiffProfilesFromDomain(profiles))
+}
+
+func TestCriticalPathContributionsFromDomain(t *testing.T) {
+	contributions := []latency.CriticalPathContribution{
+		{
+			Service:   "db",
+			Operation: "query",
+			Total:     30 * time.Millisecond,
+			PerTrace:  10 * time.Millisecond,
+			Share:     0.25,
+			Traces:    2,
+		},
+	}
+	expected := []jModel.CriticalPathContribution{
+		{Service: "db", Operation: "query", Total: 30000, PerTrace: 10000, Share: 0.25, Traces: 2},
+	}
+	assert.Equal(t, expected, CriticalPathContributionsFromDomain(contributions))
+}
+
+func TestRegressionFromDomain(t *testing.T) {
+	shift := latency.Shift{
+		Baseline: stats.Summary{Count: 4, Mean: 2 * time.Millisecond},
+		Current:  stats.Summary{Count: 5, Mean: 3 * time.Millisecond},
+		Diff:     stats.Summary{Count: 1, Mean: time.Millisecond},
+		Test:     stats.RankTest{U: 18, PValue: 0.01, Effect: 0.8},
+	}
+	regression := &latency.Regression{
+		Significance: 0.05,
+		Traces:       shift,
+		Operations:   []latency.OperationShift{{Service: "db", Operation: "query", Shift: shift}},
+		Structures:   []latency.StructureShift{{Hash: "abc", Signature: "sig", Shift: shift}},
+		Appeared:     []latency.StructureCount{{Hash: "def", Signature: "new", Traces: 2}},
+	}
+	jShift := jModel.LatencyShift{
+		Baseline: jModel.LatencyStats{Count: 4, Mean: 2000},
+		Current:  jModel.LatencyStats{Count: 5, Mean: 3000},
+		Diff:     jModel.LatencyStats{Count: 1, Mean: 1000},
+		Effect:   0.8,
+		PValue:   0.01,
+	}
+	expected := &jModel.Regression{
+		Significance: 0.05,
+		Traces:       jShift,
+		Operations:   []jModel.OperationShift{{Service: "db", Operation: "query", LatencyShift: jShift}},
+		Structures:   []jModel.StructureShift{{Hash: "abc", Signature: "sig", LatencyShift: jShift}},
+		Appeared:     []jModel.StructureCount{{Hash: "def", Signature: "new", Traces: 2}},
+		Disappeared:  []jModel.StructureCount{},
+	}
+	assert.Equal(t, expected, RegressionFromDomain(regression))
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package json
+
+import (
+	"sort"
+	"time"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/model/json"
+	"github.com/jaegertracing/jaeger/model/structure"
+)
+
+// TraceDiffFromDomain converts structure.TraceDiff into json.TraceDiff format.
+func TraceDiffFromDomain(diff *structure.TraceDiff) *json.TraceDiff {
+	retMe := &json.TraceDiff{
+		DurationDelta: signedMicroseconds(diff.DurationDelta),
+		Missing:       diff.Missing,
+		Extra:         diff.Extra,
+		Spans:         make([]json.SpanDiff, len(diff.Spans)),
+	}
+	for i, span := range diff.Spans {
+		tags := make([]json.TagDiff, len(span.Tags))
+		for j, tag := range span.Tags {
+			tags[j] = json.TagDiff{
+				Key:      tag.Key,
+				Baseline: keyValueFromDomain(tag.Baseline),
+				Current:  keyValueFromDomain(tag.Current),
+			}
+		}
+		retMe.Spans[i] = json.SpanDiff{
+			Path:             span.Path,
+			Baseline:         diffedSpanFromDomain(span.Baseline, span.BaselineOffset),
+			Current:          diffedSpanFromDomain(span.Current, span.CurrentOffset),
+			DurationDelta:    signedMicroseconds(span.DurationDelta()),
+			StartOffsetDelta: signedMicroseconds(span.StartOffsetDelta()),
+			Tags:             tags,
+		}
+	}
+	return retMe
+}
+
+func diffedSpanFromDomain(span *model.Span, offset time.Duration) *json.DiffedSpan {
+	if span == nil {
+		return nil
+	}
+	return &json.DiffedSpan{
+		SpanID:        json.SpanID(span.SpanID.String()),
+		ServiceName:   span.Process.GetServiceName(),
+		OperationName: span.OperationName,
+		StartOffset:   signedMicroseconds(offset),
+		Duration:      model.DurationAsMicroseconds(span.Duration),
+	}
+}
+
+func keyValueFromDomain(kv *model.KeyValue) *json.KeyValue {
+	if kv == nil {
+		return nil
+	}
+	converted := fromDomain{}.convertKeyValues(model.KeyValues{*kv})[0]
+	return &converted
+}
+
+// StructureCountsFromIndex converts the trace counts by structural hash read from a structure
+// index into json.StructureCount format, largest counts first. The index does not keep the
+// signatures, so they are left empty.
+func StructureCountsFromIndex(counts map[string]int) []json.StructureCount {
+	retMe := make([]json.StructureCount, 0, len(counts))
+	for hash, traces := range counts {
+		retMe = append(retMe, json.StructureCount{
+			Hash:   hash,
+			Traces: traces,
+		})
+	}
+	sort.Slice(retMe, func(i, j int) bool {
+		if retMe[i].Traces != retMe[j].Traces {
+			return retMe[i].Traces > retMe[j].Traces
+		}
+		return retMe[i].Hash < retMe[j].Hash
+	})
+	return retMe
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package json
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jaegertracing/jaeger/model"
+	jModel "github.com/jaegertracing/jaeger/model/json"
+	"github.com/jaegertracing/jaeger/model/structure"
+)
+
+func TestTraceDiffFromDomain(t *testing.T) {
+	baseline := &model.Span{
+		SpanID:        model.NewSpanID(1),
+		OperationName: "GET",
+		Duration:      10 * time.Millisecond,
+		Process:       &model.Process{ServiceName: "frontend"},
+	}
+	current := &model.Span{
+		SpanID:        model.NewSpanID(2),
+		OperationName: "GET",
+		Duration:      8 * time.Millisecond,
+		Process:       &model.Process{ServiceName: "frontend"},
+	}
+	status := model.Int64("http.status_code", 500)
+	diff := &structure.TraceDiff{
+		DurationDelta: -2 * time.Millisecond,
+		Extra:         1,
+		Spans: []structure.SpanDiff{
+			{
+				Path:           "frontend:GET#0",
+				Baseline:       baseline,
+				Current:        current,
+				BaselineOffset: time.Millisecond,
+				Tags:           []structure.TagDiff{{Key: "http.status_code", Current: &status}},
+			},
+			{
+				Path:          "frontend:GET#0~frontend:GET#0",
+				Current:       current,
+				CurrentOffset: 3 * time.Millisecond,
+			},
+		},
+	}
+	expected := &jModel.TraceDiff{
+		DurationDelta: -2000,
+		Extra:         1,
+		Spans: []jModel.SpanDiff{
+			{
+				Path:             "frontend:GET#0",
+				Baseline:         &jModel.DiffedSpan{SpanID: "1", ServiceName: "frontend", OperationName: "GET", StartOffset: 1000, Duration: 10000},
+				Current:          &jModel.DiffedSpan{SpanID: "2", ServiceName: "frontend", OperationName: "GET", Duration: 8000},
+				DurationDelta:    -2000,
+				StartOffsetDelta: -1000,
+				Tags: []jModel.TagDiff{
+					{Key: "http.status_code", Current: &jModel.KeyValue{Key: "http.status_code", Type: jModel.Int64Type, Value: int64(500)}},
+				},
+			},
+			{
+				Path:    "frontend:GET#0~frontend:GET#0",
+				Current: &jModel.DiffedSpan{SpanID: "2", ServiceName: "frontend", OperationName: "GET", StartOffset: 3000, Duration: 8000},
+				Tags:    []jModel.TagDiff{},
+			},
+		},
+	}
+	assert.Equal(t, expected, TraceDiffFromDomain(diff))
+}
+
+func TestStructureCountsFromIndex(t *testing.T) {
+	counts := StructureCountsFromIndex(map[string]int{"b": 2, "a": 2, "c": 5})
+	assert.Equal(t, []jModel.StructureCount{
+		{Hash: "c", Traces: 5},
+		{Hash: "a", Traces: 2},
+		{Hash: "b", Traces: 2},
+	}, counts)
+}
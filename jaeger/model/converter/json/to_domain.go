@@ -0,0 +1,204 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package json
+
+import (
+	"encoding/base64"
+	gojson "encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/model/json"
+)
+
+// ToDomain converts json.Trace, e.g. as returned by the /api/traces endpoint, back into
+// model.Trace. The processes of the spans are either embedded in them or looked up in the
+// processes of the trace. Numeric values should be decoded with json.Decoder.UseNumber, so
+// that int64 values larger than 2^53 are not rounded.
+func ToDomain(trace *json.Trace) (*model.Trace, error) {
+	processes := make(map[json.ProcessID]*model.Process, len(trace.Processes))
+	for id, process := range trace.Processes {
+		p, err := convertProcessToDomain(process)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid process %s", id)
+		}
+		processes[id] = p
+	}
+	spans := make([]*model.Span, len(trace.Spans))
+	for i := range trace.Spans {
+		span, err := spanToDomain(&trace.Spans[i], processes)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid span %s", trace.Spans[i].SpanID)
+		}
+		spans[i] = span
+	}
+	return &model.Trace{Spans: spans, Warnings: trace.Warnings}, nil
+}
+
+func spanToDomain(span *json.Span, processes map[json.ProcessID]*model.Process) (*model.Span, error) {
+	traceID, err := model.TraceIDFromString(string(span.TraceID))
+	if err != nil {
+		return nil, err
+	}
+	spanID, err := model.SpanIDFromString(string(span.SpanID))
+	if err != nil {
+		return nil, err
+	}
+	refs, err := convertRefsToDomain(span.References)
+	if err != nil {
+		return nil, err
+	}
+	if span.ParentSpanID != "" {
+		parentSpanID, err := model.SpanIDFromString(string(span.ParentSpanID))
+		if err != nil {
+			return nil, err
+		}
+		refs = model.MaybeAddParentSpanID(traceID, parentSpanID, refs)
+	}
+	tags, err := convertKeyValuesToDomain(span.Tags)
+	if err != nil {
+		return nil, err
+	}
+	logs := make([]model.Log, len(span.Logs))
+	for i, log := range span.Logs {
+		fields, err := convertKeyValuesToDomain(log.Fields)
+		if err != nil {
+			return nil, err
+		}
+		logs[i] = model.Log{
+			Timestamp: model.EpochMicrosecondsAsTime(log.Timestamp),
+			Fields:    fields,
+		}
+	}
+	var process *model.Process
+	if span.Process != nil {
+		if process, err = convertProcessToDomain(*span.Process); err != nil {
+			return nil, err
+		}
+	} else if process = processes[span.ProcessID]; process == nil {
+		return nil, fmt.Errorf("unknown process %q", span.ProcessID)
+	}
+	return &model.Span{
+		TraceID:       traceID,
+		SpanID:        spanID,
+		OperationName: span.OperationName,
+		References:    refs,
+		Flags:         model.Flags(span.Flags),
+		StartTime:     model.EpochMicrosecondsAsTime(span.StartTime),
+		Duration:      model.MicrosecondsAsDuration(span.Duration),
+		Tags:          tags,
+		Logs:          logs,
+		Process:       process,
+		Warnings:      span.Warnings,
+	}, nil
+}
+
+func convertRefsToDomain(refs []json.Reference) ([]model.SpanRef, error) {
+	retMe := make([]model.SpanRef, len(refs))
+	for i, ref := range refs {
+		var refType model.SpanRefType
+		switch ref.RefType {
+		case json.ChildOf:
+			refType = model.ChildOf
+		case json.FollowsFrom:
+			refType = model.FollowsFrom
+		default:
+			return nil, fmt.Errorf("not a valid reference type %q", ref.RefType)
+		}
+		traceID, err := model.TraceIDFromString(string(ref.TraceID))
+		if err != nil {
+			return nil, err
+		}
+		spanID, err := model.SpanIDFromString(string(ref.SpanID))
+		if err != nil {
+			return nil, err
+		}
+		retMe[i] = model.SpanRef{RefType: refType, TraceID: traceID, SpanID: spanID}
+	}
+	return retMe, nil
+}
+
+func convertProcessToDomain(process json.Process) (*model.Process, error) {
+	tags, err := convertKeyValuesToDomain(process.Tags)
+	if err != nil {
+		return nil, err
+	}
+	return model.NewProcess(process.ServiceName, tags), nil
+}
+
+func convertKeyValuesToDomain(keyValues []json.KeyValue) (model.KeyValues, error) {
+	retMe := make(model.KeyValues, len(keyValues))
+	for i, kv := range keyValues {
+		converted, err := convertKeyValueToDomain(kv)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid value of %s", kv.Key)
+		}
+		retMe[i] = converted
+	}
+	return retMe, nil
+}
+
+// convertKeyValueToDomain accepts the values of FromDomain, as well as the string values of
+// FromDomainEmbedProcess except for binary values, which are expected in base64.
+func convertKeyValueToDomain(kv json.KeyValue) (model.KeyValue, error) {
+	switch kv.Type {
+	case json.StringType, "":
+		if value, ok := kv.Value.(string); ok {
+			return model.String(kv.Key, value), nil
+		}
+	case json.BoolType:
+		switch value := kv.Value.(type) {
+		case bool:
+			return model.Bool(kv.Key, value), nil
+		case string:
+			b, err := strconv.ParseBool(value)
+			return model.Bool(kv.Key, b), err
+		}
+	case json.Int64Type:
+		switch value := kv.Value.(type) {
+		case gojson.Number:
+			i, err := value.Int64()
+			return model.Int64(kv.Key, i), err
+		case float64:
+			return model.Int64(kv.Key, int64(value)), nil
+		case string:
+			i, err := strconv.ParseInt(value, 10, 64)
+			return model.Int64(kv.Key, i), err
+		}
+	case json.Float64Type:
+		switch value := kv.Value.(type) {
+		case gojson.Number:
+			f, err := value.Float64()
+			return model.Float64(kv.Key, f), err
+		case float64:
+			return model.Float64(kv.Key, value), nil
+		case string:
+			f, err := strconv.ParseFloat(value, 64)
+			return model.Float64(kv.Key, f), err
+		}
+	case json.BinaryType:
+		// encoding/json marshals the []byte values of FromDomain to base64
+		if value, ok := kv.Value.(string); ok {
+			b, err := base64.StdEncoding.DecodeString(value)
+			return model.Binary(kv.Key, b), err
+		}
+	default:
+		return model.KeyValue{}, fmt.Errorf("not a valid value type %q", kv.Type)
+	}
+	return model.KeyValue{}, fmt.Errorf("unexpected %T value for type %s", kv.Value, kv.Type)
+}
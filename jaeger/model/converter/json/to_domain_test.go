@@ -0,0 +1,168 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+	jModel "github.com/jaegertracing/jaeger/model/json"
+)
+
+func decodeUITrace(t *testing.T, data []byte) *jModel.Trace {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var trace jModel.Trace
+	require.NoError(t, decoder.Decode(&trace))
+	return &trace
+}
+
+func TestToDomain(t *testing.T) {
+	for i := 1; i <= NumberOfFixtures; i++ {
+		_, jsonStr := loadFixturesUI(t, i)
+
+		trace, err := ToDomain(decodeUITrace(t, jsonStr))
+		require.NoError(t, err)
+
+		testJSONEncoding(t, i, jsonStr, FromDomain(trace), false)
+	}
+}
+
+func TestToDomainRoundTrip(t *testing.T) {
+	process := model.NewProcess("service", model.KeyValues{model.String("hostname", "host")})
+	span := &model.Span{
+		TraceID:       model.NewTraceID(1, 2),
+		SpanID:        model.NewSpanID(3),
+		OperationName: "operation",
+		References:    []model.SpanRef{model.NewFollowsFromRef(model.NewTraceID(1, 2), model.NewSpanID(4))},
+		Flags:         model.Flags(1),
+		StartTime:     model.EpochMicrosecondsAsTime(1485467191639875),
+		Duration:      5 * time.Millisecond,
+		Tags: model.KeyValues{
+			model.String("string", "value"),
+			model.Bool("bool", true),
+			model.Int64("int64", math.MaxInt64),
+			model.Float64("float64", 0.5),
+			model.Binary("binary", []byte{0, 1, 2}),
+		},
+		Logs: []model.Log{{
+			Timestamp: model.EpochMicrosecondsAsTime(1485467191639880),
+			Fields:    model.KeyValues{model.String("event", "retry")},
+		}},
+		Process:  process,
+		Warnings: []string{"span warning"},
+	}
+	trace := &model.Trace{Spans: []*model.Span{span}, Warnings: []string{"trace warning"}}
+
+	data, err := json.Marshal(FromDomain(trace))
+	require.NoError(t, err)
+	actual, err := ToDomain(decodeUITrace(t, data))
+	require.NoError(t, err)
+	assert.Equal(t, trace, actual)
+
+	// the spans of FromDomainEmbedProcess embed their process and have string values, but no warnings
+	span.Tags = span.Tags[:4]
+	span.Warnings = nil
+	data, err = json.Marshal(&jModel.Trace{Spans: []jModel.Span{*FromDomainEmbedProcess(span)}})
+	require.NoError(t, err)
+	actual, err = ToDomain(decodeUITrace(t, data))
+	require.NoError(t, err)
+	assert.Equal(t, []*model.Span{span}, actual.Spans)
+}
+
+func TestToDomainErrors(t *testing.T) {
+	validSpan := func() jModel.Span {
+		return jModel.Span{
+			TraceID:   "1",
+			SpanID:    "2",
+			ProcessID: "p1",
+		}
+	}
+	processes := map[jModel.ProcessID]jModel.Process{"p1": {ServiceName: "service"}}
+	testCases := []struct {
+		name   string
+		modify func(span *jModel.Span)
+		err    string
+	}{
+		{
+			name:   "trace ID",
+			modify: func(span *jModel.Span) { span.TraceID = "x" },
+			err:    "invalid span 2: strconv.ParseUint: parsing \"x\": invalid syntax",
+		},
+		{
+			name:   "span ID",
+			modify: func(span *jModel.Span) { span.SpanID = "x" },
+			err:    "invalid span x: strconv.ParseUint: parsing \"x\": invalid syntax",
+		},
+		{
+			name:   "parent span ID",
+			modify: func(span *jModel.Span) { span.ParentSpanID = "x" },
+			err:    "invalid span 2: strconv.ParseUint: parsing \"x\": invalid syntax",
+		},
+		{
+			name: "reference type",
+			modify: func(span *jModel.Span) {
+				span.References = []jModel.Reference{{RefType: "PARENT", TraceID: "1", SpanID: "3"}}
+			},
+			err: "invalid span 2: not a valid reference type \"PARENT\"",
+		},
+		{
+			name:   "process",
+			modify: func(span *jModel.Span) { span.ProcessID = "p2" },
+			err:    "invalid span 2: unknown process \"p2\"",
+		},
+		{
+			name: "value type",
+			modify: func(span *jModel.Span) {
+				span.Tags = []jModel.KeyValue{{Key: "k", Type: "int32", Value: "1"}}
+			},
+			err: "invalid span 2: invalid value of k: not a valid value type \"int32\"",
+		},
+		{
+			name: "value",
+			modify: func(span *jModel.Span) {
+				span.Tags = []jModel.KeyValue{{Key: "k", Type: jModel.Int64Type, Value: true}}
+			},
+			err: "invalid span 2: invalid value of k: unexpected bool value for type int64",
+		},
+		{
+			name: "log field",
+			modify: func(span *jModel.Span) {
+				span.Logs = []jModel.Log{{Fields: []jModel.KeyValue{{Key: "k", Type: jModel.BoolType, Value: "maybe"}}}}
+			},
+			err: "invalid span 2: invalid value of k: strconv.ParseBool: parsing \"maybe\": invalid syntax",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			span := validSpan()
+			testCase.modify(&span)
+			_, err := ToDomain(&jModel.Trace{Spans: []jModel.Span{span}, Processes: processes})
+			assert.EqualError(t, err, testCase.err)
+		})
+	}
+
+	_, err := ToDomain(&jModel.Trace{Processes: map[jModel.ProcessID]jModel.Process{
+		"p1": {ServiceName: "service", Tags: []jModel.KeyValue{{Key: "k", Type: jModel.Float64Type, Value: "x"}}},
+	}})
+	assert.EqualError(t, err, "invalid process p1: invalid value of k: strconv.ParseFloat: parsing \"x\": invalid syntax")
+}
@@ -0,0 +1,174 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pprof
+
+import (
+	"github.com/gogo/protobuf/proto"
+)
+
+// Field numbers of https://github.com/google/pprof/blob/master/proto/profile.proto
+const (
+	profileSampleType        = 1
+	profileSample            = 2
+	profileLocation          = 4
+	profileFunction          = 5
+	profileStringTable       = 6
+	profileTimeNanos         = 9
+	profileDurationNanos     = 10
+	profilePeriodType        = 11
+	profilePeriod            = 12
+	profileDefaultSampleType = 14
+
+	valueTypeType = 1
+	valueTypeUnit = 2
+
+	sampleLocationID = 1
+	sampleValue      = 2
+
+	locationID   = 1
+	locationLine = 4
+
+	lineFunctionID = 1
+
+	functionID         = 1
+	functionName       = 2
+	functionSystemName = 3
+
+	wireVarint = 0
+	wireBytes  = 2
+)
+
+// encoder builds a profile.proto message, interning strings into its string table.
+type encoder struct {
+	buf     *proto.Buffer
+	strings map[string]int64
+	table   []string
+}
+
+func (p *Profile) encode() []byte {
+	e := &encoder{
+		buf:     proto.NewBuffer(nil),
+		strings: make(map[string]int64),
+	}
+	e.intern("")
+	spans, selfTime := e.intern("spans"), e.intern("self_time")
+	count, nanoseconds := e.intern("count"), e.intern("nanoseconds")
+
+	e.message(profileSampleType, func(m *encoder) {
+		m.int(valueTypeType, spans)
+		m.int(valueTypeUnit, count)
+	})
+	e.message(profileSampleType, func(m *encoder) {
+		m.int(valueTypeType, selfTime)
+		m.int(valueTypeUnit, nanoseconds)
+	})
+
+	// every service:operation is both a function and the single location calling it
+	functions := make(map[string]uint64)
+	var names []string
+	for _, sample := range p.Samples {
+		locations := make([]uint64, len(sample.Stack))
+		for i, name := range sample.Stack {
+			id, ok := functions[name]
+			if !ok {
+				id = uint64(len(functions) + 1)
+				functions[name] = id
+				names = append(names, name)
+			}
+			// pprof stacks start with the leaf
+			locations[len(sample.Stack)-1-i] = id
+		}
+		e.message(profileSample, func(m *encoder) {
+			m.packed(sampleLocationID, locations...)
+			m.packed(sampleValue, uint64(sample.Spans), uint64(sample.SelfTime.Nanoseconds()))
+		})
+	}
+	for i, name := range names {
+		id := uint64(i + 1)
+		e.message(profileLocation, func(m *encoder) {
+			m.uint(locationID, id)
+			m.message(locationLine, func(l *encoder) {
+				l.uint(lineFunctionID, id)
+			})
+		})
+		nameIndex := e.intern(name)
+		e.message(profileFunction, func(m *encoder) {
+			m.uint(functionID, id)
+			m.int(functionName, nameIndex)
+			m.int(functionSystemName, nameIndex)
+		})
+	}
+
+	if !p.Start.IsZero() {
+		e.int(profileTimeNanos, p.Start.UnixNano())
+	}
+	e.int(profileDurationNanos, p.Duration.Nanoseconds())
+	e.message(profilePeriodType, func(m *encoder) {
+		m.int(valueTypeType, selfTime)
+		m.int(valueTypeUnit, nanoseconds)
+	})
+	e.int(profilePeriod, 1)
+	e.int(profileDefaultSampleType, selfTime)
+	// the string table is written last, once every string is interned
+	for _, s := range e.table {
+		e.key(profileStringTable, wireBytes)
+		e.buf.EncodeStringBytes(s)
+	}
+	return e.buf.Bytes()
+}
+
+func (e *encoder) intern(s string) int64 {
+	if index, ok := e.strings[s]; ok {
+		return index
+	}
+	index := int64(len(e.table))
+	e.strings[s] = index
+	e.table = append(e.table, s)
+	return index
+}
+
+func (e *encoder) key(field int, wire uint64) {
+	e.buf.EncodeVarint(uint64(field)<<3 | wire)
+}
+
+func (e *encoder) uint(field int, value uint64) {
+	if value == 0 {
+		return
+	}
+	e.key(field, wireVarint)
+	e.buf.EncodeVarint(value)
+}
+
+func (e *encoder) int(field int, value int64) {
+	e.uint(field, uint64(value))
+}
+
+func (e *encoder) packed(field int, values ...uint64) {
+	packed := proto.NewBuffer(nil)
+	for _, value := range values {
+		packed.EncodeVarint(value)
+	}
+	e.key(field, wireBytes)
+	e.buf.EncodeRawBytes(packed.Bytes())
+}
+
+// message writes the embedded message built by build, sharing the string table of e.
+func (e *encoder) message(field int, build func(m *encoder)) {
+	m := &encoder{buf: proto.NewBuffer(nil), strings: e.strings, table: e.table}
+	build(m)
+	e.table = m.table
+	e.key(field, wireBytes)
+	e.buf.EncodeRawBytes(m.buf.Bytes())
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pprof
+
+import (
+	"compress/gzip"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// Sample is the self-time of the spans sharing the same stack.
+type Sample struct {
+	// Stack lists the service:operation of the spans from the root to the sampled spans.
+	Stack []string
+	// Spans is the number of sampled spans.
+	Spans int64
+	// SelfTime is the time the sampled spans spent outside of their children, see model.SpanNode.Breakdown.
+	SelfTime time.Duration
+}
+
+// Profile is the self-time of the spans of a set of traces, aggregated by stack.
+type Profile struct {
+	// Start is the start time of the earliest trace.
+	Start time.Time
+	// Duration is the time from Start to the end of the latest trace.
+	Duration time.Duration
+	// Samples are ordered by stack.
+	Samples []Sample
+}
+
+// FromDomain aggregates the self-time of the spans of the traces by stack.
+func FromDomain(traces []*model.Trace) *Profile {
+	profile := &Profile{}
+	var end time.Time
+	samples := make(map[string]*Sample)
+	for _, trace := range traces {
+		model.NewTraceTree(trace).Walk(func(n *model.SpanNode) {
+			span := n.Span
+			if profile.Start.IsZero() || span.StartTime.Before(profile.Start) {
+				profile.Start = span.StartTime
+			}
+			if spanEnd := span.StartTime.Add(span.Duration); spanEnd.After(end) {
+				end = spanEnd
+			}
+			stack := stackOf(n)
+			key := strings.Join(stack, "\n")
+			sample, ok := samples[key]
+			if !ok {
+				sample = &Sample{Stack: stack}
+				samples[key] = sample
+			}
+			sample.Spans++
+			sample.SelfTime += n.Breakdown().SelfTime
+		})
+	}
+	if !profile.Start.IsZero() {
+		profile.Duration = end.Sub(profile.Start)
+	}
+	keys := make([]string, 0, len(samples))
+	for key := range samples {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	profile.Samples = make([]Sample, len(keys))
+	for i, key := range keys {
+		profile.Samples[i] = *samples[key]
+	}
+	return profile
+}
+
+func stackOf(n *model.SpanNode) []string {
+	var stack []string
+	for ; n != nil; n = n.Parent {
+		stack = append(stack, n.Span.Process.ServiceName+":"+n.Span.OperationName)
+	}
+	for i, j := 0, len(stack)-1; i < j; i, j = i+1, j-1 {
+		stack[i], stack[j] = stack[j], stack[i]
+	}
+	return stack
+}
+
+// Write writes the profile in the gzipped profile.proto format read by `go tool pprof`.
+// Every sample has two values, the number of spans and their self-time in nanoseconds,
+// the latter being the default.
+func (p *Profile) Write(w io.Writer) error {
+	gz := gzip.NewWriter(w)
+	if _, err := gz.Write(p.encode()); err != nil {
+		return err
+	}
+	return gz.Close()
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pprof
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+var profileEpoch = time.Unix(1000, 0)
+
+// newTrace returns a frontend span of 10ms calling the db for query, 2ms after it starts.
+func newTrace(id uint64, query time.Duration) *model.Trace {
+	traceID := model.NewTraceID(0, id)
+	return &model.Trace{
+		Spans: []*model.Span{
+			{
+				TraceID:       traceID,
+				SpanID:        model.NewSpanID(1),
+				OperationName: "GET",
+				StartTime:     profileEpoch,
+				Duration:      10 * time.Millisecond,
+				Process:       &model.Process{ServiceName: "frontend"},
+			},
+			{
+				TraceID:       traceID,
+				SpanID:        model.NewSpanID(2),
+				OperationName: "query",
+				References:    []model.SpanRef{model.NewChildOfRef(traceID, model.NewSpanID(1))},
+				StartTime:     profileEpoch.Add(2 * time.Millisecond),
+				Duration:      query,
+				Process:       &model.Process{ServiceName: "db"},
+			},
+		},
+	}
+}
+
+func TestFromDomain(t *testing.T) {
+	profile := FromDomain([]*model.Trace{newTrace(1, time.Millisecond), newTrace(2, 3*time.Millisecond)})
+	assert.Equal(t, profileEpoch, profile.Start)
+	assert.Equal(t, 10*time.Millisecond, profile.Duration)
+	assert.Equal(t, []Sample{
+		{Stack: []string{"frontend:GET"}, Spans: 2, SelfTime: 16 * time.Millisecond},
+		{Stack: []string{"frontend:GET", "db:query"}, Spans: 2, SelfTime: 4 * time.Millisecond},
+	}, profile.Samples)
+
+	empty := FromDomain(nil)
+	assert.True(t, empty.Start.IsZero())
+	assert.Empty(t, empty.Samples)
+}
+
+// decodeFields returns the raw values of the fields of a protobuf message, by field number.
+func decodeFields(t *testing.T, message []byte) map[uint64][][]byte {
+	fields := make(map[uint64][][]byte)
+	buf := proto.NewBuffer(message)
+	for {
+		key, err := buf.DecodeVarint()
+		if err != nil {
+			return fields
+		}
+		var value []byte
+		switch key & 7 {
+		case 0:
+			v, err := buf.DecodeVarint()
+			require.NoError(t, err)
+			value = proto.EncodeVarint(v)
+		case 2:
+			value, err = buf.DecodeRawBytes(true)
+			require.NoError(t, err)
+		default:
+			t.Fatalf("unexpected wire type %d", key&7)
+		}
+		fields[key>>3] = append(fields[key>>3], value)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	profile := FromDomain([]*model.Trace{newTrace(1, time.Millisecond)})
+	var out bytes.Buffer
+	require.NoError(t, profile.Write(&out))
+
+	gz, err := gzip.NewReader(&out)
+	require.NoError(t, err)
+	message, err := ioutil.ReadAll(gz)
+	require.NoError(t, err)
+
+	fields := decodeFields(t, message)
+	var stringTable []string
+	for _, s := range fields[profileStringTable] {
+		stringTable = append(stringTable, string(s))
+	}
+	require.NotEmpty(t, stringTable)
+	assert.Equal(t, "", stringTable[0])
+	assert.Contains(t, stringTable, "self_time")
+	assert.Contains(t, stringTable, "frontend:GET")
+	assert.Contains(t, stringTable, "db:query")
+	assert.Len(t, fields[profileSampleType], 2)
+	assert.Len(t, fields[profileSample], 2)
+	assert.Len(t, fields[profileLocation], 2)
+	assert.Len(t, fields[profileFunction], 2)
+
+	// the db:query sample is the second one, with the db:query leaf first
+	sample := decodeFields(t, fields[profileSample][1])
+	assert.Equal(t, []byte{2, 1}, sample[sampleLocationID][0])
+	values := proto.NewBuffer(sample[sampleValue][0])
+	spans, _ := values.DecodeVarint()
+	selfTime, _ := values.DecodeVarint()
+	assert.Equal(t, uint64(1), spans)
+	assert.Equal(t, uint64(time.Millisecond), selfTime)
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"sort"
+	"time"
+)
+
+// CriticalPathSegment is an interval of the critical path spent in a span itself,
+// rather than waiting for one of its children.
+type CriticalPathSegment struct {
+	Span *Span
+	Interval
+}
+
+// CriticalPath returns the critical path of the trace in time order: the chain of self-time
+// intervals that determines its end-to-end latency.
+//
+// The path is built backwards from the end of the root finishing last. Within a span, it
+// follows the child finishing last, then the child finishing last before that one starts,
+// and so on, the time between them being spent in the span itself. Children running in
+// parallel with a child on the path are therefore left out. A parent waits for its child-of
+// children, whose time outside of the parent is ignored. It does not wait for its
+// follows-from children, which are only on the path when they finish after the parent,
+// extending the path beyond its end.
+func (t *TraceTree) CriticalPath() []CriticalPathSegment {
+	var last *SpanNode
+	for _, root := range t.Roots {
+		if last == nil || root.finish().After(last.finish()) {
+			last = root
+		}
+	}
+	if last == nil {
+		return nil
+	}
+	var path []CriticalPathSegment
+	last.criticalPath(last.finish(), &path)
+	// the path was built backwards
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+// end returns the end time of the span.
+func (n *SpanNode) end() time.Time {
+	return n.Span.StartTime.Add(n.Span.Duration)
+}
+
+// followsFrom tells whether the span is a follows-from child of its parent.
+func (n *SpanNode) followsFrom() bool {
+	return n.Parent != nil && n.Span.ParentSpanID() != n.Parent.Span.SpanID
+}
+
+// finish returns the time when the span and all its follows-from descendants are done.
+func (n *SpanNode) finish() time.Time {
+	finish := n.end()
+	for _, child := range n.Children {
+		if child.followsFrom() {
+			if f := child.finish(); f.After(finish) {
+				finish = f
+			}
+		}
+	}
+	return finish
+}
+
+// criticalPath appends the critical path of the span up to the given time, backwards.
+func (n *SpanNode) criticalPath(until time.Time, path *[]CriticalPathSegment) {
+	type candidate struct {
+		node   *SpanNode
+		finish time.Time
+	}
+	var candidates []candidate
+	for _, child := range n.Children {
+		if child.followsFrom() {
+			if f := child.finish(); f.After(n.end()) {
+				candidates = append(candidates, candidate{node: child, finish: f})
+			}
+		} else {
+			candidates = append(candidates, candidate{node: child, finish: child.finish()})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].finish.After(candidates[j].finish)
+	})
+
+	cursor := until
+	self := func(start time.Time) {
+		if start.Before(cursor) {
+			*path = append(*path, CriticalPathSegment{Span: n.Span, Interval: Interval{Start: start, End: cursor}})
+			cursor = start
+		}
+	}
+	for _, c := range candidates {
+		start := c.node.Span.StartTime
+		if !start.Before(cursor) || start.Before(n.Span.StartTime) {
+			// starts after the cursor, or before the span itself
+			continue
+		}
+		childUntil := c.finish
+		if childUntil.After(cursor) {
+			childUntil = cursor
+		}
+		self(childUntil)
+		c.node.criticalPath(childUntil, path)
+		cursor = start
+	}
+	self(n.Span.StartTime)
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func followsFrom(span *model.Span) *model.Span {
+	span.References[0].RefType = model.SpanRefType_FOLLOWS_FROM
+	return span
+}
+
+type pathSegment struct {
+	span       uint64
+	start, end int
+}
+
+func criticalPath(trace *model.Trace) []pathSegment {
+	var segments []pathSegment
+	for _, s := range model.NewTraceTree(trace).CriticalPath() {
+		segments = append(segments, pathSegment{
+			span:  uint64(s.Span.SpanID),
+			start: int(s.Start.Sub(treeEpoch).Milliseconds()),
+			end:   int(s.End.Sub(treeEpoch).Milliseconds()),
+		})
+	}
+	return segments
+}
+
+func TestCriticalPath(t *testing.T) {
+	testCases := []struct {
+		name     string
+		spans    []*model.Span
+		expected []pathSegment
+	}{
+		{
+			name: "empty trace",
+		},
+		{
+			name:     "single span",
+			spans:    []*model.Span{makeTreeSpan(1, 0, 0, 100)},
+			expected: []pathSegment{{1, 0, 100}},
+		},
+		{
+			name: "sequential children",
+			spans: []*model.Span{
+				makeTreeSpan(1, 0, 0, 100),
+				makeTreeSpan(2, 1, 10, 20),
+				makeTreeSpan(3, 1, 50, 30),
+			},
+			expected: []pathSegment{{1, 0, 10}, {2, 10, 30}, {1, 30, 50}, {3, 50, 80}, {1, 80, 100}},
+		},
+		{
+			name: "parallel children",
+			spans: []*model.Span{
+				makeTreeSpan(1, 0, 0, 100),
+				makeTreeSpan(2, 1, 10, 60),
+				makeTreeSpan(3, 1, 20, 30), // hidden by the longer call
+				makeTreeSpan(4, 1, 50, 40), // overlaps the end of the first call
+			},
+			expected: []pathSegment{{1, 0, 10}, {2, 10, 50}, {4, 50, 90}, {1, 90, 100}},
+		},
+		{
+			name: "nested children",
+			spans: []*model.Span{
+				makeTreeSpan(1, 0, 0, 100),
+				makeTreeSpan(2, 1, 10, 80),
+				makeTreeSpan(3, 2, 20, 30),
+			},
+			expected: []pathSegment{{1, 0, 10}, {2, 10, 20}, {3, 20, 50}, {2, 50, 90}, {1, 90, 100}},
+		},
+		{
+			name: "child-of child outliving its parent",
+			spans: []*model.Span{
+				makeTreeSpan(1, 0, 0, 100),
+				makeTreeSpan(2, 1, 80, 50),
+			},
+			expected: []pathSegment{{1, 0, 80}, {2, 80, 100}},
+		},
+		{
+			name: "follows-from child within its parent",
+			spans: []*model.Span{
+				makeTreeSpan(1, 0, 0, 100),
+				followsFrom(makeTreeSpan(2, 1, 10, 20)),
+			},
+			expected: []pathSegment{{1, 0, 100}},
+		},
+		{
+			name: "follows-from child finishing after its parent",
+			spans: []*model.Span{
+				makeTreeSpan(1, 0, 0, 100),
+				followsFrom(makeTreeSpan(2, 1, 90, 50)),
+				makeTreeSpan(3, 2, 100, 20),
+			},
+			expected: []pathSegment{{1, 0, 90}, {2, 90, 100}, {3, 100, 120}, {2, 120, 140}},
+		},
+		{
+			name: "child starting before its parent",
+			spans: []*model.Span{
+				makeTreeSpan(1, 0, 10, 90),
+				makeTreeSpan(2, 1, 0, 50),
+			},
+			expected: []pathSegment{{1, 10, 100}},
+		},
+		{
+			name: "root finishing last",
+			spans: []*model.Span{
+				makeTreeSpan(1, 0, 0, 100),
+				makeTreeSpan(2, 9, 50, 100), // parent missing from the trace
+			},
+			expected: []pathSegment{{2, 50, 150}},
+		},
+	}
+	for _, tc := range testCases {
+		testCase := tc // capture loop var
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, criticalPath(&model.Trace{Spans: testCase.spans}))
+		})
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// IntegrityStatus is the set of integrity problems found in a trace.
+type IntegrityStatus uint8
+
+const (
+	// IntegrityComplete is the status of a trace without integrity problems.
+	IntegrityComplete IntegrityStatus = 0
+	// IntegrityDroppedSpan means that a span references another span of the trace that is missing.
+	IntegrityDroppedSpan IntegrityStatus = 1 << 0
+	// IntegrityOrphaned means that the trace does not have exactly one root span.
+	IntegrityOrphaned IntegrityStatus = 1 << 1
+	// IntegrityMultiParent means that a span references several spans of the trace.
+	IntegrityMultiParent IntegrityStatus = 1 << 2
+	// IntegrityCrossTraceReference means that a span references a span of another trace.
+	IntegrityCrossTraceReference IntegrityStatus = 1 << 3
+)
+
+var integrityNames = []struct {
+	status IntegrityStatus
+	name   string
+}{
+	{IntegrityDroppedSpan, "dropped-span"},
+	{IntegrityOrphaned, "orphaned"},
+	{IntegrityMultiParent, "multi-parent"},
+	{IntegrityCrossTraceReference, "cross-trace-reference"},
+}
+
+// String returns "complete", or the comma separated names of the problems in the status.
+func (s IntegrityStatus) String() string {
+	if s == IntegrityComplete {
+		return "complete"
+	}
+	var names []string
+	for _, n := range integrityNames {
+		if s&n.status != 0 {
+			names = append(names, n.name)
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+// Matches returns true if the status is complete and other is complete,
+// or if the status shares a problem with other.
+func (s IntegrityStatus) Matches(other IntegrityStatus) bool {
+	if other == IntegrityComplete {
+		return s == IntegrityComplete
+	}
+	return s&other != 0
+}
+
+// IntegrityStatusFromString parses a status in the format produced by IntegrityStatus.String.
+func IntegrityStatusFromString(s string) (IntegrityStatus, error) {
+	if s == "complete" {
+		return IntegrityComplete, nil
+	}
+	var status IntegrityStatus
+	for _, name := range strings.Split(s, ",") {
+		found := false
+		for _, n := range integrityNames {
+			if n.name == name {
+				status |= n.status
+				found = true
+			}
+		}
+		if !found {
+			return IntegrityComplete, fmt.Errorf("unknown trace integrity status: %q", name)
+		}
+	}
+	return status, nil
+}
@@ -0,0 +1,54 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestIntegrityStatusString(t *testing.T) {
+	testCases := []struct {
+		status model.IntegrityStatus
+		str    string
+	}{
+		{status: model.IntegrityComplete, str: "complete"},
+		{status: model.IntegrityDroppedSpan, str: "dropped-span"},
+		{status: model.IntegrityOrphaned | model.IntegrityCrossTraceReference, str: "orphaned,cross-trace-reference"},
+		{status: model.IntegrityMultiParent, str: "multi-parent"},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.str, tc.status.String())
+		status, err := model.IntegrityStatusFromString(tc.str)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.status, status)
+	}
+
+	_, err := model.IntegrityStatusFromString("orphaned,broken")
+	assert.EqualError(t, err, `unknown trace integrity status: "broken"`)
+}
+
+func TestIntegrityStatusMatches(t *testing.T) {
+	status := model.IntegrityDroppedSpan | model.IntegrityOrphaned
+	assert.True(t, status.Matches(model.IntegrityOrphaned))
+	assert.True(t, status.Matches(model.IntegrityOrphaned|model.IntegrityMultiParent))
+	assert.False(t, status.Matches(model.IntegrityMultiParent))
+	assert.False(t, status.Matches(model.IntegrityComplete))
+	assert.True(t, model.IntegrityComplete.Matches(model.IntegrityComplete))
+	assert.False(t, model.IntegrityComplete.Matches(model.IntegrityOrphaned))
+}
@@ -75,6 +75,28 @@ type Span struct {
 	ProcessID     ProcessID   `json:"processID,omitempty"`
 	Process       *Process    `json:"process,omitempty"`
 	Warnings      []string    `json:"warnings"`
+	Breakdown     *Breakdown  `json:"breakdown,omitempty"`
+}
+
+// Breakdown splits the duration of a span between its own work and the work of its children
+type Breakdown struct {
+	SelfTime  uint64     `json:"selfTime"`  // microseconds
+	ChildTime uint64     `json:"childTime"` // microseconds
+	Gaps      []Interval `json:"gaps"`
+}
+
+// Interval is a time interval within a span
+type Interval struct {
+	StartTime uint64 `json:"startTime"` // microseconds since Unix epoch
+	Duration  uint64 `json:"duration"`  // microseconds
+}
+
+// CriticalPathSegment is an interval of the critical path of a trace spent in a span
+type CriticalPathSegment struct {
+	SpanID        SpanID `json:"spanID"`
+	ServiceName   string `json:"serviceName"`
+	OperationName string `json:"operationName"`
+	Interval
 }
 
 // Reference is a reference from one span to another
@@ -109,3 +131,143 @@ type DependencyLink struct {
 	Child     string `json:"child"`
 	CallCount uint64 `json:"callCount"`
 }
+
+// LatencyStats describes a distribution of durations in microseconds. Values are signed
+// so that the difference between two distributions can be represented.
+type LatencyStats struct {
+	Count  int   `json:"count"`
+	Mean   int64 `json:"mean"`
+	StdDev int64 `json:"stdDev"`
+	Min    int64 `json:"min"`
+	Max    int64 `json:"max"`
+	P50    int64 `json:"p50"`
+	P99    int64 `json:"p99"`
+}
+
+// RequestTypeGroup is a set of traces of the same request type
+type RequestTypeGroup struct {
+	RequestType string       `json:"requestType"`
+	TraceIDs    []TraceID    `json:"traceIDs"`
+	Latency     LatencyStats `json:"latency"`
+}
+
+// LatencyComparison compares a latency between the norm and the tail traces
+type LatencyComparison struct {
+	Norm         LatencyStats `json:"norm"`
+	Tail         LatencyStats `json:"tail"`
+	Diff         LatencyStats `json:"diff"`
+	Contribution int64        `json:"contribution"` // microseconds
+}
+
+// OperationTailProfile compares the duration of the spans of an operation
+type OperationTailProfile struct {
+	Service   string `json:"service"`
+	Operation string `json:"operation"`
+	LatencyComparison
+}
+
+// SubspanTailProfile compares the duration of a gap between the children of a span
+type SubspanTailProfile struct {
+	Path  string `json:"path"`
+	Index int    `json:"index"`
+	LatencyComparison
+}
+
+// TailProfile compares the norm and the tail traces of a set
+type TailProfile struct {
+	Cutoff     float64                `json:"cutoff"`
+	Traces     LatencyComparison      `json:"traces"`
+	Operations []OperationTailProfile `json:"operations"`
+	Subspans   []SubspanTailProfile   `json:"subspans"`
+}
+
+// ChildDiffProfile compares the offsets between the children of the spans at a structural path
+type ChildDiffProfile struct {
+	Path       string              `json:"path"`
+	ChildDiffs []LatencyComparison `json:"childDiffs"`
+	EndDiff    LatencyComparison   `json:"endDiff"`
+}
+
+// CriticalPathContribution is the time the spans of an operation spend on the critical paths of a set of traces
+type CriticalPathContribution struct {
+	Service   string  `json:"service"`
+	Operation string  `json:"operation"`
+	Total     int64   `json:"total"`    // microseconds
+	PerTrace  int64   `json:"perTrace"` // microseconds
+	Share     float64 `json:"share"`
+	Traces    int     `json:"traces"`
+}
+
+// LatencyShift compares a latency between the baseline and the current traces
+type LatencyShift struct {
+	Baseline LatencyStats `json:"baseline"`
+	Current  LatencyStats `json:"current"`
+	Diff     LatencyStats `json:"diff"`
+	Effect   float64      `json:"effect"`
+	PValue   float64      `json:"pValue"`
+}
+
+// OperationShift is the latency shift of the spans of an operation
+type OperationShift struct {
+	Service   string `json:"service"`
+	Operation string `json:"operation"`
+	LatencyShift
+}
+
+// StructureShift is the latency shift of the traces of a structural group
+type StructureShift struct {
+	Hash      string `json:"hash"`
+	Signature string `json:"signature"`
+	LatencyShift
+}
+
+// StructureCount is a structural group with its number of traces
+type StructureCount struct {
+	Hash      string `json:"hash"`
+	Signature string `json:"signature,omitempty"`
+	Traces    int    `json:"traces"`
+}
+
+// Regression compares the current traces against a baseline
+type Regression struct {
+	Significance float64          `json:"significance"`
+	Traces       LatencyShift     `json:"traces"`
+	Operations   []OperationShift `json:"operations"`
+	Structures   []StructureShift `json:"structures"`
+	Appeared     []StructureCount `json:"appeared"`
+	Disappeared  []StructureCount `json:"disappeared"`
+}
+
+// DiffedSpan is the span found at a structural path of one of the two compared traces
+type DiffedSpan struct {
+	SpanID        SpanID `json:"spanID"`
+	ServiceName   string `json:"serviceName"`
+	OperationName string `json:"operationName"`
+	StartOffset   int64  `json:"startOffset"` // microseconds from the start of the parent span
+	Duration      uint64 `json:"duration"`    // microseconds
+}
+
+// TagDiff is a span tag whose value differs between the baseline and the current span
+type TagDiff struct {
+	Key      string    `json:"key"`
+	Baseline *KeyValue `json:"baseline"`
+	Current  *KeyValue `json:"current"`
+}
+
+// SpanDiff compares the spans found at the same structural path in two traces
+type SpanDiff struct {
+	Path             string      `json:"path"`
+	Baseline         *DiffedSpan `json:"baseline"`
+	Current          *DiffedSpan `json:"current"`
+	DurationDelta    int64       `json:"durationDelta"`    // microseconds
+	StartOffsetDelta int64       `json:"startOffsetDelta"` // microseconds
+	Tags             []TagDiff   `json:"tags"`
+}
+
+// TraceDiff compares a trace against a baseline trace span by span
+type TraceDiff struct {
+	DurationDelta int64      `json:"durationDelta"` // microseconds
+	Missing       int        `json:"missing"`
+	Extra         int        `json:"extra"`
+	Spans         []SpanDiff `json:"spans"`
+}
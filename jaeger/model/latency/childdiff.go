@@ -0,0 +1,107 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package latency
+
+import (
+	"sort"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/model/structure"
+)
+
+// ChildDiffProfile compares the child offsets of the spans found at Path, see model.ChildOffsets.
+// It reveals the time spent between calls, such as serialization or queueing delays.
+type ChildDiffProfile struct {
+	Path string
+	// ChildDiffs compares, for each child in start time order, the time elapsed since
+	// the start of the previous child, or since the start of the span for the first child.
+	ChildDiffs []Comparison
+	// EndDiff compares the time between the end of the last child and the end of the span.
+	EndDiff Comparison
+}
+
+// MaxContribution returns the largest contribution to the tail among the offsets of the profile.
+func (p ChildDiffProfile) MaxContribution() Comparison {
+	max := p.EndDiff
+	for _, c := range p.ChildDiffs {
+		if c.Contribution > max.Contribution {
+			max = c
+		}
+	}
+	return max
+}
+
+// NewChildDiffProfiles splits the traces at the cutoff percentile and compares the child
+// offsets of their spans, matched by structural path. The traces are expected to share
+// the same structure, see structure.GroupTraces, so that their spans have the same children.
+// Only spans with children are profiled, ordered by decreasing maximum contribution.
+func NewChildDiffProfiles(traces []*model.Trace, cutoff float64) []ChildDiffProfile {
+	norm, tail := SplitTail(traces, cutoff)
+	spans := make(map[string]*childDiffSamples)
+	collect := func(traces []*model.Trace, side func(p *samplePair) *samples) {
+		for _, trace := range traces {
+			tree := model.NewTraceTree(trace)
+			paths := structure.Paths(tree)
+			tree.Walk(func(n *model.SpanNode) {
+				if len(n.Children) == 0 {
+					return
+				}
+				path := paths[n]
+				if spans[path] == nil {
+					spans[path] = &childDiffSamples{}
+				}
+				spans[path].add(n.ChildOffsets(), side)
+			})
+		}
+	}
+	collect(norm, func(p *samplePair) *samples { return &p.norm })
+	collect(tail, func(p *samplePair) *samples { return &p.tail })
+
+	profiles := make([]ChildDiffProfile, 0, len(spans))
+	for path, s := range spans {
+		profile := ChildDiffProfile{
+			Path:       path,
+			ChildDiffs: make([]Comparison, len(s.starts)),
+			EndDiff:    s.end.compare(len(norm), len(tail)),
+		}
+		for i := range s.starts {
+			profile.ChildDiffs[i] = s.starts[i].compare(len(norm), len(tail))
+		}
+		profiles = append(profiles, profile)
+	}
+	sort.Slice(profiles, func(i, j int) bool {
+		a, b := profiles[i].MaxContribution().Contribution, profiles[j].MaxContribution().Contribution
+		if a != b {
+			return a > b
+		}
+		return profiles[i].Path < profiles[j].Path
+	})
+	return profiles
+}
+
+type childDiffSamples struct {
+	starts []samplePair
+	end    samplePair
+}
+
+func (s *childDiffSamples) add(offsets model.ChildOffsets, side func(p *samplePair) *samples) {
+	for len(s.starts) < len(offsets.Starts) {
+		s.starts = append(s.starts, samplePair{})
+	}
+	for i, start := range offsets.Starts {
+		side(&s.starts[i]).add(start)
+	}
+	side(&s.end).add(offsets.End)
+}
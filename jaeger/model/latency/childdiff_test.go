@@ -0,0 +1,80 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package latency
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// newSequentialTrace returns a frontend span calling the database twice, each query taking
+// 1ms, with the given delay between the end of the first and the start of the second query.
+func newSequentialTrace(id uint64, delay time.Duration) *model.Trace {
+	trace := newTrace(id, 10*time.Millisecond+delay, time.Millisecond)
+	second := *trace.Spans[1]
+	second.SpanID = model.NewSpanID(3)
+	second.StartTime = second.StartTime.Add(time.Millisecond + delay)
+	trace.Spans = append(trace.Spans, &second)
+	return trace
+}
+
+func TestNewChildDiffProfiles(t *testing.T) {
+	var traces []*model.Trace
+	for i := 0; i < 9; i++ {
+		traces = append(traces, newSequentialTrace(uint64(i), time.Millisecond))
+	}
+	traces = append(traces, newSequentialTrace(9, 11*time.Millisecond))
+
+	profiles := NewChildDiffProfiles(traces, DefaultTailCutoff)
+	require.Len(t, profiles, 1, "leaf spans are not profiled")
+	profile := profiles[0]
+	assert.Equal(t, "frontend:GET#0", profile.Path)
+	require.Len(t, profile.ChildDiffs, 2)
+
+	first := profile.ChildDiffs[0]
+	assert.Equal(t, 9, first.Norm.Count)
+	assert.Equal(t, time.Millisecond, first.Norm.Mean)
+	assert.Equal(t, time.Duration(0), first.Contribution)
+
+	second := profile.ChildDiffs[1]
+	assert.Equal(t, 2*time.Millisecond, second.Norm.Mean)
+	assert.Equal(t, 12*time.Millisecond, second.Tail.Mean)
+	assert.Equal(t, 10*time.Millisecond, second.Contribution)
+	assert.Equal(t, second, profile.MaxContribution())
+
+	// the frontend span always ends 7ms after the second query
+	assert.Equal(t, 7*time.Millisecond, profile.EndDiff.Norm.Mean)
+	assert.Equal(t, time.Duration(0), profile.EndDiff.Contribution)
+}
+
+func TestNewChildDiffProfilesMixedStructures(t *testing.T) {
+	traces := []*model.Trace{
+		newSequentialTrace(1, time.Millisecond),
+		newTrace(2, 10*time.Millisecond, time.Millisecond),
+	}
+	profiles := NewChildDiffProfiles(traces, 50)
+	require.Len(t, profiles, 1)
+	// the single-query trace is the fastest, so it is the norm
+	assert.Len(t, profiles[0].ChildDiffs, 2)
+	assert.Equal(t, 1, profiles[0].ChildDiffs[1].Tail.Count)
+	assert.Equal(t, 0, profiles[0].ChildDiffs[1].Norm.Count)
+
+	assert.Empty(t, NewChildDiffProfiles(nil, DefaultTailCutoff))
+}
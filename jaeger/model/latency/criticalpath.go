@@ -0,0 +1,81 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package latency
+
+import (
+	"sort"
+	"time"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// CriticalPathContribution is the time the spans of an operation spend on the critical
+// paths of a set of traces, see model.TraceTree.CriticalPath.
+type CriticalPathContribution struct {
+	Service   string
+	Operation string
+	// Total is the time spent on the critical paths, summed over all the traces.
+	Total time.Duration
+	// PerTrace is the average time spent on the critical path of a trace.
+	PerTrace time.Duration
+	// Share is the fraction of the summed length of the critical paths.
+	Share float64
+	// Traces is the number of traces whose critical path goes through the operation.
+	Traces int
+}
+
+// CriticalPathContributions aggregates the critical paths of the traces per operation.
+// The contributions are ordered by decreasing total time.
+func CriticalPathContributions(traces []*model.Trace) []CriticalPathContribution {
+	contributions := make(map[operationKey]*CriticalPathContribution)
+	var total time.Duration
+	for _, trace := range traces {
+		seen := make(map[operationKey]bool)
+		for _, segment := range model.NewTraceTree(trace).CriticalPath() {
+			key := operationKey{service: segment.Span.Process.ServiceName, operation: segment.Span.OperationName}
+			c := contributions[key]
+			if c == nil {
+				c = &CriticalPathContribution{Service: key.service, Operation: key.operation}
+				contributions[key] = c
+			}
+			c.Total += segment.Duration()
+			total += segment.Duration()
+			if !seen[key] {
+				seen[key] = true
+				c.Traces++
+			}
+		}
+	}
+
+	retMe := make([]CriticalPathContribution, 0, len(contributions))
+	for _, c := range contributions {
+		c.PerTrace = c.Total / time.Duration(len(traces))
+		if total > 0 {
+			c.Share = float64(c.Total) / float64(total)
+		}
+		retMe = append(retMe, *c)
+	}
+	sort.Slice(retMe, func(i, j int) bool {
+		a, b := retMe[i], retMe[j]
+		if a.Total != b.Total {
+			return a.Total > b.Total
+		}
+		if a.Service != b.Service {
+			return a.Service < b.Service
+		}
+		return a.Operation < b.Operation
+	})
+	return retMe
+}
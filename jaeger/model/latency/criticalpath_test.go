@@ -0,0 +1,50 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package latency
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestCriticalPathContributions(t *testing.T) {
+	traces := []*model.Trace{
+		newTrace(1, 10*time.Millisecond, 2*time.Millisecond),
+		newTrace(2, 10*time.Millisecond, 6*time.Millisecond),
+	}
+	contributions := CriticalPathContributions(traces)
+	require.Len(t, contributions, 2)
+
+	frontend := contributions[0]
+	assert.Equal(t, "frontend", frontend.Service)
+	assert.Equal(t, "GET", frontend.Operation)
+	assert.Equal(t, 12*time.Millisecond, frontend.Total)
+	assert.Equal(t, 6*time.Millisecond, frontend.PerTrace)
+	assert.Equal(t, 0.6, frontend.Share)
+	assert.Equal(t, 2, frontend.Traces)
+
+	db := contributions[1]
+	assert.Equal(t, "db", db.Service)
+	assert.Equal(t, 8*time.Millisecond, db.Total)
+	assert.Equal(t, 4*time.Millisecond, db.PerTrace)
+	assert.Equal(t, 0.4, db.Share)
+
+	assert.Empty(t, CriticalPathContributions(nil))
+}
@@ -0,0 +1,178 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package latency
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/model/structure"
+	"github.com/jaegertracing/jaeger/pkg/stats"
+)
+
+// DefaultSignificance is the default p-value below which a latency shift is significant.
+const DefaultSignificance = 0.05
+
+// Shift compares the latency of an item between the baseline and the current traces.
+type Shift struct {
+	Baseline stats.Summary
+	Current  stats.Summary
+	// Diff is Current minus Baseline.
+	Diff stats.Summary
+	// Test compares the baseline (x) and current (y) samples, a positive effect
+	// meaning that the item got slower.
+	Test stats.RankTest
+}
+
+// OperationShift is the latency shift of the spans of an operation.
+type OperationShift struct {
+	Service   string
+	Operation string
+	Shift
+}
+
+// StructureShift is the latency shift of the traces of a structural group present in both sets.
+type StructureShift struct {
+	Hash      string
+	Signature string
+	Shift
+}
+
+// StructureCount is a structural group found in only one of the sets, with its number of traces.
+type StructureCount struct {
+	Hash      string
+	Signature string
+	Traces    int
+}
+
+// Regression compares a current set of traces against a baseline.
+type Regression struct {
+	Significance float64
+	// Traces is the shift of the end-to-end trace latency.
+	Traces Shift
+	// Operations and Structures hold the significant shifts only, ordered by decreasing
+	// absolute effect size.
+	Operations []OperationShift
+	Structures []StructureShift
+	// Appeared and Disappeared are the structural groups found only in the current,
+	// respectively baseline, traces, largest groups first.
+	Appeared    []StructureCount
+	Disappeared []StructureCount
+}
+
+// NewRegression compares the current traces against the baseline traces. A shift is
+// significant when the p-value of its Mann-Whitney U test is below significance.
+func NewRegression(baseline, current []*model.Trace, significance float64) *Regression {
+	regression := &Regression{
+		Significance: significance,
+		Traces:       newShift(traceDurations(baseline), traceDurations(current)),
+	}
+
+	baseOps, currentOps := operationDurations(baseline), operationDurations(current)
+	for key, currentDurations := range currentOps {
+		baseDurations, ok := baseOps[key]
+		if !ok {
+			continue
+		}
+		shift := newShift(baseDurations, currentDurations)
+		if shift.Test.PValue < significance {
+			regression.Operations = append(regression.Operations, OperationShift{
+				Service:   key.service,
+				Operation: key.operation,
+				Shift:     shift,
+			})
+		}
+	}
+	sort.Slice(regression.Operations, func(i, j int) bool {
+		a, b := regression.Operations[i], regression.Operations[j]
+		if ea, eb := math.Abs(a.Test.Effect), math.Abs(b.Test.Effect); ea != eb {
+			return ea > eb
+		}
+		if a.Service != b.Service {
+			return a.Service < b.Service
+		}
+		return a.Operation < b.Operation
+	})
+
+	baseGroupList := structure.GroupTraces(baseline)
+	baseGroups := make(map[string]*structure.Group, len(baseGroupList))
+	for _, group := range baseGroupList {
+		baseGroups[group.Signature] = group
+	}
+	for _, group := range structure.GroupTraces(current) {
+		baseGroup, ok := baseGroups[group.Signature]
+		if !ok {
+			regression.Appeared = append(regression.Appeared, structureCount(group))
+			continue
+		}
+		delete(baseGroups, group.Signature)
+		shift := newShift(traceDurations(baseGroup.Traces), traceDurations(group.Traces))
+		if shift.Test.PValue < significance {
+			regression.Structures = append(regression.Structures, StructureShift{
+				Hash:      group.Hash,
+				Signature: group.Signature,
+				Shift:     shift,
+			})
+		}
+	}
+	for _, group := range baseGroupList {
+		if _, ok := baseGroups[group.Signature]; ok {
+			regression.Disappeared = append(regression.Disappeared, structureCount(group))
+		}
+	}
+	sort.SliceStable(regression.Structures, func(i, j int) bool {
+		return math.Abs(regression.Structures[i].Test.Effect) > math.Abs(regression.Structures[j].Test.Effect)
+	})
+	return regression
+}
+
+func newShift(baseline, current []time.Duration) Shift {
+	s := Shift{
+		Test: stats.MannWhitney(baseline, current),
+	}
+	s.Baseline = stats.Summarize(baseline)
+	s.Current = stats.Summarize(current)
+	s.Diff = s.Current.Sub(s.Baseline)
+	return s
+}
+
+func structureCount(group *structure.Group) StructureCount {
+	return StructureCount{
+		Hash:      group.Hash,
+		Signature: group.Signature,
+		Traces:    len(group.Traces),
+	}
+}
+
+func traceDurations(traces []*model.Trace) []time.Duration {
+	durations := make([]time.Duration, len(traces))
+	for i, trace := range traces {
+		durations[i] = trace.Duration()
+	}
+	return durations
+}
+
+func operationDurations(traces []*model.Trace) map[operationKey][]time.Duration {
+	durations := make(map[operationKey][]time.Duration)
+	for _, trace := range traces {
+		for _, span := range trace.Spans {
+			key := operationKey{service: span.Process.ServiceName, operation: span.OperationName}
+			durations[key] = append(durations[key], span.Duration)
+		}
+	}
+	return durations
+}
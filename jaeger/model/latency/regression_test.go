@@ -0,0 +1,80 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package latency
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestNewRegression(t *testing.T) {
+	var baseline, current []*model.Trace
+	for i := 0; i < 8; i++ {
+		baseline = append(baseline, newTrace(uint64(i), time.Duration(10+i)*time.Millisecond, time.Millisecond))
+		current = append(current, newTrace(uint64(100+i), time.Duration(20+i)*time.Millisecond, 5*time.Millisecond))
+	}
+	gone := newTrace(50, 10*time.Millisecond, time.Millisecond)
+	gone.Spans = gone.Spans[:1]
+	baseline = append(baseline, gone)
+	added := newTrace(150, 20*time.Millisecond, time.Millisecond)
+	retry := *added.Spans[1]
+	retry.SpanID = model.NewSpanID(3)
+	retry.StartTime = retry.StartTime.Add(2 * time.Millisecond)
+	added.Spans = append(added.Spans, &retry)
+	current = append(current, added)
+
+	regression := NewRegression(baseline, current, DefaultSignificance)
+	assert.Equal(t, DefaultSignificance, regression.Significance)
+	assert.Equal(t, 9, regression.Traces.Baseline.Count)
+	assert.Equal(t, 9, regression.Traces.Current.Count)
+	assert.True(t, regression.Traces.Test.Effect > 0.8)
+	assert.True(t, regression.Traces.Diff.Mean > 0)
+
+	require.Len(t, regression.Operations, 2)
+	assert.Equal(t, "frontend", regression.Operations[0].Service, "largest effect first")
+	assert.Equal(t, "GET", regression.Operations[0].Operation)
+	assert.Equal(t, "db", regression.Operations[1].Service)
+	assert.Equal(t, "query", regression.Operations[1].Operation)
+	for _, op := range regression.Operations {
+		assert.True(t, op.Test.PValue < DefaultSignificance)
+	}
+
+	require.Len(t, regression.Structures, 1)
+	assert.Equal(t, 8, regression.Structures[0].Current.Count)
+	assert.Equal(t, 1.0, regression.Structures[0].Test.Effect)
+
+	require.Len(t, regression.Appeared, 1)
+	assert.Equal(t, 1, regression.Appeared[0].Traces)
+	require.Len(t, regression.Disappeared, 1)
+	assert.Equal(t, 1, regression.Disappeared[0].Traces)
+	assert.NotEqual(t, regression.Appeared[0].Hash, regression.Disappeared[0].Hash)
+}
+
+func TestNewRegressionNoShift(t *testing.T) {
+	traces := []*model.Trace{
+		newTrace(1, 10*time.Millisecond, time.Millisecond),
+		newTrace(2, 20*time.Millisecond, time.Millisecond),
+	}
+	regression := NewRegression(traces, traces, DefaultSignificance)
+	assert.Empty(t, regression.Operations)
+	assert.Empty(t, regression.Structures)
+	assert.Empty(t, regression.Appeared)
+	assert.Empty(t, regression.Disappeared)
+}
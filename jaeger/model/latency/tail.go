@@ -0,0 +1,203 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package latency compares where time is spent in the fastest and the slowest traces of a set.
+package latency
+
+import (
+	"sort"
+	"time"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/model/structure"
+	"github.com/jaegertracing/jaeger/pkg/stats"
+)
+
+// DefaultTailCutoff is the default percentile of the trace durations separating the norm from the tail.
+const DefaultTailCutoff = 90
+
+// Comparison compares the latency of an item between the norm and the tail traces.
+type Comparison struct {
+	Norm stats.Summary
+	Tail stats.Summary
+	// Diff is Tail minus Norm.
+	Diff stats.Summary
+	// Contribution is how much more time an average tail trace spends in the item
+	// than an average norm trace.
+	Contribution time.Duration
+}
+
+// OperationProfile compares the duration of the spans of an operation.
+type OperationProfile struct {
+	Service   string
+	Operation string
+	Comparison
+}
+
+// SubspanProfile compares the duration of a subspan, which is the Index-th gap
+// between the children of the spans found at Path, see structure.Paths and model.SpanBreakdown.
+type SubspanProfile struct {
+	Path  string
+	Index int
+	Comparison
+}
+
+// TailProfile compares the norm and the tail traces of a set. Operations and subspans
+// are ordered by decreasing contribution to the tail.
+type TailProfile struct {
+	Cutoff     float64
+	Traces     Comparison
+	Operations []OperationProfile
+	Subspans   []SubspanProfile
+}
+
+// SplitTail sorts the traces by duration and splits them at the cutoff percentile:
+// norm holds the floor(cutoff/100*len(traces)) fastest traces and tail holds the others.
+func SplitTail(traces []*model.Trace, cutoff float64) (norm []*model.Trace, tail []*model.Trace) {
+	sorted := make([]*model.Trace, len(traces))
+	copy(sorted, traces)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Duration() < sorted[j].Duration()
+	})
+	index := int(cutoff / 100 * float64(len(sorted)))
+	if index < 0 {
+		index = 0
+	}
+	if index > len(sorted) {
+		index = len(sorted)
+	}
+	return sorted[:index], sorted[index:]
+}
+
+// NewTailProfile splits the traces at the cutoff percentile and compares the two halves.
+func NewTailProfile(traces []*model.Trace, cutoff float64) *TailProfile {
+	norm, tail := SplitTail(traces, cutoff)
+	ps := profileSamples{
+		operations: make(map[operationKey]*samplePair),
+		subspans:   make(map[subspanKey]*samplePair),
+	}
+	ps.collect(norm, func(p *samplePair) *samples { return &p.norm })
+	ps.collect(tail, func(p *samplePair) *samples { return &p.tail })
+
+	profile := &TailProfile{
+		Cutoff: cutoff,
+		Traces: ps.traces.compare(len(norm), len(tail)),
+	}
+	for key, pair := range ps.operations {
+		profile.Operations = append(profile.Operations, OperationProfile{
+			Service:    key.service,
+			Operation:  key.operation,
+			Comparison: pair.compare(len(norm), len(tail)),
+		})
+	}
+	for key, pair := range ps.subspans {
+		profile.Subspans = append(profile.Subspans, SubspanProfile{
+			Path:       key.path,
+			Index:      key.index,
+			Comparison: pair.compare(len(norm), len(tail)),
+		})
+	}
+	sort.Slice(profile.Operations, func(i, j int) bool {
+		a, b := profile.Operations[i], profile.Operations[j]
+		if a.Contribution != b.Contribution {
+			return a.Contribution > b.Contribution
+		}
+		if a.Service != b.Service {
+			return a.Service < b.Service
+		}
+		return a.Operation < b.Operation
+	})
+	sort.Slice(profile.Subspans, func(i, j int) bool {
+		a, b := profile.Subspans[i], profile.Subspans[j]
+		if a.Contribution != b.Contribution {
+			return a.Contribution > b.Contribution
+		}
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Index < b.Index
+	})
+	return profile
+}
+
+type operationKey struct {
+	service   string
+	operation string
+}
+
+type subspanKey struct {
+	path  string
+	index int
+}
+
+type samples struct {
+	durations []time.Duration
+	total     time.Duration
+}
+
+func (s *samples) add(d time.Duration) {
+	s.durations = append(s.durations, d)
+	s.total += d
+}
+
+// perTrace returns the average time per trace, given the number of traces the samples come from.
+func (s *samples) perTrace(traces int) time.Duration {
+	if traces == 0 {
+		return 0
+	}
+	return s.total / time.Duration(traces)
+}
+
+type samplePair struct {
+	norm samples
+	tail samples
+}
+
+func (p *samplePair) compare(normTraces, tailTraces int) Comparison {
+	c := Comparison{
+		Norm: stats.Summarize(p.norm.durations),
+		Tail: stats.Summarize(p.tail.durations),
+	}
+	c.Diff = c.Tail.Sub(c.Norm)
+	c.Contribution = p.tail.perTrace(tailTraces) - p.norm.perTrace(normTraces)
+	return c
+}
+
+type profileSamples struct {
+	traces     samplePair
+	operations map[operationKey]*samplePair
+	subspans   map[subspanKey]*samplePair
+}
+
+func (ps *profileSamples) collect(traces []*model.Trace, side func(p *samplePair) *samples) {
+	for _, trace := range traces {
+		side(&ps.traces).add(trace.Duration())
+		tree := model.NewTraceTree(trace)
+		paths := structure.Paths(tree)
+		tree.Walk(func(n *model.SpanNode) {
+			opKey := operationKey{service: n.Span.Process.ServiceName, operation: n.Span.OperationName}
+			if ps.operations[opKey] == nil {
+				ps.operations[opKey] = &samplePair{}
+			}
+			side(ps.operations[opKey]).add(n.Span.Duration)
+			for i, gap := range n.Breakdown().Gaps {
+				key := subspanKey{path: paths[n], index: i}
+				if ps.subspans[key] == nil {
+					ps.subspans[key] = &samplePair{}
+				}
+				side(ps.subspans[key]).add(gap.Duration())
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package latency
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// newTrace returns a frontend span of the given duration calling the database
+// after 1ms, for the given query duration.
+func newTrace(id uint64, duration, query time.Duration) *model.Trace {
+	traceID := model.NewTraceID(0, id)
+	start := time.Unix(1000, 0)
+	return &model.Trace{
+		Spans: []*model.Span{
+			{
+				TraceID:       traceID,
+				SpanID:        model.NewSpanID(1),
+				OperationName: "GET",
+				StartTime:     start,
+				Duration:      duration,
+				Process:       &model.Process{ServiceName: "frontend"},
+			},
+			{
+				TraceID:       traceID,
+				SpanID:        model.NewSpanID(2),
+				OperationName: "query",
+				References:    []model.SpanRef{model.NewChildOfRef(traceID, model.NewSpanID(1))},
+				StartTime:     start.Add(time.Millisecond),
+				Duration:      query,
+				Process:       &model.Process{ServiceName: "db"},
+			},
+		},
+	}
+}
+
+func TestSplitTail(t *testing.T) {
+	a := newTrace(1, 30*time.Millisecond, time.Millisecond)
+	b := newTrace(2, 10*time.Millisecond, time.Millisecond)
+	c := newTrace(3, 20*time.Millisecond, time.Millisecond)
+	traces := []*model.Trace{a, b, c}
+
+	norm, tail := SplitTail(traces, 50)
+	assert.Equal(t, []*model.Trace{b}, norm)
+	assert.Equal(t, []*model.Trace{c, a}, tail)
+	assert.Equal(t, []*model.Trace{a, b, c}, traces, "input is left untouched")
+
+	norm, tail = SplitTail(traces, 100)
+	assert.Len(t, norm, 3)
+	assert.Empty(t, tail)
+}
+
+func TestNewTailProfile(t *testing.T) {
+	var traces []*model.Trace
+	for i := 0; i < 9; i++ {
+		traces = append(traces, newTrace(uint64(i), 10*time.Millisecond, 2*time.Millisecond))
+	}
+	traces = append(traces, newTrace(9, 30*time.Millisecond, 20*time.Millisecond))
+
+	profile := NewTailProfile(traces, DefaultTailCutoff)
+	assert.Equal(t, float64(DefaultTailCutoff), profile.Cutoff)
+	assert.Equal(t, 9, profile.Traces.Norm.Count)
+	assert.Equal(t, 1, profile.Traces.Tail.Count)
+	assert.Equal(t, 20*time.Millisecond, profile.Traces.Diff.Mean)
+	assert.Equal(t, 20*time.Millisecond, profile.Traces.Contribution)
+
+	require.Len(t, profile.Operations, 2)
+	assert.Equal(t, "frontend", profile.Operations[0].Service)
+	assert.Equal(t, "GET", profile.Operations[0].Operation)
+	assert.Equal(t, 20*time.Millisecond, profile.Operations[0].Contribution)
+	assert.Equal(t, "db", profile.Operations[1].Service)
+	assert.Equal(t, 2*time.Millisecond, profile.Operations[1].Norm.P50)
+	assert.Equal(t, 20*time.Millisecond, profile.Operations[1].Tail.P99)
+	assert.Equal(t, 18*time.Millisecond, profile.Operations[1].Diff.Mean)
+	assert.Equal(t, 18*time.Millisecond, profile.Operations[1].Contribution)
+
+	require.Len(t, profile.Subspans, 3)
+	assert.Equal(t, "frontend:GET#0~db:query#0", profile.Subspans[0].Path)
+	assert.Equal(t, 0, profile.Subspans[0].Index)
+	assert.Equal(t, 18*time.Millisecond, profile.Subspans[0].Contribution)
+	assert.Equal(t, "frontend:GET#0", profile.Subspans[1].Path)
+	assert.Equal(t, 1, profile.Subspans[1].Index)
+	assert.Equal(t, 7*time.Millisecond, profile.Subspans[1].Norm.Mean)
+	assert.Equal(t, 9*time.Millisecond, profile.Subspans[1].Tail.Mean)
+	assert.Equal(t, 2*time.Millisecond, profile.Subspans[1].Contribution)
+	assert.Equal(t, "frontend:GET#0", profile.Subspans[2].Path)
+	assert.Equal(t, 0, profile.Subspans[2].Index)
+	assert.Equal(t, time.Duration(0), profile.Subspans[2].Contribution)
+}
+
+func TestNewTailProfileOperationOnlyInTail(t *testing.T) {
+	norm := newTrace(1, 10*time.Millisecond, 2*time.Millisecond)
+	norm.Spans = norm.Spans[:1]
+	tail := newTrace(2, 20*time.Millisecond, 2*time.Millisecond)
+
+	profile := NewTailProfile([]*model.Trace{norm, tail}, 50)
+	require.Len(t, profile.Operations, 2)
+	db := profile.Operations[1]
+	assert.Equal(t, "db", db.Service)
+	assert.Equal(t, 0, db.Norm.Count)
+	assert.Equal(t, 1, db.Tail.Count)
+	assert.Equal(t, 2*time.Millisecond, db.Contribution)
+}
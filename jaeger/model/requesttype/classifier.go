@@ -0,0 +1,147 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package requesttype
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// Tag is the tag holding the request type stamped onto root spans.
+const Tag = "request.type"
+
+// Rule assigns a request type to the root spans matching all of its non-empty conditions.
+type Rule struct {
+	// Type is the request type assigned to the matching spans.
+	Type string `mapstructure:"type" json:"type"`
+	// Service and Operation must equal the service and operation names of the span.
+	Service   string `mapstructure:"service" json:"service"`
+	Operation string `mapstructure:"operation" json:"operation"`
+	// ServicePattern and OperationPattern are regular expressions the service and
+	// operation names of the span must match.
+	ServicePattern   string `mapstructure:"servicePattern" json:"servicePattern"`
+	OperationPattern string `mapstructure:"operationPattern" json:"operationPattern"`
+	// Tags maps tag keys, e.g. http.route, to the values the tags of the span must equal.
+	Tags map[string]string `mapstructure:"tags" json:"tags"`
+	// TagPatterns maps tag keys to regular expressions the tags of the span must match.
+	TagPatterns map[string]string `mapstructure:"tagPatterns" json:"tagPatterns"`
+}
+
+type compiledRule struct {
+	Rule
+	servicePattern   *regexp.Regexp
+	operationPattern *regexp.Regexp
+	tagPatterns      map[string]*regexp.Regexp
+}
+
+// Classifier assigns request types to traces with an ordered list of rules, the first
+// matching rule winning. Spans matching no rule are classified by DefaultType.
+// A nil *Classifier classifies every span by DefaultType.
+type Classifier struct {
+	rules []compiledRule
+}
+
+// NewClassifier compiles the rules into a Classifier.
+func NewClassifier(rules []Rule) (*Classifier, error) {
+	c := &Classifier{rules: make([]compiledRule, len(rules))}
+	for i, rule := range rules {
+		if rule.Type == "" {
+			return nil, fmt.Errorf("request type rule #%d has no type", i)
+		}
+		compiled := compiledRule{Rule: rule, tagPatterns: make(map[string]*regexp.Regexp, len(rule.TagPatterns))}
+		var err error
+		if compiled.servicePattern, err = compilePattern(rule.ServicePattern); err != nil {
+			return nil, fmt.Errorf("request type %q: invalid service pattern: %v", rule.Type, err)
+		}
+		if compiled.operationPattern, err = compilePattern(rule.OperationPattern); err != nil {
+			return nil, fmt.Errorf("request type %q: invalid operation pattern: %v", rule.Type, err)
+		}
+		for key, pattern := range rule.TagPatterns {
+			if compiled.tagPatterns[key], err = compilePattern(pattern); err != nil {
+				return nil, fmt.Errorf("request type %q: invalid pattern for tag %s: %v", rule.Type, key, err)
+			}
+		}
+		c.rules[i] = compiled
+	}
+	return c, nil
+}
+
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	return regexp.Compile(pattern)
+}
+
+// Classify returns the request type of the earliest root span of the trace,
+// or an empty string when the trace has no root.
+func (c *Classifier) Classify(trace *model.Trace) string {
+	tree := model.NewTraceTree(trace)
+	if len(tree.Roots) == 0 {
+		return ""
+	}
+	return c.ClassifySpan(tree.Roots[0].Span)
+}
+
+// ClassifySpan returns the request type of a root span.
+func (c *Classifier) ClassifySpan(span *model.Span) string {
+	if c != nil {
+		for i := range c.rules {
+			if c.rules[i].matches(span) {
+				return c.rules[i].Type
+			}
+		}
+	}
+	return DefaultType(span)
+}
+
+// DefaultType returns the service and operation names of the span, joined by a colon.
+func DefaultType(span *model.Span) string {
+	return span.Process.ServiceName + ":" + span.OperationName
+}
+
+func (r *compiledRule) matches(span *model.Span) bool {
+	service, operation := span.Process.ServiceName, span.OperationName
+	if (r.Service != "" && r.Service != service) ||
+		(r.Operation != "" && r.Operation != operation) ||
+		(r.servicePattern != nil && !r.servicePattern.MatchString(service)) ||
+		(r.operationPattern != nil && !r.operationPattern.MatchString(operation)) {
+		return false
+	}
+	for key, value := range r.Tags {
+		if tag, ok := findTag(span, key); !ok || tag != value {
+			return false
+		}
+	}
+	for key, pattern := range r.tagPatterns {
+		if tag, ok := findTag(span, key); !ok || !pattern.MatchString(tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// findTag looks the key up in the span tags, then in the process tags.
+func findTag(span *model.Span, key string) (string, bool) {
+	if kv, ok := model.KeyValues(span.Tags).FindByKey(key); ok {
+		return kv.AsString(), true
+	}
+	if kv, ok := model.KeyValues(span.Process.Tags).FindByKey(key); ok {
+		return kv.AsString(), true
+	}
+	return "", false
+}
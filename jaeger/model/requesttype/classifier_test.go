@@ -0,0 +1,100 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package requesttype
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func makeSpan(service, operation string, tags ...model.KeyValue) *model.Span {
+	return &model.Span{
+		TraceID:       model.NewTraceID(0, 1),
+		SpanID:        model.NewSpanID(1),
+		OperationName: operation,
+		StartTime:     time.Unix(1000, 0),
+		Duration:      time.Millisecond,
+		Tags:          tags,
+		Process:       &model.Process{ServiceName: service},
+	}
+}
+
+func TestClassifySpan(t *testing.T) {
+	c, err := NewClassifier([]Rule{
+		{Type: "checkout", Service: "frontend", Tags: map[string]string{"http.method": "POST"}},
+		{Type: "search", OperationPattern: "^GET /search"},
+		{Type: "api", TagPatterns: map[string]string{"http.route": "^/api/"}},
+		{Type: "admin", ServicePattern: "^admin-", Operation: "login"},
+	})
+	require.NoError(t, err)
+
+	process := makeSpan("frontend", "POST /cart")
+	process.Process.Tags = []model.KeyValue{model.String("http.method", "POST")}
+
+	testCases := []struct {
+		span     *model.Span
+		expected string
+	}{
+		{span: makeSpan("frontend", "POST /cart", model.String("http.method", "POST")), expected: "checkout"},
+		{span: process, expected: "checkout"},
+		{span: makeSpan("frontend", "GET /search?q=x", model.String("http.method", "GET")), expected: "search"},
+		{span: makeSpan("backend", "GET /search"), expected: "search"},
+		{span: makeSpan("backend", "route", model.String("http.route", "/api/users")), expected: "api"},
+		{span: makeSpan("backend", "route", model.String("http.route", "/static")), expected: "backend:route"},
+		{span: makeSpan("admin-ui", "login"), expected: "admin"},
+		{span: makeSpan("admin-ui", "logout"), expected: "admin-ui:logout"},
+		{span: makeSpan("frontend", "POST /cart", model.Int64("http.method", 1)), expected: "frontend:POST /cart"},
+	}
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.expected, c.ClassifySpan(testCase.span))
+	}
+}
+
+func TestClassify(t *testing.T) {
+	root := makeSpan("frontend", "GET /search")
+	child := makeSpan("db", "query")
+	child.SpanID = model.NewSpanID(2)
+	child.References = []model.SpanRef{model.NewChildOfRef(root.TraceID, root.SpanID)}
+	trace := &model.Trace{Spans: []*model.Span{child, root}}
+
+	c, err := NewClassifier([]Rule{{Type: "search", Operation: "GET /search"}})
+	require.NoError(t, err)
+	assert.Equal(t, "search", c.Classify(trace))
+	assert.Equal(t, "", c.Classify(&model.Trace{}))
+
+	var defaultClassifier *Classifier
+	assert.Equal(t, "frontend:GET /search", defaultClassifier.Classify(trace))
+}
+
+func TestNewClassifierErrors(t *testing.T) {
+	testCases := []struct {
+		rule   Rule
+		errMsg string
+	}{
+		{rule: Rule{Service: "frontend"}, errMsg: "request type rule #0 has no type"},
+		{rule: Rule{Type: "a", ServicePattern: "("}, errMsg: "request type \"a\": invalid service pattern: error parsing regexp: missing closing ): `(`"},
+		{rule: Rule{Type: "a", OperationPattern: "("}, errMsg: "request type \"a\": invalid operation pattern: error parsing regexp: missing closing ): `(`"},
+		{rule: Rule{Type: "a", TagPatterns: map[string]string{"k": "("}}, errMsg: "request type \"a\": invalid pattern for tag k: error parsing regexp: missing closing ): `(`"},
+	}
+	for _, testCase := range testCases {
+		_, err := NewClassifier([]Rule{testCase.rule})
+		assert.EqualError(t, err, testCase.errMsg)
+	}
+}
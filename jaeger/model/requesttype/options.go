@@ -0,0 +1,72 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package requesttype
+
+import (
+	"flag"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+const (
+	rulesFile = "request-types.rules-file"
+	rulesKey  = "rules"
+)
+
+// Options holds configuration for the request type Classifier.
+type Options struct {
+	// RulesFile is the path of the request type rules file in YAML or JSON format
+	RulesFile string
+}
+
+// AddFlags adds flags for Options
+func AddFlags(flagSet *flag.FlagSet) {
+	flagSet.String(rulesFile, "", "The path of the request type rules file in YAML or JSON format. When empty, traces are classified by the service and operation of their root span")
+}
+
+// InitFromViper initializes Options with properties from viper
+func (opts *Options) InitFromViper(v *viper.Viper) *Options {
+	opts.RulesFile = v.GetString(rulesFile)
+	return opts
+}
+
+// NewClassifier loads the rules file into a Classifier. It returns a nil Classifier,
+// which classifies traces by DefaultType, when no file is configured.
+func (opts *Options) NewClassifier() (*Classifier, error) {
+	if opts.RulesFile == "" {
+		return nil, nil
+	}
+	rules, err := LoadRules(opts.RulesFile)
+	if err != nil {
+		return nil, err
+	}
+	return NewClassifier(rules)
+}
+
+// LoadRules reads the list of rules under the "rules" key of a YAML or JSON file,
+// the format being inferred from the file extension.
+func LoadRules(file string) ([]Rule, error) {
+	v := viper.New()
+	v.SetConfigFile(file)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, errors.Wrap(err, "failed to read request type rules")
+	}
+	var rules []Rule
+	if err := v.UnmarshalKey(rulesKey, &rules); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal request type rules")
+	}
+	return rules, nil
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package requesttype
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/config"
+)
+
+func TestOptionsWithFlags(t *testing.T) {
+	v, command := config.Viperize(AddFlags)
+	command.ParseFlags([]string{"--request-types.rules-file=fixtures/rules.yaml"})
+	opts := new(Options).InitFromViper(v)
+	assert.Equal(t, "fixtures/rules.yaml", opts.RulesFile)
+}
+
+func TestLoadRules(t *testing.T) {
+	expected := []Rule{
+		{
+			Type:    "checkout",
+			Service: "frontend",
+			Tags:    map[string]string{"http.method": "POST", "http.route": "/cart/checkout"},
+		},
+		{Type: "search", OperationPattern: "^GET /search"},
+		{Type: "admin", ServicePattern: "^admin-"},
+	}
+	for _, file := range []string{"fixtures/rules.yaml", "fixtures/rules.json"} {
+		rules, err := LoadRules(file)
+		require.NoError(t, err, file)
+		assert.Equal(t, expected, rules, file)
+	}
+
+	_, err := LoadRules("fixtures/missing.yaml")
+	assert.Error(t, err)
+}
+
+func TestOptionsNewClassifier(t *testing.T) {
+	c, err := (&Options{}).NewClassifier()
+	require.NoError(t, err)
+	assert.Nil(t, c)
+
+	c, err = (&Options{RulesFile: "fixtures/rules.yaml"}).NewClassifier()
+	require.NoError(t, err)
+	span := makeSpan("frontend", "POST", model.String("http.method", "POST"), model.String("http.route", "/cart/checkout"))
+	assert.Equal(t, "checkout", c.ClassifySpan(span))
+
+	_, err = (&Options{RulesFile: "fixtures/missing.yaml"}).NewClassifier()
+	assert.Error(t, err)
+
+	_, err = (&Options{RulesFile: "fixtures/bad_rules.yaml"}).NewClassifier()
+	assert.Error(t, err)
+}
@@ -74,6 +74,24 @@ func SortTrace(trace *Trace) {
 	}
 }
 
+type spanNodeByStartTime []*SpanNode
+
+func (s spanNodeByStartTime) Len() int      { return len(s) }
+func (s spanNodeByStartTime) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s spanNodeByStartTime) Less(i, j int) bool {
+	a, b := s[i].Span, s[j].Span
+	if !a.StartTime.Equal(b.StartTime) {
+		return a.StartTime.Before(b.StartTime)
+	}
+	return a.SpanID < b.SpanID
+}
+
+// SortSpanNodes sorts span nodes by start time, breaking ties by SpanID,
+// so that siblings are ordered the same way regardless of how they were stored.
+func SortSpanNodes(nodes []*SpanNode) {
+	sort.Sort(spanNodeByStartTime(nodes))
+}
+
 // SortSpan deep sorts a span: this sorts its tags, logs by timestamp, tags in logs, and tags in process.
 func SortSpan(span *Span) {
 	span.NormalizeTimestamps()
@@ -140,3 +140,12 @@ func TestSortByTraceID(t *testing.T) {
 	SortTraceIDs(traces)
 	assert.EqualValues(t, tracesExpected, traces)
 }
+
+func TestSortSpanNodes(t *testing.T) {
+	a := &SpanNode{Span: &Span{SpanID: 3, StartTime: currTime}}
+	b := &SpanNode{Span: &Span{SpanID: 2, StartTime: currTime}}
+	c := &SpanNode{Span: &Span{SpanID: 1, StartTime: currTime.Add(time.Second)}}
+	nodes := []*SpanNode{c, a, b}
+	SortSpanNodes(nodes)
+	assert.Equal(t, []*SpanNode{b, a, c}, nodes)
+}
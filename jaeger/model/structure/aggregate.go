@@ -0,0 +1,172 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package structure
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/stats"
+)
+
+const (
+	// AggregateCountTag holds the number of spans a synthetic span stands for.
+	AggregateCountTag = "aggregate.count"
+	// AggregateP50Tag holds the median duration of the spans a synthetic span stands for, in microseconds.
+	AggregateP50Tag = "aggregate.duration.p50_us"
+	// AggregateP99Tag holds the 99th percentile duration of the spans a synthetic span stands for, in microseconds.
+	AggregateP99Tag = "aggregate.duration.p99_us"
+	// HighlightTag marks the spans of a synthetic trace that deserve attention.
+	HighlightTag = "aggregate.highlight"
+)
+
+type aggregateSpan struct {
+	template   *model.Span
+	parentPath string
+	refType    model.SpanRefType
+	offsets    []time.Duration
+	durations  []time.Duration
+}
+
+// Aggregate synthesizes a trace with the given ID standing for all the traces, which are
+// expected to belong to the same structural group. Each synthetic span stands for the spans
+// found at the same path in the traces, see Paths: it starts at their mean offset from the
+// start of their parent, and lasts their mean duration. Service, operation and reference
+// type are taken from the first span found at the path, while tags and logs are replaced
+// with statistics of the aggregated durations.
+func Aggregate(traces []*model.Trace, traceID model.TraceID) *model.Trace {
+	var base time.Time
+	var order []string
+	spans := make(map[string]*aggregateSpan)
+	for i, trace := range traces {
+		tree := model.NewTraceTree(trace)
+		if len(tree.Roots) == 0 {
+			continue
+		}
+		traceStart := tree.Roots[0].Span.StartTime
+		if i == 0 {
+			base = traceStart
+		}
+		paths := Paths(tree)
+		tree.Walk(func(n *model.SpanNode) {
+			path := paths[n]
+			a, ok := spans[path]
+			if !ok {
+				a = &aggregateSpan{template: n.Span}
+				if n.Parent != nil {
+					a.parentPath = paths[n.Parent]
+					a.refType = refType(n.Span, n.Parent.Span.SpanID)
+				}
+				spans[path] = a
+				order = append(order, path)
+			}
+			parentStart := traceStart
+			if n.Parent != nil {
+				parentStart = n.Parent.Span.StartTime
+			}
+			a.offsets = append(a.offsets, n.Span.StartTime.Sub(parentStart))
+			a.durations = append(a.durations, n.Span.Duration)
+		})
+	}
+
+	trace := &model.Trace{
+		Warnings: []string{fmt.Sprintf("synthetic trace aggregating %d traces", len(traces))},
+	}
+	spanIDs := make(map[string]model.SpanID, len(order))
+	startTimes := make(map[string]time.Time, len(order))
+	// parents are always visited before their children, so they come first in order
+	for i, path := range order {
+		a := spans[path]
+		spanID := model.NewSpanID(uint64(i + 1))
+		startTime := base
+		if a.parentPath != "" {
+			startTime = startTimes[a.parentPath]
+		}
+		startTime = startTime.Add(stats.Summarize(a.offsets).Mean)
+		spanIDs[path] = spanID
+		startTimes[path] = startTime
+
+		durations := stats.Summarize(a.durations)
+		span := &model.Span{
+			TraceID:       traceID,
+			SpanID:        spanID,
+			OperationName: a.template.OperationName,
+			StartTime:     startTime,
+			Duration:      durations.Mean,
+			Process:       a.template.Process,
+			Tags: model.KeyValues{
+				model.Int64(AggregateCountTag, int64(durations.Count)),
+				model.Int64(AggregateP50Tag, int64(durations.P50/time.Microsecond)),
+				model.Int64(AggregateP99Tag, int64(durations.P99/time.Microsecond)),
+			},
+		}
+		if a.parentPath != "" {
+			span.References = []model.SpanRef{{TraceID: traceID, SpanID: spanIDs[a.parentPath], RefType: a.refType}}
+		}
+		trace.Spans = append(trace.Spans, span)
+	}
+	return trace
+}
+
+// refType returns the type of the reference from the span to its parent.
+func refType(span *model.Span, parentID model.SpanID) model.SpanRefType {
+	for _, ref := range span.References {
+		if ref.TraceID == span.TraceID && ref.SpanID == parentID {
+			return ref.RefType
+		}
+	}
+	return model.ChildOf
+}
+
+// Highlight tags the span found at the path in the trace with HighlightTag. When subspan
+// is not negative, it also adds a highlighted child to that span, covering its subspan-th
+// gap between children, see model.SpanBreakdown. It returns false when there is no such
+// span or gap, in which case the trace is left untouched.
+func Highlight(trace *model.Trace, path string, subspan int) bool {
+	tree := model.NewTraceTree(trace)
+	for n, p := range Paths(tree) {
+		if p != path {
+			continue
+		}
+		if subspan < 0 {
+			n.Span.Tags = append(n.Span.Tags, model.Bool(HighlightTag, true))
+			return true
+		}
+		gaps := n.Breakdown().Gaps
+		if subspan >= len(gaps) {
+			return false
+		}
+		var maxSpanID model.SpanID
+		for _, span := range trace.Spans {
+			if span.SpanID > maxSpanID {
+				maxSpanID = span.SpanID
+			}
+		}
+		n.Span.Tags = append(n.Span.Tags, model.Bool(HighlightTag, true))
+		trace.Spans = append(trace.Spans, &model.Span{
+			TraceID:       n.Span.TraceID,
+			SpanID:        maxSpanID + 1,
+			OperationName: fmt.Sprintf("subspan #%d", subspan),
+			References:    []model.SpanRef{model.NewChildOfRef(n.Span.TraceID, n.Span.SpanID)},
+			StartTime:     gaps[subspan].Start,
+			Duration:      gaps[subspan].Duration(),
+			Process:       n.Span.Process,
+			Tags:          model.KeyValues{model.Bool(HighlightTag, true)},
+		})
+		return true
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package structure
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestAggregate(t *testing.T) {
+	fast := sequentialTrace(time.Millisecond)
+	slow := sequentialTrace(3 * time.Millisecond)
+	slow.Spans[0].References = []model.SpanRef{model.NewFollowsFromRef(traceID, model.NewSpanID(1))}
+	aggregateID := model.NewTraceID(7, 7)
+
+	trace := Aggregate([]*model.Trace{fast, slow}, aggregateID)
+	require.Len(t, trace.Spans, 3)
+	assert.Equal(t, []string{"synthetic trace aggregating 2 traces"}, trace.Warnings)
+	assert.Equal(t, Signature(fast), Signature(trace))
+
+	root, first, second := trace.Spans[0], trace.Spans[1], trace.Spans[2]
+	assert.Equal(t, aggregateID, root.TraceID)
+	assert.Equal(t, "GET", root.OperationName)
+	assert.Equal(t, "frontend", root.Process.ServiceName)
+	assert.Equal(t, start, root.StartTime)
+	assert.Equal(t, 20*time.Millisecond, root.Duration)
+	assert.Empty(t, root.References)
+	count, _ := model.KeyValues(root.Tags).FindByKey(AggregateCountTag)
+	assert.EqualValues(t, 2, count.Int64())
+	p99, _ := model.KeyValues(root.Tags).FindByKey(AggregateP99Tag)
+	assert.EqualValues(t, 29800, p99.Int64())
+
+	assert.Equal(t, "query", first.OperationName)
+	assert.Equal(t, start.Add(2*time.Millisecond), first.StartTime)
+	assert.Equal(t, 8*time.Millisecond, first.Duration)
+	assert.Equal(t, []model.SpanRef{model.NewChildOfRef(aggregateID, root.SpanID)}, first.References)
+
+	assert.Equal(t, start.Add(12*time.Millisecond), second.StartTime)
+	assert.Equal(t, 4*time.Millisecond, second.Duration)
+	// the reference type comes from the first trace
+	assert.Equal(t, model.ChildOf, second.References[0].RefType)
+}
+
+func TestHighlight(t *testing.T) {
+	trace := sequentialTrace(time.Millisecond)
+	assert.False(t, Highlight(trace, "frontend:GET#0~db:query#2", -1))
+	assert.False(t, Highlight(trace, "frontend:GET#0", 3))
+	assert.Len(t, trace.Spans, 3)
+
+	assert.True(t, Highlight(trace, "frontend:GET#0~db:query#1", -1))
+	_, ok := model.KeyValues(trace.Spans[0].Tags).FindByKey(HighlightTag)
+	assert.True(t, ok)
+
+	assert.True(t, Highlight(trace, "frontend:GET#0", 1))
+	_, ok = model.KeyValues(trace.Spans[1].Tags).FindByKey(HighlightTag)
+	assert.True(t, ok)
+	require.Len(t, trace.Spans, 4)
+	subspan := trace.Spans[3]
+	assert.Equal(t, model.NewSpanID(4), subspan.SpanID)
+	assert.Equal(t, "subspan #1", subspan.OperationName)
+	assert.Equal(t, model.NewSpanID(1), subspan.ParentSpanID())
+	assert.Equal(t, start.Add(5*time.Millisecond), subspan.StartTime)
+	assert.Equal(t, time.Millisecond, subspan.Duration)
+}
@@ -0,0 +1,195 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package structure
+
+import (
+	"sort"
+	"time"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// TraceDiff compares two traces span by span, the spans being aligned by structural path.
+type TraceDiff struct {
+	// DurationDelta is the end-to-end duration of the current trace minus the baseline's.
+	DurationDelta time.Duration
+	// Missing and Extra count the paths found only in the baseline and only in the current trace.
+	Missing int
+	Extra   int
+	// Spans are ordered depth-first, siblings by start offset.
+	Spans []SpanDiff
+}
+
+// SpanDiff compares the spans found at the same path in two traces, see Paths.
+type SpanDiff struct {
+	Path string
+	// Baseline and Current are nil when the path is missing from the trace.
+	Baseline *model.Span
+	Current  *model.Span
+	// BaselineOffset and CurrentOffset are the start offsets of the spans from the start of
+	// their parent, or from the start of the trace for the roots.
+	BaselineOffset time.Duration
+	CurrentOffset  time.Duration
+	// Tags lists the span tags that differ, by key.
+	Tags []TagDiff
+}
+
+// TagDiff is a span tag whose value differs between two spans.
+type TagDiff struct {
+	Key string
+	// Baseline and Current are nil when the tag is missing from the span.
+	Baseline *model.KeyValue
+	Current  *model.KeyValue
+}
+
+// DurationDelta is the duration of the current span minus the baseline's, or zero when
+// either is missing.
+func (d SpanDiff) DurationDelta() time.Duration {
+	if d.Baseline == nil || d.Current == nil {
+		return 0
+	}
+	return d.Current.Duration - d.Baseline.Duration
+}
+
+// StartOffsetDelta is the start offset of the current span minus the baseline's, or zero
+// when either is missing.
+func (d SpanDiff) StartOffsetDelta() time.Duration {
+	if d.Baseline == nil || d.Current == nil {
+		return 0
+	}
+	return d.CurrentOffset - d.BaselineOffset
+}
+
+// Diff aligns the spans of the two traces by structural path and compares them. Unlike the
+// structural groups, the traces may have different call trees.
+func Diff(baseline, current *model.Trace) *TraceDiff {
+	d := &TraceDiff{DurationDelta: current.Duration() - baseline.Duration()}
+	baselineTree, currentTree := model.NewTraceTree(baseline), model.NewTraceTree(current)
+	d.diffSiblings("", baselineTree.Roots, currentTree.Roots, earliestStart(baselineTree.Roots), earliestStart(currentTree.Roots))
+	return d
+}
+
+func earliestStart(nodes []*model.SpanNode) time.Time {
+	var earliest time.Time
+	for _, n := range nodes {
+		if earliest.IsZero() || n.Span.StartTime.Before(earliest) {
+			earliest = n.Span.StartTime
+		}
+	}
+	return earliest
+}
+
+func (d *TraceDiff) diffSiblings(prefix string, baseline, current []*model.SpanNode, baselineStart, currentStart time.Time) {
+	byPath := make(map[string]*SpanDiff)
+	var diffs []*SpanDiff
+	for _, side := range []struct {
+		nodes   []*model.SpanNode
+		start   time.Time
+		current bool
+	}{
+		{nodes: baseline, start: baselineStart},
+		{nodes: current, start: currentStart, current: true},
+	} {
+		for _, sibling := range indexSiblings(side.nodes) {
+			path := sibling.path(prefix)
+			diff := byPath[path]
+			if diff == nil {
+				diff = &SpanDiff{Path: path}
+				byPath[path] = diff
+				diffs = append(diffs, diff)
+			}
+			offset := sibling.node.Span.StartTime.Sub(side.start)
+			if side.current {
+				diff.Current, diff.CurrentOffset = sibling.node.Span, offset
+			} else {
+				diff.Baseline, diff.BaselineOffset = sibling.node.Span, offset
+			}
+		}
+	}
+	sort.SliceStable(diffs, func(i, j int) bool {
+		oi, oj := diffs[i].offset(), diffs[j].offset()
+		if oi != oj {
+			return oi < oj
+		}
+		return diffs[i].Path < diffs[j].Path
+	})
+
+	nodes := make(map[*model.Span]*model.SpanNode, len(baseline)+len(current))
+	for _, n := range baseline {
+		nodes[n.Span] = n
+	}
+	for _, n := range current {
+		nodes[n.Span] = n
+	}
+	for _, diff := range diffs {
+		var baselineChildren, currentChildren []*model.SpanNode
+		var baselineParentStart, currentParentStart time.Time
+		switch {
+		case diff.Baseline == nil:
+			d.Extra++
+		case diff.Current == nil:
+			d.Missing++
+		default:
+			diff.Tags = diffTags(diff.Baseline.Tags, diff.Current.Tags)
+		}
+		if diff.Baseline != nil {
+			baselineChildren, baselineParentStart = nodes[diff.Baseline].Children, diff.Baseline.StartTime
+		}
+		if diff.Current != nil {
+			currentChildren, currentParentStart = nodes[diff.Current].Children, diff.Current.StartTime
+		}
+		d.Spans = append(d.Spans, *diff)
+		d.diffSiblings(diff.Path+"~", baselineChildren, currentChildren, baselineParentStart, currentParentStart)
+	}
+}
+
+// offset is the start offset of the current span, or of the baseline span when the current
+// one is missing, which places the missing spans among their siblings.
+func (d *SpanDiff) offset() time.Duration {
+	if d.Current != nil {
+		return d.CurrentOffset
+	}
+	return d.BaselineOffset
+}
+
+func diffTags(baseline, current model.KeyValues) []TagDiff {
+	keys := make(map[string]struct{})
+	for _, kv := range baseline {
+		keys[kv.Key] = struct{}{}
+	}
+	for _, kv := range current {
+		keys[kv.Key] = struct{}{}
+	}
+	var diffs []TagDiff
+	for key := range keys {
+		b, inBaseline := baseline.FindByKey(key)
+		c, inCurrent := current.FindByKey(key)
+		if inBaseline && inCurrent && b.Equal(&c) {
+			continue
+		}
+		diff := TagDiff{Key: key}
+		if inBaseline {
+			diff.Baseline = &b
+		}
+		if inCurrent {
+			diff.Current = &c
+		}
+		diffs = append(diffs, diff)
+	}
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i].Key < diffs[j].Key
+	})
+	return diffs
+}
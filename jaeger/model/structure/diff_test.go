@@ -0,0 +1,103 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package structure
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestDiff(t *testing.T) {
+	baseline := sequentialTrace(time.Millisecond)
+	baseline.Spans[1].Tags = []model.KeyValue{model.Int64("http.status_code", 200), model.String("region", "us")}
+	baseline.Spans = append(baseline.Spans, newSpan(4, 3, "disk", "read", 7*time.Millisecond, time.Millisecond))
+	current := &model.Trace{
+		Spans: []*model.Span{
+			newSpan(1, 0, "frontend", "GET", 0, 15*time.Millisecond),
+			newSpan(2, 1, "db", "query", 2*time.Millisecond, 6*time.Millisecond),
+			newSpan(3, 1, "cache", "get", 9*time.Millisecond, time.Millisecond),
+		},
+	}
+	current.Spans[0].Tags = []model.KeyValue{model.Int64("http.status_code", 500), model.Bool("retry", true)}
+
+	diff := Diff(baseline, current)
+	assert.Equal(t, 5*time.Millisecond, diff.DurationDelta)
+	assert.Equal(t, 2, diff.Missing)
+	assert.Equal(t, 1, diff.Extra)
+
+	paths := make([]string, len(diff.Spans))
+	for i, span := range diff.Spans {
+		paths[i] = span.Path
+	}
+	require.Equal(t, []string{
+		"frontend:GET#0",
+		"frontend:GET#0~db:query#0",
+		"frontend:GET#0~db:query#1",
+		"frontend:GET#0~db:query#1~disk:read#0",
+		"frontend:GET#0~cache:get#0",
+	}, paths)
+
+	root := diff.Spans[0]
+	assert.Equal(t, baseline.Spans[1], root.Baseline)
+	assert.Equal(t, current.Spans[0], root.Current)
+	assert.Equal(t, 5*time.Millisecond, root.DurationDelta())
+	assert.Equal(t, time.Duration(0), root.StartOffsetDelta())
+	status200, status500 := model.Int64("http.status_code", 200), model.Int64("http.status_code", 500)
+	region, retry := model.String("region", "us"), model.Bool("retry", true)
+	assert.Equal(t, []TagDiff{
+		{Key: "http.status_code", Baseline: &status200, Current: &status500},
+		{Key: "region", Baseline: &region},
+		{Key: "retry", Current: &retry},
+	}, root.Tags)
+
+	query := diff.Spans[1]
+	assert.Equal(t, 2*time.Millisecond, query.DurationDelta())
+	assert.Equal(t, time.Millisecond, query.BaselineOffset)
+	assert.Equal(t, 2*time.Millisecond, query.CurrentOffset)
+	assert.Equal(t, time.Millisecond, query.StartOffsetDelta())
+	assert.Empty(t, query.Tags)
+
+	missing := diff.Spans[2]
+	assert.Equal(t, baseline.Spans[0], missing.Baseline)
+	assert.Nil(t, missing.Current)
+	assert.Equal(t, time.Duration(0), missing.DurationDelta())
+	assert.Equal(t, time.Duration(0), missing.StartOffsetDelta())
+	assert.Equal(t, time.Millisecond, diff.Spans[3].BaselineOffset)
+
+	extra := diff.Spans[4]
+	assert.Nil(t, extra.Baseline)
+	assert.Equal(t, current.Spans[2], extra.Current)
+	assert.Equal(t, 9*time.Millisecond, extra.CurrentOffset)
+}
+
+func TestDiffIdentical(t *testing.T) {
+	diff := Diff(sequentialTrace(time.Millisecond), sequentialTrace(time.Millisecond))
+	assert.Equal(t, time.Duration(0), diff.DurationDelta)
+	assert.Zero(t, diff.Missing)
+	assert.Zero(t, diff.Extra)
+	assert.Len(t, diff.Spans, 3)
+	for _, span := range diff.Spans {
+		assert.NotNil(t, span.Baseline)
+		assert.NotNil(t, span.Current)
+		assert.Zero(t, span.DurationDelta())
+		assert.Zero(t, span.StartOffsetDelta())
+		assert.Empty(t, span.Tags)
+	}
+}
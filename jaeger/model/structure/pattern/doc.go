@@ -0,0 +1,48 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pattern implements a small query language matching traces by the shape of
+// their span tree, rather than by the attributes of a single span.
+//
+// A pattern is a chain of span selectors linked by the child (>) or descendant (>>)
+// relation, and several chains can be required at once with "and":
+//
+//     pattern   ::= chain ( ( 'and' | '&&' ) chain )*
+//     chain     ::= step ( ( '>' | '>>' ) step )*
+//     step      ::= selector ( '#' compare number )?
+//     selector  ::= '*' | name | '{' ( predicate ( ',' predicate )* )? '}'
+//     name      ::= value -- 'service' or 'service:operation'
+//     predicate ::= field ( '=' | '!=' | '=~' | '!~' | compare ) value
+//     compare   ::= '=' | '!=' | '<' | '<=' | '>' | '>='
+//     field     ::= 'service' | 'operation' | 'duration' | tag key -- quoted fields are tag keys
+//     value     ::= word | '"' quoted string '"'
+//
+// The spans matching a step are searched among all the spans of the trace for the first
+// step of a chain, and among the children or the descendants of the span matching the
+// previous step otherwise. A step matches when the number of spans satisfying its selector
+// and the rest of the chain satisfies its count, one or more by default. For example:
+//
+//     A > B > C #>3                   A calls B, which calls C more than 3 times
+//     frontend:GET > {error=true}     frontend:GET has a child with error=true
+//     {operation=Z} >> {operation=Y}  the trace contains operation Y under operation Z
+//     {duration>1s} and db #=0        a span takes over a second and no span is from db
+//
+// Words are made of letters, digits and the characters _.-:/@; any other value, such as
+// most regular expressions, must be quoted. Durations are written as Go durations, e.g.
+// 150ms. Tags are looked up in the span tags, then in the process tags, and are compared
+// numerically by the ordering operators.
+//
+// A chain has at most 8 steps and a pattern at most 32, so that matching stays cheap on
+// large traces.
+package pattern
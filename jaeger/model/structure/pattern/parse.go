@@ -0,0 +1,310 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pattern
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// maxChainSteps bounds the depth of the span tree searched by a chain.
+	maxChainSteps = 8
+	// maxSteps bounds the number of steps of all the chains of a pattern.
+	maxSteps = 32
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenString
+	tokenSymbol
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	value string
+	pos   int
+}
+
+// symbols lists the punctuation of the language, longest first.
+var symbols = []string{">>", ">=", "<=", "!=", "=~", "!~", "&&", "{", "}", ",", "*", "#", ">", "<", "="}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_.-:/@", r)
+}
+
+func tokenize(input string) ([]token, error) {
+	var tokens []token
+	for pos := 0; pos < len(input); {
+		rest := input[pos:]
+		r, size := utf8.DecodeRuneInString(rest)
+		switch {
+		case unicode.IsSpace(r):
+			pos += size
+		case r == '"':
+			end := 1
+			for ; end < len(rest) && rest[end] != '"'; end++ {
+				if rest[end] == '\\' {
+					end++
+				}
+			}
+			if end >= len(rest) {
+				return nil, fmt.Errorf("unterminated string at offset %d", pos)
+			}
+			value, err := strconv.Unquote(rest[:end+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid string at offset %d: %v", pos, err)
+			}
+			tokens = append(tokens, token{kind: tokenString, text: rest[:end+1], value: value, pos: pos})
+			pos += end + 1
+		case isWordRune(r):
+			end := strings.IndexFunc(rest, func(r rune) bool { return !isWordRune(r) })
+			if end < 0 {
+				end = len(rest)
+			}
+			tokens = append(tokens, token{kind: tokenWord, text: rest[:end], value: rest[:end], pos: pos})
+			pos += end
+		default:
+			symbol := ""
+			for _, s := range symbols {
+				if strings.HasPrefix(rest, s) {
+					symbol = s
+					break
+				}
+			}
+			if symbol == "" {
+				return nil, fmt.Errorf("unexpected character %q at offset %d", r, pos)
+			}
+			tokens = append(tokens, token{kind: tokenSymbol, text: symbol, value: symbol, pos: pos})
+			pos += len(symbol)
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(input)}), nil
+}
+
+type parser struct {
+	tokens []token
+	next   int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.next]
+}
+
+func (p *parser) advance() token {
+	t := p.tokens[p.next]
+	if t.kind != tokenEOF {
+		p.next++
+	}
+	return t
+}
+
+func (p *parser) isSymbol(symbols ...string) bool {
+	t := p.peek()
+	if t.kind != tokenSymbol {
+		return false
+	}
+	for _, s := range symbols {
+		if t.text == s {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	t := p.peek()
+	found := "end of pattern"
+	if t.kind != tokenEOF {
+		found = strconv.Quote(t.text)
+	}
+	return fmt.Errorf("%s, found %s at offset %d", fmt.Sprintf(format, args...), found, t.pos)
+}
+
+// Parse parses a pattern, see the package documentation for the syntax.
+func Parse(input string) (*Pattern, error) {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	pattern := &Pattern{text: strings.TrimSpace(input)}
+	steps := 0
+	for {
+		start := p.peek().pos
+		chain, err := p.parseChain()
+		if err != nil {
+			return nil, err
+		}
+		if steps += len(chain); steps > maxSteps {
+			return nil, fmt.Errorf("pattern has more than %d steps at offset %d", maxSteps, start)
+		}
+		pattern.chains = append(pattern.chains, chain)
+		if t := p.peek(); p.isSymbol("&&") || (t.kind == tokenWord && t.text == "and") {
+			p.advance()
+			continue
+		}
+		if p.peek().kind != tokenEOF {
+			return nil, p.errorf("expected '>', '>>' or 'and'")
+		}
+		return pattern, nil
+	}
+}
+
+func (p *parser) parseChain() ([]step, error) {
+	var chain []step
+	relation := relationAny
+	for {
+		step, err := p.parseStep(relation)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, step)
+		switch {
+		case p.isSymbol(">"):
+			relation = relationChild
+		case p.isSymbol(">>"):
+			relation = relationDescendant
+		default:
+			return chain, nil
+		}
+		if len(chain) == maxChainSteps {
+			return nil, p.errorf("chain has more than %d steps", maxChainSteps)
+		}
+		p.advance()
+	}
+}
+
+func (p *parser) parseStep(relation relation) (step, error) {
+	s := step{relation: relation, count: compareInt{op: ">=", value: 1}}
+	var err error
+	if s.predicates, err = p.parseSelector(); err != nil {
+		return s, err
+	}
+	if !p.isSymbol("#") {
+		return s, nil
+	}
+	p.advance()
+	if !p.isSymbol("=", "!=", "<", "<=", ">", ">=") {
+		return s, p.errorf("expected a comparison after '#'")
+	}
+	s.count.op = p.advance().text
+	t := p.peek()
+	if s.count.value, err = strconv.Atoi(t.value); err != nil || t.kind != tokenWord || s.count.value < 0 {
+		return s, p.errorf("expected a span count")
+	}
+	p.advance()
+	return s, nil
+}
+
+func (p *parser) parseSelector() ([]predicate, error) {
+	t := p.peek()
+	switch {
+	case p.isSymbol("*"):
+		p.advance()
+		return nil, nil
+	case t.kind == tokenWord || t.kind == tokenString:
+		p.advance()
+		service, operation := t.value, ""
+		if i := strings.Index(t.value, ":"); i >= 0 {
+			service, operation = t.value[:i], t.value[i+1:]
+		}
+		predicates := []predicate{{field: fieldService, op: "=", value: service}}
+		if operation != "" {
+			predicates = append(predicates, predicate{field: fieldOperation, op: "=", value: operation})
+		}
+		return predicates, nil
+	case p.isSymbol("{"):
+		p.advance()
+		var predicates []predicate
+		for !p.isSymbol("}") {
+			if len(predicates) > 0 {
+				if !p.isSymbol(",") {
+					return nil, p.errorf("expected ',' or '}'")
+				}
+				p.advance()
+			}
+			predicate, err := p.parsePredicate()
+			if err != nil {
+				return nil, err
+			}
+			predicates = append(predicates, predicate)
+		}
+		p.advance()
+		return predicates, nil
+	}
+	return nil, p.errorf("expected a span selector")
+}
+
+func (p *parser) parsePredicate() (predicate, error) {
+	var pr predicate
+	t := p.peek()
+	if t.kind != tokenWord && t.kind != tokenString {
+		return pr, p.errorf("expected a field")
+	}
+	p.advance()
+	pr.field = t.value
+	if t.kind == tokenString {
+		// a quoted field is always a tag, so that tags can be named like the span fields
+		pr.field = tagPrefix + t.value
+	} else if pr.field != fieldService && pr.field != fieldOperation && pr.field != fieldDuration {
+		pr.field = tagPrefix + pr.field
+	}
+	if !p.isSymbol("=", "!=", "=~", "!~", "<", "<=", ">", ">=") {
+		return pr, p.errorf("expected an operator after %q", t.value)
+	}
+	opToken := p.peek()
+	pr.op = p.advance().text
+	t = p.peek()
+	if t.kind != tokenWord && t.kind != tokenString {
+		return pr, p.errorf("expected a value")
+	}
+	p.advance()
+	pr.value = t.value
+
+	var err error
+	switch {
+	case pr.op == "=~" || pr.op == "!~":
+		if pr.field == fieldDuration {
+			err = fmt.Errorf("duration cannot be matched by %s", pr.op)
+		} else if pr.regexp, err = regexp.Compile(pr.value); err != nil {
+			err = fmt.Errorf("invalid regular expression %q: %v", pr.value, err)
+		}
+	case pr.field == fieldDuration:
+		if pr.duration, err = time.ParseDuration(pr.value); err != nil {
+			err = fmt.Errorf("invalid duration %q", pr.value)
+		}
+	case pr.op == "=" || pr.op == "!=":
+	case pr.field == fieldService || pr.field == fieldOperation:
+		err = fmt.Errorf("%s cannot be compared by %s", pr.field, pr.op)
+	default:
+		if pr.number, err = strconv.ParseFloat(pr.value, 64); err != nil {
+			err = fmt.Errorf("invalid number %q", pr.value)
+		}
+	}
+	if err != nil {
+		return pr, fmt.Errorf("%v at offset %d", err, opToken.pos)
+	}
+	return pr, nil
+}
@@ -0,0 +1,239 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pattern
+
+import (
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+const (
+	fieldService   = "service"
+	fieldOperation = "operation"
+	fieldDuration  = "duration"
+
+	// tagPrefix marks the predicates on tags, so that a tag is never mistaken for a span field.
+	tagPrefix = "tag:"
+)
+
+type relation int
+
+const (
+	// relationAny links the first step of a chain to all the spans of the trace.
+	relationAny relation = iota
+	relationChild
+	relationDescendant
+)
+
+// Pattern is a parsed span tree pattern.
+type Pattern struct {
+	text string
+	// chains must all match the trace.
+	chains [][]step
+}
+
+type step struct {
+	relation   relation
+	predicates []predicate
+	count      compareInt
+}
+
+type predicate struct {
+	field    string
+	op       string
+	value    string
+	regexp   *regexp.Regexp
+	duration time.Duration
+	number   float64
+}
+
+type compareInt struct {
+	op    string
+	value int
+}
+
+// String returns the pattern as it was parsed.
+func (p *Pattern) String() string {
+	return p.text
+}
+
+// Match returns whether the span tree of the trace matches all the chains of the pattern.
+func (p *Pattern) Match(trace *model.Trace) bool {
+	var spans []*model.SpanNode
+	model.NewTraceTree(trace).Walk(func(n *model.SpanNode) {
+		spans = append(spans, n)
+	})
+	for _, chain := range p.chains {
+		m := &matcher{chain: chain, memo: make(map[matchKey]bool)}
+		if !m.matchStep(0, spans) {
+			return false
+		}
+	}
+	return true
+}
+
+type matchKey struct {
+	step int
+	node *model.SpanNode
+}
+
+// matcher matches a chain against the span tree, remembering whether each span matches
+// each step and the rest of the chain, so that every pair is evaluated at most once.
+type matcher struct {
+	chain []step
+	memo  map[matchKey]bool
+}
+
+// matchStep returns whether the number of candidate spans matching the step of the chain,
+// and the rest of the chain from there, satisfies the count of the step. It stops as soon
+// as the remaining candidates cannot change the outcome.
+func (m *matcher) matchStep(i int, candidates []*model.SpanNode) bool {
+	count := m.chain[i].count
+	matched := 0
+	for j, n := range candidates {
+		if m.matchSpan(i, n) {
+			matched++
+		}
+		low, high := matched-count.value, matched+len(candidates)-j-1-count.value
+		if decided(count.op, low, high) {
+			return compare(count.op, low)
+		}
+	}
+	return compare(count.op, matched-count.value)
+}
+
+func (m *matcher) matchSpan(i int, n *model.SpanNode) bool {
+	key := matchKey{step: i, node: n}
+	if match, ok := m.memo[key]; ok {
+		return match
+	}
+	match := m.chain[i].matchSpan(n.Span) &&
+		(i == len(m.chain)-1 || m.matchStep(i+1, related(n, m.chain[i+1].relation)))
+	m.memo[key] = match
+	return match
+}
+
+// decided returns whether the comparison has the same outcome for all the differences
+// between low and high.
+func decided(op string, low, high int) bool {
+	if op == "=" || op == "!=" {
+		return low > 0 || high < 0 || low == high
+	}
+	return compare(op, low) == compare(op, high)
+}
+
+func related(n *model.SpanNode, relation relation) []*model.SpanNode {
+	if relation == relationChild {
+		return n.Children
+	}
+	var descendants []*model.SpanNode
+	var walk func(n *model.SpanNode)
+	walk = func(n *model.SpanNode) {
+		for _, child := range n.Children {
+			descendants = append(descendants, child)
+			walk(child)
+		}
+	}
+	walk(n)
+	return descendants
+}
+
+func (s step) matchSpan(span *model.Span) bool {
+	for _, p := range s.predicates {
+		if !p.matchSpan(span) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p predicate) matchSpan(span *model.Span) bool {
+	switch p.field {
+	case fieldService:
+		return p.matchString(span.Process.GetServiceName())
+	case fieldOperation:
+		return p.matchString(span.OperationName)
+	case fieldDuration:
+		return compare(p.op, int(span.Duration-p.duration))
+	}
+	tag, ok := findTag(span, p.field[len(tagPrefix):])
+	if !ok {
+		return p.op == "!=" || p.op == "!~"
+	}
+	switch p.op {
+	case "=", "!=", "=~", "!~":
+		return p.matchString(tag.AsString())
+	}
+	var number float64
+	switch tag.VType {
+	case model.Int64Type:
+		number = float64(tag.Int64())
+	case model.Float64Type:
+		number = tag.Float64()
+	case model.StringType:
+		var err error
+		if number, err = strconv.ParseFloat(tag.VStr, 64); err != nil {
+			return false
+		}
+	default:
+		return false
+	}
+	switch {
+	case number < p.number:
+		return compare(p.op, -1)
+	case number > p.number:
+		return compare(p.op, 1)
+	}
+	return compare(p.op, 0)
+}
+
+func (p predicate) matchString(value string) bool {
+	switch p.op {
+	case "=":
+		return value == p.value
+	case "!=":
+		return value != p.value
+	case "=~":
+		return p.regexp.MatchString(value)
+	}
+	return !p.regexp.MatchString(value)
+}
+
+// compare applies the comparison operator to the sign of the difference of its operands.
+func compare(op string, diff int) bool {
+	switch op {
+	case "=":
+		return diff == 0
+	case "!=":
+		return diff != 0
+	case "<":
+		return diff < 0
+	case "<=":
+		return diff <= 0
+	case ">":
+		return diff > 0
+	}
+	return diff >= 0
+}
+
+func findTag(span *model.Span, key string) (model.KeyValue, bool) {
+	if kv, ok := model.KeyValues(span.Tags).FindByKey(key); ok {
+		return kv, true
+	}
+	return model.KeyValues(span.Process.GetTags()).FindByKey(key)
+}
@@ -0,0 +1,170 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pattern
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+var traceID = model.NewTraceID(0, 1)
+
+func makeSpan(id, parent uint64, service, operation string, duration time.Duration, tags ...model.KeyValue) *model.Span {
+	span := &model.Span{
+		TraceID:       traceID,
+		SpanID:        model.NewSpanID(id),
+		OperationName: operation,
+		StartTime:     time.Unix(0, int64(id)),
+		Duration:      duration,
+		Tags:          tags,
+		Process:       &model.Process{ServiceName: service, Tags: []model.KeyValue{model.String("hostname", service+"-1")}},
+	}
+	if parent != 0 {
+		span.References = []model.SpanRef{model.NewChildOfRef(traceID, model.NewSpanID(parent))}
+	}
+	return span
+}
+
+// makeTrace returns frontend:GET calling cache:get and api:call, which queries db four times.
+func makeTrace() *model.Trace {
+	return &model.Trace{Spans: []*model.Span{
+		makeSpan(1, 0, "frontend", "GET", 2*time.Second, model.Int64("http.status_code", 200), model.String("http.route", "/users/{id}")),
+		makeSpan(2, 1, "cache", "get", time.Millisecond, model.Bool("error", true)),
+		makeSpan(3, 1, "api", "call", time.Second),
+		makeSpan(4, 3, "db", "query", 100*time.Millisecond, model.Float64("rows", 1.5)),
+		makeSpan(5, 3, "db", "query", 200*time.Millisecond, model.String("rows", "20")),
+		makeSpan(6, 3, "db", "query", 300*time.Millisecond),
+		makeSpan(7, 3, "db", "query", 400*time.Millisecond),
+	}}
+}
+
+func TestMatch(t *testing.T) {
+	testCases := []struct {
+		pattern string
+		match   bool
+	}{
+		{pattern: "*", match: true},
+		{pattern: "{}", match: true},
+		{pattern: "frontend", match: true},
+		{pattern: "frontend:GET", match: true},
+		{pattern: `"frontend:GET"`, match: true},
+		{pattern: "frontend:POST", match: false},
+		{pattern: "frontend > api > db", match: true},
+		{pattern: "frontend > db", match: false},
+		{pattern: "frontend >> db", match: true},
+		{pattern: "frontend > api > db #>3", match: true},
+		{pattern: "frontend > api > db #>4", match: false},
+		{pattern: "frontend > api > db #=4", match: true},
+		{pattern: "db #=4", match: true},
+		{pattern: "db > *", match: false},
+		{pattern: "db > * #=0", match: true},
+		{pattern: "payments #=0", match: true},
+		{pattern: "frontend > {error=true}", match: true},
+		{pattern: "api > {error=true}", match: false},
+		{pattern: "{operation=GET} >> {operation=query}", match: true},
+		{pattern: "{operation=query} >> {operation=GET}", match: false},
+		{pattern: "frontend >> {service=db, duration>=300ms} #=2", match: true},
+		{pattern: "{duration>1s}", match: true},
+		{pattern: "{duration>2s}", match: false},
+		{pattern: "{duration<2ms, service!=cache}", match: false},
+		{pattern: `{service=~"^d"}`, match: true},
+		{pattern: `{service!~"^(frontend|cache|api|db)$"}`, match: false},
+		{pattern: "{http.status_code=200}", match: true},
+		{pattern: "{http.status_code>=500}", match: false},
+		{pattern: `{http.route="/users/{id}"}`, match: true},
+		{pattern: "{rows>1} #=2", match: true},
+		{pattern: "{rows<=1.5} #=1", match: true},
+		{pattern: "{http.route>1}", match: false},
+		{pattern: "{error>0}", match: false},
+		{pattern: "{error!=true} #=6", match: true},
+		{pattern: "{error!~true} #=6", match: true},
+		{pattern: "{hostname=db-1} #=4", match: true},
+		{pattern: `{"service"=frontend}`, match: false},
+		{pattern: "frontend > cache and api > db", match: true},
+		{pattern: "frontend > cache && payments", match: false},
+	}
+	trace := makeTrace()
+	for _, tc := range testCases {
+		testCase := tc // capture loop var
+		t.Run(testCase.pattern, func(t *testing.T) {
+			p, err := Parse(testCase.pattern)
+			require.NoError(t, err)
+			assert.Equal(t, testCase.match, p.Match(trace))
+		})
+	}
+}
+
+func TestMatchDeepTrace(t *testing.T) {
+	// every step would otherwise be matched again against each of the descendants
+	trace := &model.Trace{}
+	for i := uint64(1); i <= 500; i++ {
+		trace.Spans = append(trace.Spans, makeSpan(i, i-1, "a", "op", time.Millisecond))
+	}
+	p, err := Parse("a >> a >> a >> a >> a >> a >> a >> b")
+	require.NoError(t, err)
+	assert.False(t, p.Match(trace))
+	p, err = Parse("a >> a >> a >> a >> a >> a >> a >> a #=493")
+	require.NoError(t, err)
+	assert.True(t, p.Match(trace))
+}
+
+func TestString(t *testing.T) {
+	p, err := Parse("  frontend > api  ")
+	require.NoError(t, err)
+	assert.Equal(t, "frontend > api", p.String())
+}
+
+func TestParseErrors(t *testing.T) {
+	testCases := []struct {
+		pattern string
+		err     string
+	}{
+		{pattern: "", err: "expected a span selector, found end of pattern at offset 0"},
+		{pattern: "a >", err: "expected a span selector, found end of pattern at offset 3"},
+		{pattern: "a b", err: `expected '>', '>>' or 'and', found "b" at offset 2`},
+		{pattern: "a and", err: "expected a span selector, found end of pattern at offset 5"},
+		{pattern: "a #3", err: `expected a comparison after '#', found "3" at offset 3`},
+		{pattern: "a #>x", err: `expected a span count, found "x" at offset 4`},
+		{pattern: "a #>-1", err: `expected a span count, found "-1" at offset 4`},
+		{pattern: "{a=1 b=2}", err: `expected ',' or '}', found "b" at offset 5`},
+		{pattern: "{a=1,}", err: `expected a field, found "}" at offset 5`},
+		{pattern: "{a}", err: `expected an operator after "a", found "}" at offset 2`},
+		{pattern: "{a=}", err: `expected a value, found "}" at offset 3`},
+		{pattern: "{a=1", err: "expected ',' or '}', found end of pattern at offset 4"},
+		{pattern: "{duration>fast}", err: `invalid duration "fast" at offset 9`},
+		{pattern: "{duration=~1s}", err: "duration cannot be matched by =~ at offset 9"},
+		{pattern: "{service>a}", err: "service cannot be compared by > at offset 8"},
+		{pattern: "{rows>many}", err: `invalid number "many" at offset 5`},
+		{pattern: `{service=~"("}`, err: "invalid regular expression \"(\": error parsing regexp: missing closing ): `(` at offset 8"},
+		{pattern: `"abc`, err: "unterminated string at offset 0"},
+		{pattern: `"\q"`, err: "invalid string at offset 0: invalid syntax"},
+		{pattern: "a ? b", err: "unexpected character '?' at offset 2"},
+		{pattern: "a>a>a>a>a>a>a>a>a", err: `chain has more than 8 steps, found ">" at offset 15`},
+		{pattern: "a>a>a>a>a>a>a>a and a>a>a>a>a>a>a>a and a>a>a>a>a>a>a>a and a>a>a>a>a>a>a>a and a",
+			err: "pattern has more than 32 steps at offset 80"},
+	}
+	for _, tc := range testCases {
+		testCase := tc // capture loop var
+		t.Run(testCase.pattern, func(t *testing.T) {
+			_, err := Parse(testCase.pattern)
+			assert.EqualError(t, err, testCase.err)
+		})
+	}
+}
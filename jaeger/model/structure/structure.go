@@ -0,0 +1,188 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package structure
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// Group is a set of traces that share the same structural signature.
+type Group struct {
+	Signature string
+	Hash      string
+	Traces    []*model.Trace
+}
+
+// GroupTraces groups the traces by their structural signature.
+// Groups are ordered from the largest to the smallest.
+func GroupTraces(traces []*model.Trace) []*Group {
+	groups := make(map[string]*Group)
+	for _, trace := range traces {
+		signature := Signature(trace)
+		group, ok := groups[signature]
+		if !ok {
+			group = &Group{
+				Signature: signature,
+				Hash:      Hash(signature),
+			}
+			groups[signature] = group
+		}
+		group.Traces = append(group.Traces, trace)
+	}
+	retMe := make([]*Group, 0, len(groups))
+	for _, group := range groups {
+		retMe = append(retMe, group)
+	}
+	sort.Slice(retMe, func(i, j int) bool {
+		if len(retMe[i].Traces) != len(retMe[j].Traces) {
+			return len(retMe[i].Traces) > len(retMe[j].Traces)
+		}
+		return retMe[i].Hash < retMe[j].Hash
+	})
+	return retMe
+}
+
+// Hash returns a short fixed-length digest of a signature.
+func Hash(signature string) string {
+	h := fnv.New64a()
+	h.Write([]byte(signature))
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// Signature returns the canonical structural signature of the trace.
+//
+// Each span is labeled with its service and operation names, followed by its index
+// among the siblings carrying the same names, in start time order. The signature of a
+// span lists the start (">") and end ("<") events of its children in time order, each
+// start event being followed by the signature of the child. Two traces therefore share a
+// signature when they have the same call tree and their calls overlap in the same order,
+// regardless of the actual timings. Spans whose parent is not in the trace are treated
+// as children of a virtual root.
+func Signature(trace *model.Trace) string {
+	var sb strings.Builder
+	writeEvents(&sb, model.NewTraceTree(trace).Roots)
+	return sb.String()
+}
+
+func label(span *model.Span) string {
+	if span.OperationName == "" {
+		return span.Process.ServiceName
+	}
+	return span.Process.ServiceName + ":" + span.OperationName
+}
+
+type event struct {
+	child *model.SpanNode
+	name  string
+	end   bool
+}
+
+func (e event) time() time.Time {
+	if e.end {
+		return e.child.Span.StartTime.Add(e.child.Span.Duration)
+	}
+	return e.child.Span.StartTime
+}
+
+// Paths returns the structural path of every span of the tree. The path of a span
+// lists the labels of its ancestors and of the span itself from the root down, each
+// followed by its index among the siblings carrying the same label, like in
+// "frontend:GET#0~db:query#1". Spans at the same path in two traces of the same
+// structural group play the same role in the call tree.
+func Paths(tree *model.TraceTree) map[*model.SpanNode]string {
+	paths := make(map[*model.SpanNode]string)
+	var walk func(prefix string, nodes []*model.SpanNode)
+	walk = func(prefix string, nodes []*model.SpanNode) {
+		for _, sibling := range indexSiblings(nodes) {
+			path := sibling.path(prefix)
+			paths[sibling.node] = path
+			walk(path+"~", sibling.node.Children)
+		}
+	}
+	walk("", tree.Roots)
+	return paths
+}
+
+type sibling struct {
+	node  *model.SpanNode
+	label string
+	index int
+}
+
+// path returns the path of the sibling under the path prefix of its parent.
+func (s sibling) path(prefix string) string {
+	return prefix + s.label + "#" + strconv.Itoa(s.index)
+}
+
+// indexSiblings orders the nodes by start time and numbers the ones sharing the same label.
+func indexSiblings(nodes []*model.SpanNode) []sibling {
+	siblings := make([]sibling, len(nodes))
+	for i, n := range nodes {
+		siblings[i] = sibling{node: n, label: label(n.Span)}
+	}
+	sort.SliceStable(siblings, func(i, j int) bool {
+		a, b := siblings[i].node.Span, siblings[j].node.Span
+		if !a.StartTime.Equal(b.StartTime) {
+			return a.StartTime.Before(b.StartTime)
+		}
+		if siblings[i].label != siblings[j].label {
+			return siblings[i].label < siblings[j].label
+		}
+		return a.SpanID < b.SpanID
+	})
+	indexes := make(map[string]int)
+	for i := range siblings {
+		siblings[i].index = indexes[siblings[i].label]
+		indexes[siblings[i].label]++
+	}
+	return siblings
+}
+
+func writeEvents(sb *strings.Builder, nodes []*model.SpanNode) {
+	siblings := indexSiblings(nodes)
+	events := make([]event, 0, 2*len(siblings))
+	for _, sibling := range siblings {
+		name := strconv.Quote(sibling.label) + "#" + strconv.Itoa(sibling.index)
+		events = append(events, event{child: sibling.node, name: name}, event{child: sibling.node, name: name, end: true})
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		ti, tj := events[i].time(), events[j].time()
+		if !ti.Equal(tj) {
+			return ti.Before(tj)
+		}
+		// a call starting when another one ends is considered to start first
+		return !events[i].end && events[j].end
+	})
+
+	sb.WriteByte('{')
+	for _, e := range events {
+		if e.end {
+			sb.WriteByte('<')
+			sb.WriteString(e.name)
+			continue
+		}
+		sb.WriteByte('>')
+		sb.WriteString(e.name)
+		writeEvents(sb, e.child.Children)
+	}
+	sb.WriteByte('}')
+}
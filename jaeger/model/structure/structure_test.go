@@ -0,0 +1,132 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package structure
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+var (
+	traceID = model.NewTraceID(1, 2)
+	start   = time.Unix(1000, 0)
+)
+
+func newSpan(id, parent uint64, service, operation string, startOffset, duration time.Duration) *model.Span {
+	span := &model.Span{
+		TraceID:       traceID,
+		SpanID:        model.NewSpanID(id),
+		OperationName: operation,
+		Process:       &model.Process{ServiceName: service},
+		StartTime:     start.Add(startOffset),
+		Duration:      duration,
+	}
+	if parent != 0 {
+		span.References = []model.SpanRef{model.NewChildOfRef(traceID, model.NewSpanID(parent))}
+	}
+	return span
+}
+
+// sequentialTrace is a root calling two children one after the other.
+func sequentialTrace(scale time.Duration) *model.Trace {
+	return &model.Trace{
+		Spans: []*model.Span{
+			newSpan(3, 1, "db", "query", 6*scale, 2*scale),
+			newSpan(1, 0, "frontend", "GET", 0, 10*scale),
+			newSpan(2, 1, "db", "query", 1*scale, 4*scale),
+		},
+	}
+}
+
+func TestSignature(t *testing.T) {
+	signature := Signature(sequentialTrace(time.Millisecond))
+	assert.Equal(t,
+		`{>"frontend:GET"#0{>"db:query"#0{}<"db:query"#0>"db:query"#1{}<"db:query"#1}<"frontend:GET"#0}`,
+		signature)
+}
+
+func TestSignatureIgnoresTimings(t *testing.T) {
+	assert.Equal(t, Signature(sequentialTrace(time.Millisecond)), Signature(sequentialTrace(time.Second)))
+}
+
+func TestSignatureOverlappingCalls(t *testing.T) {
+	parallel := &model.Trace{
+		Spans: []*model.Span{
+			newSpan(1, 0, "frontend", "GET", 0, 10),
+			newSpan(2, 1, "db", "query", 1, 4),
+			newSpan(3, 1, "db", "query", 2, 4),
+		},
+	}
+	assert.NotEqual(t, Signature(sequentialTrace(1)), Signature(parallel))
+}
+
+func TestSignatureOrphansAndCycles(t *testing.T) {
+	trace := &model.Trace{
+		Spans: []*model.Span{
+			newSpan(1, 2, "a", "", 0, 10),
+			newSpan(2, 1, "b", "", 1, 5),
+			newSpan(3, 42, "c", "", 20, 5),
+			newSpan(3, 0, "duplicate", "", 20, 5),
+		},
+	}
+	signature := Signature(trace)
+	assert.Equal(t, `{>"a"#0{>"b"#0{}<"b"#0}<"a"#0>"c"#0{}<"c"#0}`, signature)
+}
+
+func TestSignatureFollowsFrom(t *testing.T) {
+	trace := &model.Trace{
+		Spans: []*model.Span{
+			newSpan(1, 0, "a", "", 0, 10),
+			newSpan(2, 0, "b", "", 20, 5),
+		},
+	}
+	trace.Spans[1].References = []model.SpanRef{model.NewFollowsFromRef(traceID, model.NewSpanID(1))}
+	assert.Equal(t, `{>"a"#0{>"b"#0{}<"b"#0}<"a"#0}`, Signature(trace))
+}
+
+func TestGroupTraces(t *testing.T) {
+	single := &model.Trace{
+		Spans: []*model.Span{newSpan(1, 0, "frontend", "GET", 0, 10)},
+	}
+	traces := []*model.Trace{
+		sequentialTrace(time.Millisecond),
+		single,
+		sequentialTrace(time.Second),
+	}
+	groups := GroupTraces(traces)
+	if assert.Len(t, groups, 2) {
+		assert.Equal(t, []*model.Trace{traces[0], traces[2]}, groups[0].Traces)
+		assert.Equal(t, Signature(traces[0]), groups[0].Signature)
+		assert.Equal(t, Hash(groups[0].Signature), groups[0].Hash)
+		assert.Equal(t, []*model.Trace{single}, groups[1].Traces)
+	}
+	assert.Len(t, groups[0].Hash, 16)
+}
+
+func TestPaths(t *testing.T) {
+	trace := sequentialTrace(time.Millisecond)
+	trace.Spans = append(trace.Spans, newSpan(4, 3, "cache", "", 7*time.Millisecond, time.Millisecond))
+	tree := model.NewTraceTree(trace)
+	paths := Paths(tree)
+	assert.Len(t, paths, 4)
+	assert.Equal(t, "frontend:GET#0", paths[tree.FindNode(model.NewSpanID(1))])
+	assert.Equal(t, "frontend:GET#0~db:query#0", paths[tree.FindNode(model.NewSpanID(2))])
+	assert.Equal(t, "frontend:GET#0~db:query#1", paths[tree.FindNode(model.NewSpanID(3))])
+	assert.Equal(t, "frontend:GET#0~db:query#1~cache#0", paths[tree.FindNode(model.NewSpanID(4))])
+}
@@ -15,6 +15,8 @@
 
 package model
 
+import "time"
+
 // FindSpanByID looks for a span with given span ID and returns the first one
 // it finds (search order is unspecified), or nil if no spans have that ID.
 func (t *Trace) FindSpanByID(id SpanID) *Span {
@@ -32,3 +34,87 @@ func (t *Trace) NormalizeTimestamps() {
 		span.NormalizeTimestamps()
 	}
 }
+
+// Duration returns the time elapsed between the earliest span start
+// and the latest span end in this trace.
+func (t *Trace) Duration() time.Duration {
+	if len(t.Spans) == 0 {
+		return 0
+	}
+	start, end := t.Spans[0].StartTime, t.Spans[0].StartTime.Add(t.Spans[0].Duration)
+	for _, span := range t.Spans[1:] {
+		if span.StartTime.Before(start) {
+			start = span.StartTime
+		}
+		if spanEnd := span.StartTime.Add(span.Duration); spanEnd.After(end) {
+			end = spanEnd
+		}
+	}
+	return end.Sub(start)
+}
+
+// DeepCopy returns a copy of the trace which can be modified, e.g. adjusted, without changing
+// the trace. The spans sharing a process still share the copy of the process.
+func (t *Trace) DeepCopy() *Trace {
+	processes := make(map[*Process]*Process)
+	copyProcess := func(process *Process) *Process {
+		if process == nil {
+			return nil
+		}
+		if retMe, ok := processes[process]; ok {
+			return retMe
+		}
+		retMe := &Process{ServiceName: process.ServiceName, Tags: copyKeyValues(process.Tags)}
+		processes[process] = retMe
+		return retMe
+	}
+	retMe := &Trace{Warnings: copyStrings(t.Warnings)}
+	if t.Spans != nil {
+		retMe.Spans = make([]*Span, len(t.Spans))
+	}
+	for i, span := range t.Spans {
+		spanCopy := *span
+		if span.References != nil {
+			spanCopy.References = append(make([]SpanRef, 0, len(span.References)), span.References...)
+		}
+		spanCopy.Tags = copyKeyValues(span.Tags)
+		if span.Logs != nil {
+			spanCopy.Logs = make([]Log, len(span.Logs))
+			for j, log := range span.Logs {
+				spanCopy.Logs[j] = Log{Timestamp: log.Timestamp, Fields: copyKeyValues(log.Fields)}
+			}
+		}
+		spanCopy.Process = copyProcess(span.Process)
+		spanCopy.Warnings = copyStrings(span.Warnings)
+		retMe.Spans[i] = &spanCopy
+	}
+	for _, mapping := range t.ProcessMap {
+		process := mapping.Process
+		retMe.ProcessMap = append(retMe.ProcessMap, Trace_ProcessMapping{
+			ProcessID: mapping.ProcessID,
+			Process:   *copyProcess(&process),
+		})
+	}
+	return retMe
+}
+
+func copyKeyValues(kvs KeyValues) KeyValues {
+	if kvs == nil {
+		return nil
+	}
+	retMe := make(KeyValues, len(kvs))
+	for i, kv := range kvs {
+		retMe[i] = kv
+		if kv.VBinary != nil {
+			retMe[i].VBinary = append(make([]byte, 0, len(kv.VBinary)), kv.VBinary...)
+		}
+	}
+	return retMe
+}
+
+func copyStrings(strs []string) []string {
+	if strs == nil {
+		return nil
+	}
+	return append(make([]string, 0, len(strs)), strs...)
+}
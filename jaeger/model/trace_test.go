@@ -66,3 +66,50 @@ func TestTraceNormalizeTimestamps(t *testing.T) {
 	assert.Equal(t, span.StartTime, tt1.UTC())
 	assert.Equal(t, span.Logs[0].Timestamp, tt2.UTC())
 }
+
+func TestTraceDuration(t *testing.T) {
+	start := time.Unix(100, 0)
+	trace := &model.Trace{
+		Spans: []*model.Span{
+			{StartTime: start.Add(time.Second), Duration: 5 * time.Second},
+			{StartTime: start, Duration: 2 * time.Second},
+			{StartTime: start.Add(2 * time.Second), Duration: time.Second},
+		},
+	}
+	assert.Equal(t, 6*time.Second, trace.Duration())
+	assert.Equal(t, time.Duration(0), (&model.Trace{}).Duration())
+}
+
+func TestTraceDeepCopy(t *testing.T) {
+	process := &model.Process{ServiceName: "service", Tags: model.KeyValues{model.String("host", "a")}}
+	trace := &model.Trace{
+		Spans: []*model.Span{
+			{
+				SpanID:     model.NewSpanID(1),
+				References: []model.SpanRef{model.NewChildOfRef(model.NewTraceID(0, 1), model.NewSpanID(2))},
+				Tags:       model.KeyValues{model.Binary("binary", []byte{1, 2})},
+				Logs:       []model.Log{{Fields: model.KeyValues{model.String("event", "x")}}},
+				Process:    process,
+				Warnings:   []string{"warning"},
+			},
+			{SpanID: model.NewSpanID(2), Process: process},
+		},
+		ProcessMap: []model.Trace_ProcessMapping{{ProcessID: "p1", Process: *process}},
+		Warnings:   []string{"warning"},
+	}
+	retMe := trace.DeepCopy()
+	assert.Equal(t, trace, retMe)
+	assert.True(t, retMe.Spans[0].Process == retMe.Spans[1].Process)
+	assert.False(t, retMe.Spans[0].Process == process)
+
+	retMe.Spans[0].Tags[0].VBinary[0] = 9
+	retMe.Spans[0].Logs[0].Fields[0].VStr = "y"
+	retMe.Spans[0].References[0].SpanID = model.NewSpanID(3)
+	retMe.Spans[0].Process.Tags[0].VStr = "b"
+	retMe.ProcessMap[0].Process.Tags[0].VStr = "b"
+	assert.Equal(t, []byte{1, 2}, trace.Spans[0].Tags[0].VBinary)
+	assert.Equal(t, "x", trace.Spans[0].Logs[0].Fields[0].VStr)
+	assert.Equal(t, model.NewSpanID(2), trace.Spans[0].References[0].SpanID)
+	assert.Equal(t, "a", process.Tags[0].VStr)
+	assert.Equal(t, "a", trace.ProcessMap[0].Process.Tags[0].VStr)
+}
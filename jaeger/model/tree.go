@@ -0,0 +1,210 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"sort"
+	"time"
+)
+
+// SpanNode is a span placed in the call tree of its trace.
+type SpanNode struct {
+	Span     *Span
+	Parent   *SpanNode
+	Children []*SpanNode
+}
+
+// TraceTree is the call tree of a trace, see NewTraceTree.
+type TraceTree struct {
+	// Roots are the spans without a parent in the trace, ordered by start time.
+	Roots []*SpanNode
+	nodes map[SpanID]*SpanNode
+}
+
+// NewTraceTree builds the call tree of the trace.
+//
+// The parent of a span is the span referenced by its child-of reference, or else by
+// its first follows-from reference within the trace. Spans whose parent is missing
+// from the trace become roots, and so does the first span found on a reference cycle.
+// When several spans share the same ID, only the first one is placed in the tree.
+// Children are ordered by start time, then by span ID.
+func NewTraceTree(trace *Trace) *TraceTree {
+	tree := &TraceTree{
+		nodes: make(map[SpanID]*SpanNode, len(trace.Spans)),
+	}
+	ordered := make([]*SpanNode, 0, len(trace.Spans))
+	for _, span := range trace.Spans {
+		if _, ok := tree.nodes[span.SpanID]; !ok {
+			n := &SpanNode{Span: span}
+			tree.nodes[span.SpanID] = n
+			ordered = append(ordered, n)
+		}
+	}
+	for _, n := range ordered {
+		if parent, ok := tree.nodes[treeParentID(n.Span)]; ok && parent != n {
+			n.Parent = parent
+		}
+	}
+	for _, n := range ordered {
+		seen := make(map[*SpanNode]bool)
+		for p := n; p != nil; p = p.Parent {
+			if seen[p] {
+				// break the reference cycle
+				p.Parent = nil
+				break
+			}
+			seen[p] = true
+		}
+	}
+	for _, n := range ordered {
+		if n.Parent == nil {
+			tree.Roots = append(tree.Roots, n)
+		} else {
+			n.Parent.Children = append(n.Parent.Children, n)
+		}
+	}
+	SortSpanNodes(tree.Roots)
+	for _, n := range ordered {
+		SortSpanNodes(n.Children)
+	}
+	return tree
+}
+
+// treeParentID returns the ID of the span referenced as parent by the given span,
+// or 0 if it has no reference within its trace.
+func treeParentID(span *Span) SpanID {
+	if id := span.ParentSpanID(); id != 0 {
+		return id
+	}
+	for _, ref := range span.References {
+		if ref.TraceID == span.TraceID {
+			return ref.SpanID
+		}
+	}
+	return 0
+}
+
+// FindNode returns the node of the span with the given ID, or nil if there is none.
+func (t *TraceTree) FindNode(id SpanID) *SpanNode {
+	return t.nodes[id]
+}
+
+// Walk calls f for every node of the tree, parents before their children.
+func (t *TraceTree) Walk(f func(n *SpanNode)) {
+	for _, root := range t.Roots {
+		root.walk(f)
+	}
+}
+
+func (n *SpanNode) walk(f func(n *SpanNode)) {
+	f(n)
+	for _, child := range n.Children {
+		child.walk(f)
+	}
+}
+
+// Interval is a time interval within a trace.
+type Interval struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Duration returns the length of the interval.
+func (i Interval) Duration() time.Duration {
+	return i.End.Sub(i.Start)
+}
+
+// SpanBreakdown splits the duration of a span between the time covered by
+// at least one of its children and the time spent in the span itself.
+type SpanBreakdown struct {
+	SelfTime  time.Duration
+	ChildTime time.Duration
+	// Gaps are the intervals of the span not covered by any child, in time order.
+	// There is always one more gap than there are groups of overlapping children,
+	// so the first and last gaps are empty when children start or end with the span.
+	Gaps []Interval
+}
+
+// Breakdown computes the breakdown of the span. The time of children running
+// outside of the span, such as asynchronous calls, is not accounted for.
+func (n *SpanNode) Breakdown() SpanBreakdown {
+	start := n.Span.StartTime
+	end := start.Add(n.Span.Duration)
+
+	covered := make([]Interval, 0, len(n.Children))
+	for _, child := range n.Children {
+		c := Interval{Start: child.Span.StartTime, End: child.Span.StartTime.Add(child.Span.Duration)}
+		if c.End.Before(start) || c.Start.After(end) {
+			continue
+		}
+		if c.Start.Before(start) {
+			c.Start = start
+		}
+		if c.End.After(end) {
+			c.End = end
+		}
+		covered = append(covered, c)
+	}
+	sort.SliceStable(covered, func(i, j int) bool {
+		return covered[i].Start.Before(covered[j].Start)
+	})
+
+	var breakdown SpanBreakdown
+	gapStart := start
+	for i := 0; i < len(covered); {
+		blockStart, blockEnd := covered[i].Start, covered[i].End
+		for i++; i < len(covered) && !covered[i].Start.After(blockEnd); i++ {
+			if covered[i].End.After(blockEnd) {
+				blockEnd = covered[i].End
+			}
+		}
+		breakdown.Gaps = append(breakdown.Gaps, Interval{Start: gapStart, End: blockStart})
+		gapStart = blockEnd
+	}
+	breakdown.Gaps = append(breakdown.Gaps, Interval{Start: gapStart, End: end})
+	for _, gap := range breakdown.Gaps {
+		breakdown.SelfTime += gap.Duration()
+	}
+	breakdown.ChildTime = n.Span.Duration - breakdown.SelfTime
+	return breakdown
+}
+
+// ChildOffsets measures how the children of a span are spaced out in time.
+type ChildOffsets struct {
+	// Starts holds, for each child in start time order, the time elapsed since the start
+	// of the previous child, or since the start of the span for the first child.
+	Starts []time.Duration
+	// End is the time between the end of the last child to start and the end of the span,
+	// or zero when the span has no children. It is negative when that child outlives the span.
+	End time.Duration
+}
+
+// ChildOffsets computes the child offsets of the span. Children are expected to be
+// ordered by start time, as they are in a TraceTree.
+func (n *SpanNode) ChildOffsets() ChildOffsets {
+	var offsets ChildOffsets
+	if len(n.Children) == 0 {
+		return offsets
+	}
+	offsets.Starts = make([]time.Duration, len(n.Children))
+	previous := n.Span.StartTime
+	for i, child := range n.Children {
+		offsets.Starts[i] = child.Span.StartTime.Sub(previous)
+		previous = child.Span.StartTime
+	}
+	last := n.Children[len(n.Children)-1].Span
+	offsets.End = n.Span.StartTime.Add(n.Span.Duration).Sub(last.StartTime.Add(last.Duration))
+	return offsets
+}
@@ -0,0 +1,182 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+var treeEpoch = time.Unix(1000, 0)
+
+func makeTreeSpan(id, parent uint64, start, duration int) *model.Span {
+	traceID := model.NewTraceID(0, 1)
+	span := &model.Span{
+		TraceID:   traceID,
+		SpanID:    model.NewSpanID(id),
+		StartTime: treeEpoch.Add(time.Duration(start) * time.Millisecond),
+		Duration:  time.Duration(duration) * time.Millisecond,
+	}
+	if parent != 0 {
+		span.References = []model.SpanRef{model.NewChildOfRef(traceID, model.NewSpanID(parent))}
+	}
+	return span
+}
+
+func treeInterval(start, end int) model.Interval {
+	return model.Interval{
+		Start: treeEpoch.Add(time.Duration(start) * time.Millisecond),
+		End:   treeEpoch.Add(time.Duration(end) * time.Millisecond),
+	}
+}
+
+func TestNewTraceTree(t *testing.T) {
+	trace := &model.Trace{
+		Spans: []*model.Span{
+			makeTreeSpan(3, 1, 20, 10),
+			makeTreeSpan(2, 1, 10, 10),
+			makeTreeSpan(1, 0, 0, 100),
+			makeTreeSpan(4, 9, 50, 10), // parent missing from the trace
+			makeTreeSpan(2, 1, 0, 10),  // duplicate span ID
+		},
+	}
+	tree := model.NewTraceTree(trace)
+	require.Len(t, tree.Roots, 2)
+	root := tree.Roots[0]
+	assert.Equal(t, model.NewSpanID(1), root.Span.SpanID)
+	assert.Nil(t, root.Parent)
+	require.Len(t, root.Children, 2)
+	assert.Equal(t, trace.Spans[1], root.Children[0].Span)
+	assert.Equal(t, trace.Spans[0], root.Children[1].Span)
+	assert.Equal(t, root, root.Children[0].Parent)
+	assert.Equal(t, model.NewSpanID(4), tree.Roots[1].Span.SpanID)
+
+	assert.Equal(t, root.Children[1], tree.FindNode(model.NewSpanID(3)))
+	assert.Nil(t, tree.FindNode(model.NewSpanID(9)))
+
+	var visited []model.SpanID
+	tree.Walk(func(n *model.SpanNode) {
+		visited = append(visited, n.Span.SpanID)
+	})
+	assert.Equal(t, []model.SpanID{1, 2, 3, 4}, visited)
+}
+
+func TestNewTraceTreeReferences(t *testing.T) {
+	traceID := model.NewTraceID(0, 1)
+	otherTraceID := model.NewTraceID(0, 2)
+	follower := makeTreeSpan(2, 0, 10, 10)
+	follower.References = []model.SpanRef{
+		model.NewFollowsFromRef(otherTraceID, model.NewSpanID(1)),
+		model.NewFollowsFromRef(traceID, model.NewSpanID(1)),
+	}
+	cycleA := makeTreeSpan(3, 4, 20, 10)
+	cycleB := makeTreeSpan(4, 3, 30, 10)
+	trace := &model.Trace{
+		Spans: []*model.Span{makeTreeSpan(1, 0, 0, 100), follower, cycleA, cycleB},
+	}
+	tree := model.NewTraceTree(trace)
+	require.Len(t, tree.Roots, 2)
+	assert.Equal(t, follower, tree.Roots[0].Children[0].Span)
+	assert.Equal(t, cycleA, tree.Roots[1].Span)
+	assert.Equal(t, cycleB, tree.Roots[1].Children[0].Span)
+}
+
+func TestSpanNodeBreakdown(t *testing.T) {
+	testCases := []struct {
+		name     string
+		children []*model.Span
+		self     time.Duration
+		gaps     []model.Interval
+	}{
+		{
+			name: "no children",
+			self: 100 * time.Millisecond,
+			gaps: []model.Interval{treeInterval(0, 100)},
+		},
+		{
+			name: "sequential children",
+			children: []*model.Span{
+				makeTreeSpan(3, 1, 50, 20),
+				makeTreeSpan(2, 1, 10, 20),
+			},
+			self: 60 * time.Millisecond,
+			gaps: []model.Interval{treeInterval(0, 10), treeInterval(30, 50), treeInterval(70, 100)},
+		},
+		{
+			name: "overlapping children",
+			children: []*model.Span{
+				makeTreeSpan(2, 1, 10, 30),
+				makeTreeSpan(3, 1, 20, 10),
+				makeTreeSpan(4, 1, 40, 20),
+			},
+			self: 50 * time.Millisecond,
+			gaps: []model.Interval{treeInterval(0, 10), treeInterval(60, 100)},
+		},
+		{
+			name: "children at the span boundaries",
+			children: []*model.Span{
+				makeTreeSpan(2, 1, 0, 40),
+				makeTreeSpan(3, 1, 60, 40),
+			},
+			self: 20 * time.Millisecond,
+			gaps: []model.Interval{treeInterval(0, 0), treeInterval(40, 60), treeInterval(100, 100)},
+		},
+		{
+			name: "children outside of the span",
+			children: []*model.Span{
+				makeTreeSpan(2, 1, -10, 20),
+				makeTreeSpan(3, 1, 90, 30),
+				makeTreeSpan(4, 1, 150, 10),
+			},
+			self: 80 * time.Millisecond,
+			gaps: []model.Interval{treeInterval(0, 0), treeInterval(10, 90), treeInterval(100, 100)},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			trace := &model.Trace{
+				Spans: append([]*model.Span{makeTreeSpan(1, 0, 0, 100)}, tc.children...),
+			}
+			breakdown := model.NewTraceTree(trace).Roots[0].Breakdown()
+			assert.Equal(t, tc.self, breakdown.SelfTime)
+			assert.Equal(t, 100*time.Millisecond-tc.self, breakdown.ChildTime)
+			assert.Equal(t, tc.gaps, breakdown.Gaps)
+		})
+	}
+}
+
+func TestSpanNodeChildOffsets(t *testing.T) {
+	trace := &model.Trace{
+		Spans: []*model.Span{
+			makeTreeSpan(1, 0, 0, 100),
+			makeTreeSpan(4, 1, 50, 60), // outlives its parent
+			makeTreeSpan(2, 1, 10, 20),
+			makeTreeSpan(3, 1, 15, 5),
+		},
+	}
+	tree := model.NewTraceTree(trace)
+	offsets := tree.Roots[0].ChildOffsets()
+	assert.Equal(t, []time.Duration{10 * time.Millisecond, 5 * time.Millisecond, 35 * time.Millisecond}, offsets.Starts)
+	assert.Equal(t, -10*time.Millisecond, offsets.End)
+
+	leaf := tree.FindNode(model.NewSpanID(2)).ChildOffsets()
+	assert.Empty(t, leaf.Starts)
+	assert.Equal(t, time.Duration(0), leaf.End)
+}
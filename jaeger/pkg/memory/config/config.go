@@ -14,7 +14,17 @@
 
 package config
 
+import "time"
+
 // Configuration describes the options to customize the storage behavior
 type Configuration struct {
 	MaxTraces int `yaml:"max-traces"`
+	// MaxAge evicts the traces whose most recent span started longer ago, unlimited when zero
+	MaxAge time.Duration `yaml:"max-age"`
+	// MaxBytes is the approximate size the spans may use, unlimited when zero
+	MaxBytes int64 `yaml:"max-bytes"`
+	// SnapshotFile is the file the traces are saved to and restored from, none when empty
+	SnapshotFile string `yaml:"snapshot-file"`
+	// SnapshotInterval is the interval between two snapshots, zero saving the traces on shutdown only
+	SnapshotInterval time.Duration `yaml:"snapshot-interval"`
 }
@@ -0,0 +1,91 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stats
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// RankTest is the result of a Mann-Whitney U test comparing the samples of x and y.
+type RankTest struct {
+	// U is the number of pairs of samples where the sample of y is greater than the
+	// sample of x, ties counting for one half.
+	U float64
+	// PValue is the two-sided p-value of the test, computed with the normal approximation,
+	// corrected for ties and continuity. It is 1 when either set of samples is empty.
+	PValue float64
+	// Effect is Cliff's delta, the probability that a sample of y is greater than a sample
+	// of x minus the probability of the opposite, from -1 to 1.
+	Effect float64
+}
+
+// MannWhitney tests whether the samples of y tend to be greater or smaller than the samples
+// of x, without assuming anything about their distribution. The samples are left untouched.
+func MannWhitney(x, y []time.Duration) RankTest {
+	nx, ny := float64(len(x)), float64(len(y))
+	if nx == 0 || ny == 0 {
+		return RankTest{PValue: 1}
+	}
+
+	type sample struct {
+		value time.Duration
+		fromY bool
+	}
+	samples := make([]sample, 0, len(x)+len(y))
+	for _, v := range x {
+		samples = append(samples, sample{value: v})
+	}
+	for _, v := range y {
+		samples = append(samples, sample{value: v, fromY: true})
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i].value < samples[j].value })
+
+	// rank the samples from 1, ties getting the average of their ranks
+	var rankSumY, tieCorrection float64
+	for i := 0; i < len(samples); {
+		j := i
+		for j < len(samples) && samples[j].value == samples[i].value {
+			j++
+		}
+		rank := float64(i+j+1) / 2
+		for k := i; k < j; k++ {
+			if samples[k].fromY {
+				rankSumY += rank
+			}
+		}
+		t := float64(j - i)
+		tieCorrection += t*t*t - t
+		i = j
+	}
+
+	n := nx + ny
+	u := rankSumY - ny*(ny+1)/2
+	test := RankTest{
+		U:      u,
+		PValue: 1,
+		Effect: (2*u - nx*ny) / (nx * ny),
+	}
+	variance := nx * ny / 12 * ((n + 1) - tieCorrection/(n*(n-1)))
+	if variance > 0 {
+		z := (math.Abs(u-nx*ny/2) - 0.5) / math.Sqrt(variance)
+		if z < 0 {
+			z = 0
+		}
+		test.PValue = math.Erfc(z / math.Sqrt2)
+	}
+	return test
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func millis(values ...int) []time.Duration {
+	retMe := make([]time.Duration, len(values))
+	for i, v := range values {
+		retMe[i] = time.Duration(v) * time.Millisecond
+	}
+	return retMe
+}
+
+func TestMannWhitney(t *testing.T) {
+	x := millis(5, 1, 3, 2, 4)
+	y := millis(10, 6, 8, 7, 9)
+
+	test := MannWhitney(x, y)
+	assert.Equal(t, 25.0, test.U)
+	assert.Equal(t, 1.0, test.Effect)
+	// same as scipy.stats.mannwhitneyu(x, y, alternative='two-sided', method='asymptotic')
+	assert.InDelta(t, 0.01219, test.PValue, 1e-5)
+	assert.Equal(t, millis(5, 1, 3, 2, 4), x, "samples are left untouched")
+
+	reversed := MannWhitney(y, x)
+	assert.Equal(t, 0.0, reversed.U)
+	assert.Equal(t, -1.0, reversed.Effect)
+	assert.InDelta(t, test.PValue, reversed.PValue, 1e-12)
+}
+
+func TestMannWhitneyTies(t *testing.T) {
+	test := MannWhitney(millis(1, 2, 2, 3), millis(2, 3, 3, 4))
+	assert.Equal(t, 13.0, test.U)
+	assert.Equal(t, 0.625, test.Effect)
+	assert.InDelta(t, 0.1720, test.PValue, 1e-4)
+
+	same := MannWhitney(millis(1, 1, 1), millis(1, 1))
+	assert.Equal(t, 0.0, same.Effect)
+	assert.Equal(t, 1.0, same.PValue)
+}
+
+func TestMannWhitneyEmpty(t *testing.T) {
+	assert.Equal(t, RankTest{PValue: 1}, MannWhitney(nil, millis(1)))
+	assert.Equal(t, RankTest{PValue: 1}, MannWhitney(millis(1), nil))
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package stats computes summary statistics over latency samples.
+package stats
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Summary describes the distribution of a set of durations.
+type Summary struct {
+	Count  int
+	Mean   time.Duration
+	StdDev time.Duration
+	Min    time.Duration
+	Max    time.Duration
+	P50    time.Duration
+	P99    time.Duration
+}
+
+// Summarize computes the Summary of the given samples. The samples slice is sorted in place.
+func Summarize(samples []time.Duration) Summary {
+	if len(samples) == 0 {
+		return Summary{}
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+
+	var sum float64
+	for _, s := range samples {
+		sum += float64(s)
+	}
+	mean := sum / float64(len(samples))
+	var stdDev float64
+	if len(samples) > 1 {
+		var sq float64
+		for _, s := range samples {
+			sq += (float64(s) - mean) * (float64(s) - mean)
+		}
+		stdDev = math.Sqrt(sq / float64(len(samples)-1))
+	}
+	return Summary{
+		Count:  len(samples),
+		Mean:   time.Duration(mean),
+		StdDev: time.Duration(stdDev),
+		Min:    samples[0],
+		Max:    samples[len(samples)-1],
+		P50:    Percentile(samples, 50),
+		P99:    Percentile(samples, 99),
+	}
+}
+
+// Sub returns the field by field difference between s and other.
+func (s Summary) Sub(other Summary) Summary {
+	return Summary{
+		Count:  s.Count - other.Count,
+		Mean:   s.Mean - other.Mean,
+		StdDev: s.StdDev - other.StdDev,
+		Min:    s.Min - other.Min,
+		Max:    s.Max - other.Max,
+		P50:    s.P50 - other.P50,
+		P99:    s.P99 - other.P99,
+	}
+}
+
+// Percentile returns the p-th percentile (0 <= p <= 100) of already sorted samples,
+// linearly interpolating between the closest ranks.
+func Percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := p / 100 * float64(len(sorted)-1)
+	lo := int(math.Floor(rank))
+	hi := int(math.Ceil(rank))
+	if lo < 0 {
+		lo = 0
+	}
+	if hi >= len(sorted) {
+		hi = len(sorted) - 1
+	}
+	frac := rank - float64(lo)
+	return sorted[lo] + time.Duration(frac*float64(sorted[hi]-sorted[lo]))
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarizeEmpty(t *testing.T) {
+	assert.Equal(t, Summary{}, Summarize(nil))
+}
+
+func TestSummarizeSingle(t *testing.T) {
+	s := Summarize([]time.Duration{time.Second})
+	assert.Equal(t, Summary{
+		Count: 1,
+		Mean:  time.Second,
+		Min:   time.Second,
+		Max:   time.Second,
+		P50:   time.Second,
+		P99:   time.Second,
+	}, s)
+}
+
+func TestSummarize(t *testing.T) {
+	var samples []time.Duration
+	for i := 100; i >= 1; i-- {
+		samples = append(samples, time.Duration(i)*time.Millisecond)
+	}
+	s := Summarize(samples)
+	assert.Equal(t, 100, s.Count)
+	assert.Equal(t, 50500*time.Microsecond, s.Mean)
+	assert.Equal(t, time.Millisecond, s.Min)
+	assert.Equal(t, 100*time.Millisecond, s.Max)
+	assert.Equal(t, 50500*time.Microsecond, s.P50)
+	assert.Equal(t, 99010*time.Microsecond, s.P99)
+	assert.InDelta(t, float64(29011*time.Microsecond), float64(s.StdDev), float64(time.Microsecond))
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := []time.Duration{10, 20, 30, 40}
+	assert.Equal(t, time.Duration(10), Percentile(sorted, 0))
+	assert.Equal(t, time.Duration(25), Percentile(sorted, 50))
+	assert.Equal(t, time.Duration(40), Percentile(sorted, 100))
+	assert.Equal(t, time.Duration(0), Percentile(nil, 50))
+}
+
+func TestSummarySub(t *testing.T) {
+	a := Summary{Count: 3, Mean: 10, StdDev: 4, Min: 2, Max: 20, P50: 9, P99: 19}
+	b := Summary{Count: 1, Mean: 4, StdDev: 0, Min: 4, Max: 4, P50: 4, P99: 4}
+	assert.Equal(t, Summary{Count: 2, Mean: 6, StdDev: 4, Min: -2, Max: 16, P50: 5, P99: 15}, a.Sub(b))
+}
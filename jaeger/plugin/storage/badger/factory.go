@@ -20,6 +20,7 @@ import (
 	"io/ioutil"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/dgraph-io/badger"
@@ -28,9 +29,10 @@ import (
 	"github.com/uber/jaeger-lib/metrics"
 	"go.uber.org/zap"
 
-	depStore "github.com/jaegertracing/jaeger/plugin/storage/badger/dependencystore"
 	badgerStore "github.com/jaegertracing/jaeger/plugin/storage/badger/spanstore"
+	"github.com/jaegertracing/jaeger/storage"
 	"github.com/jaegertracing/jaeger/storage/dependencystore"
+	"github.com/jaegertracing/jaeger/storage/dependencystore/spanreader"
 	"github.com/jaegertracing/jaeger/storage/spanstore"
 )
 
@@ -39,6 +41,9 @@ const (
 	keyLogSpaceAvailableName   = "badger_key_log_bytes_available"
 	lastMaintenanceRunName     = "badger_storage_maintenance_last_run"
 	lastValueLogCleanedName    = "badger_storage_valueloggc_last_run"
+
+	primaryNamespace = "badger"
+	archiveNamespace = "badger-archive"
 )
 
 // Factory implements storage.Factory for Badger backend.
@@ -51,6 +56,15 @@ type Factory struct {
 	tmpDir          string
 	maintenanceDone chan bool
 
+	// the span writer closes the factory too, so that the factory is only closed once
+	closeOnce sync.Once
+	closeErr  error
+
+	// the archive storage is a separate database, nil when it is not enabled
+	archiveStore  *badger.DB
+	archiveCache  *badgerStore.CacheStore
+	archiveTmpDir string
+
 	// TODO initialize via reflection; convert comments to tag 'description'.
 	metrics struct {
 		// ValueLogSpaceAvailable returns the amount of space left on the value log mount point in bytes
@@ -70,7 +84,7 @@ type Factory struct {
 // NewFactory creates a new Factory.
 func NewFactory() *Factory {
 	return &Factory{
-		Options:         NewOptions("badger"),
+		Options:         NewOptions(primaryNamespace, archiveNamespace),
 		maintenanceDone: make(chan bool),
 	}
 }
@@ -89,41 +103,27 @@ func (f *Factory) InitFromViper(v *viper.Viper) {
 func (f *Factory) Initialize(metricsFactory metrics.Factory, logger *zap.Logger) error {
 	f.logger = logger
 
-	opts := badger.DefaultOptions
-	opts.TableLoadingMode = options.MemoryMap
-
-	if f.Options.primary.Ephemeral {
-		opts.SyncWrites = false
-		// Error from TempDir is ignored to satisfy Codecov
-		dir, _ := ioutil.TempDir("", "badger")
-		f.tmpDir = dir
-		opts.Dir = f.tmpDir
-		opts.ValueDir = f.tmpDir
-
-		f.Options.primary.KeyDirectory = f.tmpDir
-		f.Options.primary.ValueDirectory = f.tmpDir
-	} else {
-		// Errors are ignored as they're caught in the Open call
-		initializeDir(f.Options.primary.KeyDirectory)
-		initializeDir(f.Options.primary.ValueDirectory)
-
-		opts.SyncWrites = f.Options.primary.SyncWrites
-		opts.Dir = f.Options.primary.KeyDirectory
-		opts.ValueDir = f.Options.primary.ValueDirectory
-
-		// These options make no sense with ephemeral data
-		opts.Truncate = f.Options.primary.Truncate
-		opts.ReadOnly = f.Options.primary.ReadOnly
-	}
-
-	store, err := badger.Open(opts)
+	store, tmpDir, err := f.openStore(f.Options.primary)
 	if err != nil {
 		return err
 	}
-	f.store = store
+	f.store, f.tmpDir = store, tmpDir
 
 	f.cache = badgerStore.NewCacheStore(f.store, f.Options.primary.SpanStoreTTL, true)
 
+	if cfg := f.Options.Get(archiveNamespace); cfg != nil {
+		archiveStore, archiveTmpDir, err := f.openStore(cfg)
+		if err != nil {
+			f.store.Close()
+			os.RemoveAll(f.tmpDir)
+			return err
+		}
+		f.archiveStore, f.archiveTmpDir = archiveStore, archiveTmpDir
+		f.archiveCache = badgerStore.NewCacheStore(f.archiveStore, cfg.SpanStoreTTL, true)
+	} else {
+		logger.Info("Badger archive storage configuration is empty, skipping")
+	}
+
 	f.metrics.ValueLogSpaceAvailable = metricsFactory.Gauge(metrics.Options{Name: valueLogSpaceAvailableName})
 	f.metrics.KeyLogSpaceAvailable = metricsFactory.Gauge(metrics.Options{Name: keyLogSpaceAvailableName})
 	f.metrics.LastMaintenanceRun = metricsFactory.Gauge(metrics.Options{Name: lastMaintenanceRunName})
@@ -134,11 +134,47 @@ func (f *Factory) Initialize(metricsFactory metrics.Factory, logger *zap.Logger)
 	go f.maintenance()
 	go f.metricsCopier()
 
-	logger.Info("Badger storage configuration", zap.Any("configuration", opts))
-
 	return nil
 }
 
+// openStore opens the badger database of the namespace, in a temporary directory if it is
+// ephemeral, in which case the directory is returned so that it can be removed on Close.
+func (f *Factory) openStore(cfg *NamespaceConfig) (*badger.DB, string, error) {
+	opts := badger.DefaultOptions
+	opts.TableLoadingMode = options.MemoryMap
+
+	var tmpDir string
+	if cfg.Ephemeral {
+		opts.SyncWrites = false
+		// Error from TempDir is ignored to satisfy Codecov
+		tmpDir, _ = ioutil.TempDir("", "badger")
+		opts.Dir = tmpDir
+		opts.ValueDir = tmpDir
+
+		cfg.KeyDirectory = tmpDir
+		cfg.ValueDirectory = tmpDir
+	} else {
+		// Errors are ignored as they're caught in the Open call
+		initializeDir(cfg.KeyDirectory)
+		initializeDir(cfg.ValueDirectory)
+
+		opts.SyncWrites = cfg.SyncWrites
+		opts.Dir = cfg.KeyDirectory
+		opts.ValueDir = cfg.ValueDirectory
+
+		// These options make no sense with ephemeral data
+		opts.Truncate = cfg.Truncate
+		opts.ReadOnly = cfg.ReadOnly
+	}
+
+	store, err := badger.Open(opts)
+	if err != nil {
+		return nil, "", err
+	}
+	f.logger.Info("Badger storage configuration", zap.String("namespace", cfg.namespace), zap.Any("configuration", opts))
+	return store, tmpDir, nil
+}
+
 // initializeDir makes the directory and parent directories if the path doesn't exists yet.
 func initializeDir(path string) {
 	if _, err := os.Stat(path); err != nil && os.IsNotExist(err) {
@@ -156,14 +192,48 @@ func (f *Factory) CreateSpanWriter() (spanstore.Writer, error) {
 	return badgerStore.NewSpanWriter(f.store, f.cache, f.Options.primary.SpanStoreTTL, f), nil
 }
 
+// CreateArchiveSpanReader implements storage.ArchiveFactory
+func (f *Factory) CreateArchiveSpanReader() (spanstore.Reader, error) {
+	if f.archiveStore == nil {
+		return nil, storage.ErrArchiveStorageNotConfigured
+	}
+	return badgerStore.NewTraceReader(f.archiveStore, f.archiveCache), nil
+}
+
+// CreateArchiveSpanWriter implements storage.ArchiveFactory
+func (f *Factory) CreateArchiveSpanWriter() (spanstore.Writer, error) {
+	if f.archiveStore == nil {
+		return nil, storage.ErrArchiveStorageNotConfigured
+	}
+	// The archive storage is closed along with the primary one, see Close
+	return badgerStore.NewSpanWriter(f.archiveStore, f.archiveCache, f.Options.Get(archiveNamespace).SpanStoreTTL, nopCloser{}), nil
+}
+
+// CreateStructureReader implements storage.StructureIndexFactory
+func (f *Factory) CreateStructureReader() (spanstore.StructureReader, error) {
+	return badgerStore.NewTraceReader(f.store, f.cache), nil
+}
+
+// CreateStructureWriter implements storage.StructureIndexFactory
+func (f *Factory) CreateStructureWriter() (spanstore.StructureWriter, error) {
+	return badgerStore.NewSpanWriter(f.store, f.cache, f.Options.primary.SpanStoreTTL, f), nil
+}
+
 // CreateDependencyReader implements storage.Factory
 func (f *Factory) CreateDependencyReader() (dependencystore.Reader, error) {
 	sr, _ := f.CreateSpanReader() // err is always nil
-	return depStore.NewDependencyStore(sr), nil
+	return spanreader.NewDependencyStore(sr), nil
 }
 
 // Close Implements io.Closer and closes the underlying storage
 func (f *Factory) Close() error {
+	f.closeOnce.Do(func() {
+		f.closeErr = f.close()
+	})
+	return f.closeErr
+}
+
+func (f *Factory) close() error {
 	close(f.maintenanceDone)
 	err := f.store.Close()
 
@@ -175,9 +245,29 @@ func (f *Factory) Close() error {
 		}
 	}
 
+	if f.archiveStore != nil {
+		errArchive := f.archiveStore.Close()
+		if err == nil {
+			err = errArchive
+		}
+		if f.archiveTmpDir != "" {
+			errArchive = os.RemoveAll(f.archiveTmpDir)
+			if err == nil {
+				err = errArchive
+			}
+		}
+	}
+
 	return err
 }
 
+// nopCloser is the io.Closer of the archive span writer, which must not close the factory
+type nopCloser struct{}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
 // Maintenance starts a background maintenance job for the badger K/V store, such as ValueLogGC
 func (f *Factory) maintenance() {
 	maintenanceTicker := time.NewTicker(f.Options.primary.MaintenanceInterval)
@@ -187,17 +277,16 @@ func (f *Factory) maintenance() {
 		case <-f.maintenanceDone:
 			return
 		case t := <-maintenanceTicker.C:
-			var err error
-
-			// After there's nothing to clean, the err is raised
-			for err == nil {
-				err = f.store.RunValueLogGC(0.5) // 0.5 is selected to rewrite a file if half of it can be discarded
-			}
-			if err == badger.ErrNoRewrite {
+			if err := runValueLogGC(f.store); err == nil {
 				f.metrics.LastValueLogCleaned.Update(t.UnixNano())
 			} else {
 				f.logger.Error("Failed to run ValueLogGC", zap.Error(err))
 			}
+			if f.archiveStore != nil {
+				if err := runValueLogGC(f.archiveStore); err != nil {
+					f.logger.Error("Failed to run ValueLogGC on the archive storage", zap.Error(err))
+				}
+			}
 
 			f.metrics.LastMaintenanceRun.Update(t.UnixNano())
 			f.diskStatisticsUpdate()
@@ -205,6 +294,20 @@ func (f *Factory) maintenance() {
 	}
 }
 
+// runValueLogGC rewrites the value log files of the store until there is nothing left to clean
+func runValueLogGC(store *badger.DB) error {
+	var err error
+
+	// After there's nothing to clean, the err is raised
+	for err == nil {
+		err = store.RunValueLogGC(0.5) // 0.5 is selected to rewrite a file if half of it can be discarded
+	}
+	if err == badger.ErrNoRewrite {
+		return nil
+	}
+	return err
+}
+
 func (f *Factory) metricsCopier() {
 	metricsTicker := time.NewTicker(f.Options.primary.MetricsUpdateInterval)
 	defer metricsTicker.Stop()
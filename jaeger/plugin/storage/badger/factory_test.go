@@ -15,6 +15,7 @@
 package badger
 
 import (
+	"context"
 	"expvar"
 	"fmt"
 	"io"
@@ -27,9 +28,14 @@ import (
 	"github.com/uber/jaeger-lib/metrics/metricstest"
 	"go.uber.org/zap"
 
+	"github.com/jaegertracing/jaeger/model"
 	"github.com/jaegertracing/jaeger/pkg/config"
+	"github.com/jaegertracing/jaeger/storage"
 )
 
+var _ storage.StructureIndexFactory = new(Factory)
+var _ storage.ArchiveFactory = new(Factory)
+
 func TestInitializationErrors(t *testing.T) {
 	f := NewFactory()
 	v, command := config.Viperize(f.AddFlags)
@@ -77,6 +83,19 @@ func TestForCodecov(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestCloseTwice(t *testing.T) {
+	f := NewFactory()
+	v, _ := config.Viperize(f.AddFlags)
+	f.InitFromViper(v)
+	assert.NoError(t, f.Initialize(metrics.NullFactory, zap.NewNop()))
+
+	// the span writer closes the factory, and so does the shutdown of the binaries
+	w, err := f.CreateSpanWriter()
+	assert.NoError(t, err)
+	assert.NoError(t, w.(io.Closer).Close())
+	assert.NoError(t, f.Close())
+}
+
 func TestMaintenanceRun(t *testing.T) {
 	// For Codecov - this does not test anything
 	f := NewFactory()
@@ -187,3 +206,56 @@ func TestBadgerMetrics(t *testing.T) {
 	err := f.Close()
 	assert.NoError(t, err)
 }
+
+func TestArchiveStorage(t *testing.T) {
+	f := NewFactory()
+	v, _ := config.Viperize(f.AddFlags)
+	f.InitFromViper(v)
+	assert.NoError(t, f.Initialize(metrics.NullFactory, zap.NewNop()))
+	_, err := f.CreateArchiveSpanReader()
+	assert.Equal(t, storage.ErrArchiveStorageNotConfigured, err)
+	_, err = f.CreateArchiveSpanWriter()
+	assert.Equal(t, storage.ErrArchiveStorageNotConfigured, err)
+	assert.NoError(t, f.Close())
+
+	f = NewFactory()
+	v, command := config.Viperize(f.AddFlags)
+	command.ParseFlags([]string{
+		"--badger-archive.enabled=true",
+		"--badger-archive.span-store-ttl=720h",
+	})
+	f.InitFromViper(v)
+	assert.NoError(t, f.Initialize(metrics.NullFactory, zap.NewNop()))
+	assert.NotEqual(t, f.tmpDir, f.archiveTmpDir)
+
+	archiveWriter, err := f.CreateArchiveSpanWriter()
+	assert.NoError(t, err)
+	archiveReader, err := f.CreateArchiveSpanReader()
+	assert.NoError(t, err)
+	reader, err := f.CreateSpanReader()
+	assert.NoError(t, err)
+
+	span := &model.Span{
+		TraceID:       model.NewTraceID(1, 2),
+		SpanID:        model.NewSpanID(3),
+		OperationName: "operation",
+		StartTime:     time.Now(),
+		Process:       &model.Process{ServiceName: "service"},
+	}
+	assert.NoError(t, archiveWriter.WriteSpan(span))
+	trace, err := archiveReader.GetTrace(context.Background(), span.TraceID)
+	assert.NoError(t, err)
+	assert.Len(t, trace.Spans, 1)
+	trace, err = reader.GetTrace(context.Background(), span.TraceID)
+	assert.NoError(t, err)
+	assert.Nil(t, trace, "the trace is not in the primary storage")
+
+	// closing the archive writer leaves the storage open
+	assert.NoError(t, archiveWriter.(io.Closer).Close())
+	_, err = archiveReader.GetTrace(context.Background(), span.TraceID)
+	assert.NoError(t, err)
+
+	assert.NoError(t, f.Close())
+	_, err = os.Stat(f.archiveTmpDir)
+	assert.True(t, os.IsNotExist(err))
+}
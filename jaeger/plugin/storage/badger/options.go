@@ -26,12 +26,15 @@ import (
 // Options store storage plugin related configs
 type Options struct {
 	primary *NamespaceConfig
-	// This storage plugin does not support additional namespaces
+	// others are the additional namespaces, e.g. the archive storage, stored in their own directories
+	others map[string]*NamespaceConfig
 }
 
 // NamespaceConfig is badger's internal configuration data
 type NamespaceConfig struct {
 	namespace             string
+	primary               bool
+	Enabled               bool // Only used by the additional namespaces, the primary one is always enabled
 	SpanStoreTTL          time.Duration
 	ValueDirectory        string
 	KeyDirectory          string
@@ -50,6 +53,7 @@ const (
 )
 
 const (
+	suffixEnabled             = ".enabled"
 	suffixKeyDirectory        = ".directory-key"
 	suffixValueDirectory      = ".directory-value"
 	suffixEphemeral           = ".ephemeral"
@@ -72,6 +76,8 @@ func NewOptions(primaryNamespace string, otherNamespaces ...string) *Options {
 	options := &Options{
 		primary: &NamespaceConfig{
 			namespace:             primaryNamespace,
+			primary:               true,
+			Enabled:               true,
 			SpanStoreTTL:          defaultTTL,
 			SyncWrites:            false, // Performance over durability
 			Ephemeral:             true,  // Default is ephemeral storage
@@ -80,6 +86,20 @@ func NewOptions(primaryNamespace string, otherNamespaces ...string) *Options {
 			MaintenanceInterval:   defaultMaintenanceInterval,
 			MetricsUpdateInterval: defaultMetricsUpdateInterval,
 		},
+		others: make(map[string]*NamespaceConfig, len(otherNamespaces)),
+	}
+
+	for _, namespace := range otherNamespaces {
+		// The additional namespaces must not share the directories of the primary one
+		namespaceDir := defaultBadgerDataDir + defaultDataDir + string(os.PathSeparator) + namespace
+		options.others[namespace] = &NamespaceConfig{
+			namespace:      namespace,
+			SpanStoreTTL:   defaultTTL,
+			SyncWrites:     false,
+			Ephemeral:      true,
+			ValueDirectory: namespaceDir + string(os.PathSeparator) + "values",
+			KeyDirectory:   namespaceDir + string(os.PathSeparator) + "keys",
+		}
 	}
 
 	return options
@@ -94,9 +114,19 @@ func getCurrentExecutableDir() string {
 // AddFlags adds flags for Options
 func (opt *Options) AddFlags(flagSet *flag.FlagSet) {
 	addFlags(flagSet, opt.primary)
+	for _, cfg := range opt.others {
+		addFlags(flagSet, cfg)
+	}
 }
 
 func addFlags(flagSet *flag.FlagSet, nsConfig *NamespaceConfig) {
+	if !nsConfig.primary {
+		flagSet.Bool(
+			nsConfig.namespace+suffixEnabled,
+			false,
+			"Enable extra storage",
+		)
+	}
 	flagSet.Bool(
 		nsConfig.namespace+suffixEphemeral,
 		nsConfig.Ephemeral,
@@ -122,16 +152,19 @@ func addFlags(flagSet *flag.FlagSet, nsConfig *NamespaceConfig) {
 		nsConfig.SyncWrites,
 		"If all writes should be synced immediately to physical disk. This will impact write performance.",
 	)
-	flagSet.Duration(
-		nsConfig.namespace+suffixMaintenanceInterval,
-		nsConfig.MaintenanceInterval,
-		"How often the maintenance thread for values is ran. Format is time.Duration (https://golang.org/pkg/time/#Duration)",
-	)
-	flagSet.Duration(
-		nsConfig.namespace+suffixMetricsInterval,
-		nsConfig.MetricsUpdateInterval,
-		"How often the badger metrics are collected by Jaeger. Format is time.Duration (https://golang.org/pkg/time/#Duration)",
-	)
+	if nsConfig.primary {
+		// The maintenance of all namespaces is run along with the primary one
+		flagSet.Duration(
+			nsConfig.namespace+suffixMaintenanceInterval,
+			nsConfig.MaintenanceInterval,
+			"How often the maintenance thread for values is ran. Format is time.Duration (https://golang.org/pkg/time/#Duration)",
+		)
+		flagSet.Duration(
+			nsConfig.namespace+suffixMetricsInterval,
+			nsConfig.MetricsUpdateInterval,
+			"How often the badger metrics are collected by Jaeger. Format is time.Duration (https://golang.org/pkg/time/#Duration)",
+		)
+	}
 	flagSet.Bool(
 		nsConfig.namespace+suffixTruncate,
 		nsConfig.Truncate,
@@ -147,21 +180,37 @@ func addFlags(flagSet *flag.FlagSet, nsConfig *NamespaceConfig) {
 // InitFromViper initializes Options with properties from viper
 func (opt *Options) InitFromViper(v *viper.Viper) {
 	initFromViper(opt.primary, v)
+	for _, cfg := range opt.others {
+		initFromViper(cfg, v)
+	}
 }
 
 func initFromViper(cfg *NamespaceConfig, v *viper.Viper) {
+	if !cfg.primary {
+		cfg.Enabled = v.GetBool(cfg.namespace + suffixEnabled)
+	}
 	cfg.Ephemeral = v.GetBool(cfg.namespace + suffixEphemeral)
 	cfg.KeyDirectory = v.GetString(cfg.namespace + suffixKeyDirectory)
 	cfg.ValueDirectory = v.GetString(cfg.namespace + suffixValueDirectory)
 	cfg.SyncWrites = v.GetBool(cfg.namespace + suffixSyncWrite)
 	cfg.SpanStoreTTL = v.GetDuration(cfg.namespace + suffixSpanstoreTTL)
-	cfg.MaintenanceInterval = v.GetDuration(cfg.namespace + suffixMaintenanceInterval)
-	cfg.MetricsUpdateInterval = v.GetDuration(cfg.namespace + suffixMetricsInterval)
 	cfg.Truncate = v.GetBool(cfg.namespace + suffixTruncate)
 	cfg.ReadOnly = v.GetBool(cfg.namespace + suffixReadOnly)
+	if cfg.primary {
+		cfg.MaintenanceInterval = v.GetDuration(cfg.namespace + suffixMaintenanceInterval)
+		cfg.MetricsUpdateInterval = v.GetDuration(cfg.namespace + suffixMetricsInterval)
+	}
 }
 
 // GetPrimary returns the primary namespace configuration
 func (opt *Options) GetPrimary() *NamespaceConfig {
 	return opt.primary
 }
+
+// Get returns the configuration of an additional namespace, or nil if it is not enabled
+func (opt *Options) Get(namespace string) *NamespaceConfig {
+	if cfg, ok := opt.others[namespace]; ok && cfg.Enabled {
+		return cfg
+	}
+	return nil
+}
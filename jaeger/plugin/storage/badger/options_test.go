@@ -67,3 +67,29 @@ func TestTruncateAndReadOnlyOptions(t *testing.T) {
 	assert.True(t, opts.GetPrimary().ReadOnly)
 	assert.True(t, opts.GetPrimary().Truncate)
 }
+
+func TestArchiveOptions(t *testing.T) {
+	opts := NewOptions("badger", "badger-archive")
+	v, command := config.Viperize(opts.AddFlags)
+	command.ParseFlags([]string{})
+	opts.InitFromViper(v)
+	assert.Nil(t, opts.Get("badger-archive"))
+
+	command.ParseFlags([]string{
+		"--badger-archive.enabled=true",
+		"--badger-archive.ephemeral=false",
+		"--badger-archive.directory-key=/var/lib/badger-archive",
+		"--badger-archive.span-store-ttl=720h",
+	})
+	opts.InitFromViper(v)
+
+	archive := opts.Get("badger-archive")
+	assert.NotNil(t, archive)
+	assert.False(t, archive.Ephemeral)
+	assert.Equal(t, "/var/lib/badger-archive", archive.KeyDirectory)
+	assert.NotEqual(t, opts.GetPrimary().ValueDirectory, archive.ValueDirectory)
+	assert.Equal(t, 720*time.Hour, archive.SpanStoreTTL)
+	assert.Equal(t, 72*time.Hour, opts.GetPrimary().SpanStoreTTL)
+	assert.True(t, opts.GetPrimary().Ephemeral)
+	assert.Nil(t, opts.Get("unknown"))
+}
@@ -37,6 +37,8 @@ import (
 )
 
 var _ io.Closer = new(bss.SpanWriter)
+var _ spanstore.StructureWriter = new(bss.SpanWriter)
+var _ spanstore.StructureReader = new(bss.TraceReader)
 
 func TestWriteReadBack(t *testing.T) {
 	runFactoryTest(t, func(tb testing.TB, sw spanstore.Writer, sr spanstore.Reader) {
@@ -278,6 +280,104 @@ func TestFindNothing(t *testing.T) {
 	})
 }
 
+func TestStructureIndex(t *testing.T) {
+	runFactoryTest(t, func(tb testing.TB, sw spanstore.Writer, sr spanstore.Reader) {
+		tid := time.Now()
+		traces := 10
+		for i := 0; i < traces; i++ {
+			traceID := model.TraceID{
+				Low:  uint64(i),
+				High: 1,
+			}
+			s := model.Span{
+				TraceID:       traceID,
+				SpanID:        model.SpanID(1),
+				OperationName: "operation",
+				Process: &model.Process{
+					ServiceName: "service",
+				},
+				StartTime: tid.Add(time.Duration(i) * time.Millisecond),
+				Duration:  time.Millisecond,
+			}
+			assert.NoError(t, sw.WriteSpan(&s))
+			hash := "even"
+			if i%2 == 1 {
+				hash = "odd"
+			}
+			assert.NoError(t, sw.(spanstore.StructureWriter).WriteStructure(traceID, s.StartTime, hash))
+		}
+
+		counts, err := sr.(spanstore.StructureReader).CountStructures(context.Background(), time.Time{}, time.Time{})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]int{"even": 5, "odd": 5}, counts)
+
+		counts, err = sr.(spanstore.StructureReader).CountStructures(context.Background(), tid.Add(2*time.Millisecond), tid.Add(4*time.Millisecond))
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]int{"even": 2, "odd": 1}, counts)
+
+		params := &spanstore.TraceQueryParameters{
+			StartTimeMin:  tid,
+			StartTimeMax:  tid.Add(time.Second),
+			ServiceName:   "service",
+			StructureHash: "odd",
+		}
+		traceIDs, err := sr.FindTraceIDs(context.Background(), params)
+		assert.NoError(t, err)
+		assert.Equal(t, []model.TraceID{{High: 1, Low: 9}, {High: 1, Low: 7}, {High: 1, Low: 5}, {High: 1, Low: 3}, {High: 1, Low: 1}}, traceIDs)
+
+		// the structure index can be searched on its own
+		params.ServiceName = ""
+		params.NumTraces = 2
+		trs, err := sr.FindTraces(context.Background(), params)
+		assert.NoError(t, err)
+		if assert.Len(t, trs, 2) {
+			assert.Equal(t, uint64(9), trs[0].Spans[0].TraceID.Low)
+			assert.Equal(t, uint64(7), trs[1].Spans[0].TraceID.Low)
+		}
+	})
+}
+
+func TestStructureIndexRewrite(t *testing.T) {
+	runFactoryTest(t, func(tb testing.TB, sw spanstore.Writer, sr spanstore.Reader) {
+		tid := time.Now()
+		traceID := model.TraceID{Low: 1, High: 1}
+		s := model.Span{
+			TraceID:       traceID,
+			SpanID:        model.SpanID(1),
+			OperationName: "operation",
+			Process: &model.Process{
+				ServiceName: "service",
+			},
+			StartTime: tid,
+			Duration:  time.Millisecond,
+		}
+		assert.NoError(t, sw.WriteSpan(&s))
+		structureWriter := sw.(spanstore.StructureWriter)
+		assert.NoError(t, structureWriter.WriteStructure(traceID, tid, "before"))
+		assert.NoError(t, structureWriter.WriteStructure(traceID, tid, "before"))
+		// late spans changed the structure of the trace
+		assert.NoError(t, structureWriter.WriteStructure(traceID, tid, "after"))
+
+		counts, err := sr.(spanstore.StructureReader).CountStructures(context.Background(), time.Time{}, time.Time{})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]int{"after": 1}, counts)
+
+		params := &spanstore.TraceQueryParameters{
+			StartTimeMin:  tid.Add(-time.Second),
+			StartTimeMax:  tid.Add(time.Second),
+			StructureHash: "before",
+		}
+		traceIDs, err := sr.FindTraceIDs(context.Background(), params)
+		assert.NoError(t, err)
+		assert.Empty(t, traceIDs)
+
+		params.StructureHash = "after"
+		traceIDs, err = sr.FindTraceIDs(context.Background(), params)
+		assert.NoError(t, err)
+		assert.Equal(t, []model.TraceID{traceID}, traceIDs)
+	})
+}
+
 func TestWriteDuplicates(t *testing.T) {
 	runFactoryTest(t, func(tb testing.TB, sw spanstore.Writer, sr spanstore.Reader) {
 		tid := time.Now()
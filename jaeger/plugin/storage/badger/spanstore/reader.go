@@ -445,6 +445,9 @@ func (r *TraceReader) FindTraceIDs(ctx context.Context, query *spanstore.TraceQu
 	// Find matches using indexes that are using service as part of the key
 	indexSeeks := make([][]byte, 0, 1)
 	indexSeeks = serviceQueries(query, indexSeeks)
+	if query.StructureHash != "" {
+		indexSeeks = append(indexSeeks, append([]byte{structureIndexKey}, query.StructureHash...))
+	}
 
 	ids := make([][][]byte, 0, len(indexSeeks)+1)
 	ids, err := r.indexSeeksToTraceIDs(query, indexSeeks, ids)
@@ -467,6 +470,37 @@ func (r *TraceReader) FindTraceIDs(ctx context.Context, query *spanstore.TraceQu
 	return nil, ErrNotSupported
 }
 
+// CountStructures implements spanstore.StructureReader. It only scans the keys of the structure index.
+func (r *TraceReader) CountStructures(ctx context.Context, startTimeMin, startTimeMax time.Time) (map[string]int, error) {
+	timeMin, timeMax := uint64(0), uint64(math.MaxUint64)
+	if !startTimeMin.IsZero() {
+		timeMin = model.TimeAsEpochMicroseconds(startTimeMin)
+	}
+	if !startTimeMax.IsZero() {
+		timeMax = model.TimeAsEpochMicroseconds(startTimeMax)
+	}
+
+	counts := map[string]int{}
+	err := r.store.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false // Don't fetch values since we're only interested in the keys
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		prefix := []byte{structureIndexKey}
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			key := it.Item().Key()
+			timestampStartIndex := len(key) - (sizeOfTraceID + 8) // timestamp is stored with 8 bytes
+			timestamp := binary.BigEndian.Uint64(key[timestampStartIndex : timestampStartIndex+8])
+			if timestamp >= timeMin && timestamp <= timeMax {
+				counts[string(key[1:timestampStartIndex])]++
+			}
+		}
+		return nil
+	})
+	return counts, err
+}
+
 // validateQuery returns an error if certain restrictions are not met
 func validateQuery(p *spanstore.TraceQueryParameters) error {
 	if p == nil {
@@ -535,6 +569,10 @@ func scanFunction(it *badger.Iterator, indexPrefix []byte, timeIndexEnd uint64)
 	if it.Item() != nil {
 		// We can't use the indexPrefix length, because we might have the same prefixValue for non-matching cases also
 		timestampStartIndex := len(it.Item().Key()) - (sizeOfTraceID + 8) // timestamp is stored with 8 bytes
+		if timestampStartIndex < len(indexPrefix) {
+			// past the index keys, e.g. on the trace structure keys
+			return false
+		}
 		timestamp := binary.BigEndian.Uint64(it.Item().Key()[timestampStartIndex : timestampStartIndex+8])
 
 		return bytes.HasPrefix(it.Item().Key()[:timestampStartIndex], indexPrefix) && timestamp <= timeIndexEnd
@@ -15,6 +15,7 @@
 package spanstore
 
 import (
+	"bytes"
 	"encoding/binary"
 	"encoding/json"
 	"fmt"
@@ -41,6 +42,8 @@ const (
 	operationNameIndexKey byte = 0x82
 	tagIndexKey           byte = 0x83
 	durationIndexKey      byte = 0x84
+	structureIndexKey     byte = 0x85
+	traceStructureKey     byte = 0x86 // Maps a trace to its key in the structure index
 	jsonEncoding          byte = 0x01 // Last 4 bits of the meta byte are for encoding type
 	protoEncoding         byte = 0x02 // Last 4 bits of the meta byte are for encoding type
 	defaultEncoding       byte = protoEncoding
@@ -126,6 +129,47 @@ func (w *SpanWriter) WriteSpan(span *model.Span) error {
 	return err
 }
 
+// WriteStructure implements spanstore.StructureWriter. The hash is indexed with the same TTL as the spans.
+// When the trace is indexed again, e.g. because late spans changed its structure, its previous
+// hash is removed from the index.
+func (w *SpanWriter) WriteStructure(traceID model.TraceID, startTime time.Time, hash string) error {
+	expireTime := uint64(time.Now().Add(w.ttl).Unix())
+	// KEY: si<hash><startTime><traceId>
+	indexKey := createIndexKey(structureIndexKey, []byte(hash), model.TimeAsEpochMicroseconds(startTime), traceID)
+	// KEY: st<traceId> VALUE: the structure index key of the trace
+	traceKey := createTraceStructureKey(traceID)
+	return w.store.Update(func(txn *badger.Txn) error {
+		item, err := txn.Get(traceKey)
+		switch err {
+		case nil:
+			previous, err := item.ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			if !bytes.Equal(previous, indexKey) {
+				if err := txn.Delete(previous); err != nil {
+					return err
+				}
+			}
+		case badger.ErrKeyNotFound:
+		default:
+			return err
+		}
+		if err := txn.SetEntry(w.createBadgerEntry(indexKey, nil, expireTime)); err != nil {
+			return err
+		}
+		return txn.SetEntry(w.createBadgerEntry(traceKey, indexKey, expireTime))
+	})
+}
+
+func createTraceStructureKey(traceID model.TraceID) []byte {
+	key := make([]byte, 1+sizeOfTraceID)
+	key[0] = traceStructureKey
+	binary.BigEndian.PutUint64(key[1:], traceID.High)
+	binary.BigEndian.PutUint64(key[9:], traceID.Low)
+	return key
+}
+
 func createIndexKey(indexPrefixKey byte, value []byte, startTime uint64, traceID model.TraceID) []byte {
 	// KEY: indexKey<indexValue><startTime><traceId> (traceId is last 16 bytes of the key)
 	key := make([]byte, 1+len(value)+8+sizeOfTraceID)
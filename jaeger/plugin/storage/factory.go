@@ -18,15 +18,18 @@ package storage
 import (
 	"flag"
 	"fmt"
+	"io"
 
 	"github.com/spf13/viper"
 	"github.com/uber/jaeger-lib/metrics"
 	"go.uber.org/zap"
 
+	"github.com/jaegertracing/jaeger/pkg/multierror"
 	"github.com/jaegertracing/jaeger/plugin"
 	"github.com/jaegertracing/jaeger/plugin/storage/badger"
 	"github.com/jaegertracing/jaeger/plugin/storage/cassandra"
 	"github.com/jaegertracing/jaeger/plugin/storage/es"
+	"github.com/jaegertracing/jaeger/plugin/storage/file"
 	"github.com/jaegertracing/jaeger/plugin/storage/grpc"
 	"github.com/jaegertracing/jaeger/plugin/storage/kafka"
 	"github.com/jaegertracing/jaeger/plugin/storage/memory"
@@ -42,6 +45,7 @@ const (
 	kafkaStorageType         = "kafka"
 	grpcPluginStorageType    = "grpc-plugin"
 	badgerStorageType        = "badger"
+	fileStorageType          = "file"
 	downsamplingRatio        = "downsampling.ratio"
 	downsamplingHashSalt     = "downsampling.hashsalt"
 
@@ -52,7 +56,7 @@ const (
 )
 
 // AllStorageTypes defines all available storage backends
-var AllStorageTypes = []string{cassandraStorageType, elasticsearchStorageType, memoryStorageType, kafkaStorageType, badgerStorageType, grpcPluginStorageType}
+var AllStorageTypes = []string{cassandraStorageType, elasticsearchStorageType, memoryStorageType, kafkaStorageType, badgerStorageType, grpcPluginStorageType, fileStorageType}
 
 // Factory implements storage.Factory interface as a meta-factory for storage components.
 type Factory struct {
@@ -96,6 +100,8 @@ func (f *Factory) getFactoryOfType(factoryType string) (storage.Factory, error)
 		return badger.NewFactory(), nil
 	case grpcPluginStorageType:
 		return grpc.NewFactory(), nil
+	case fileStorageType:
+		return file.NewFactory(), nil
 	default:
 		return nil, fmt.Errorf("unknown storage type %s. Valid types are %v", factoryType, AllStorageTypes)
 	}
@@ -229,3 +235,43 @@ func (f *Factory) CreateArchiveSpanWriter() (spanstore.Writer, error) {
 	}
 	return archive.CreateArchiveSpanWriter()
 }
+
+// CreateStructureReader implements storage.StructureIndexFactory
+func (f *Factory) CreateStructureReader() (spanstore.StructureReader, error) {
+	factory, ok := f.factories[f.SpanReaderType]
+	if !ok {
+		return nil, fmt.Errorf("no %s backend registered for span store", f.SpanReaderType)
+	}
+	index, ok := factory.(storage.StructureIndexFactory)
+	if !ok {
+		return nil, storage.ErrStructureIndexNotSupported
+	}
+	return index.CreateStructureReader()
+}
+
+// CreateStructureWriter implements storage.StructureIndexFactory
+func (f *Factory) CreateStructureWriter() (spanstore.StructureWriter, error) {
+	factory, ok := f.factories[f.SpanWriterTypes[0]]
+	if !ok {
+		return nil, fmt.Errorf("no %s backend registered for span store", f.SpanWriterTypes[0])
+	}
+	index, ok := factory.(storage.StructureIndexFactory)
+	if !ok {
+		return nil, storage.ErrStructureIndexNotSupported
+	}
+	return index.CreateStructureWriter()
+}
+
+// Close implements io.Closer and closes the underlying factories that hold resources,
+// such as the files, the databases or the background tasks of the storage.
+func (f *Factory) Close() error {
+	var errs []error
+	for _, factory := range f.factories {
+		if closer, ok := factory.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return multierror.Wrap(errs)
+}
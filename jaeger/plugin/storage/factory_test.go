@@ -38,6 +38,7 @@ import (
 
 var _ storage.Factory = new(Factory)
 var _ storage.ArchiveFactory = new(Factory)
+var _ storage.StructureIndexFactory = new(Factory)
 
 func defaultCfg() FactoryConfig {
 	return FactoryConfig{
@@ -99,6 +100,34 @@ func TestInitialize(t *testing.T) {
 	assert.EqualError(t, f.Initialize(m, l), "init-error")
 }
 
+type closerFactory struct {
+	mocks.Factory
+	err    error
+	closed bool
+}
+
+func (f *closerFactory) Close() error {
+	f.closed = true
+	return f.err
+}
+
+func TestClose(t *testing.T) {
+	f, err := NewFactory(FactoryConfig{
+		SpanWriterTypes:         []string{cassandraStorageType, elasticsearchStorageType},
+		SpanReaderType:          cassandraStorageType,
+		DependenciesStorageType: cassandraStorageType,
+	})
+	require.NoError(t, err)
+	closer := &closerFactory{}
+	f.factories[cassandraStorageType] = closer
+	f.factories[elasticsearchStorageType] = new(mocks.Factory)
+	assert.NoError(t, f.Close())
+	assert.True(t, closer.closed)
+
+	closer.err = errors.New("close-error")
+	assert.EqualError(t, f.Close(), "close-error")
+}
+
 func TestCreate(t *testing.T) {
 	f, err := NewFactory(defaultCfg())
 	require.NoError(t, err)
@@ -236,6 +265,37 @@ func TestCreateArchive(t *testing.T) {
 	assert.EqualError(t, err, "archive-span-writer-error")
 }
 
+func TestCreateStructureIndex(t *testing.T) {
+	f, err := NewFactory(defaultCfg())
+	require.NoError(t, err)
+	assert.NotEmpty(t, f.factories[cassandraStorageType])
+
+	_, err = f.CreateStructureReader()
+	assert.Equal(t, storage.ErrStructureIndexNotSupported, err)
+	_, err = f.CreateStructureWriter()
+	assert.Equal(t, storage.ErrStructureIndexNotSupported, err)
+
+	mock := &struct {
+		mocks.Factory
+		mocks.StructureIndexFactory
+	}{}
+	f.factories[cassandraStorageType] = mock
+
+	structureReader := new(spanStoreMocks.StructureReader)
+	structureWriter := new(spanStoreMocks.StructureWriter)
+
+	mock.StructureIndexFactory.On("CreateStructureReader").Return(structureReader, nil)
+	mock.StructureIndexFactory.On("CreateStructureWriter").Return(structureWriter, errors.New("structure-writer-error"))
+
+	sr, err := f.CreateStructureReader()
+	assert.Equal(t, structureReader, sr)
+	assert.NoError(t, err)
+
+	sw, err := f.CreateStructureWriter()
+	assert.Equal(t, structureWriter, sw)
+	assert.EqualError(t, err, "structure-writer-error")
+}
+
 func TestCreateError(t *testing.T) {
 	f, err := NewFactory(defaultCfg())
 	require.NoError(t, err)
@@ -274,6 +334,18 @@ func TestCreateError(t *testing.T) {
 		assert.Nil(t, w)
 		assert.EqualError(t, err, expectedErr)
 	}
+
+	{
+		r, err := f.CreateStructureReader()
+		assert.Nil(t, r)
+		assert.EqualError(t, err, expectedErr)
+	}
+
+	{
+		w, err := f.CreateStructureWriter()
+		assert.Nil(t, w)
+		assert.EqualError(t, err, expectedErr)
+	}
 }
 
 type configurable struct {
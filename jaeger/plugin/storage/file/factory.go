@@ -0,0 +1,103 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"errors"
+	"flag"
+	"os"
+
+	"github.com/spf13/viper"
+	"github.com/uber/jaeger-lib/metrics"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/storage/dependencystore"
+	"github.com/jaegertracing/jaeger/storage/dependencystore/spanreader"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+// Factory implements storage.Factory and creates storage components backed by trace files,
+// typically exported from another storage with jaeger-export for offline analysis.
+type Factory struct {
+	options Options
+	logger  *zap.Logger
+	store   *Store
+}
+
+// NewFactory creates a new Factory.
+func NewFactory() *Factory {
+	return &Factory{
+		options: Options{Format: FormatJSON},
+	}
+}
+
+// AddFlags implements plugin.Configurable
+func (f *Factory) AddFlags(flagSet *flag.FlagSet) {
+	f.options.AddFlags(flagSet)
+}
+
+// InitFromViper implements plugin.Configurable
+func (f *Factory) InitFromViper(v *viper.Viper) {
+	f.options.InitFromViper(v)
+}
+
+// Initialize implements storage.Factory
+func (f *Factory) Initialize(metricsFactory metrics.Factory, logger *zap.Logger) error {
+	f.logger = logger
+	if f.options.Directory == "" {
+		return errors.New("the directory of the trace files is not set, see --" + storageDirectory)
+	}
+	if err := os.MkdirAll(f.options.Directory, 0755); err != nil {
+		return err
+	}
+	store, err := NewStore(f.options.Directory, f.options.Format)
+	if err != nil {
+		return err
+	}
+	f.store = store
+	if store.skipped > 0 {
+		logger.Warn("Skipped the invalid records of the trace files",
+			zap.Int("skipped", store.skipped),
+			zap.Error(store.skipErr))
+	}
+	logger.Info("File storage initialized",
+		zap.String("directory", f.options.Directory),
+		zap.String("format", f.options.Format),
+		zap.Int("traces", len(store.traces)))
+	return nil
+}
+
+// CreateSpanReader implements storage.Factory
+func (f *Factory) CreateSpanReader() (spanstore.Reader, error) {
+	return f.store, nil
+}
+
+// CreateSpanWriter implements storage.Factory
+func (f *Factory) CreateSpanWriter() (spanstore.Writer, error) {
+	return f.store, nil
+}
+
+// CreateDependencyReader implements storage.Factory
+func (f *Factory) CreateDependencyReader() (dependencystore.Reader, error) {
+	return spanreader.NewDependencyStore(f.store), nil
+}
+
+// Close implements io.Closer and closes the trace files
+func (f *Factory) Close() error {
+	if f.store == nil {
+		return nil
+	}
+	return f.store.Close()
+}
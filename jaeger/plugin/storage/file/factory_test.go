@@ -0,0 +1,99 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/config"
+	"github.com/jaegertracing/jaeger/pkg/testutils"
+	"github.com/jaegertracing/jaeger/storage"
+)
+
+var _ storage.Factory = new(Factory)
+
+func TestFileStorageFactory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "file-storage")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	f := NewFactory()
+	v, command := config.Viperize(f.AddFlags)
+	command.ParseFlags([]string{"--file.directory=" + filepath.Join(dir, "traces")})
+	f.InitFromViper(v)
+	require.NoError(t, f.Initialize(nil, zap.NewNop()))
+	defer f.Close()
+
+	reader, err := f.CreateSpanReader()
+	assert.NoError(t, err)
+	assert.Equal(t, f.store, reader)
+	writer, err := f.CreateSpanWriter()
+	assert.NoError(t, err)
+	assert.Equal(t, f.store, writer)
+	depReader, err := f.CreateDependencyReader()
+	require.NoError(t, err)
+
+	now := time.Now()
+	parent := newSpan(1, 1, "frontend", "GET /", now)
+	child := newSpan(1, 2, "backend", "query", now.Add(time.Millisecond))
+	child.References = []model.SpanRef{model.NewChildOfRef(parent.TraceID, parent.SpanID)}
+	require.NoError(t, writer.WriteSpan(parent))
+	require.NoError(t, writer.WriteSpan(child))
+
+	links, err := depReader.GetDependencies(now.Add(time.Minute), time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, []model.DependencyLink{{Parent: "frontend", Child: "backend", CallCount: 1}}, links)
+
+	trace, err := reader.GetTrace(context.Background(), parent.TraceID)
+	require.NoError(t, err)
+	assert.Len(t, trace.Spans, 2)
+}
+
+func TestFileStorageFactoryErrors(t *testing.T) {
+	f := NewFactory()
+	assert.EqualError(t, f.Initialize(nil, zap.NewNop()), "the directory of the trace files is not set, see --file.directory")
+	assert.NoError(t, f.Close())
+
+	dir, err := ioutil.TempDir("", "file-storage")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	f.options = Options{Directory: dir, Format: "csv"}
+	assert.EqualError(t, f.Initialize(nil, zap.NewNop()), `unknown format "csv", expected json or protobuf`)
+}
+
+func TestFileStorageFactorySkippedRecords(t *testing.T) {
+	dir, err := ioutil.TempDir("", "file-storage")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	content := append(encodeTestRecord(t, FormatJSON, 1), "{\"traceID\"\n"...)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "traces.json"), content, 0644))
+
+	logger, logBuffer := testutils.NewLogger()
+	f := NewFactory()
+	f.options = Options{Directory: dir, Format: FormatJSON}
+	require.NoError(t, f.Initialize(nil, logger))
+	defer f.Close()
+	assert.Contains(t, logBuffer.String(), `"msg":"Skipped the invalid records of the trace files","skipped":1`)
+}
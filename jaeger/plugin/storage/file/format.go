@@ -0,0 +1,228 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"github.com/jaegertracing/jaeger/model"
+	uiconv "github.com/jaegertracing/jaeger/model/converter/json"
+	jModel "github.com/jaegertracing/jaeger/model/json"
+)
+
+const (
+	// FormatJSON is newline-delimited JSON, each line being a trace in the format of the
+	// /api/traces endpoint, see uiconv.FromDomain. The spans of a trace may be split across lines.
+	FormatJSON = "json"
+	// FormatProtobuf is a sequence of model.Span protobuf messages, each prefixed with its
+	// size as a uvarint.
+	FormatProtobuf = "protobuf"
+
+	// maxRecordSize guards against allocating huge buffers when reading a corrupted file
+	maxRecordSize = 64 << 20
+)
+
+// extensions maps the formats to the extensions of their files
+var extensions = map[string]string{
+	FormatJSON:     ".json",
+	FormatProtobuf: ".pb",
+}
+
+// formatOf returns the format of a trace file based on its extension, or "" if it is not one.
+func formatOf(path string) string {
+	ext := filepath.Ext(path)
+	for format, formatExt := range extensions {
+		if ext == formatExt {
+			return format
+		}
+	}
+	return ""
+}
+
+func validateFormat(format string) error {
+	if _, ok := extensions[format]; !ok {
+		return fmt.Errorf("unknown format %q, expected %s or %s", format, FormatJSON, FormatProtobuf)
+	}
+	return nil
+}
+
+// Encoder writes traces in one of the formats of the trace files.
+type Encoder struct {
+	w      io.Writer
+	format string
+}
+
+// NewEncoder creates an Encoder writing to w in the format, FormatJSON or FormatProtobuf.
+func NewEncoder(w io.Writer, format string) (*Encoder, error) {
+	if err := validateFormat(format); err != nil {
+		return nil, err
+	}
+	return &Encoder{w: w, format: format}, nil
+}
+
+// Encode writes the trace.
+func (e *Encoder) Encode(trace *model.Trace) error {
+	records, err := encodeRecords(e.format, trace)
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		if _, err := e.w.Write(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeRecords encodes a trace into a single JSON line, or a protobuf record per span.
+func encodeRecords(format string, trace *model.Trace) ([][]byte, error) {
+	if len(trace.Spans) == 0 {
+		return nil, nil
+	}
+	if format == FormatJSON {
+		line, err := json.Marshal(uiconv.FromDomain(trace))
+		if err != nil {
+			return nil, err
+		}
+		return [][]byte{append(line, '\n')}, nil
+	}
+	records := make([][]byte, len(trace.Spans))
+	for i, span := range trace.Spans {
+		payload, err := span.Marshal()
+		if err != nil {
+			return nil, err
+		}
+		record := make([]byte, binary.MaxVarintLen64, binary.MaxVarintLen64+len(payload))
+		record = append(record[:binary.PutUvarint(record, uint64(len(payload)))], payload...)
+		records[i] = record
+	}
+	return records, nil
+}
+
+// decodeRecord decodes the spans of a record written by encodeRecords.
+func decodeRecord(format string, record []byte) ([]*model.Span, error) {
+	if format == FormatJSON {
+		return decodeJSON(record)
+	}
+	size, n := binary.Uvarint(record)
+	if n <= 0 || uint64(len(record)-n) != size {
+		return nil, errors.New("invalid protobuf record size")
+	}
+	return decodeSpan(record[n:])
+}
+
+func decodeJSON(line []byte) ([]*model.Span, error) {
+	decoder := json.NewDecoder(bytes.NewReader(line))
+	decoder.UseNumber()
+	var trace jModel.Trace
+	if err := decoder.Decode(&trace); err != nil {
+		return nil, err
+	}
+	domainTrace, err := uiconv.ToDomain(&trace)
+	if err != nil {
+		return nil, err
+	}
+	return domainTrace.Spans, nil
+}
+
+func decodeSpan(payload []byte) ([]*model.Span, error) {
+	span := &model.Span{}
+	if err := span.Unmarshal(payload); err != nil {
+		return nil, err
+	}
+	return []*model.Span{span}, nil
+}
+
+// invalidRecordError is returned by recordReader.next for a record which cannot be decoded,
+// the following records can still be read.
+type invalidRecordError struct {
+	error
+}
+
+// recordReader reads the records of a trace file, keeping track of their offsets.
+type recordReader struct {
+	r      *bufio.Reader
+	format string
+	offset int64
+}
+
+func newRecordReader(r io.Reader, format string) *recordReader {
+	return &recordReader{r: bufio.NewReader(r), format: format}
+}
+
+// ReadByte implements io.ByteReader for binary.ReadUvarint
+func (rr *recordReader) ReadByte() (byte, error) {
+	b, err := rr.r.ReadByte()
+	if err == nil {
+		rr.offset++
+	}
+	return b, err
+}
+
+// next decodes the next record, and returns its spans along with its offset and size in the
+// file. It returns io.EOF after the last record, and an invalidRecordError when the record
+// cannot be decoded. Any other error means that the remaining records cannot be delimited,
+// e.g. when the last record of a file being written is torn.
+func (rr *recordReader) next() ([]*model.Span, int64, int64, error) {
+	if rr.format == FormatJSON {
+		for {
+			offset := rr.offset
+			line, err := rr.r.ReadBytes('\n')
+			rr.offset += int64(len(line))
+			if err != nil && (err != io.EOF || len(line) == 0) {
+				return nil, offset, 0, err
+			}
+			if len(bytes.TrimSpace(line)) == 0 {
+				// blank lines are allowed, e.g. at the end of the file
+				continue
+			}
+			spans, err := decodeJSON(line)
+			if err != nil {
+				return nil, offset, rr.offset - offset, invalidRecordError{err}
+			}
+			return spans, offset, rr.offset - offset, nil
+		}
+	}
+	offset := rr.offset
+	size, err := binary.ReadUvarint(rr)
+	if err == io.EOF && rr.offset == offset {
+		return nil, 0, 0, io.EOF
+	}
+	if err != nil {
+		return nil, offset, 0, errors.Wrap(err, "cannot read the record size")
+	}
+	if size > maxRecordSize {
+		return nil, offset, 0, fmt.Errorf("record of %d bytes exceeds the maximum size", size)
+	}
+	payload := make([]byte, size)
+	n, err := io.ReadFull(rr.r, payload)
+	rr.offset += int64(n)
+	if err != nil {
+		return nil, offset, 0, errors.Wrap(err, "cannot read the record")
+	}
+	spans, err := decodeSpan(payload)
+	if err != nil {
+		return nil, offset, rr.offset - offset, invalidRecordError{err}
+	}
+	return spans, offset, rr.offset - offset, nil
+}
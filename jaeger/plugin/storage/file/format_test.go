@@ -0,0 +1,97 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestEncoder(t *testing.T) {
+	traces := []*model.Trace{
+		{Spans: []*model.Span{
+			newSpan(1, 1, "frontend", "GET /", baseTime),
+			newSpan(1, 2, "backend", "query", baseTime.Add(time.Millisecond)),
+		}},
+		{},
+		{Spans: []*model.Span{newSpan(2, 3, "frontend", "GET /users", baseTime.Add(time.Second))}},
+	}
+	for _, format := range []string{FormatJSON, FormatProtobuf} {
+		t.Run(format, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			encoder, err := NewEncoder(buf, format)
+			require.NoError(t, err)
+			for _, trace := range traces {
+				require.NoError(t, encoder.Encode(trace))
+			}
+			content := buf.Bytes()
+
+			var spans []*model.Span
+			reader := newRecordReader(bytes.NewReader(content), format)
+			for {
+				recordSpans, offset, size, err := reader.next()
+				if err == io.EOF {
+					break
+				}
+				require.NoError(t, err)
+				// the records can be read back from their location
+				decoded, err := decodeRecord(format, content[offset:offset+size])
+				require.NoError(t, err)
+				assert.Len(t, decoded, len(recordSpans))
+				spans = append(spans, recordSpans...)
+			}
+			assert.Equal(t, int64(len(content)), reader.offset)
+			require.Len(t, spans, 3)
+			for i, spanID := range []uint64{1, 2, 3} {
+				assert.Equal(t, model.NewSpanID(spanID), spans[i].SpanID)
+			}
+			assert.Equal(t, "backend", spans[1].Process.ServiceName)
+		})
+	}
+
+	_, err := NewEncoder(&bytes.Buffer{}, "csv")
+	assert.EqualError(t, err, `unknown format "csv", expected json or protobuf`)
+}
+
+func TestFormatOf(t *testing.T) {
+	assert.Equal(t, FormatJSON, formatOf("/tmp/traces.json"))
+	assert.Equal(t, FormatProtobuf, formatOf("traces.pb"))
+	assert.Equal(t, "", formatOf("traces.json.gz"))
+	assert.Equal(t, "", formatOf("traces"))
+}
+
+func TestDecodeRecordErrors(t *testing.T) {
+	_, err := decodeRecord(FormatProtobuf, []byte{5, 1})
+	assert.EqualError(t, err, "invalid protobuf record size")
+	_, err = decodeRecord(FormatProtobuf, []byte{1, 0xff})
+	assert.Error(t, err)
+	_, err = decodeRecord(FormatJSON, []byte("{"))
+	assert.Error(t, err)
+}
+
+func TestRecordReaderMaxSize(t *testing.T) {
+	record := make([]byte, binary.MaxVarintLen64)
+	record = record[:binary.PutUvarint(record, maxRecordSize+1)]
+	_, _, _, err := newRecordReader(bytes.NewReader(record), FormatProtobuf).next()
+	assert.EqualError(t, err, "record of 67108865 bytes exceeds the maximum size")
+}
@@ -0,0 +1,46 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"flag"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	storageDirectory = "file.directory"
+	storageFormat    = "file.format"
+)
+
+// Options stores the configuration entries for this storage
+type Options struct {
+	// Directory holds the trace files, which are all loaded on startup
+	Directory string
+	// Format is the format of the file the written spans are appended to
+	Format string
+}
+
+// AddFlags from this storage to the CLI
+func (opt *Options) AddFlags(flagSet *flag.FlagSet) {
+	flagSet.String(storageDirectory, opt.Directory, "The directory of the trace files to load, *.json for the JSON format and *.pb for the protobuf one, and to write the spans to")
+	flagSet.String(storageFormat, opt.Format, "The format of the file the spans are written to, json (newline-delimited traces of the /api/traces endpoint) or protobuf (length-prefixed spans)")
+}
+
+// InitFromViper initializes the options struct with values from Viper
+func (opt *Options) InitFromViper(v *viper.Viper) {
+	opt.Directory = v.GetString(storageDirectory)
+	opt.Format = v.GetString(storageFormat)
+}
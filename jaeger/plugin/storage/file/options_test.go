@@ -0,0 +1,37 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jaegertracing/jaeger/pkg/config"
+)
+
+func TestOptionsWithFlags(t *testing.T) {
+	f := NewFactory()
+	v, command := config.Viperize(f.AddFlags)
+	command.ParseFlags([]string{"--file.directory=/tmp/traces"})
+	f.InitFromViper(v)
+
+	assert.Equal(t, "/tmp/traces", f.options.Directory)
+	assert.Equal(t, FormatJSON, f.options.Format)
+
+	command.ParseFlags([]string{"--file.format=protobuf"})
+	f.InitFromViper(v)
+	assert.Equal(t, FormatProtobuf, f.options.Format)
+}
@@ -0,0 +1,441 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+// Store is a spanstore.Reader and spanstore.Writer backed by the trace files of a directory.
+// The files are indexed when the store is created: the index holds the location of the
+// records of each trace along with a summary of its spans, so that traces can be looked up
+// without reading the files, except when searching by tags. The spans are read from the
+// files when the traces are fetched.
+type Store struct {
+	sync.RWMutex
+	directory  string
+	format     string
+	files      []*traceFile
+	traces     map[model.TraceID]*traceEntry
+	operations map[string]map[string]struct{}
+	// output is the file the written spans are appended to, created on the first write
+	output *traceFile
+	// skipped counts the records which could not be loaded, and skipErr is the first reason
+	skipped int
+	skipErr error
+}
+
+type traceFile struct {
+	path   string
+	format string
+	file   *os.File
+	// size is the offset of the next record of the output file
+	size int64
+}
+
+type recordLocation struct {
+	file   *traceFile
+	offset int64
+	size   int64
+}
+
+type spanSummary struct {
+	service   string
+	operation string
+	startTime time.Time
+	duration  time.Duration
+}
+
+type traceEntry struct {
+	traceID   model.TraceID
+	records   []recordLocation
+	spans     []spanSummary
+	startTime time.Time
+}
+
+// NewStore loads the trace files of the directory, and appends the written spans to a new
+// file in the format, FormatJSON or FormatProtobuf. The records which cannot be decoded are
+// skipped rather than failing the load, along with the rest of a file past a torn record.
+func NewStore(directory, format string) (*Store, error) {
+	if err := validateFormat(format); err != nil {
+		return nil, err
+	}
+	store := &Store{
+		directory:  directory,
+		format:     format,
+		traces:     map[model.TraceID]*traceEntry{},
+		operations: map[string]map[string]struct{}{},
+	}
+	infos, err := ioutil.ReadDir(directory)
+	if err != nil {
+		return nil, err
+	}
+	for _, info := range infos {
+		if info.IsDir() || formatOf(info.Name()) == "" {
+			continue
+		}
+		if err := store.load(filepath.Join(directory, info.Name())); err != nil {
+			store.Close()
+			return nil, err
+		}
+	}
+	return store, nil
+}
+
+func (s *Store) load(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	tf := &traceFile{path: path, format: formatOf(path), file: file}
+	s.files = append(s.files, tf)
+	reader := newRecordReader(file, tf.format)
+	for {
+		spans, offset, size, err := reader.next()
+		if err == io.EOF {
+			return nil
+		}
+		if err == nil {
+			err = validateSpans(spans)
+		}
+		if err != nil {
+			s.skip(errors.Wrapf(err, "cannot load %s at offset %d", path, offset))
+			if _, ok := err.(invalidRecordError); !ok {
+				return nil
+			}
+			continue
+		}
+		s.indexRecord(recordLocation{file: tf, offset: offset, size: size}, spans)
+	}
+}
+
+func validateSpans(spans []*model.Span) error {
+	for _, span := range spans {
+		if span.Process == nil {
+			return invalidRecordError{fmt.Errorf("span %s has no process", span.SpanID)}
+		}
+	}
+	return nil
+}
+
+func (s *Store) skip(err error) {
+	if s.skipped == 0 {
+		s.skipErr = err
+	}
+	s.skipped++
+}
+
+func (s *Store) indexRecord(location recordLocation, spans []*model.Span) {
+	for _, span := range spans {
+		entry, ok := s.traces[span.TraceID]
+		if !ok {
+			entry = &traceEntry{traceID: span.TraceID, startTime: span.StartTime}
+			s.traces[span.TraceID] = entry
+		}
+		if len(entry.records) == 0 || entry.records[len(entry.records)-1] != location {
+			entry.records = append(entry.records, location)
+		}
+		entry.spans = append(entry.spans, summarize(span))
+		if span.StartTime.Before(entry.startTime) {
+			entry.startTime = span.StartTime
+		}
+		service := span.Process.ServiceName
+		if _, ok := s.operations[service]; !ok {
+			s.operations[service] = map[string]struct{}{}
+		}
+		s.operations[service][span.OperationName] = struct{}{}
+	}
+}
+
+func summarize(span *model.Span) spanSummary {
+	return spanSummary{
+		service:   span.Process.ServiceName,
+		operation: span.OperationName,
+		startTime: span.StartTime,
+		duration:  span.Duration,
+	}
+}
+
+// WriteSpan appends the span to the output file of the store. When a write fails, the output
+// file is truncated back to its last complete record, and the next spans go to a new file.
+func (s *Store) WriteSpan(span *model.Span) error {
+	records, err := encodeRecords(s.format, &model.Trace{Spans: []*model.Span{span}})
+	if err != nil {
+		return err
+	}
+	s.Lock()
+	defer s.Unlock()
+	if s.output == nil {
+		if err := s.createOutput(); err != nil {
+			return err
+		}
+	}
+	for _, record := range records {
+		if _, err := s.output.file.Write(record); err != nil {
+			// a torn record left behind is skipped when the file is loaded again
+			os.Truncate(s.output.path, s.output.size)
+			s.output = nil
+			return err
+		}
+		location := recordLocation{file: s.output, offset: s.output.size, size: int64(len(record))}
+		s.output.size += location.size
+		s.indexRecord(location, []*model.Span{span})
+	}
+	return nil
+}
+
+func (s *Store) createOutput() error {
+	path := filepath.Join(s.directory, fmt.Sprintf("traces-%d%s", time.Now().UnixNano(), extensions[s.format]))
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	s.output = &traceFile{path: path, format: s.format, file: file}
+	s.files = append(s.files, s.output)
+	return nil
+}
+
+// GetTrace reads the trace from the files.
+func (s *Store) GetTrace(ctx context.Context, traceID model.TraceID) (*model.Trace, error) {
+	s.RLock()
+	defer s.RUnlock()
+	entry, ok := s.traces[traceID]
+	if !ok {
+		return nil, spanstore.ErrTraceNotFound
+	}
+	return s.readTrace(entry)
+}
+
+// readTrace reads the records of the trace, keeping only its own spans as the records of
+// JSON files may hold other traces. The caller must hold the read lock.
+func (s *Store) readTrace(entry *traceEntry) (*model.Trace, error) {
+	trace := &model.Trace{}
+	for _, location := range entry.records {
+		record := make([]byte, location.size)
+		if _, err := location.file.file.ReadAt(record, location.offset); err != nil {
+			return nil, errors.Wrapf(err, "cannot read %s at offset %d", location.file.path, location.offset)
+		}
+		spans, err := decodeRecord(location.file.format, record)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot decode %s at offset %d", location.file.path, location.offset)
+		}
+		for _, span := range spans {
+			if span.TraceID == entry.traceID {
+				trace.Spans = append(trace.Spans, span)
+			}
+		}
+	}
+	return trace, nil
+}
+
+// GetServices returns the services of the spans.
+func (s *Store) GetServices(ctx context.Context) ([]string, error) {
+	s.RLock()
+	defer s.RUnlock()
+	var retMe []string
+	for service := range s.operations {
+		retMe = append(retMe, service)
+	}
+	return retMe, nil
+}
+
+// GetOperations returns the operations of the spans of the service.
+func (s *Store) GetOperations(ctx context.Context, service string) ([]string, error) {
+	s.RLock()
+	defer s.RUnlock()
+	var retMe []string
+	for operation := range s.operations[service] {
+		retMe = append(retMe, operation)
+	}
+	return retMe, nil
+}
+
+// FindTraces returns the traces matching the query.
+func (s *Store) FindTraces(ctx context.Context, query *spanstore.TraceQueryParameters) ([]*model.Trace, error) {
+	s.RLock()
+	defer s.RUnlock()
+	entries, err := s.findTraces(query)
+	if err != nil {
+		return nil, err
+	}
+	retMe := make([]*model.Trace, len(entries))
+	for i, entry := range entries {
+		if retMe[i], err = s.readTrace(entry); err != nil {
+			return nil, err
+		}
+	}
+	return retMe, nil
+}
+
+// FindTraceIDs returns the IDs of the traces matching the query.
+func (s *Store) FindTraceIDs(ctx context.Context, query *spanstore.TraceQueryParameters) ([]model.TraceID, error) {
+	s.RLock()
+	defer s.RUnlock()
+	entries, err := s.findTraces(query)
+	if err != nil {
+		return nil, err
+	}
+	retMe := make([]model.TraceID, len(entries))
+	for i, entry := range entries {
+		retMe[i] = entry.traceID
+	}
+	return retMe, nil
+}
+
+// findTraces looks the traces up from the newest, so that at most query.NumTraces of them
+// are read when searching by tags. Unlike the other storages, an empty query.ServiceName
+// matches every service, which lets the dependencies be computed from all the traces.
+// The structure hash is not indexed and left to the query service. The caller must hold
+// the read lock.
+func (s *Store) findTraces(query *spanstore.TraceQueryParameters) ([]*traceEntry, error) {
+	var candidates []*traceEntry
+	for _, entry := range s.traces {
+		for _, span := range entry.spans {
+			if matchesSpan(span, query) {
+				candidates = append(candidates, entry)
+				break
+			}
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[j].before(candidates[i])
+	})
+
+	var retMe []*traceEntry
+	for _, entry := range candidates {
+		if query.NumTraces > 0 && len(retMe) == query.NumTraces {
+			break
+		}
+		if len(query.Tags) > 0 {
+			trace, err := s.readTrace(entry)
+			if err != nil {
+				return nil, err
+			}
+			if !matchesTags(trace, query) {
+				continue
+			}
+		}
+		retMe = append(retMe, entry)
+	}
+
+	// Query result order doesn't matter, as the query frontend will sort them anyway,
+	// but keep it stable from oldest to newest trace.
+	sort.Slice(retMe, func(i, j int) bool {
+		return retMe[i].before(retMe[j])
+	})
+	return retMe, nil
+}
+
+// before orders the traces by start time, and by ID to be deterministic.
+func (entry *traceEntry) before(other *traceEntry) bool {
+	if !entry.startTime.Equal(other.startTime) {
+		return entry.startTime.Before(other.startTime)
+	}
+	if entry.traceID.High != other.traceID.High {
+		return entry.traceID.High < other.traceID.High
+	}
+	return entry.traceID.Low < other.traceID.Low
+}
+
+func matchesSpan(span spanSummary, query *spanstore.TraceQueryParameters) bool {
+	if query.ServiceName != "" && query.ServiceName != span.service {
+		return false
+	}
+	if query.OperationName != "" && query.OperationName != span.operation {
+		return false
+	}
+	if query.DurationMin != 0 && span.duration < query.DurationMin {
+		return false
+	}
+	if query.DurationMax != 0 && span.duration > query.DurationMax {
+		return false
+	}
+	if !query.StartTimeMin.IsZero() && span.startTime.Before(query.StartTimeMin) {
+		return false
+	}
+	if !query.StartTimeMax.IsZero() && span.startTime.After(query.StartTimeMax) {
+		return false
+	}
+	return true
+}
+
+// matchesTags returns whether a span of the trace matching the query has all its tags,
+// looking them up in the tags of the span, of its process and of its logs.
+func matchesTags(trace *model.Trace, query *spanstore.TraceQueryParameters) bool {
+	for _, span := range trace.Spans {
+		if !matchesSpan(summarize(span), query) {
+			continue
+		}
+		kvs := flattenTags(span)
+		matches := true
+		for key, value := range query.Tags {
+			if !hasKeyValue(kvs, key, value) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return true
+		}
+	}
+	return false
+}
+
+func flattenTags(span *model.Span) model.KeyValues {
+	retMe := append(model.KeyValues{}, span.Tags...)
+	retMe = append(retMe, span.Process.Tags...)
+	for _, l := range span.Logs {
+		retMe = append(retMe, l.Fields...)
+	}
+	return retMe
+}
+
+// hasKeyValue does not use KeyValues.FindKey, since a key may have several values.
+func hasKeyValue(kvs model.KeyValues, key, value string) bool {
+	for _, kv := range kvs {
+		if kv.Key == key && kv.AsString() == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Close closes the trace files.
+func (s *Store) Close() error {
+	s.Lock()
+	defer s.Unlock()
+	var firstErr error
+	for _, tf := range s.files {
+		if err := tf.file.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	s.files = nil
+	s.output = nil
+	return firstErr
+}
@@ -0,0 +1,386 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+var (
+	_ spanstore.Reader = new(Store)
+	_ spanstore.Writer = new(Store)
+)
+
+var baseTime = time.Unix(1500000000, 0).UTC()
+
+func newSpan(traceID, spanID uint64, service, operation string, startTime time.Time) *model.Span {
+	return &model.Span{
+		TraceID:       model.NewTraceID(0, traceID),
+		SpanID:        model.NewSpanID(spanID),
+		OperationName: operation,
+		StartTime:     startTime,
+		Duration:      time.Duration(spanID) * time.Millisecond,
+		Tags:          model.KeyValues{model.Int64("span.id", int64(spanID))},
+		Process: &model.Process{
+			ServiceName: service,
+			Tags:        model.KeyValues{model.String("hostname", service+"-host")},
+		},
+		Logs: []model.Log{
+			{Timestamp: startTime, Fields: model.KeyValues{model.String("event", operation)}},
+		},
+	}
+}
+
+func withStore(t *testing.T, format string, fn func(dir string, store *Store)) {
+	dir, err := ioutil.TempDir("", "file-store")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	store, err := NewStore(dir, format)
+	require.NoError(t, err)
+	defer store.Close()
+	fn(dir, store)
+}
+
+func writeSpans(t *testing.T, store *Store, spans ...*model.Span) {
+	for _, span := range spans {
+		require.NoError(t, store.WriteSpan(span))
+	}
+}
+
+func TestStoreReload(t *testing.T) {
+	for _, format := range []string{FormatJSON, FormatProtobuf} {
+		t.Run(format, func(t *testing.T) {
+			withStore(t, format, func(dir string, store *Store) {
+				writeSpans(t, store,
+					newSpan(1, 1, "frontend", "GET /", baseTime),
+					newSpan(2, 2, "frontend", "GET /users", baseTime.Add(time.Second)),
+					newSpan(1, 3, "backend", "query", baseTime.Add(time.Millisecond)),
+				)
+				require.NoError(t, store.Close())
+
+				files, err := filepath.Glob(filepath.Join(dir, "traces-*"+extensions[format]))
+				require.NoError(t, err)
+				assert.Len(t, files, 1)
+
+				reloaded, err := NewStore(dir, format)
+				require.NoError(t, err)
+				defer reloaded.Close()
+				assert.Len(t, reloaded.traces, 2)
+
+				trace, err := reloaded.GetTrace(context.Background(), model.NewTraceID(0, 1))
+				require.NoError(t, err)
+				require.Len(t, trace.Spans, 2)
+				assert.Equal(t, model.NewSpanID(1), trace.Spans[0].SpanID)
+				assert.Equal(t, "frontend", trace.Spans[0].Process.ServiceName)
+				assert.Equal(t, model.NewSpanID(3), trace.Spans[1].SpanID)
+				assert.Equal(t, "query", trace.Spans[1].OperationName)
+				assert.True(t, baseTime.Add(time.Millisecond).Equal(trace.Spans[1].StartTime))
+
+				services, err := reloaded.GetServices(context.Background())
+				require.NoError(t, err)
+				sort.Strings(services)
+				assert.Equal(t, []string{"backend", "frontend"}, services)
+				operations, err := reloaded.GetOperations(context.Background(), "frontend")
+				require.NoError(t, err)
+				sort.Strings(operations)
+				assert.Equal(t, []string{"GET /", "GET /users"}, operations)
+
+				// the written spans go to a new file, next to the loaded one
+				writeSpans(t, reloaded, newSpan(1, 4, "backend", "cache", baseTime.Add(2*time.Millisecond)))
+				trace, err = reloaded.GetTrace(context.Background(), model.NewTraceID(0, 1))
+				require.NoError(t, err)
+				assert.Len(t, trace.Spans, 3)
+				files, err = filepath.Glob(filepath.Join(dir, "traces-*"+extensions[format]))
+				require.NoError(t, err)
+				assert.Len(t, files, 2)
+			})
+		})
+	}
+}
+
+func TestStoreGetTraceNotFound(t *testing.T) {
+	withStore(t, FormatJSON, func(dir string, store *Store) {
+		trace, err := store.GetTrace(context.Background(), model.NewTraceID(0, 1))
+		assert.Equal(t, spanstore.ErrTraceNotFound, err)
+		assert.Nil(t, trace)
+	})
+}
+
+func TestStoreFindTraces(t *testing.T) {
+	withStore(t, FormatProtobuf, func(dir string, store *Store) {
+		writeSpans(t, store,
+			newSpan(1, 1, "frontend", "GET /", baseTime),
+			newSpan(1, 2, "backend", "query", baseTime.Add(time.Millisecond)),
+			newSpan(2, 3, "frontend", "GET /users", baseTime.Add(time.Second)),
+			newSpan(3, 4, "frontend", "GET /", baseTime.Add(2*time.Second)),
+		)
+		traceIDs := func(ids ...uint64) []model.TraceID {
+			var retMe []model.TraceID
+			for _, id := range ids {
+				retMe = append(retMe, model.NewTraceID(0, id))
+			}
+			return retMe
+		}
+		testCases := []struct {
+			caption  string
+			query    spanstore.TraceQueryParameters
+			expected []model.TraceID
+		}{
+			{
+				caption:  "service",
+				query:    spanstore.TraceQueryParameters{ServiceName: "frontend"},
+				expected: traceIDs(1, 2, 3),
+			},
+			{
+				caption:  "any service",
+				query:    spanstore.TraceQueryParameters{},
+				expected: traceIDs(1, 2, 3),
+			},
+			{
+				caption:  "operation",
+				query:    spanstore.TraceQueryParameters{ServiceName: "frontend", OperationName: "GET /"},
+				expected: traceIDs(1, 3),
+			},
+			{
+				caption:  "child service",
+				query:    spanstore.TraceQueryParameters{ServiceName: "backend"},
+				expected: traceIDs(1),
+			},
+			{
+				caption: "start time",
+				query: spanstore.TraceQueryParameters{
+					ServiceName:  "frontend",
+					StartTimeMin: baseTime.Add(time.Millisecond),
+					StartTimeMax: baseTime.Add(time.Second),
+				},
+				expected: traceIDs(2),
+			},
+			{
+				caption:  "duration",
+				query:    spanstore.TraceQueryParameters{DurationMin: 2 * time.Millisecond, DurationMax: 3 * time.Millisecond},
+				expected: traceIDs(1, 2),
+			},
+			{
+				caption:  "most recent",
+				query:    spanstore.TraceQueryParameters{ServiceName: "frontend", NumTraces: 2},
+				expected: traceIDs(2, 3),
+			},
+			{
+				caption:  "span tag",
+				query:    spanstore.TraceQueryParameters{Tags: map[string]string{"span.id": "3"}},
+				expected: traceIDs(2),
+			},
+			{
+				caption:  "process and log tags",
+				query:    spanstore.TraceQueryParameters{Tags: map[string]string{"hostname": "backend-host", "event": "query"}},
+				expected: traceIDs(1),
+			},
+			{
+				caption: "tags of another span",
+				query: spanstore.TraceQueryParameters{
+					ServiceName: "frontend",
+					Tags:        map[string]string{"hostname": "backend-host"},
+				},
+				expected: []model.TraceID{},
+			},
+			{
+				caption:  "tags with limit",
+				query:    spanstore.TraceQueryParameters{Tags: map[string]string{"hostname": "frontend-host"}, NumTraces: 1},
+				expected: traceIDs(3),
+			},
+		}
+		for _, testCase := range testCases {
+			testCase := testCase // capture loop var
+			t.Run(testCase.caption, func(t *testing.T) {
+				ids, err := store.FindTraceIDs(context.Background(), &testCase.query)
+				require.NoError(t, err)
+				assert.Equal(t, testCase.expected, ids)
+
+				traces, err := store.FindTraces(context.Background(), &testCase.query)
+				require.NoError(t, err)
+				require.Len(t, traces, len(testCase.expected))
+				for i, trace := range traces {
+					assert.Equal(t, testCase.expected[i], trace.Spans[0].TraceID)
+				}
+			})
+		}
+	})
+}
+
+func TestStoreLoadUIJSON(t *testing.T) {
+	withStore(t, FormatJSON, func(dir string, _ *Store) {
+		// as returned by the /api/traces endpoint, spread over several lines and files
+		lines := `{"traceID":"1","spans":[{"traceID":"1","spanID":"2","operationName":"GET /","references":[],` +
+			`"startTime":1485467191639875,"duration":5,"tags":[{"key":"http.status_code","type":"int64","value":200}],` +
+			`"logs":[],"processID":"p1","warnings":null}],"processes":{"p1":{"serviceName":"frontend","tags":[]}},"warnings":null}` +
+			"\n\n" +
+			`{"traceID":"1","spans":[{"traceID":"1","spanID":"3","operationName":"query",` +
+			`"references":[{"refType":"CHILD_OF","traceID":"1","spanID":"2"}],` +
+			`"startTime":1485467191639876,"duration":3,"tags":[],"logs":[],"processID":"p1","warnings":null}],` +
+			`"processes":{"p1":{"serviceName":"backend","tags":[]}},"warnings":null}`
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "ui.json"), []byte(lines), 0644))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "other.json"), []byte(`{"traceID":"2","spans":[],"processes":{}}`), 0644))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "README.md"), []byte("not a trace file"), 0644))
+		require.NoError(t, os.Mkdir(filepath.Join(dir, "archive.json"), 0755))
+
+		store, err := NewStore(dir, FormatJSON)
+		require.NoError(t, err)
+		defer store.Close()
+
+		trace, err := store.GetTrace(context.Background(), model.NewTraceID(0, 1))
+		require.NoError(t, err)
+		require.Len(t, trace.Spans, 2)
+		assert.Equal(t, "frontend", trace.Spans[0].Process.ServiceName)
+		assert.Equal(t, int64(200), trace.Spans[0].Tags[0].Int64())
+		assert.Equal(t, "backend", trace.Spans[1].Process.ServiceName)
+		assert.Equal(t, model.NewSpanID(2), trace.Spans[1].ParentSpanID())
+
+		ids, err := store.FindTraceIDs(context.Background(), &spanstore.TraceQueryParameters{
+			Tags: map[string]string{"http.status_code": "200"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []model.TraceID{model.NewTraceID(0, 1)}, ids)
+	})
+}
+
+// encodeTestRecord returns the records of a trace of a single span.
+func encodeTestRecord(t *testing.T, format string, traceID uint64) []byte {
+	records, err := encodeRecords(format, &model.Trace{Spans: []*model.Span{newSpan(traceID, 1, "frontend", "GET", baseTime)}})
+	require.NoError(t, err)
+	return records[0]
+}
+
+func TestStoreSkipsInvalidRecords(t *testing.T) {
+	concat := func(records ...[]byte) []byte {
+		var content []byte
+		for _, record := range records {
+			content = append(content, record...)
+		}
+		return content
+	}
+	json1, json2 := encodeTestRecord(t, FormatJSON, 1), encodeTestRecord(t, FormatJSON, 2)
+	pb1, pb2 := encodeTestRecord(t, FormatProtobuf, 1), encodeTestRecord(t, FormatProtobuf, 2)
+
+	testCases := []struct {
+		caption  string
+		file     string
+		content  []byte
+		expected []model.TraceID
+		err      string
+	}{
+		{
+			caption:  "invalid JSON",
+			file:     "traces.json",
+			content:  concat(json1, []byte("{\"traceID\"\n"), json2),
+			expected: []model.TraceID{model.NewTraceID(0, 1), model.NewTraceID(0, 2)},
+			err:      fmt.Sprintf("at offset %d: unexpected EOF", len(json1)),
+		},
+		{
+			caption:  "invalid span",
+			file:     "traces.json",
+			content:  concat([]byte(`{"traceID":"1","spans":[{"traceID":"x","spanID":"2"}]}`+"\n"), json2),
+			expected: []model.TraceID{model.NewTraceID(0, 2)},
+			err:      `at offset 0: invalid span 2: strconv.ParseUint: parsing "x": invalid syntax`,
+		},
+		{
+			caption:  "torn protobuf",
+			file:     "traces.pb",
+			content:  concat(pb1, []byte{10, 1, 2}),
+			expected: []model.TraceID{model.NewTraceID(0, 1)},
+			err:      fmt.Sprintf("at offset %d: cannot read the record: unexpected EOF", len(pb1)),
+		},
+		{
+			caption:  "invalid protobuf",
+			file:     "traces.pb",
+			content:  concat(pb1, []byte{2, 0xff, 0xff}, pb2),
+			expected: []model.TraceID{model.NewTraceID(0, 1), model.NewTraceID(0, 2)},
+			err:      fmt.Sprintf("at offset %d: ", len(pb1)),
+		},
+		{
+			caption:  "span without process",
+			file:     "traces.pb",
+			content:  concat([]byte{0}, pb2),
+			expected: []model.TraceID{model.NewTraceID(0, 2)},
+			err:      "at offset 0: span 0 has no process",
+		},
+	}
+	for _, testCase := range testCases {
+		testCase := testCase // capture loop var
+		t.Run(testCase.caption, func(t *testing.T) {
+			withStore(t, FormatJSON, func(dir string, _ *Store) {
+				path := filepath.Join(dir, testCase.file)
+				require.NoError(t, ioutil.WriteFile(path, testCase.content, 0644))
+				store, err := NewStore(dir, FormatJSON)
+				require.NoError(t, err)
+				defer store.Close()
+
+				ids, err := store.FindTraceIDs(context.Background(), &spanstore.TraceQueryParameters{})
+				require.NoError(t, err)
+				assert.Equal(t, testCase.expected, ids)
+				assert.Equal(t, 1, store.skipped)
+				require.Error(t, store.skipErr)
+				assert.Contains(t, store.skipErr.Error(), fmt.Sprintf("cannot load %s %s", path, testCase.err))
+			})
+		})
+	}
+}
+
+func TestStoreWriteFailure(t *testing.T) {
+	withStore(t, FormatJSON, func(dir string, store *Store) {
+		writeSpans(t, store, newSpan(1, 1, "frontend", "GET /", baseTime))
+		failed := store.output
+		size := failed.size
+		// a partially written record, and an output file which can no longer be written
+		_, err := failed.file.Write([]byte(`{"trace`))
+		require.NoError(t, err)
+		require.NoError(t, failed.file.Close())
+		failed.file, err = os.Open(failed.path)
+		require.NoError(t, err)
+
+		assert.Error(t, store.WriteSpan(newSpan(2, 2, "frontend", "GET /users", baseTime)))
+		info, err := os.Stat(failed.path)
+		require.NoError(t, err)
+		assert.Equal(t, size, info.Size(), "the partial record is truncated")
+
+		// the next spans go to a new file, and the offsets of both files are right
+		writeSpans(t, store, newSpan(2, 3, "frontend", "GET /users", baseTime))
+		assert.NotEqual(t, failed, store.output)
+		for _, traceID := range []uint64{1, 2} {
+			trace, err := store.GetTrace(context.Background(), model.NewTraceID(0, traceID))
+			require.NoError(t, err)
+			assert.Len(t, trace.Spans, 1)
+		}
+	})
+}
+
+func TestStoreLoadErrors(t *testing.T) {
+	_, err := NewStore("/non-existent-directory", FormatJSON)
+	assert.Error(t, err)
+	_, err = NewStore(os.TempDir(), "csv")
+	assert.EqualError(t, err, `unknown format "csv", expected json or protobuf`)
+}
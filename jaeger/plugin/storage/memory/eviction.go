@@ -0,0 +1,154 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"container/heap"
+	"time"
+
+	"github.com/uber/jaeger-lib/metrics"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// maxExpiryInterval bounds the interval between two evictions of the expired traces, which
+// are otherwise only evicted when spans are written, see startExpiry.
+const maxExpiryInterval = time.Minute
+
+// storeMetrics tracks the evictions of traces and the footprint of the store.
+type storeMetrics struct {
+	EvictedByCount metrics.Counter `metric:"evicted-traces" tags:"reason=max-traces"`
+	EvictedByAge   metrics.Counter `metric:"evicted-traces" tags:"reason=max-age"`
+	EvictedBySize  metrics.Counter `metric:"evicted-traces" tags:"reason=max-bytes"`
+
+	Traces metrics.Gauge `metric:"traces"`
+	Spans  metrics.Gauge `metric:"spans"`
+	// Bytes is the approximate size of the spans, as computed by model.Span.Size
+	Bytes metrics.Gauge `metric:"bytes"`
+}
+
+// retainedTrace is the footprint of a stored trace.
+type retainedTrace struct {
+	traceID model.TraceID
+	// lastStartTime is the start time of the most recent span of the trace
+	lastStartTime time.Time
+	bytes         int64
+	// index is the position of the trace in the retentionQueue
+	index int
+}
+
+// retentionQueue is a heap of the stored traces ordered by the start time of their most
+// recent span, so that its first trace is both the next one to expire and the one to evict
+// when the store exceeds its byte budget.
+type retentionQueue []*retainedTrace
+
+func (q retentionQueue) Len() int { return len(q) }
+
+func (q retentionQueue) Less(i, j int) bool {
+	return q[i].lastStartTime.Before(q[j].lastStartTime)
+}
+
+func (q retentionQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *retentionQueue) Push(x interface{}) {
+	trace := x.(*retainedTrace)
+	trace.index = len(*q)
+	*q = append(*q, trace)
+}
+
+func (q *retentionQueue) Pop() interface{} {
+	old := *q
+	trace := old[len(old)-1]
+	old[len(old)-1] = nil
+	*q = old[:len(old)-1]
+	return trace
+}
+
+// retainSpan adds the span to the footprint of the store and of its trace.
+func (m *Store) retainSpan(span *model.Span) {
+	size := int64(span.Size())
+	m.spans++
+	m.bytes += size
+	trace, ok := m.retained[span.TraceID]
+	if !ok {
+		trace = &retainedTrace{traceID: span.TraceID, lastStartTime: span.StartTime}
+		m.retained[span.TraceID] = trace
+		heap.Push(&m.retention, trace)
+	}
+	trace.bytes += size
+	if span.StartTime.After(trace.lastStartTime) {
+		trace.lastStartTime = span.StartTime
+		heap.Fix(&m.retention, trace.index)
+	}
+}
+
+// releaseTrace removes the footprint of an evicted trace of the given number of spans.
+func (m *Store) releaseTrace(traceID model.TraceID, spans int) {
+	m.spans -= spans
+	if trace, ok := m.retained[traceID]; ok {
+		heap.Remove(&m.retention, trace.index)
+		delete(m.retained, traceID)
+		m.bytes -= trace.bytes
+	}
+}
+
+// evictExcess evicts the traces whose most recent span is older than the maximum age, then
+// the least recent traces until the store fits in its byte budget. The indexes are rebuilt
+// at most once, after all the evictions, including the ones made by WriteSpan beforehand.
+func (m *Store) evictExcess() {
+	if m.config.MaxAge > 0 {
+		expiry := m.timeNow().Add(-m.config.MaxAge)
+		for len(m.retention) > 0 && m.retention[0].lastStartTime.Before(expiry) {
+			m.evictTrace(m.retention[0].traceID)
+			m.metrics.EvictedByAge.Inc(1)
+		}
+	}
+	for m.config.MaxBytes > 0 && m.bytes > m.config.MaxBytes && len(m.retention) > 0 {
+		m.evictTrace(m.retention[0].traceID)
+		m.metrics.EvictedBySize.Inc(1)
+	}
+	m.compactIndexes()
+}
+
+// startExpiry periodically evicts the expired traces, so that they are evicted even when no
+// spans are written. It is stopped by Close.
+func (m *Store) startExpiry() {
+	if m.config.MaxAge <= 0 {
+		return
+	}
+	interval := maxExpiryInterval
+	if m.config.MaxAge < interval {
+		interval = m.config.MaxAge
+	}
+	m.runEvery(interval, m.evictExpired)
+}
+
+// evictExpired evicts the expired traces when no spans are written.
+func (m *Store) evictExpired() {
+	m.Lock()
+	defer m.Unlock()
+	m.evictExcess()
+	m.updateFootprint()
+}
+
+func (m *Store) updateFootprint() {
+	m.metrics.Traces.Update(int64(len(m.traces)))
+	m.metrics.Spans.Update(int64(m.spans))
+	m.metrics.Bytes.Update(m.bytes)
+}
@@ -0,0 +1,191 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber/jaeger-lib/metrics"
+	"github.com/uber/jaeger-lib/metrics/metricstest"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/memory/config"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+func newEvictionSpan(traceID uint64, startTime time.Time) *model.Span {
+	return &model.Span{
+		TraceID:       model.NewTraceID(1, traceID),
+		SpanID:        model.NewSpanID(traceID),
+		OperationName: "operationName",
+		StartTime:     startTime,
+		Process:       &model.Process{ServiceName: "serviceName"},
+	}
+}
+
+func assertStoredTraces(t *testing.T, store *Store, stored map[uint64]bool) {
+	for traceID, expected := range stored {
+		_, err := store.GetTrace(context.Background(), model.NewTraceID(1, traceID))
+		assert.Equal(t, expected, err == nil, "trace %d", traceID)
+	}
+}
+
+func TestStoreEvictsByAge(t *testing.T) {
+	metricsFactory := metricstest.NewFactory(0)
+	store := newStore(config.Configuration{MaxAge: time.Hour}, metricsFactory.Namespace(metrics.NSOptions{Name: "memory"}))
+	now := time.Unix(100000, 0)
+	store.timeNow = func() time.Time { return now }
+
+	require.NoError(t, store.WriteSpan(newEvictionSpan(1, now.Add(-50*time.Minute))))
+	require.NoError(t, store.WriteSpan(newEvictionSpan(2, now.Add(-10*time.Minute))))
+	// the most recent span of the third trace keeps it alive
+	require.NoError(t, store.WriteSpan(newEvictionSpan(3, now.Add(-55*time.Minute))))
+	require.NoError(t, store.WriteSpan(newEvictionSpan(3, now.Add(-time.Minute))))
+	// spans older than the maximum age are evicted as soon as they are written
+	require.NoError(t, store.WriteSpan(newEvictionSpan(4, now.Add(-2*time.Hour))))
+	assertStoredTraces(t, store, map[uint64]bool{1: true, 2: true, 3: true, 4: false})
+
+	now = now.Add(20 * time.Minute)
+	store.evictExpired()
+	assertStoredTraces(t, store, map[uint64]bool{1: false, 2: true, 3: true})
+
+	metricsFactory.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "memory.evicted-traces|reason=max-age", Value: 2},
+	)
+	metricsFactory.AssertGaugeMetrics(t,
+		metricstest.ExpectedMetric{Name: "memory.traces", Value: 2},
+		metricstest.ExpectedMetric{Name: "memory.spans", Value: 3},
+	)
+}
+
+func TestStoreEvictsBySize(t *testing.T) {
+	start := time.Unix(100000, 0)
+	size := int64(newEvictionSpan(1, start).Size())
+	metricsFactory := metricstest.NewFactory(0)
+	store := newStore(config.Configuration{MaxBytes: 3 * size}, metricsFactory.Namespace(metrics.NSOptions{Name: "memory"}))
+
+	require.NoError(t, store.WriteSpan(newEvictionSpan(1, start)))
+	require.NoError(t, store.WriteSpan(newEvictionSpan(2, start.Add(time.Second))))
+	require.NoError(t, store.WriteSpan(newEvictionSpan(2, start.Add(2*time.Second))))
+	assertStoredTraces(t, store, map[uint64]bool{1: true, 2: true})
+
+	// the least recent trace is evicted to make room for the new one
+	require.NoError(t, store.WriteSpan(newEvictionSpan(3, start.Add(3*time.Second))))
+	assertStoredTraces(t, store, map[uint64]bool{1: false, 2: true, 3: true})
+	assert.Equal(t, 3*size, store.bytes)
+
+	// a trace exceeding the budget on its own is evicted along with all the others
+	require.NoError(t, store.WriteSpan(&model.Span{
+		TraceID:       model.NewTraceID(1, 4),
+		SpanID:        model.NewSpanID(4),
+		OperationName: string(make([]byte, 4*size)),
+		StartTime:     start.Add(4 * time.Second),
+		Process:       &model.Process{ServiceName: "serviceName"},
+	}))
+	assertStoredTraces(t, store, map[uint64]bool{2: false, 3: false, 4: false})
+
+	metricsFactory.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "memory.evicted-traces|reason=max-bytes", Value: 4},
+	)
+	metricsFactory.AssertGaugeMetrics(t,
+		metricstest.ExpectedMetric{Name: "memory.traces", Value: 0},
+		metricstest.ExpectedMetric{Name: "memory.spans", Value: 0},
+		metricstest.ExpectedMetric{Name: "memory.bytes", Value: 0},
+	)
+}
+
+func TestStoreEvictsByCount(t *testing.T) {
+	start := time.Unix(100000, 0)
+	metricsFactory := metricstest.NewFactory(0)
+	store := newStore(config.Configuration{MaxTraces: 2}, metricsFactory.Namespace(metrics.NSOptions{Name: "memory"}))
+	for i := uint64(1); i <= 3; i++ {
+		require.NoError(t, store.WriteSpan(newEvictionSpan(i, start)))
+	}
+	assertStoredTraces(t, store, map[uint64]bool{1: false, 2: true, 3: true})
+
+	metricsFactory.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "memory.evicted-traces|reason=max-traces", Value: 1},
+	)
+	metricsFactory.AssertGaugeMetrics(t,
+		metricstest.ExpectedMetric{Name: "memory.traces", Value: 2},
+		metricstest.ExpectedMetric{Name: "memory.spans", Value: 2},
+		metricstest.ExpectedMetric{Name: "memory.bytes", Value: 2 * newEvictionSpan(1, start).Size()},
+	)
+}
+
+func TestStoreEvictsByCountAfterAge(t *testing.T) {
+	store := newStore(config.Configuration{MaxTraces: 2, MaxAge: time.Hour}, metrics.NullFactory)
+	now := time.Unix(100000, 0)
+	store.timeNow = func() time.Time { return now }
+
+	require.NoError(t, store.WriteSpan(newEvictionSpan(1, now)))
+	now = now.Add(2 * time.Hour)
+	store.evictExpired()
+	assertStoredTraces(t, store, map[uint64]bool{1: false})
+
+	// the slot left behind by the expired trace does not evict its successor
+	require.NoError(t, store.WriteSpan(newEvictionSpan(1, now)))
+	require.NoError(t, store.WriteSpan(newEvictionSpan(2, now)))
+	assertStoredTraces(t, store, map[uint64]bool{1: true, 2: true})
+
+	require.NoError(t, store.WriteSpan(newEvictionSpan(3, now)))
+	assertStoredTraces(t, store, map[uint64]bool{1: false, 2: true, 3: true})
+}
+
+func TestStoreEvictsBatch(t *testing.T) {
+	store := newStore(config.Configuration{MaxAge: time.Hour}, metrics.NullFactory)
+	now := time.Unix(100000, 0)
+	store.timeNow = func() time.Time { return now }
+	for i := uint64(1); i <= 10; i++ {
+		require.NoError(t, store.WriteSpan(newEvictionSpan(i, now.Add(time.Duration(i)*time.Minute))))
+	}
+
+	now = now.Add(67 * time.Minute)
+	store.evictExpired()
+	assertStoredTraces(t, store, map[uint64]bool{6: false, 7: true})
+	// the indexes are rebuilt once all the expired traces are evicted
+	assert.Equal(t, 0, store.staleEntries)
+	assert.Equal(t, 8, store.indexedEntries)
+	traces, err := store.FindTraces(context.Background(), &spanstore.TraceQueryParameters{
+		ServiceName:  "serviceName",
+		StartTimeMin: now.Add(-time.Hour),
+		StartTimeMax: now,
+		NumTraces:    10,
+	})
+	require.NoError(t, err)
+	assert.Len(t, traces, 4)
+}
+
+func TestFactoryEvictsExpiredTraces(t *testing.T) {
+	f := NewFactory()
+	f.options.Configuration = config.Configuration{MaxAge: 10 * time.Millisecond}
+	require.NoError(t, f.Initialize(nil, zap.NewNop()))
+	defer f.Close()
+	require.NoError(t, f.store.WriteSpan(newEvictionSpan(1, time.Now())))
+
+	for i := 0; i < 100; i++ {
+		if _, err := f.store.GetTrace(context.Background(), model.NewTraceID(1, 1)); err != nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	_, err := f.store.GetTrace(context.Background(), model.NewTraceID(1, 1))
+	assert.Equal(t, spanstore.ErrTraceNotFound, err, "the trace is evicted without writing other spans")
+}
@@ -18,6 +18,7 @@ package memory
 import (
 	"flag"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/viper"
 	"github.com/uber/jaeger-lib/metrics"
 	"go.uber.org/zap"
@@ -32,6 +33,7 @@ type Factory struct {
 	metricsFactory metrics.Factory
 	logger         *zap.Logger
 	store          *Store
+	archiveStore   *Store
 }
 
 // NewFactory creates a new Factory.
@@ -52,8 +54,37 @@ func (f *Factory) InitFromViper(v *viper.Viper) {
 // Initialize implements storage.Factory
 func (f *Factory) Initialize(metricsFactory metrics.Factory, logger *zap.Logger) error {
 	f.metricsFactory, f.logger = metricsFactory, logger
-	f.store = WithConfiguration(f.options.Configuration)
-	logger.Info("Memory storage initialized", zap.Any("configuration", f.store.config))
+	if metricsFactory == nil {
+		metricsFactory = metrics.NullFactory
+	}
+	f.store = newStore(f.options.Configuration, metricsFactory.Namespace(metrics.NSOptions{Name: "memory"}))
+	f.archiveStore = newStore(f.options.ArchiveConfiguration, metricsFactory.Namespace(metrics.NSOptions{Name: "memory-archive"}))
+	for _, store := range []*Store{f.store, f.archiveStore} {
+		if err := startSnapshots(store, logger); err != nil {
+			return err
+		}
+		store.startExpiry()
+	}
+	logger.Info("Memory storage initialized",
+		zap.Any("configuration", f.store.config),
+		zap.Any("archive-configuration", f.archiveStore.config))
+	return nil
+}
+
+// startSnapshots restores the snapshot of the store, and saves it periodically, when the
+// store is configured with a snapshot file.
+func startSnapshots(store *Store, logger *zap.Logger) error {
+	file := store.config.SnapshotFile
+	if file == "" {
+		return nil
+	}
+	if err := store.LoadSnapshot(file); err != nil {
+		return errors.Wrap(err, "cannot load memory snapshot")
+	}
+	store.snapshots = &snapshotter{store: store, file: file, logger: logger}
+	if interval := store.config.SnapshotInterval; interval > 0 {
+		store.runEvery(interval, func() { store.snapshots.save() })
+	}
 	return nil
 }
 
@@ -71,3 +102,36 @@ func (f *Factory) CreateSpanWriter() (spanstore.Writer, error) {
 func (f *Factory) CreateDependencyReader() (dependencystore.Reader, error) {
 	return f.store, nil
 }
+
+// CreateArchiveSpanReader implements storage.ArchiveFactory
+func (f *Factory) CreateArchiveSpanReader() (spanstore.Reader, error) {
+	return f.archiveStore, nil
+}
+
+// CreateArchiveSpanWriter implements storage.ArchiveFactory
+func (f *Factory) CreateArchiveSpanWriter() (spanstore.Writer, error) {
+	return f.archiveStore, nil
+}
+
+// CreateStructureReader implements storage.StructureIndexFactory
+func (f *Factory) CreateStructureReader() (spanstore.StructureReader, error) {
+	return f.store, nil
+}
+
+// CreateStructureWriter implements storage.StructureIndexFactory
+func (f *Factory) CreateStructureWriter() (spanstore.StructureWriter, error) {
+	return f.store, nil
+}
+
+// Close implements io.Closer. It stops the periodic tasks of both stores, such as the eviction
+// of the expired traces, and saves their snapshots when they are enabled.
+func (f *Factory) Close() error {
+	if f.store == nil {
+		return nil
+	}
+	err := f.store.Close()
+	if errArchive := f.archiveStore.Close(); err == nil {
+		err = errArchive
+	}
+	return err
+}
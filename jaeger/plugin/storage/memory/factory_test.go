@@ -16,16 +16,27 @@
 package memory
 
 import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 
 	"github.com/jaegertracing/jaeger/pkg/config"
 	"github.com/jaegertracing/jaeger/storage"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
 )
 
 var _ storage.Factory = new(Factory)
+var _ storage.StructureIndexFactory = new(Factory)
+var _ storage.ArchiveFactory = new(Factory)
+var _ io.Closer = new(Factory)
 
 func TestMemoryStorageFactory(t *testing.T) {
 	f := NewFactory()
@@ -40,6 +51,96 @@ func TestMemoryStorageFactory(t *testing.T) {
 	depReader, err := f.CreateDependencyReader()
 	assert.NoError(t, err)
 	assert.Equal(t, f.store, depReader)
+	structureReader, err := f.CreateStructureReader()
+	assert.NoError(t, err)
+	assert.Equal(t, f.store, structureReader)
+	structureWriter, err := f.CreateStructureWriter()
+	assert.NoError(t, err)
+	assert.Equal(t, f.store, structureWriter)
+	archiveReader, err := f.CreateArchiveSpanReader()
+	assert.NoError(t, err)
+	assert.Equal(t, f.archiveStore, archiveReader)
+	archiveWriter, err := f.CreateArchiveSpanWriter()
+	assert.NoError(t, err)
+	assert.Equal(t, f.archiveStore, archiveWriter)
+}
+
+func TestArchiveStorage(t *testing.T) {
+	f := NewFactory()
+	v, command := config.Viperize(f.AddFlags)
+	command.ParseFlags([]string{"--memory.max-traces=1", "--memory.archive.max-traces=2"})
+	f.InitFromViper(v)
+	assert.NoError(t, f.Initialize(nil, zap.NewNop()))
+	assert.Equal(t, 2, f.archiveStore.config.MaxTraces)
+
+	writer, err := f.CreateSpanWriter()
+	assert.NoError(t, err)
+	archiveWriter, err := f.CreateArchiveSpanWriter()
+	assert.NoError(t, err)
+	archiveReader, err := f.CreateArchiveSpanReader()
+	assert.NoError(t, err)
+	assert.NoError(t, writer.WriteSpan(testingSpan))
+	assert.NoError(t, archiveWriter.WriteSpan(testingSpan))
+	// the archived trace outlives the eviction of the primary one
+	assert.NoError(t, writer.WriteSpan(newEvictionSpan(1, time.Unix(0, 0))))
+	_, err = f.store.GetTrace(context.Background(), traceID)
+	assert.Equal(t, spanstore.ErrTraceNotFound, err)
+	trace, err := archiveReader.GetTrace(context.Background(), traceID)
+	assert.NoError(t, err)
+	assert.Len(t, trace.Spans, 1)
+}
+
+func TestArchiveStorageSnapshot(t *testing.T) {
+	dir, err := ioutil.TempDir("", "memory-archive")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	flags := []string{
+		"--memory.snapshot-file=" + filepath.Join(dir, "traces"),
+		"--memory.archive.snapshot-file=" + filepath.Join(dir, "archive"),
+	}
+
+	f := NewFactory()
+	v, command := config.Viperize(f.AddFlags)
+	command.ParseFlags(flags)
+	f.InitFromViper(v)
+	require.NoError(t, f.Initialize(nil, zap.NewNop()))
+	archiveWriter, err := f.CreateArchiveSpanWriter()
+	require.NoError(t, err)
+	require.NoError(t, archiveWriter.WriteSpan(testingSpan))
+	require.NoError(t, f.Close())
+
+	restored := NewFactory()
+	v, command = config.Viperize(restored.AddFlags)
+	command.ParseFlags(flags)
+	restored.InitFromViper(v)
+	require.NoError(t, restored.Initialize(nil, zap.NewNop()))
+	defer restored.Close()
+	archiveReader, err := restored.CreateArchiveSpanReader()
+	require.NoError(t, err)
+	trace, err := archiveReader.GetTrace(context.Background(), traceID)
+	require.NoError(t, err)
+	assert.Len(t, trace.Spans, 1)
+	_, err = restored.store.GetTrace(context.Background(), traceID)
+	assert.Equal(t, spanstore.ErrTraceNotFound, err, "the archived trace is not restored to the primary store")
+}
+
+func TestFactoryClose(t *testing.T) {
+	assert.NoError(t, NewFactory().Close(), "an uninitialized factory has nothing to close")
+
+	f := NewFactory()
+	v, command := config.Viperize(f.AddFlags)
+	command.ParseFlags([]string{"--memory.max-age=1h", "--memory.archive.max-age=1h"})
+	f.InitFromViper(v)
+	assert.NoError(t, f.Initialize(nil, zap.NewNop()))
+	assert.NoError(t, f.Close())
+	for _, store := range []*Store{f.store, f.archiveStore} {
+		select {
+		case <-store.closed:
+		default:
+			t.Error("the store is not closed")
+		}
+	}
+	assert.NoError(t, f.Close(), "closing twice is harmless")
 }
 
 func TestWithConfiguration(t *testing.T) {
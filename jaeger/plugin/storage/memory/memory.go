@@ -17,11 +17,12 @@ package memory
 
 import (
 	"context"
-	"errors"
 	"sort"
 	"sync"
 	"time"
 
+	"github.com/uber/jaeger-lib/metrics"
+
 	"github.com/jaegertracing/jaeger/model"
 	"github.com/jaegertracing/jaeger/model/adjuster"
 	"github.com/jaegertracing/jaeger/pkg/memory/config"
@@ -31,13 +32,108 @@ import (
 // Store is an in-memory store of traces
 type Store struct {
 	sync.RWMutex
-	ids        []*model.TraceID
+	ids        []traceSlot
 	traces     map[model.TraceID]*model.Trace
 	services   map[string]struct{}
 	operations map[string]map[string]struct{}
 	deduper    adjuster.Adjuster
 	config     config.Configuration
 	index      int
+
+	// secondary indexes used by FindTraces and FindTraceIDs, see spanIndex
+	serviceIndex   map[string]spanIndex
+	operationIndex map[string]map[string]spanIndex
+	indexedEntries int
+	staleEntries   int
+
+	// footprint of the traces, and their eviction order by age and size, see retentionQueue
+	retention retentionQueue
+	retained  map[model.TraceID]*retainedTrace
+	spans     int
+	bytes     int64
+	metrics   storeMetrics
+	timeNow   func() time.Time
+
+	// snapshots is set when the store is saved to a snapshot file, see Factory.Initialize
+	snapshots *snapshotter
+
+	// periodic tasks of the store, stopped by Close, see runEvery
+	closed     chan struct{}
+	closeOnce  sync.Once
+	background sync.WaitGroup
+
+	// structural hashes of the complete traces, see WriteStructure
+	structures map[model.TraceID]structureEntry
+}
+
+// structureEntry is the structural hash of a trace, kept with the trace start time so that
+// CountStructures does not need to look at the spans.
+type structureEntry struct {
+	hash      string
+	startTime time.Time
+}
+
+// traceSlot is an entry of the ring of the most recent traces, which evicts the oldest ones
+// beyond MaxTraces. Like indexEntry, it keeps the trace pointer, so that the slot of a trace
+// evicted for age or size does not evict a newer trace with the same ID.
+type traceSlot struct {
+	traceID model.TraceID
+	trace   *model.Trace
+}
+
+// indexEntry points from a secondary index to a stored span. The trace pointer is used
+// to detect entries left behind by evicted traces: such an entry is stale as soon as
+// the trace stored under its trace ID is not the one it was indexed with.
+type indexEntry struct {
+	startTime time.Time
+	duration  time.Duration
+	span      *model.Span
+	trace     *model.Trace
+}
+
+// spanIndex is a list of index entries sorted by span start time, so that time range
+// lookups are a binary search and the newest matches can be read from the end.
+type spanIndex []indexEntry
+
+func (idx spanIndex) insert(entry indexEntry) spanIndex {
+	// spans mostly arrive in time order, so the insertion point is usually at the end
+	i := sort.Search(len(idx), func(i int) bool {
+		return idx[i].startTime.After(entry.startTime)
+	})
+	idx = append(idx, indexEntry{})
+	copy(idx[i+1:], idx[i:])
+	idx[i] = entry
+	return idx
+}
+
+// push appends the entry without keeping the index sorted, see sortByStartTime.
+func (idx spanIndex) push(entry indexEntry) spanIndex {
+	return append(idx, entry)
+}
+
+// sortByStartTime sorts the entries added by push, keeping the order of equal start times
+// like insert does.
+func (idx spanIndex) sortByStartTime() {
+	sort.SliceStable(idx, func(i, j int) bool {
+		return idx[i].startTime.Before(idx[j].startTime)
+	})
+}
+
+// timeRange returns the bounds of the entries whose start time is within [min, max],
+// treating zero values as unbounded.
+func (idx spanIndex) timeRange(min, max time.Time) (int, int) {
+	lo, hi := 0, len(idx)
+	if !min.IsZero() {
+		lo = sort.Search(len(idx), func(i int) bool {
+			return !idx[i].startTime.Before(min)
+		})
+	}
+	if !max.IsZero() {
+		hi = sort.Search(len(idx), func(i int) bool {
+			return idx[i].startTime.After(max)
+		})
+	}
+	return lo, hi
 }
 
 // NewStore creates an unbounded in-memory store
@@ -47,14 +143,26 @@ func NewStore() *Store {
 
 // WithConfiguration creates a new in memory storage based on the given configuration
 func WithConfiguration(configuration config.Configuration) *Store {
-	return &Store{
-		ids:        make([]*model.TraceID, configuration.MaxTraces),
-		traces:     map[model.TraceID]*model.Trace{},
-		services:   map[string]struct{}{},
-		operations: map[string]map[string]struct{}{},
-		deduper:    adjuster.SpanIDDeduper(),
-		config:     configuration,
+	return newStore(configuration, metrics.NullFactory)
+}
+
+func newStore(configuration config.Configuration, metricsFactory metrics.Factory) *Store {
+	store := &Store{
+		ids:            make([]traceSlot, configuration.MaxTraces),
+		traces:         map[model.TraceID]*model.Trace{},
+		services:       map[string]struct{}{},
+		operations:     map[string]map[string]struct{}{},
+		deduper:        adjuster.SpanIDDeduper(),
+		config:         configuration,
+		serviceIndex:   map[string]spanIndex{},
+		operationIndex: map[string]map[string]spanIndex{},
+		structures:     map[model.TraceID]structureEntry{},
+		retained:       map[model.TraceID]*retainedTrace{},
+		timeNow:        time.Now,
+		closed:         make(chan struct{}),
 	}
+	metrics.Init(&store.metrics, metricsFactory, nil)
+	return store
 }
 
 // GetDependencies returns dependencies between services
@@ -130,22 +238,89 @@ func (m *Store) WriteSpan(span *model.Span) error {
 			// we only have to deal with this slice if we have a limit
 			m.index = (m.index + 1) % m.config.MaxTraces
 
-			// do we have an item already on this position? if so, we are overriding it,
+			// do we have a stored trace already on this position? if so, we are overriding it,
 			// and we need to remove from the map
-			if m.ids[m.index] != nil {
-				delete(m.traces, *m.ids[m.index])
+			if slot := m.ids[m.index]; slot.trace != nil && m.traces[slot.traceID] == slot.trace && m.evictTrace(slot.traceID) {
+				m.metrics.EvictedByCount.Inc(1)
 			}
 
-			// update the ring with the trace id
-			m.ids[m.index] = &span.TraceID
+			// update the ring with the trace
+			m.ids[m.index] = traceSlot{traceID: span.TraceID, trace: m.traces[span.TraceID]}
 		}
 
 	}
-	m.traces[span.TraceID].Spans = append(m.traces[span.TraceID].Spans, span)
+	trace := m.traces[span.TraceID]
+	trace.Spans = append(trace.Spans, span)
+	m.indexSpan(span, trace, spanIndex.insert)
+	m.retainSpan(span)
+	m.evictExcess()
+	m.updateFootprint()
 
 	return nil
 }
 
+// indexSpan adds the span to the indexes with add, either spanIndex.insert to keep them
+// sorted or spanIndex.push when they are sorted afterwards.
+func (m *Store) indexSpan(span *model.Span, trace *model.Trace, add func(spanIndex, indexEntry) spanIndex) {
+	entry := indexEntry{
+		startTime: span.StartTime,
+		duration:  span.Duration,
+		span:      span,
+		trace:     trace,
+	}
+	service := span.Process.ServiceName
+	m.serviceIndex[service] = add(m.serviceIndex[service], entry)
+	if _, ok := m.operationIndex[service]; !ok {
+		m.operationIndex[service] = map[string]spanIndex{}
+	}
+	m.operationIndex[service][span.OperationName] = add(m.operationIndex[service][span.OperationName], entry)
+	m.indexedEntries += 2
+}
+
+// evictTrace removes the trace from the store, and returns false if it was not stored. Its
+// index entries are left in place and skipped by lookups until compactIndexes rebuilds the
+// indexes.
+func (m *Store) evictTrace(traceID model.TraceID) bool {
+	trace, ok := m.traces[traceID]
+	if !ok {
+		return false
+	}
+	delete(m.traces, traceID)
+	delete(m.structures, traceID)
+	m.releaseTrace(traceID, len(trace.Spans))
+	m.staleEntries += 2 * len(trace.Spans)
+	return true
+}
+
+// compactIndexes rebuilds the indexes once there are enough stale entries to justify it.
+// It is called after a batch of evictions, rather than after each one.
+func (m *Store) compactIndexes() {
+	if m.staleEntries*2 > m.indexedEntries {
+		m.rebuildIndexes()
+	}
+}
+
+// rebuildIndexes drops the stale entries. The entries are collected in map order and sorted
+// once per index, since inserting them one by one is quadratic.
+func (m *Store) rebuildIndexes() {
+	m.serviceIndex = map[string]spanIndex{}
+	m.operationIndex = map[string]map[string]spanIndex{}
+	m.indexedEntries, m.staleEntries = 0, 0
+	for _, trace := range m.traces {
+		for _, span := range trace.Spans {
+			m.indexSpan(span, trace, spanIndex.push)
+		}
+	}
+	for _, idx := range m.serviceIndex {
+		idx.sortByStartTime()
+	}
+	for _, operations := range m.operationIndex {
+		for _, idx := range operations {
+			idx.sortByStartTime()
+		}
+	}
+}
+
 // GetTrace gets a trace
 func (m *Store) GetTrace(ctx context.Context, traceID model.TraceID) (*model.Trace, error) {
 	m.RLock()
@@ -193,37 +368,103 @@ func (m *Store) GetOperations(ctx context.Context, service string) ([]string, er
 func (m *Store) FindTraces(ctx context.Context, query *spanstore.TraceQueryParameters) ([]*model.Trace, error) {
 	m.RLock()
 	defer m.RUnlock()
-	var retMe []*model.Trace
-	for _, trace := range m.traces {
-		if m.validTrace(trace, query) {
-			retMe = append(retMe, m.copyTrace(trace))
-		}
+	traces := m.findTraces(query)
+	retMe := make([]*model.Trace, len(traces))
+	for i, trace := range traces {
+		retMe[i] = m.copyTrace(trace)
 	}
+	return retMe, nil
+}
 
-	// Query result order doesn't matter, as the query frontend will sort them anyway.
-	// However, if query.NumTraces < results, then we should return the newest traces.
-	if query.NumTraces > 0 && len(retMe) > query.NumTraces {
-		sort.Slice(retMe, func(i, j int) bool {
-			return retMe[i].Spans[0].StartTime.Before(retMe[j].Spans[0].StartTime)
-		})
-		retMe = retMe[len(retMe)-query.NumTraces:]
+// FindTraceIDs returns the IDs of all traces in which the query parameters are satisfied by a span
+func (m *Store) FindTraceIDs(ctx context.Context, query *spanstore.TraceQueryParameters) ([]model.TraceID, error) {
+	m.RLock()
+	defer m.RUnlock()
+	traces := m.findTraces(query)
+	retMe := make([]model.TraceID, len(traces))
+	for i, trace := range traces {
+		retMe[i] = trace.Spans[0].TraceID
 	}
-
 	return retMe, nil
 }
 
-// FindTraceIDs is not implemented.
-func (m *Store) FindTraceIDs(ctx context.Context, query *spanstore.TraceQueryParameters) ([]model.TraceID, error) {
-	return nil, errors.New("not implemented")
+// findTraces looks up the matching traces through the secondary indexes. The index for the
+// queried service (or service and operation) is searched for spans within the time range,
+// newest first, so that only the most recent query.NumTraces traces are ever examined.
+// The caller must hold the read lock.
+func (m *Store) findTraces(query *spanstore.TraceQueryParameters) []*model.Trace {
+	var idx spanIndex
+	if query.OperationName == "" {
+		idx = m.serviceIndex[query.ServiceName]
+	} else {
+		idx = m.operationIndex[query.ServiceName][query.OperationName]
+	}
+	lo, hi := idx.timeRange(query.StartTimeMin, query.StartTimeMax)
+
+	var retMe []*model.Trace
+	found := map[*model.Trace]struct{}{}
+	for i := hi - 1; i >= lo; i-- {
+		entry := idx[i]
+		if query.DurationMin != 0 && entry.duration < query.DurationMin {
+			continue
+		}
+		if query.DurationMax != 0 && entry.duration > query.DurationMax {
+			continue
+		}
+		if _, ok := found[entry.trace]; ok {
+			continue
+		}
+		if m.traces[entry.span.TraceID] != entry.trace {
+			// stale entry of an evicted trace
+			continue
+		}
+		if !m.validSpan(entry.span, query) {
+			continue
+		}
+		if query.StructureHash != "" && m.structures[entry.span.TraceID].hash != query.StructureHash {
+			continue
+		}
+		found[entry.trace] = struct{}{}
+		retMe = append(retMe, entry.trace)
+		if query.NumTraces > 0 && len(retMe) == query.NumTraces {
+			break
+		}
+	}
+
+	// Query result order doesn't matter, as the query frontend will sort them anyway,
+	// but keep it stable from oldest to newest trace.
+	sort.Slice(retMe, func(i, j int) bool {
+		return retMe[i].Spans[0].StartTime.Before(retMe[j].Spans[0].StartTime)
+	})
+	return retMe
 }
 
-func (m *Store) validTrace(trace *model.Trace, query *spanstore.TraceQueryParameters) bool {
-	for _, span := range trace.Spans {
-		if m.validSpan(span, query) {
-			return true
+// WriteStructure implements spanstore.StructureWriter. The hash of a trace which is not
+// in the store, e.g. because it has already been evicted, is discarded.
+func (m *Store) WriteStructure(traceID model.TraceID, startTime time.Time, hash string) error {
+	m.Lock()
+	defer m.Unlock()
+	if _, ok := m.traces[traceID]; ok {
+		m.structures[traceID] = structureEntry{hash: hash, startTime: startTime}
+	}
+	return nil
+}
+
+// CountStructures implements spanstore.StructureReader
+func (m *Store) CountStructures(ctx context.Context, startTimeMin, startTimeMax time.Time) (map[string]int, error) {
+	m.RLock()
+	defer m.RUnlock()
+	counts := map[string]int{}
+	for _, entry := range m.structures {
+		if !startTimeMin.IsZero() && entry.startTime.Before(startTimeMin) {
+			continue
+		}
+		if !startTimeMax.IsZero() && entry.startTime.After(startTimeMax) {
+			continue
 		}
+		counts[entry.hash]++
 	}
-	return false
+	return counts, nil
 }
 
 func findKeyValueMatch(kvs model.KeyValues, key, value string) (model.KeyValue, bool) {
@@ -272,3 +513,35 @@ func (m *Store) flattenTags(span *model.Span) model.KeyValues {
 	}
 	return retMe
 }
+
+// runEvery calls task at every interval until the store is closed.
+func (m *Store) runEvery(interval time.Duration, task func()) {
+	m.background.Add(1)
+	go func() {
+		defer m.background.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-m.closed:
+				return
+			case <-ticker.C:
+				task()
+			}
+		}
+	}()
+}
+
+// Close stops the periodic tasks of the store, and saves a last snapshot of the store when
+// it is configured with a snapshot file.
+func (m *Store) Close() error {
+	var err error
+	m.closeOnce.Do(func() {
+		close(m.closed)
+		m.background.Wait()
+		if m.snapshots != nil {
+			err = m.snapshots.save()
+		}
+	})
+	return err
+}
@@ -21,6 +21,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/jaegertracing/jaeger/model"
 	"github.com/jaegertracing/jaeger/pkg/memory/config"
@@ -377,10 +378,154 @@ func TestStoreGetTrace(t *testing.T) {
 	}
 }
 
-func TestStore_FindTraceIDs(t *testing.T) {
-	withMemoryStore(func(store *Store) {
-		traceIDs, err := store.FindTraceIDs(context.Background(), nil)
-		assert.Nil(t, traceIDs)
-		assert.EqualError(t, err, "not implemented")
+func TestStoreFindTraceIDs(t *testing.T) {
+	withPopulatedMemoryStore(func(store *Store) {
+		traceIDs, err := store.FindTraceIDs(context.Background(), &spanstore.TraceQueryParameters{
+			ServiceName: testingSpan.Process.ServiceName,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []model.TraceID{testingSpan.TraceID}, traceIDs)
+
+		traceIDs, err = store.FindTraceIDs(context.Background(), &spanstore.TraceQueryParameters{
+			ServiceName: "wrongServiceName",
+		})
+		assert.NoError(t, err)
+		assert.Empty(t, traceIDs)
+	})
+}
+
+func TestStoreFindTracesByOperationAndTimeRange(t *testing.T) {
+	memStore := NewStore()
+	for i := 0; i < 10; i++ {
+		operationName := "even"
+		if i%2 == 1 {
+			operationName = "odd"
+		}
+		memStore.WriteSpan(&model.Span{
+			TraceID:       model.NewTraceID(1, uint64(i)),
+			SpanID:        model.NewSpanID(1),
+			OperationName: operationName,
+			Duration:      time.Duration(i) * time.Second,
+			StartTime:     time.Unix(int64(i*60), 0),
+			Process: &model.Process{
+				ServiceName: "serviceName",
+			},
+		})
+	}
+
+	traceIDs, err := memStore.FindTraceIDs(context.Background(), &spanstore.TraceQueryParameters{
+		ServiceName:   "serviceName",
+		OperationName: "odd",
+		StartTimeMin:  time.Unix(2*60, 0),
+		StartTimeMax:  time.Unix(7*60, 0),
+		DurationMin:   4 * time.Second,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []model.TraceID{model.NewTraceID(1, 5), model.NewTraceID(1, 7)}, traceIDs)
+
+	traces, err := memStore.FindTraces(context.Background(), &spanstore.TraceQueryParameters{
+		ServiceName: "serviceName",
+		DurationMax: 2 * time.Second,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, traces, 3)
+}
+
+func TestStoreFindTracesAfterEviction(t *testing.T) {
+	maxTraces := 10
+	store := WithConfiguration(config.Configuration{MaxTraces: maxTraces})
+
+	for i := 0; i < maxTraces*3; i++ {
+		id := model.NewTraceID(1, uint64(i))
+		for j := 0; j < 2; j++ {
+			err := store.WriteSpan(&model.Span{
+				TraceID:       id,
+				SpanID:        model.NewSpanID(uint64(j)),
+				OperationName: "operationName",
+				StartTime:     time.Unix(int64(i), 0),
+				Process: &model.Process{
+					ServiceName: "serviceName",
+				},
+			})
+			assert.NoError(t, err)
+		}
+	}
+
+	traces, err := store.FindTraces(context.Background(), &spanstore.TraceQueryParameters{
+		ServiceName: "serviceName",
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, traces, maxTraces) {
+		for i, trace := range traces {
+			assert.Len(t, trace.Spans, 2)
+			assert.Equal(t, model.NewTraceID(1, uint64(maxTraces*2+i)), trace.Spans[0].TraceID)
+		}
+	}
+	assert.True(t, store.staleEntries*2 <= store.indexedEntries)
+}
+
+func TestStoreRebuildIndexes(t *testing.T) {
+	store := NewStore()
+	for i := 0; i < 100; i++ {
+		// out of time order, over two operations
+		span := newEvictionSpan(uint64(i), time.Unix(int64((i*37)%100), 0))
+		if i%2 == 0 {
+			span.OperationName = "otherOperation"
+		}
+		assert.NoError(t, store.WriteSpan(span))
+	}
+	store.rebuildIndexes()
+
+	assertSorted := func(idx spanIndex) {
+		for i := 1; i < len(idx); i++ {
+			assert.False(t, idx[i].startTime.Before(idx[i-1].startTime))
+		}
+	}
+	assert.Len(t, store.serviceIndex["serviceName"], 100)
+	assertSorted(store.serviceIndex["serviceName"])
+	assert.Len(t, store.operationIndex["serviceName"]["operationName"], 50)
+	assertSorted(store.operationIndex["serviceName"]["operationName"])
+	assert.Len(t, store.operationIndex["serviceName"]["otherOperation"], 50)
+	assertSorted(store.operationIndex["serviceName"]["otherOperation"])
+	assert.Equal(t, 200, store.indexedEntries)
+}
+
+func TestStoreStructures(t *testing.T) {
+	store := WithConfiguration(config.Configuration{MaxTraces: 3})
+	for i := 0; i < 4; i++ {
+		id := model.NewTraceID(1, uint64(i))
+		err := store.WriteSpan(&model.Span{
+			TraceID:       id,
+			SpanID:        model.NewSpanID(1),
+			OperationName: "operationName",
+			StartTime:     time.Unix(int64(i*60), 0),
+			Process: &model.Process{
+				ServiceName: "serviceName",
+			},
+		})
+		require.NoError(t, err)
+		hash := "even"
+		if i%2 == 1 {
+			hash = "odd"
+		}
+		assert.NoError(t, store.WriteStructure(id, time.Unix(int64(i*60), 0), hash))
+	}
+	// the trace is unknown, so its hash is discarded
+	assert.NoError(t, store.WriteStructure(model.NewTraceID(2, 0), time.Unix(0, 0), "odd"))
+
+	// the first trace has been evicted along with its hash
+	counts, err := store.CountStructures(context.Background(), time.Time{}, time.Time{})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"even": 1, "odd": 2}, counts)
+
+	counts, err = store.CountStructures(context.Background(), time.Unix(2*60, 0), time.Unix(3*60, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"even": 1, "odd": 1}, counts)
+
+	traceIDs, err := store.FindTraceIDs(context.Background(), &spanstore.TraceQueryParameters{
+		ServiceName:   "serviceName",
+		StructureHash: "odd",
 	})
+	assert.NoError(t, err)
+	assert.Equal(t, []model.TraceID{model.NewTraceID(1, 1), model.NewTraceID(1, 3)}, traceIDs)
 }
@@ -16,25 +16,58 @@ package memory
 
 import (
 	"flag"
+	"time"
 
 	"github.com/spf13/viper"
 
 	"github.com/jaegertracing/jaeger/pkg/memory/config"
 )
 
-const limit = "memory.max-traces"
+const (
+	limit            = "memory.max-traces"
+	maxAge           = "memory.max-age"
+	maxBytes         = "memory.max-bytes"
+	snapshotFile     = "memory.snapshot-file"
+	snapshotInterval = "memory.snapshot-interval"
+
+	archiveLimit        = "memory.archive.max-traces"
+	archiveMaxAge       = "memory.archive.max-age"
+	archiveMaxBytes     = "memory.archive.max-bytes"
+	archiveSnapshotFile = "memory.archive.snapshot-file"
+
+	defaultSnapshotInterval = time.Minute
+)
 
 // Options stores the configuration entries for this storage
 type Options struct {
 	Configuration config.Configuration
+	// ArchiveConfiguration is the retention of the archived traces, which are kept in a separate store
+	ArchiveConfiguration config.Configuration
 }
 
 // AddFlags from this storage to the CLI
 func (opt *Options) AddFlags(flagSet *flag.FlagSet) {
 	flagSet.Int(limit, opt.Configuration.MaxTraces, "The maximum amount of traces to store in memory")
+	flagSet.Duration(maxAge, opt.Configuration.MaxAge, "The maximum age of the traces to store in memory, based on the start time of their most recent span, unlimited when zero")
+	flagSet.Int64(maxBytes, opt.Configuration.MaxBytes, "The approximate maximum size in bytes of the spans to store in memory, unlimited when zero")
+	flagSet.String(snapshotFile, opt.Configuration.SnapshotFile, "The file the traces are periodically saved to, and restored from on startup, none when empty")
+	flagSet.Duration(snapshotInterval, defaultSnapshotInterval, "The interval between two snapshots of the traces, zero saving them on shutdown only")
+	flagSet.Int(archiveLimit, opt.ArchiveConfiguration.MaxTraces, "The maximum amount of archived traces to store in memory")
+	flagSet.Duration(archiveMaxAge, opt.ArchiveConfiguration.MaxAge, "The maximum age of the archived traces to store in memory, unlimited when zero")
+	flagSet.Int64(archiveMaxBytes, opt.ArchiveConfiguration.MaxBytes, "The approximate maximum size in bytes of the archived spans to store in memory, unlimited when zero")
+	flagSet.String(archiveSnapshotFile, opt.ArchiveConfiguration.SnapshotFile, "The file the archived traces are saved to, as often as the other traces, and restored from on startup, none when empty")
 }
 
 // InitFromViper initializes the options struct with values from Viper
 func (opt *Options) InitFromViper(v *viper.Viper) {
 	opt.Configuration.MaxTraces = v.GetInt(limit)
+	opt.Configuration.MaxAge = v.GetDuration(maxAge)
+	opt.Configuration.MaxBytes = v.GetInt64(maxBytes)
+	opt.Configuration.SnapshotFile = v.GetString(snapshotFile)
+	opt.Configuration.SnapshotInterval = v.GetDuration(snapshotInterval)
+	opt.ArchiveConfiguration.MaxTraces = v.GetInt(archiveLimit)
+	opt.ArchiveConfiguration.MaxAge = v.GetDuration(archiveMaxAge)
+	opt.ArchiveConfiguration.MaxBytes = v.GetInt64(archiveMaxBytes)
+	opt.ArchiveConfiguration.SnapshotFile = v.GetString(archiveSnapshotFile)
+	opt.ArchiveConfiguration.SnapshotInterval = opt.Configuration.SnapshotInterval
 }
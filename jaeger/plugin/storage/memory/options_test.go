@@ -16,6 +16,7 @@ package memory
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -25,8 +26,25 @@ import (
 func TestOptionsWithFlags(t *testing.T) {
 	opts := &Options{}
 	v, command := config.Viperize(opts.AddFlags)
-	command.ParseFlags([]string{"--memory.max-traces=100"})
+	command.ParseFlags([]string{
+		"--memory.max-traces=100",
+		"--memory.max-age=1h",
+		"--memory.max-bytes=1000000",
+		"--memory.snapshot-file=/tmp/traces",
+		"--memory.archive.max-traces=10",
+		"--memory.archive.max-age=720h",
+		"--memory.archive.snapshot-file=/tmp/archive",
+	})
 	opts.InitFromViper(v)
 
 	assert.Equal(t, 100, opts.Configuration.MaxTraces)
+	assert.Equal(t, time.Hour, opts.Configuration.MaxAge)
+	assert.Equal(t, int64(1000000), opts.Configuration.MaxBytes)
+	assert.Equal(t, "/tmp/traces", opts.Configuration.SnapshotFile)
+	assert.Equal(t, time.Minute, opts.Configuration.SnapshotInterval)
+	assert.Equal(t, 10, opts.ArchiveConfiguration.MaxTraces)
+	assert.Equal(t, 720*time.Hour, opts.ArchiveConfiguration.MaxAge)
+	assert.Equal(t, int64(0), opts.ArchiveConfiguration.MaxBytes)
+	assert.Equal(t, "/tmp/archive", opts.ArchiveConfiguration.SnapshotFile)
+	assert.Equal(t, time.Minute, opts.ArchiveConfiguration.SnapshotInterval)
 }
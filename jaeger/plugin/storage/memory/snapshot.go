@@ -0,0 +1,222 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// A snapshot starts with snapshotMagic and the format version, followed by records made of
+// a record type byte, the uvarint length of the payload, and the payload. The payload of a
+// spanRecord is a model.Span protobuf message, and the payload of a structureRecord is a trace
+// ID (16 bytes) and the trace start time in unix microseconds (8 bytes), both big endian,
+// followed by the structural hash of the trace.
+// The traces are written from the oldest to the newest, so that restoring them into a store
+// with a trace limit keeps the newest ones, and the structures are written after all spans.
+const (
+	snapshotMagic   = "jaeger-memory-snapshot"
+	snapshotVersion = 1
+
+	spanRecord      byte = 1
+	structureRecord byte = 2
+
+	// maxRecordSize guards against allocating huge buffers when reading a corrupted snapshot
+	maxRecordSize = 64 << 20
+)
+
+// WriteSnapshot writes all the traces of the store, and their structural hashes, to w.
+// The store is only locked while the spans are marshaled, not while they are written.
+func (m *Store) WriteSnapshot(w io.Writer) error {
+	spans, structures, err := m.snapshotRecords()
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	bw.WriteString(snapshotMagic)
+	writeUvarint(bw, snapshotVersion)
+	for _, payload := range spans {
+		writeRecord(bw, spanRecord, payload)
+	}
+	for traceID, entry := range structures {
+		payload := make([]byte, 24, 24+len(entry.hash))
+		binary.BigEndian.PutUint64(payload, traceID.High)
+		binary.BigEndian.PutUint64(payload[8:], traceID.Low)
+		binary.BigEndian.PutUint64(payload[16:], model.TimeAsEpochMicroseconds(entry.startTime))
+		writeRecord(bw, structureRecord, append(payload, entry.hash...))
+	}
+	return bw.Flush()
+}
+
+// snapshotRecords marshals the spans of the store, from the oldest trace to the newest, and
+// copies the structures. The spans are marshaled under the lock since they are shared with
+// the readers of the store, some of which adjust them under the write lock.
+func (m *Store) snapshotRecords() ([][]byte, map[model.TraceID]structureEntry, error) {
+	m.RLock()
+	defer m.RUnlock()
+	traces := make([]*model.Trace, 0, len(m.traces))
+	for _, trace := range m.traces {
+		traces = append(traces, trace)
+	}
+	sort.Slice(traces, func(i, j int) bool {
+		return traces[i].Spans[0].StartTime.Before(traces[j].Spans[0].StartTime)
+	})
+	var spans [][]byte
+	for _, trace := range traces {
+		for _, span := range trace.Spans {
+			payload, err := span.Marshal()
+			if err != nil {
+				return nil, nil, err
+			}
+			spans = append(spans, payload)
+		}
+	}
+	structures := make(map[model.TraceID]structureEntry, len(m.structures))
+	for traceID, entry := range m.structures {
+		structures[traceID] = entry
+	}
+	return spans, structures, nil
+}
+
+func writeUvarint(w *bufio.Writer, value uint64) {
+	var buf [binary.MaxVarintLen64]byte
+	w.Write(buf[:binary.PutUvarint(buf[:], value)])
+}
+
+// writeRecord ignores the write errors, which are reported by the final Flush.
+func writeRecord(w *bufio.Writer, recordType byte, payload []byte) {
+	w.WriteByte(recordType)
+	writeUvarint(w, uint64(len(payload)))
+	w.Write(payload)
+}
+
+// ReadSnapshot writes the traces of a snapshot written by WriteSnapshot to the store.
+func (m *Store) ReadSnapshot(r io.Reader) error {
+	br := bufio.NewReader(r)
+	magic := make([]byte, len(snapshotMagic))
+	if _, err := io.ReadFull(br, magic); err != nil || string(magic) != snapshotMagic {
+		return errors.New("not a memory storage snapshot")
+	}
+	version, err := binary.ReadUvarint(br)
+	if err != nil {
+		return errors.Wrap(err, "cannot read the snapshot version")
+	}
+	if version != snapshotVersion {
+		return fmt.Errorf("unsupported snapshot version %d", version)
+	}
+	for {
+		recordType, err := br.ReadByte()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		size, err := binary.ReadUvarint(br)
+		if err != nil {
+			return errors.Wrap(err, "cannot read the snapshot record size")
+		}
+		if size > maxRecordSize {
+			return fmt.Errorf("snapshot record of %d bytes exceeds the maximum size", size)
+		}
+		payload := make([]byte, size)
+		if _, err := io.ReadFull(br, payload); err != nil {
+			return errors.Wrap(err, "cannot read the snapshot record")
+		}
+		switch recordType {
+		case spanRecord:
+			span := &model.Span{}
+			if err := span.Unmarshal(payload); err != nil {
+				return errors.Wrap(err, "cannot unmarshal the snapshot span")
+			}
+			m.WriteSpan(span)
+		case structureRecord:
+			if len(payload) < 24 {
+				return errors.New("truncated snapshot structure record")
+			}
+			traceID := model.NewTraceID(binary.BigEndian.Uint64(payload), binary.BigEndian.Uint64(payload[8:]))
+			startTime := model.EpochMicrosecondsAsTime(binary.BigEndian.Uint64(payload[16:]))
+			m.WriteStructure(traceID, startTime, string(payload[24:]))
+		default:
+			// records of unknown types are skipped, so that newer minor additions stay readable
+		}
+	}
+}
+
+// SaveSnapshot writes a snapshot of the store to the file. The snapshot is written to a
+// temporary file first, so that the previous snapshot is only replaced by a complete one.
+func (m *Store) SaveSnapshot(file string) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(file), filepath.Base(file)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := m.WriteSnapshot(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	// the snapshot must be on disk before it replaces the previous one
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), file)
+}
+
+// LoadSnapshot restores the traces of the snapshot file, if it exists.
+func (m *Store) LoadSnapshot(file string) error {
+	f, err := os.Open(file)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return m.ReadSnapshot(f)
+}
+
+// snapshotter saves the store to the snapshot file, see Factory.Initialize and Store.Close.
+type snapshotter struct {
+	store  *Store
+	file   string
+	logger *zap.Logger
+}
+
+func (s *snapshotter) save() error {
+	start := time.Now()
+	err := s.store.SaveSnapshot(s.file)
+	if err != nil {
+		s.logger.Error("Failed to save the memory storage snapshot", zap.String("file", s.file), zap.Error(err))
+	} else {
+		s.logger.Debug("Saved the memory storage snapshot", zap.String("file", s.file), zap.Duration("duration", time.Since(start)))
+	}
+	return err
+}
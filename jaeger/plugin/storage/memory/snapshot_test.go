@@ -0,0 +1,238 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/memory/config"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	withPopulatedMemoryStore(func(store *Store) {
+		require.NoError(t, store.WriteStructure(traceID, testingSpan.StartTime, "hash"))
+		buf := &bytes.Buffer{}
+		require.NoError(t, store.WriteSnapshot(buf))
+
+		restored := NewStore()
+		require.NoError(t, restored.ReadSnapshot(buf))
+		trace, err := restored.GetTrace(context.Background(), traceID)
+		require.NoError(t, err)
+		require.Len(t, trace.Spans, 1)
+		// times are restored in UTC, so the spans are compared in their protobuf form
+		expected, err := testingSpan.Marshal()
+		require.NoError(t, err)
+		actual, err := trace.Spans[0].Marshal()
+		require.NoError(t, err)
+		assert.Equal(t, expected, actual)
+		services, err := restored.GetServices(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"serviceName"}, services)
+		counts, err := restored.CountStructures(context.Background(), time.Time{}, time.Time{})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int{"hash": 1}, counts)
+	})
+}
+
+func TestSnapshotKeepsMostRecentTraces(t *testing.T) {
+	store := NewStore()
+	for i := 3; i >= 0; i-- {
+		require.NoError(t, store.WriteSpan(&model.Span{
+			TraceID:   model.NewTraceID(1, uint64(i)),
+			SpanID:    model.NewSpanID(1),
+			StartTime: time.Unix(int64(i*60), 0),
+			Process:   &model.Process{ServiceName: "serviceName"},
+		}))
+	}
+	buf := &bytes.Buffer{}
+	require.NoError(t, store.WriteSnapshot(buf))
+
+	restored := WithConfiguration(config.Configuration{MaxTraces: 2})
+	require.NoError(t, restored.ReadSnapshot(buf))
+	for i := 0; i < 4; i++ {
+		_, err := restored.GetTrace(context.Background(), model.NewTraceID(1, uint64(i)))
+		assert.Equal(t, i >= 2, err == nil, "trace %d", i)
+	}
+}
+
+func TestWriteSnapshotDoesNotBlockWrites(t *testing.T) {
+	withPopulatedMemoryStore(func(store *Store) {
+		r, w := io.Pipe()
+		done := make(chan error)
+		go func() {
+			err := store.WriteSnapshot(w)
+			w.Close()
+			done <- err
+		}()
+		// the snapshot writer is stuck until the pipe is read
+		written := make(chan error)
+		go func() {
+			written <- store.WriteSpan(&model.Span{
+				TraceID:   model.NewTraceID(1, 2),
+				SpanID:    model.NewSpanID(1),
+				StartTime: time.Now(),
+				Process:   &model.Process{ServiceName: "serviceName"},
+			})
+		}()
+		select {
+		case err := <-written:
+			assert.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("WriteSpan blocked by WriteSnapshot")
+		}
+		_, err := io.Copy(ioutil.Discard, r)
+		require.NoError(t, err)
+		assert.NoError(t, <-done)
+	})
+}
+
+func TestWriteSnapshotWithDependencies(t *testing.T) {
+	store := NewStore()
+	for i := uint64(1); i <= 100; i++ {
+		// the deduper of GetDependencies changes the ID of the server span
+		for _, kind := range []string{"client", "server"} {
+			require.NoError(t, store.WriteSpan(&model.Span{
+				TraceID:   model.NewTraceID(0, i),
+				SpanID:    model.NewSpanID(1),
+				StartTime: time.Now(),
+				Tags:      model.KeyValues{model.String("span.kind", kind)},
+				Process:   &model.Process{ServiceName: kind},
+			}))
+		}
+	}
+	// the spans must not be marshaled while GetDependencies adjusts them, see go test -race
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		store.GetDependencies(time.Now(), time.Hour)
+	}()
+	for i := 0; i < 10; i++ {
+		require.NoError(t, store.WriteSnapshot(ioutil.Discard))
+	}
+	<-done
+}
+
+func TestReadSnapshotErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		snapshot []byte
+		err      string
+	}{
+		{
+			name:     "bad magic",
+			snapshot: []byte("not a snapshot at all"),
+			err:      "not a memory storage snapshot",
+		},
+		{
+			name:     "unsupported version",
+			snapshot: append([]byte(snapshotMagic), 2),
+			err:      "unsupported snapshot version 2",
+		},
+		{
+			name:     "truncated record",
+			snapshot: append([]byte(snapshotMagic), snapshotVersion, spanRecord, 10, 1),
+			err:      "cannot read the snapshot record: unexpected EOF",
+		},
+		{
+			name:     "invalid span",
+			snapshot: append([]byte(snapshotMagic), snapshotVersion, spanRecord, 1, 0xff),
+			err:      "cannot unmarshal the snapshot span",
+		},
+		{
+			name:     "truncated structure",
+			snapshot: append([]byte(snapshotMagic), snapshotVersion, structureRecord, 1, 0),
+			err:      "truncated snapshot structure record",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := NewStore().ReadSnapshot(bytes.NewReader(testCase.snapshot))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), testCase.err)
+		})
+	}
+}
+
+func TestSnapshotFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "memory-snapshot")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "traces")
+
+	// a missing snapshot is not an error
+	store := NewStore()
+	require.NoError(t, store.LoadSnapshot(file))
+
+	withPopulatedMemoryStore(func(store *Store) {
+		require.NoError(t, store.SaveSnapshot(file))
+	})
+	require.NoError(t, store.LoadSnapshot(file))
+	_, err = store.GetTrace(context.Background(), traceID)
+	assert.NoError(t, err)
+
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, files, 1, "the temporary file is removed")
+}
+
+func TestFactorySnapshots(t *testing.T) {
+	dir, err := ioutil.TempDir("", "memory-snapshot")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "traces")
+
+	f := NewFactory()
+	f.options.Configuration = config.Configuration{SnapshotFile: file, SnapshotInterval: time.Millisecond}
+	require.NoError(t, f.Initialize(nil, zap.NewNop()))
+	require.NoError(t, f.store.WriteSpan(testingSpan))
+	for i := 0; i < 100; i++ {
+		if _, err := os.Stat(file); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.FileExists(t, file, "the snapshot is saved periodically")
+	require.NoError(t, f.store.Close())
+	assert.NoError(t, f.store.Close(), "closing twice is a no-op")
+
+	restored := NewFactory()
+	restored.options.Configuration = config.Configuration{SnapshotFile: file}
+	require.NoError(t, restored.Initialize(nil, zap.NewNop()))
+	_, err = restored.store.GetTrace(context.Background(), traceID)
+	assert.NoError(t, err)
+	require.NoError(t, restored.store.Close())
+
+	require.NoError(t, ioutil.WriteFile(file, []byte("garbage"), 0600))
+	corrupted := NewFactory()
+	corrupted.options.Configuration = config.Configuration{SnapshotFile: file}
+	err = corrupted.Initialize(nil, zap.NewNop())
+	assert.EqualError(t, err, "cannot load memory snapshot: not a memory storage snapshot")
+}
+
+func TestStoreCloseWithoutSnapshots(t *testing.T) {
+	assert.NoError(t, NewStore().Close())
+}
@@ -34,11 +34,49 @@ var _ = time.Kitchen
 // proto package needs to be updated.
 const _ = proto.GoGoProtoPackageIsVersion2 // please upgrade the proto package
 
+type TraceIntegrity int32
+
+const (
+	TraceIntegrity_COMPLETE              TraceIntegrity = 0
+	TraceIntegrity_DROPPED_SPAN          TraceIntegrity = 1
+	TraceIntegrity_ORPHANED              TraceIntegrity = 2
+	TraceIntegrity_MULTI_PARENT          TraceIntegrity = 3
+	TraceIntegrity_CROSS_TRACE_REFERENCE TraceIntegrity = 4
+)
+
+var TraceIntegrity_name = map[int32]string{
+	0: "COMPLETE",
+	1: "DROPPED_SPAN",
+	2: "ORPHANED",
+	3: "MULTI_PARENT",
+	4: "CROSS_TRACE_REFERENCE",
+}
+
+var TraceIntegrity_value = map[string]int32{
+	"COMPLETE":              0,
+	"DROPPED_SPAN":          1,
+	"ORPHANED":              2,
+	"MULTI_PARENT":          3,
+	"CROSS_TRACE_REFERENCE": 4,
+}
+
+func (x TraceIntegrity) String() string {
+	return proto.EnumName(TraceIntegrity_name, int32(x))
+}
+
+func (TraceIntegrity) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_26651706f9f8a4f0, []int{0}
+}
+
 type GetTraceRequest struct {
-	TraceID              github_com_jaegertracing_jaeger_model.TraceID `protobuf:"bytes,1,opt,name=trace_id,json=traceId,proto3,customtype=github.com/jaegertracing/jaeger/model.TraceID" json:"trace_id"`
-	XXX_NoUnkeyedLiteral struct{}                                      `json:"-"`
-	XXX_unrecognized     []byte                                        `json:"-"`
-	XXX_sizecache        int32                                         `json:"-"`
+	TraceID github_com_jaegertracing_jaeger_model.TraceID `protobuf:"bytes,1,opt,name=trace_id,json=traceId,proto3,customtype=github.com/jaegertracing/jaeger/model.TraceID" json:"trace_id"`
+	// adjust applies the query service adjusters (span ID deduplication, clock skew, etc.)
+	// to the trace before sending it. It implies a trace-delimited stream, so that the
+	// trace warnings reported by the adjusters are returned with the last chunk.
+	Adjust               bool     `protobuf:"varint,2,opt,name=adjust,proto3" json:"adjust,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *GetTraceRequest) Reset()         { *m = GetTraceRequest{} }
@@ -74,11 +112,27 @@ func (m *GetTraceRequest) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_GetTraceRequest proto.InternalMessageInfo
 
+func (m *GetTraceRequest) GetAdjust() bool {
+	if m != nil {
+		return m.Adjust
+	}
+	return false
+}
+
 type SpansResponseChunk struct {
-	Spans                []model.Span `protobuf:"bytes,1,rep,name=spans,proto3" json:"spans"`
-	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
-	XXX_unrecognized     []byte       `json:"-"`
-	XXX_sizecache        int32        `json:"-"`
+	Spans []model.Span `protobuf:"bytes,1,rep,name=spans,proto3" json:"spans"`
+	// trace_end is set on the last chunk of each trace when the stream is trace-delimited.
+	TraceEnd bool `protobuf:"varint,2,opt,name=trace_end,json=traceEnd,proto3" json:"trace_end,omitempty"`
+	// warnings are the trace-level warnings, sent with the last chunk of each trace
+	// when the stream is trace-delimited.
+	Warnings []string `protobuf:"bytes,3,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	// trace_id is the ID of the trace the chunk belongs to when the stream is trace-delimited.
+	TraceID github_com_jaegertracing_jaeger_model.TraceID `protobuf:"bytes,4,opt,name=trace_id,json=traceId,proto3,customtype=github.com/jaegertracing/jaeger/model.TraceID" json:"trace_id"`
+	// error is set when the trace could not be fetched, in which case the chunk has no spans.
+	Error                string   `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *SpansResponseChunk) Reset()         { *m = SpansResponseChunk{} }
@@ -121,6 +175,86 @@ func (m *SpansResponseChunk) GetSpans() []model.Span {
 	return nil
 }
 
+func (m *SpansResponseChunk) GetTraceEnd() bool {
+	if m != nil {
+		return m.TraceEnd
+	}
+	return false
+}
+
+func (m *SpansResponseChunk) GetWarnings() []string {
+	if m != nil {
+		return m.Warnings
+	}
+	return nil
+}
+
+func (m *SpansResponseChunk) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+type GetTracesRequest struct {
+	TraceIDs []github_com_jaegertracing_jaeger_model.TraceID `protobuf:"bytes,1,rep,name=trace_ids,json=traceIds,proto3,customtype=github.com/jaegertracing/jaeger/model.TraceID" json:"trace_ids"`
+	// chunk_size is the maximum number of spans per response chunk. Defaults to 10 when zero,
+	// and is capped to 1000.
+	ChunkSize uint32 `protobuf:"varint,2,opt,name=chunk_size,json=chunkSize,proto3" json:"chunk_size,omitempty"`
+	// adjust applies the query service adjusters to each trace, see GetTraceRequest.adjust.
+	Adjust               bool     `protobuf:"varint,3,opt,name=adjust,proto3" json:"adjust,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetTracesRequest) Reset()         { *m = GetTracesRequest{} }
+func (m *GetTracesRequest) String() string { return proto.CompactTextString(m) }
+func (*GetTracesRequest) ProtoMessage()    {}
+func (*GetTracesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_26651706f9f8a4f0, []int{2}
+}
+func (m *GetTracesRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *GetTracesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_GetTracesRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *GetTracesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetTracesRequest.Merge(m, src)
+}
+func (m *GetTracesRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *GetTracesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetTracesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetTracesRequest proto.InternalMessageInfo
+
+func (m *GetTracesRequest) GetChunkSize() uint32 {
+	if m != nil {
+		return m.ChunkSize
+	}
+	return 0
+}
+
+func (m *GetTracesRequest) GetAdjust() bool {
+	if m != nil {
+		return m.Adjust
+	}
+	return false
+}
+
 type ArchiveTraceRequest struct {
 	TraceID              github_com_jaegertracing_jaeger_model.TraceID `protobuf:"bytes,1,opt,name=trace_id,json=traceId,proto3,customtype=github.com/jaegertracing/jaeger/model.TraceID" json:"trace_id"`
 	XXX_NoUnkeyedLiteral struct{}                                      `json:"-"`
@@ -132,7 +266,7 @@ func (m *ArchiveTraceRequest) Reset()         { *m = ArchiveTraceRequest{} }
 func (m *ArchiveTraceRequest) String() string { return proto.CompactTextString(m) }
 func (*ArchiveTraceRequest) ProtoMessage()    {}
 func (*ArchiveTraceRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_26651706f9f8a4f0, []int{2}
+	return fileDescriptor_26651706f9f8a4f0, []int{3}
 }
 func (m *ArchiveTraceRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -171,7 +305,7 @@ func (m *ArchiveTraceResponse) Reset()         { *m = ArchiveTraceResponse{} }
 func (m *ArchiveTraceResponse) String() string { return proto.CompactTextString(m) }
 func (*ArchiveTraceResponse) ProtoMessage()    {}
 func (*ArchiveTraceResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_26651706f9f8a4f0, []int{3}
+	return fileDescriptor_26651706f9f8a4f0, []int{4}
 }
 func (m *ArchiveTraceResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -201,24 +335,32 @@ func (m *ArchiveTraceResponse) XXX_DiscardUnknown() {
 var xxx_messageInfo_ArchiveTraceResponse proto.InternalMessageInfo
 
 type TraceQueryParameters struct {
-	ServiceName          string            `protobuf:"bytes,1,opt,name=service_name,json=serviceName,proto3" json:"service_name,omitempty"`
-	OperationName        string            `protobuf:"bytes,2,opt,name=operation_name,json=operationName,proto3" json:"operation_name,omitempty"`
-	Tags                 map[string]string `protobuf:"bytes,3,rep,name=tags,proto3" json:"tags,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
-	StartTimeMin         time.Time         `protobuf:"bytes,4,opt,name=start_time_min,json=startTimeMin,proto3,stdtime" json:"start_time_min"`
-	StartTimeMax         time.Time         `protobuf:"bytes,5,opt,name=start_time_max,json=startTimeMax,proto3,stdtime" json:"start_time_max"`
-	DurationMin          time.Duration     `protobuf:"bytes,6,opt,name=duration_min,json=durationMin,proto3,stdduration" json:"duration_min"`
-	DurationMax          time.Duration     `protobuf:"bytes,7,opt,name=duration_max,json=durationMax,proto3,stdduration" json:"duration_max"`
-	SearchDepth          int32             `protobuf:"varint,8,opt,name=search_depth,json=searchDepth,proto3" json:"search_depth,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
-	XXX_unrecognized     []byte            `json:"-"`
-	XXX_sizecache        int32             `json:"-"`
+	ServiceName   string            `protobuf:"bytes,1,opt,name=service_name,json=serviceName,proto3" json:"service_name,omitempty"`
+	OperationName string            `protobuf:"bytes,2,opt,name=operation_name,json=operationName,proto3" json:"operation_name,omitempty"`
+	Tags          map[string]string `protobuf:"bytes,3,rep,name=tags,proto3" json:"tags,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	StartTimeMin  time.Time         `protobuf:"bytes,4,opt,name=start_time_min,json=startTimeMin,proto3,stdtime" json:"start_time_min"`
+	StartTimeMax  time.Time         `protobuf:"bytes,5,opt,name=start_time_max,json=startTimeMax,proto3,stdtime" json:"start_time_max"`
+	DurationMin   time.Duration     `protobuf:"bytes,6,opt,name=duration_min,json=durationMin,proto3,stdduration" json:"duration_min"`
+	DurationMax   time.Duration     `protobuf:"bytes,7,opt,name=duration_max,json=durationMax,proto3,stdduration" json:"duration_max"`
+	SearchDepth   int32             `protobuf:"varint,8,opt,name=search_depth,json=searchDepth,proto3" json:"search_depth,omitempty"`
+	// integrity restricts the results to the traces matching any of the statuses.
+	Integrity []TraceIntegrity `protobuf:"varint,9,rep,packed,name=integrity,proto3,enum=jaeger.api_v2.TraceIntegrity" json:"integrity,omitempty"`
+	// pattern restricts the results to the traces whose span tree matches the pattern,
+	// e.g. "frontend > api > db #>3", see the Go package model/structure/pattern for the syntax.
+	Pattern string `protobuf:"bytes,10,opt,name=pattern,proto3" json:"pattern,omitempty"`
+	// structure_hash restricts the results to the traces with the structural hash, as
+	// returned in StructureGroup.hash.
+	StructureHash        string   `protobuf:"bytes,11,opt,name=structure_hash,json=structureHash,proto3" json:"structure_hash,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *TraceQueryParameters) Reset()         { *m = TraceQueryParameters{} }
 func (m *TraceQueryParameters) String() string { return proto.CompactTextString(m) }
 func (*TraceQueryParameters) ProtoMessage()    {}
 func (*TraceQueryParameters) Descriptor() ([]byte, []int) {
-	return fileDescriptor_26651706f9f8a4f0, []int{4}
+	return fileDescriptor_26651706f9f8a4f0, []int{5}
 }
 func (m *TraceQueryParameters) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -303,18 +445,49 @@ func (m *TraceQueryParameters) GetSearchDepth() int32 {
 	return 0
 }
 
+func (m *TraceQueryParameters) GetIntegrity() []TraceIntegrity {
+	if m != nil {
+		return m.Integrity
+	}
+	return nil
+}
+
+func (m *TraceQueryParameters) GetPattern() string {
+	if m != nil {
+		return m.Pattern
+	}
+	return ""
+}
+
+func (m *TraceQueryParameters) GetStructureHash() string {
+	if m != nil {
+		return m.StructureHash
+	}
+	return ""
+}
+
 type FindTracesRequest struct {
-	Query                *TraceQueryParameters `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
-	XXX_unrecognized     []byte                `json:"-"`
-	XXX_sizecache        int32                 `json:"-"`
+	Query *TraceQueryParameters `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	// chunk_size is the maximum number of spans per response chunk. Defaults to 10 when zero,
+	// and is capped to 1000.
+	ChunkSize uint32 `protobuf:"varint,2,opt,name=chunk_size,json=chunkSize,proto3" json:"chunk_size,omitempty"`
+	// trace_delimited marks the last chunk of each trace and carries the trace-level warnings.
+	// Chunks never mix the spans of several traces, so a chunk_size larger than any trace
+	// yields exactly one chunk per trace.
+	TraceDelimited bool `protobuf:"varint,3,opt,name=trace_delimited,json=traceDelimited,proto3" json:"trace_delimited,omitempty"`
+	// adjust applies the query service adjusters to each trace, see GetTraceRequest.adjust.
+	// It implies trace_delimited.
+	Adjust               bool     `protobuf:"varint,4,opt,name=adjust,proto3" json:"adjust,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *FindTracesRequest) Reset()         { *m = FindTracesRequest{} }
 func (m *FindTracesRequest) String() string { return proto.CompactTextString(m) }
 func (*FindTracesRequest) ProtoMessage()    {}
 func (*FindTracesRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_26651706f9f8a4f0, []int{5}
+	return fileDescriptor_26651706f9f8a4f0, []int{6}
 }
 func (m *FindTracesRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -350,24 +523,46 @@ func (m *FindTracesRequest) GetQuery() *TraceQueryParameters {
 	return nil
 }
 
-type GetServicesRequest struct {
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+func (m *FindTracesRequest) GetChunkSize() uint32 {
+	if m != nil {
+		return m.ChunkSize
+	}
+	return 0
 }
 
-func (m *GetServicesRequest) Reset()         { *m = GetServicesRequest{} }
-func (m *GetServicesRequest) String() string { return proto.CompactTextString(m) }
-func (*GetServicesRequest) ProtoMessage()    {}
-func (*GetServicesRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_26651706f9f8a4f0, []int{6}
+func (m *FindTracesRequest) GetTraceDelimited() bool {
+	if m != nil {
+		return m.TraceDelimited
+	}
+	return false
 }
-func (m *GetServicesRequest) XXX_Unmarshal(b []byte) error {
+
+func (m *FindTracesRequest) GetAdjust() bool {
+	if m != nil {
+		return m.Adjust
+	}
+	return false
+}
+
+type FindTraceIDsRequest struct {
+	Query                *TraceQueryParameters `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *FindTraceIDsRequest) Reset()         { *m = FindTraceIDsRequest{} }
+func (m *FindTraceIDsRequest) String() string { return proto.CompactTextString(m) }
+func (*FindTraceIDsRequest) ProtoMessage()    {}
+func (*FindTraceIDsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_26651706f9f8a4f0, []int{7}
+}
+func (m *FindTraceIDsRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
 }
-func (m *GetServicesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+func (m *FindTraceIDsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
 	if deterministic {
-		return xxx_messageInfo_GetServicesRequest.Marshal(b, m, deterministic)
+		return xxx_messageInfo_FindTraceIDsRequest.Marshal(b, m, deterministic)
 	} else {
 		b = b[:cap(b)]
 		n, err := m.MarshalTo(b)
@@ -377,37 +572,44 @@ func (m *GetServicesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte,
 		return b[:n], nil
 	}
 }
-func (m *GetServicesRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_GetServicesRequest.Merge(m, src)
+func (m *FindTraceIDsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FindTraceIDsRequest.Merge(m, src)
 }
-func (m *GetServicesRequest) XXX_Size() int {
+func (m *FindTraceIDsRequest) XXX_Size() int {
 	return m.Size()
 }
-func (m *GetServicesRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_GetServicesRequest.DiscardUnknown(m)
+func (m *FindTraceIDsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_FindTraceIDsRequest.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_GetServicesRequest proto.InternalMessageInfo
+var xxx_messageInfo_FindTraceIDsRequest proto.InternalMessageInfo
 
-type GetServicesResponse struct {
-	Services             []string `protobuf:"bytes,1,rep,name=services,proto3" json:"services,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+func (m *FindTraceIDsRequest) GetQuery() *TraceQueryParameters {
+	if m != nil {
+		return m.Query
+	}
+	return nil
 }
 
-func (m *GetServicesResponse) Reset()         { *m = GetServicesResponse{} }
-func (m *GetServicesResponse) String() string { return proto.CompactTextString(m) }
-func (*GetServicesResponse) ProtoMessage()    {}
-func (*GetServicesResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_26651706f9f8a4f0, []int{7}
+type TraceIDsResponseChunk struct {
+	TraceIDs             []github_com_jaegertracing_jaeger_model.TraceID `protobuf:"bytes,1,rep,name=trace_ids,json=traceIds,proto3,customtype=github.com/jaegertracing/jaeger/model.TraceID" json:"trace_ids"`
+	XXX_NoUnkeyedLiteral struct{}                                        `json:"-"`
+	XXX_unrecognized     []byte                                          `json:"-"`
+	XXX_sizecache        int32                                           `json:"-"`
 }
-func (m *GetServicesResponse) XXX_Unmarshal(b []byte) error {
+
+func (m *TraceIDsResponseChunk) Reset()         { *m = TraceIDsResponseChunk{} }
+func (m *TraceIDsResponseChunk) String() string { return proto.CompactTextString(m) }
+func (*TraceIDsResponseChunk) ProtoMessage()    {}
+func (*TraceIDsResponseChunk) Descriptor() ([]byte, []int) {
+	return fileDescriptor_26651706f9f8a4f0, []int{8}
+}
+func (m *TraceIDsResponseChunk) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
 }
-func (m *GetServicesResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+func (m *TraceIDsResponseChunk) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
 	if deterministic {
-		return xxx_messageInfo_GetServicesResponse.Marshal(b, m, deterministic)
+		return xxx_messageInfo_TraceIDsResponseChunk.Marshal(b, m, deterministic)
 	} else {
 		b = b[:cap(b)]
 		n, err := m.MarshalTo(b)
@@ -417,44 +619,43 @@ func (m *GetServicesResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte,
 		return b[:n], nil
 	}
 }
-func (m *GetServicesResponse) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_GetServicesResponse.Merge(m, src)
+func (m *TraceIDsResponseChunk) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TraceIDsResponseChunk.Merge(m, src)
 }
-func (m *GetServicesResponse) XXX_Size() int {
+func (m *TraceIDsResponseChunk) XXX_Size() int {
 	return m.Size()
 }
-func (m *GetServicesResponse) XXX_DiscardUnknown() {
-	xxx_messageInfo_GetServicesResponse.DiscardUnknown(m)
+func (m *TraceIDsResponseChunk) XXX_DiscardUnknown() {
+	xxx_messageInfo_TraceIDsResponseChunk.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_GetServicesResponse proto.InternalMessageInfo
-
-func (m *GetServicesResponse) GetServices() []string {
-	if m != nil {
-		return m.Services
-	}
-	return nil
-}
+var xxx_messageInfo_TraceIDsResponseChunk proto.InternalMessageInfo
 
-type GetOperationsRequest struct {
-	Service              string   `protobuf:"bytes,1,opt,name=service,proto3" json:"service,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+type LatencyStats struct {
+	Count                int64         `protobuf:"varint,1,opt,name=count,proto3" json:"count,omitempty"`
+	Mean                 time.Duration `protobuf:"bytes,2,opt,name=mean,proto3,stdduration" json:"mean"`
+	StdDev               time.Duration `protobuf:"bytes,3,opt,name=std_dev,json=stdDev,proto3,stdduration" json:"std_dev"`
+	Min                  time.Duration `protobuf:"bytes,4,opt,name=min,proto3,stdduration" json:"min"`
+	Max                  time.Duration `protobuf:"bytes,5,opt,name=max,proto3,stdduration" json:"max"`
+	P50                  time.Duration `protobuf:"bytes,6,opt,name=p50,proto3,stdduration" json:"p50"`
+	P99                  time.Duration `protobuf:"bytes,7,opt,name=p99,proto3,stdduration" json:"p99"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
 }
 
-func (m *GetOperationsRequest) Reset()         { *m = GetOperationsRequest{} }
-func (m *GetOperationsRequest) String() string { return proto.CompactTextString(m) }
-func (*GetOperationsRequest) ProtoMessage()    {}
-func (*GetOperationsRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_26651706f9f8a4f0, []int{8}
+func (m *LatencyStats) Reset()         { *m = LatencyStats{} }
+func (m *LatencyStats) String() string { return proto.CompactTextString(m) }
+func (*LatencyStats) ProtoMessage()    {}
+func (*LatencyStats) Descriptor() ([]byte, []int) {
+	return fileDescriptor_26651706f9f8a4f0, []int{9}
 }
-func (m *GetOperationsRequest) XXX_Unmarshal(b []byte) error {
+func (m *LatencyStats) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
 }
-func (m *GetOperationsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+func (m *LatencyStats) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
 	if deterministic {
-		return xxx_messageInfo_GetOperationsRequest.Marshal(b, m, deterministic)
+		return xxx_messageInfo_LatencyStats.Marshal(b, m, deterministic)
 	} else {
 		b = b[:cap(b)]
 		n, err := m.MarshalTo(b)
@@ -464,44 +665,89 @@ func (m *GetOperationsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte
 		return b[:n], nil
 	}
 }
-func (m *GetOperationsRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_GetOperationsRequest.Merge(m, src)
+func (m *LatencyStats) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LatencyStats.Merge(m, src)
 }
-func (m *GetOperationsRequest) XXX_Size() int {
+func (m *LatencyStats) XXX_Size() int {
 	return m.Size()
 }
-func (m *GetOperationsRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_GetOperationsRequest.DiscardUnknown(m)
+func (m *LatencyStats) XXX_DiscardUnknown() {
+	xxx_messageInfo_LatencyStats.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_GetOperationsRequest proto.InternalMessageInfo
+var xxx_messageInfo_LatencyStats proto.InternalMessageInfo
 
-func (m *GetOperationsRequest) GetService() string {
+func (m *LatencyStats) GetCount() int64 {
 	if m != nil {
-		return m.Service
+		return m.Count
 	}
-	return ""
+	return 0
 }
 
-type GetOperationsResponse struct {
-	Operations           []string `protobuf:"bytes,1,rep,name=operations,proto3" json:"operations,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+func (m *LatencyStats) GetMean() time.Duration {
+	if m != nil {
+		return m.Mean
+	}
+	return 0
 }
 
-func (m *GetOperationsResponse) Reset()         { *m = GetOperationsResponse{} }
-func (m *GetOperationsResponse) String() string { return proto.CompactTextString(m) }
-func (*GetOperationsResponse) ProtoMessage()    {}
-func (*GetOperationsResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_26651706f9f8a4f0, []int{9}
+func (m *LatencyStats) GetStdDev() time.Duration {
+	if m != nil {
+		return m.StdDev
+	}
+	return 0
 }
-func (m *GetOperationsResponse) XXX_Unmarshal(b []byte) error {
+
+func (m *LatencyStats) GetMin() time.Duration {
+	if m != nil {
+		return m.Min
+	}
+	return 0
+}
+
+func (m *LatencyStats) GetMax() time.Duration {
+	if m != nil {
+		return m.Max
+	}
+	return 0
+}
+
+func (m *LatencyStats) GetP50() time.Duration {
+	if m != nil {
+		return m.P50
+	}
+	return 0
+}
+
+func (m *LatencyStats) GetP99() time.Duration {
+	if m != nil {
+		return m.P99
+	}
+	return 0
+}
+
+type StructureGroup struct {
+	Signature            string                                          `protobuf:"bytes,1,opt,name=signature,proto3" json:"signature,omitempty"`
+	Hash                 string                                          `protobuf:"bytes,2,opt,name=hash,proto3" json:"hash,omitempty"`
+	TraceIDs             []github_com_jaegertracing_jaeger_model.TraceID `protobuf:"bytes,3,rep,name=trace_ids,json=traceIds,proto3,customtype=github.com/jaegertracing/jaeger/model.TraceID" json:"trace_ids"`
+	Latency              LatencyStats                                    `protobuf:"bytes,4,opt,name=latency,proto3" json:"latency"`
+	XXX_NoUnkeyedLiteral struct{}                                        `json:"-"`
+	XXX_unrecognized     []byte                                          `json:"-"`
+	XXX_sizecache        int32                                           `json:"-"`
+}
+
+func (m *StructureGroup) Reset()         { *m = StructureGroup{} }
+func (m *StructureGroup) String() string { return proto.CompactTextString(m) }
+func (*StructureGroup) ProtoMessage()    {}
+func (*StructureGroup) Descriptor() ([]byte, []int) {
+	return fileDescriptor_26651706f9f8a4f0, []int{10}
+}
+func (m *StructureGroup) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
 }
-func (m *GetOperationsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+func (m *StructureGroup) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
 	if deterministic {
-		return xxx_messageInfo_GetOperationsResponse.Marshal(b, m, deterministic)
+		return xxx_messageInfo_StructureGroup.Marshal(b, m, deterministic)
 	} else {
 		b = b[:cap(b)]
 		n, err := m.MarshalTo(b)
@@ -511,28 +757,316 @@ func (m *GetOperationsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byt
 		return b[:n], nil
 	}
 }
-func (m *GetOperationsResponse) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_GetOperationsResponse.Merge(m, src)
+func (m *StructureGroup) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StructureGroup.Merge(m, src)
 }
-func (m *GetOperationsResponse) XXX_Size() int {
+func (m *StructureGroup) XXX_Size() int {
 	return m.Size()
 }
-func (m *GetOperationsResponse) XXX_DiscardUnknown() {
-	xxx_messageInfo_GetOperationsResponse.DiscardUnknown(m)
+func (m *StructureGroup) XXX_DiscardUnknown() {
+	xxx_messageInfo_StructureGroup.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_GetOperationsResponse proto.InternalMessageInfo
+var xxx_messageInfo_StructureGroup proto.InternalMessageInfo
 
-func (m *GetOperationsResponse) GetOperations() []string {
+func (m *StructureGroup) GetSignature() string {
 	if m != nil {
-		return m.Operations
+		return m.Signature
 	}
-	return nil
+	return ""
 }
 
-type GetDependenciesRequest struct {
-	StartTime            time.Time `protobuf:"bytes,1,opt,name=start_time,json=startTime,proto3,stdtime" json:"start_time"`
-	EndTime              time.Time `protobuf:"bytes,2,opt,name=end_time,json=endTime,proto3,stdtime" json:"end_time"`
+func (m *StructureGroup) GetHash() string {
+	if m != nil {
+		return m.Hash
+	}
+	return ""
+}
+
+func (m *StructureGroup) GetLatency() LatencyStats {
+	if m != nil {
+		return m.Latency
+	}
+	return LatencyStats{}
+}
+
+type GetStructureGroupsRequest struct {
+	Query                *TraceQueryParameters `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *GetStructureGroupsRequest) Reset()         { *m = GetStructureGroupsRequest{} }
+func (m *GetStructureGroupsRequest) String() string { return proto.CompactTextString(m) }
+func (*GetStructureGroupsRequest) ProtoMessage()    {}
+func (*GetStructureGroupsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_26651706f9f8a4f0, []int{11}
+}
+func (m *GetStructureGroupsRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *GetStructureGroupsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_GetStructureGroupsRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *GetStructureGroupsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetStructureGroupsRequest.Merge(m, src)
+}
+func (m *GetStructureGroupsRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *GetStructureGroupsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetStructureGroupsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetStructureGroupsRequest proto.InternalMessageInfo
+
+func (m *GetStructureGroupsRequest) GetQuery() *TraceQueryParameters {
+	if m != nil {
+		return m.Query
+	}
+	return nil
+}
+
+type GetStructureGroupsResponse struct {
+	Groups               []StructureGroup `protobuf:"bytes,1,rep,name=groups,proto3" json:"groups"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *GetStructureGroupsResponse) Reset()         { *m = GetStructureGroupsResponse{} }
+func (m *GetStructureGroupsResponse) String() string { return proto.CompactTextString(m) }
+func (*GetStructureGroupsResponse) ProtoMessage()    {}
+func (*GetStructureGroupsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_26651706f9f8a4f0, []int{12}
+}
+func (m *GetStructureGroupsResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *GetStructureGroupsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_GetStructureGroupsResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *GetStructureGroupsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetStructureGroupsResponse.Merge(m, src)
+}
+func (m *GetStructureGroupsResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *GetStructureGroupsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetStructureGroupsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetStructureGroupsResponse proto.InternalMessageInfo
+
+func (m *GetStructureGroupsResponse) GetGroups() []StructureGroup {
+	if m != nil {
+		return m.Groups
+	}
+	return nil
+}
+
+type GetServicesRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetServicesRequest) Reset()         { *m = GetServicesRequest{} }
+func (m *GetServicesRequest) String() string { return proto.CompactTextString(m) }
+func (*GetServicesRequest) ProtoMessage()    {}
+func (*GetServicesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_26651706f9f8a4f0, []int{13}
+}
+func (m *GetServicesRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *GetServicesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_GetServicesRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *GetServicesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetServicesRequest.Merge(m, src)
+}
+func (m *GetServicesRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *GetServicesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetServicesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetServicesRequest proto.InternalMessageInfo
+
+type GetServicesResponse struct {
+	Services             []string `protobuf:"bytes,1,rep,name=services,proto3" json:"services,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetServicesResponse) Reset()         { *m = GetServicesResponse{} }
+func (m *GetServicesResponse) String() string { return proto.CompactTextString(m) }
+func (*GetServicesResponse) ProtoMessage()    {}
+func (*GetServicesResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_26651706f9f8a4f0, []int{14}
+}
+func (m *GetServicesResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *GetServicesResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_GetServicesResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *GetServicesResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetServicesResponse.Merge(m, src)
+}
+func (m *GetServicesResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *GetServicesResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetServicesResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetServicesResponse proto.InternalMessageInfo
+
+func (m *GetServicesResponse) GetServices() []string {
+	if m != nil {
+		return m.Services
+	}
+	return nil
+}
+
+type GetOperationsRequest struct {
+	Service              string   `protobuf:"bytes,1,opt,name=service,proto3" json:"service,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetOperationsRequest) Reset()         { *m = GetOperationsRequest{} }
+func (m *GetOperationsRequest) String() string { return proto.CompactTextString(m) }
+func (*GetOperationsRequest) ProtoMessage()    {}
+func (*GetOperationsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_26651706f9f8a4f0, []int{15}
+}
+func (m *GetOperationsRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *GetOperationsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_GetOperationsRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *GetOperationsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetOperationsRequest.Merge(m, src)
+}
+func (m *GetOperationsRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *GetOperationsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetOperationsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetOperationsRequest proto.InternalMessageInfo
+
+func (m *GetOperationsRequest) GetService() string {
+	if m != nil {
+		return m.Service
+	}
+	return ""
+}
+
+type GetOperationsResponse struct {
+	Operations           []string `protobuf:"bytes,1,rep,name=operations,proto3" json:"operations,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetOperationsResponse) Reset()         { *m = GetOperationsResponse{} }
+func (m *GetOperationsResponse) String() string { return proto.CompactTextString(m) }
+func (*GetOperationsResponse) ProtoMessage()    {}
+func (*GetOperationsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_26651706f9f8a4f0, []int{16}
+}
+func (m *GetOperationsResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *GetOperationsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_GetOperationsResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *GetOperationsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetOperationsResponse.Merge(m, src)
+}
+func (m *GetOperationsResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *GetOperationsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetOperationsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetOperationsResponse proto.InternalMessageInfo
+
+func (m *GetOperationsResponse) GetOperations() []string {
+	if m != nil {
+		return m.Operations
+	}
+	return nil
+}
+
+type GetDependenciesRequest struct {
+	StartTime            time.Time `protobuf:"bytes,1,opt,name=start_time,json=startTime,proto3,stdtime" json:"start_time"`
+	EndTime              time.Time `protobuf:"bytes,2,opt,name=end_time,json=endTime,proto3,stdtime" json:"end_time"`
 	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
 	XXX_unrecognized     []byte    `json:"-"`
 	XXX_sizecache        int32     `json:"-"`
@@ -542,7 +1076,7 @@ func (m *GetDependenciesRequest) Reset()         { *m = GetDependenciesRequest{}
 func (m *GetDependenciesRequest) String() string { return proto.CompactTextString(m) }
 func (*GetDependenciesRequest) ProtoMessage()    {}
 func (*GetDependenciesRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_26651706f9f8a4f0, []int{10}
+	return fileDescriptor_26651706f9f8a4f0, []int{17}
 }
 func (m *GetDependenciesRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -596,7 +1130,7 @@ func (m *GetDependenciesResponse) Reset()         { *m = GetDependenciesResponse
 func (m *GetDependenciesResponse) String() string { return proto.CompactTextString(m) }
 func (*GetDependenciesResponse) ProtoMessage()    {}
 func (*GetDependenciesResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_26651706f9f8a4f0, []int{11}
+	return fileDescriptor_26651706f9f8a4f0, []int{18}
 }
 func (m *GetDependenciesResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -633,10 +1167,14 @@ func (m *GetDependenciesResponse) GetDependencies() []model.DependencyLink {
 }
 
 func init() {
+	proto.RegisterEnum("jaeger.api_v2.TraceIntegrity", TraceIntegrity_name, TraceIntegrity_value)
+	golang_proto.RegisterEnum("jaeger.api_v2.TraceIntegrity", TraceIntegrity_name, TraceIntegrity_value)
 	proto.RegisterType((*GetTraceRequest)(nil), "jaeger.api_v2.GetTraceRequest")
 	golang_proto.RegisterType((*GetTraceRequest)(nil), "jaeger.api_v2.GetTraceRequest")
 	proto.RegisterType((*SpansResponseChunk)(nil), "jaeger.api_v2.SpansResponseChunk")
 	golang_proto.RegisterType((*SpansResponseChunk)(nil), "jaeger.api_v2.SpansResponseChunk")
+	proto.RegisterType((*GetTracesRequest)(nil), "jaeger.api_v2.GetTracesRequest")
+	golang_proto.RegisterType((*GetTracesRequest)(nil), "jaeger.api_v2.GetTracesRequest")
 	proto.RegisterType((*ArchiveTraceRequest)(nil), "jaeger.api_v2.ArchiveTraceRequest")
 	golang_proto.RegisterType((*ArchiveTraceRequest)(nil), "jaeger.api_v2.ArchiveTraceRequest")
 	proto.RegisterType((*ArchiveTraceResponse)(nil), "jaeger.api_v2.ArchiveTraceResponse")
@@ -647,6 +1185,18 @@ func init() {
 	golang_proto.RegisterMapType((map[string]string)(nil), "jaeger.api_v2.TraceQueryParameters.TagsEntry")
 	proto.RegisterType((*FindTracesRequest)(nil), "jaeger.api_v2.FindTracesRequest")
 	golang_proto.RegisterType((*FindTracesRequest)(nil), "jaeger.api_v2.FindTracesRequest")
+	proto.RegisterType((*FindTraceIDsRequest)(nil), "jaeger.api_v2.FindTraceIDsRequest")
+	golang_proto.RegisterType((*FindTraceIDsRequest)(nil), "jaeger.api_v2.FindTraceIDsRequest")
+	proto.RegisterType((*TraceIDsResponseChunk)(nil), "jaeger.api_v2.TraceIDsResponseChunk")
+	golang_proto.RegisterType((*TraceIDsResponseChunk)(nil), "jaeger.api_v2.TraceIDsResponseChunk")
+	proto.RegisterType((*LatencyStats)(nil), "jaeger.api_v2.LatencyStats")
+	golang_proto.RegisterType((*LatencyStats)(nil), "jaeger.api_v2.LatencyStats")
+	proto.RegisterType((*StructureGroup)(nil), "jaeger.api_v2.StructureGroup")
+	golang_proto.RegisterType((*StructureGroup)(nil), "jaeger.api_v2.StructureGroup")
+	proto.RegisterType((*GetStructureGroupsRequest)(nil), "jaeger.api_v2.GetStructureGroupsRequest")
+	golang_proto.RegisterType((*GetStructureGroupsRequest)(nil), "jaeger.api_v2.GetStructureGroupsRequest")
+	proto.RegisterType((*GetStructureGroupsResponse)(nil), "jaeger.api_v2.GetStructureGroupsResponse")
+	golang_proto.RegisterType((*GetStructureGroupsResponse)(nil), "jaeger.api_v2.GetStructureGroupsResponse")
 	proto.RegisterType((*GetServicesRequest)(nil), "jaeger.api_v2.GetServicesRequest")
 	golang_proto.RegisterType((*GetServicesRequest)(nil), "jaeger.api_v2.GetServicesRequest")
 	proto.RegisterType((*GetServicesResponse)(nil), "jaeger.api_v2.GetServicesResponse")
@@ -665,68 +1215,104 @@ func init() { proto.RegisterFile("api_v2/query.proto", fileDescriptor_26651706f9
 func init() { golang_proto.RegisterFile("api_v2/query.proto", fileDescriptor_26651706f9f8a4f0) }
 
 var fileDescriptor_26651706f9f8a4f0 = []byte{
-	// 965 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x55, 0x4f, 0x73, 0xdb, 0x44,
-	0x14, 0x47, 0x4e, 0x1c, 0xdb, 0x4f, 0x76, 0x4b, 0xd7, 0x4e, 0x6b, 0x04, 0xd8, 0x8e, 0x42, 0x21,
-	0xd3, 0x21, 0xda, 0xd4, 0x0c, 0x53, 0xc8, 0x05, 0xe2, 0xa6, 0xf5, 0xa4, 0x03, 0xa5, 0xa8, 0x39,
-	0xc1, 0xc1, 0xb3, 0xb1, 0x16, 0x59, 0x24, 0x5e, 0xa9, 0xd2, 0xda, 0x4d, 0x86, 0xe1, 0xc2, 0x27,
-	0x60, 0xe0, 0xc2, 0x89, 0x2b, 0x27, 0xbe, 0x03, 0xc7, 0x1e, 0x99, 0xe1, 0xc6, 0x21, 0x30, 0x81,
-	0x0f, 0xc2, 0xec, 0x1f, 0x39, 0xb6, 0xec, 0x49, 0x43, 0x0f, 0x3d, 0x69, 0xf7, 0xed, 0x7b, 0xbf,
-	0xf7, 0xef, 0xf7, 0x9e, 0x00, 0x91, 0x28, 0xe8, 0x8d, 0xdb, 0xf8, 0xc9, 0x88, 0xc6, 0x27, 0x4e,
-	0x14, 0x87, 0x3c, 0x44, 0x95, 0xaf, 0x09, 0xf5, 0x69, 0xec, 0xa8, 0x27, 0xcb, 0x1c, 0x86, 0x1e,
-	0x3d, 0x52, 0x6f, 0x56, 0xcd, 0x0f, 0xfd, 0x50, 0x1e, 0xb1, 0x38, 0x69, 0xe9, 0x1b, 0x7e, 0x18,
-	0xfa, 0x47, 0x14, 0x93, 0x28, 0xc0, 0x84, 0xb1, 0x90, 0x13, 0x1e, 0x84, 0x2c, 0xd1, 0xaf, 0x4d,
-	0xfd, 0x2a, 0x6f, 0x07, 0xa3, 0xaf, 0x30, 0x0f, 0x86, 0x34, 0xe1, 0x64, 0x18, 0x69, 0x85, 0x46,
-	0x56, 0xc1, 0x1b, 0xc5, 0x12, 0x41, 0xbf, 0xbf, 0x2b, 0x3f, 0xfd, 0x4d, 0x9f, 0xb2, 0xcd, 0xe4,
-	0x29, 0xf1, 0x7d, 0x1a, 0xe3, 0x30, 0x92, 0x2e, 0xe6, 0xdd, 0xd9, 0x0c, 0xae, 0x76, 0x29, 0xdf,
-	0x8f, 0x49, 0x9f, 0xba, 0xf4, 0xc9, 0x88, 0x26, 0x1c, 0x7d, 0x09, 0x45, 0x2e, 0xee, 0xbd, 0xc0,
-	0xab, 0x1b, 0x2d, 0x63, 0xa3, 0xdc, 0xf9, 0xf8, 0xd9, 0x69, 0xf3, 0x95, 0x3f, 0x4f, 0x9b, 0x9b,
-	0x7e, 0xc0, 0x07, 0xa3, 0x03, 0xa7, 0x1f, 0x0e, 0xb1, 0x4a, 0x5b, 0x28, 0x06, 0xcc, 0xd7, 0x37,
-	0xac, 0x92, 0x97, 0x68, 0x7b, 0xbb, 0x67, 0xa7, 0xcd, 0x82, 0x3e, 0xba, 0x05, 0x89, 0xb8, 0xe7,
-	0xd9, 0xf7, 0x00, 0x3d, 0x8e, 0x08, 0x4b, 0x5c, 0x9a, 0x44, 0x21, 0x4b, 0xe8, 0xdd, 0xc1, 0x88,
-	0x1d, 0x22, 0x0c, 0xf9, 0x44, 0x48, 0xeb, 0x46, 0x6b, 0x69, 0xc3, 0x6c, 0x57, 0x9d, 0x99, 0xa2,
-	0x3a, 0xc2, 0xa2, 0xb3, 0x2c, 0x82, 0x70, 0x95, 0x9e, 0x1d, 0x43, 0x75, 0x27, 0xee, 0x0f, 0x82,
-	0x31, 0x7d, 0x79, 0xa1, 0x5f, 0x87, 0xda, 0xac, 0x4f, 0x95, 0x81, 0xfd, 0xcb, 0x32, 0xd4, 0xa4,
-	0xe4, 0x73, 0x41, 0x8b, 0x47, 0x24, 0x26, 0x43, 0xca, 0x69, 0x9c, 0xa0, 0x35, 0x28, 0x27, 0x34,
-	0x1e, 0x07, 0x7d, 0xda, 0x63, 0x64, 0x48, 0x65, 0x44, 0x25, 0xd7, 0xd4, 0xb2, 0x87, 0x64, 0x48,
-	0xd1, 0x4d, 0xb8, 0x12, 0x46, 0x54, 0xf5, 0x4f, 0x29, 0xe5, 0xa4, 0x52, 0x65, 0x22, 0x95, 0x6a,
-	0x3b, 0xb0, 0xcc, 0x89, 0x9f, 0xd4, 0x97, 0x64, 0x79, 0x36, 0x33, 0xe5, 0x59, 0xe4, 0xdc, 0xd9,
-	0x27, 0x7e, 0x72, 0x8f, 0xf1, 0xf8, 0xc4, 0x95, 0xa6, 0xe8, 0x01, 0x5c, 0x49, 0x38, 0x89, 0x79,
-	0x4f, 0xf0, 0xa9, 0x37, 0x0c, 0x58, 0x7d, 0xb9, 0x65, 0x6c, 0x98, 0x6d, 0xcb, 0x51, 0x7c, 0x72,
-	0x52, 0x3e, 0x39, 0xfb, 0x29, 0xe1, 0x3a, 0x45, 0x51, 0xbc, 0xef, 0xff, 0x6a, 0x1a, 0x6e, 0x59,
-	0xda, 0x8a, 0x97, 0x4f, 0x03, 0x96, 0xc5, 0x22, 0xc7, 0xf5, 0xfc, 0x8b, 0x61, 0x91, 0x63, 0x74,
-	0x1f, 0xca, 0x29, 0x81, 0x65, 0x54, 0x2b, 0x12, 0xe9, 0xb5, 0x39, 0xa4, 0x5d, 0xad, 0xa4, 0x80,
-	0x7e, 0x12, 0x40, 0x66, 0x6a, 0x28, 0x62, 0x9a, 0xc1, 0x21, 0xc7, 0xf5, 0xc2, 0x8b, 0xe0, 0x90,
-	0x63, 0xd5, 0x34, 0x12, 0xf7, 0x07, 0x3d, 0x8f, 0x46, 0x7c, 0x50, 0x2f, 0xb6, 0x8c, 0x8d, 0xbc,
-	0x68, 0x9a, 0x90, 0xed, 0x0a, 0x91, 0x75, 0x07, 0x4a, 0x93, 0xea, 0xa2, 0x57, 0x61, 0xe9, 0x90,
-	0x9e, 0xe8, 0xde, 0x8a, 0x23, 0xaa, 0x41, 0x7e, 0x4c, 0x8e, 0x46, 0x69, 0x2b, 0xd5, 0x65, 0x3b,
-	0xf7, 0x81, 0x61, 0x3f, 0x84, 0x6b, 0xf7, 0x03, 0xe6, 0xc9, 0x7e, 0x25, 0x29, 0x67, 0x3f, 0x84,
-	0xbc, 0xdc, 0x27, 0x12, 0xc2, 0x6c, 0xaf, 0x5f, 0xa2, 0xb9, 0xae, 0xb2, 0xb0, 0x6b, 0x80, 0xba,
-	0x94, 0x3f, 0x56, 0x7c, 0x4a, 0x01, 0xed, 0xdb, 0x50, 0x9d, 0x91, 0x2a, 0x9a, 0x22, 0x0b, 0x8a,
-	0x9a, 0x79, 0x6a, 0xcc, 0x4a, 0xee, 0xe4, 0x6e, 0x6f, 0x41, 0xad, 0x4b, 0xf9, 0x67, 0x29, 0xe7,
-	0x26, 0xb1, 0xd5, 0xa1, 0xa0, 0x75, 0x74, 0x82, 0xe9, 0xd5, 0xbe, 0x03, 0xab, 0x19, 0x0b, 0xed,
-	0xa6, 0x01, 0x30, 0xe1, 0x6e, 0xea, 0x68, 0x4a, 0x62, 0xff, 0x6c, 0xc0, 0xf5, 0x2e, 0xe5, 0xbb,
-	0x34, 0xa2, 0xcc, 0xa3, 0xac, 0x1f, 0x9c, 0x57, 0xe2, 0x2e, 0xc0, 0x39, 0xad, 0x74, 0x39, 0x2e,
-	0x47, 0xa9, 0xd2, 0x84, 0x52, 0xe8, 0x23, 0x28, 0x52, 0xe6, 0x29, 0x88, 0xdc, 0xff, 0x80, 0x28,
-	0x50, 0xe6, 0x09, 0xb9, 0x7d, 0x00, 0x37, 0xe6, 0xe2, 0xd3, 0xb9, 0x75, 0xa1, 0xec, 0x4d, 0xc9,
-	0xf5, 0xb6, 0x7a, 0x33, 0xd3, 0xb1, 0x89, 0xe9, 0xc9, 0x27, 0x01, 0x3b, 0xd4, 0x7b, 0x6b, 0xc6,
-	0xb0, 0xfd, 0x6b, 0x1e, 0xca, 0xb2, 0xa7, 0xba, 0x4b, 0xe8, 0x10, 0x8a, 0xe9, 0x1a, 0x46, 0x8d,
-	0x0c, 0x5e, 0x66, 0x3f, 0x5b, 0x6b, 0x0b, 0xb6, 0xe3, 0xec, 0x3e, 0xb5, 0xad, 0xef, 0xfe, 0xf8,
-	0xf7, 0xc7, 0x5c, 0x0d, 0x21, 0x2c, 0x97, 0x57, 0x82, 0xbf, 0x49, 0xd7, 0xe2, 0xb7, 0x5b, 0x06,
-	0xe2, 0x50, 0x9e, 0x5e, 0x64, 0xc8, 0xce, 0x00, 0x2e, 0xd8, 0xac, 0xd6, 0xfa, 0x85, 0x3a, 0x7a,
-	0x13, 0xbe, 0x2e, 0xdd, 0xae, 0xda, 0x55, 0x4c, 0xd4, 0xf3, 0x94, 0x5f, 0xe4, 0x03, 0x9c, 0x93,
-	0x1f, 0xb5, 0x32, 0x78, 0x73, 0x73, 0x71, 0x99, 0x34, 0x91, 0xf4, 0x57, 0xb6, 0x0b, 0x58, 0x8d,
-	0xe7, 0xb6, 0x71, 0x6b, 0xcb, 0x40, 0x3e, 0x98, 0x53, 0xfc, 0x47, 0x6b, 0xf3, 0xe5, 0xcc, 0x4c,
-	0x8c, 0x65, 0x5f, 0xa4, 0xa2, 0x73, 0xbb, 0x26, 0x7d, 0x99, 0xa8, 0x84, 0xd3, 0xa9, 0x41, 0x21,
-	0x54, 0x66, 0x66, 0x00, 0xad, 0xcf, 0xe3, 0xcc, 0xcd, 0x94, 0xf5, 0xd6, 0xc5, 0x4a, 0xda, 0x5d,
-	0x55, 0xba, 0xab, 0x20, 0x13, 0x9f, 0xcf, 0x0e, 0x7a, 0x2a, 0x7f, 0xd6, 0xd3, 0xd4, 0x44, 0x37,
-	0xe7, 0xd1, 0x16, 0x8c, 0x96, 0xf5, 0xf6, 0xf3, 0xd4, 0xb4, 0xdb, 0x55, 0xe9, 0xf6, 0x2a, 0xaa,
-	0xe0, 0x69, 0xbe, 0x76, 0xc6, 0x3f, 0xec, 0x74, 0x50, 0xbe, 0xbd, 0x74, 0xdb, 0xd9, 0xba, 0x95,
-	0x33, 0x72, 0xf1, 0xfb, 0x00, 0x0f, 0x24, 0x5e, 0x6b, 0xe7, 0xd1, 0x1e, 0x7a, 0x67, 0xc0, 0x79,
-	0x94, 0x6c, 0x63, 0xfc, 0x9c, 0x7f, 0xec, 0xb3, 0xb3, 0x86, 0xf1, 0xfb, 0x59, 0xc3, 0xf8, 0xfb,
-	0xac, 0x61, 0xfc, 0xf6, 0x4f, 0xc3, 0x80, 0x1b, 0x41, 0xe8, 0xcc, 0x28, 0xea, 0xf0, 0xbe, 0x58,
-	0x51, 0xdf, 0x83, 0x15, 0x39, 0xb2, 0xef, 0xfd, 0x17, 0x00, 0x00, 0xff, 0xff, 0xee, 0x7a, 0xc9,
-	0xa7, 0x79, 0x09, 0x00, 0x00,
+	// 1551 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x57, 0x4f, 0x73, 0x13, 0x47,
+	0x16, 0x67, 0x24, 0xcb, 0x92, 0x9e, 0x64, 0x23, 0xda, 0x7f, 0x18, 0x06, 0xb0, 0xc5, 0x00, 0x8b,
+	0x96, 0x5a, 0x6b, 0x8c, 0xb6, 0x28, 0x16, 0xd8, 0xaa, 0x5d, 0xd9, 0x12, 0xc6, 0x94, 0xb1, 0xb5,
+	0x63, 0xef, 0x56, 0x6d, 0x52, 0x15, 0x55, 0x5b, 0xd3, 0x19, 0x0d, 0xb6, 0x66, 0xc4, 0x74, 0xcb,
+	0x7f, 0x48, 0xe5, 0x90, 0x1c, 0x72, 0x4e, 0x91, 0x4b, 0x4e, 0xb9, 0xe5, 0x90, 0x4b, 0x3e, 0x43,
+	0x8e, 0x1c, 0x53, 0x95, 0x5b, 0x0e, 0x90, 0x72, 0xf2, 0x05, 0x72, 0xca, 0x35, 0x35, 0xdd, 0x3d,
+	0x92, 0x46, 0x12, 0x60, 0x53, 0x4e, 0x4e, 0x9a, 0x7e, 0xfd, 0xde, 0xef, 0xbd, 0xd7, 0xfd, 0x7e,
+	0xef, 0xb5, 0x00, 0xe1, 0xb6, 0x53, 0xdf, 0x2b, 0x19, 0x4f, 0x3b, 0xc4, 0x3f, 0x2c, 0xb6, 0x7d,
+	0x8f, 0x79, 0x68, 0xe2, 0x09, 0x26, 0x36, 0xf1, 0x8b, 0x62, 0x4b, 0xcb, 0xb4, 0x3c, 0x8b, 0xec,
+	0x8a, 0x3d, 0x6d, 0xda, 0xf6, 0x6c, 0x8f, 0x7f, 0x1a, 0xc1, 0x97, 0x94, 0x5e, 0xb2, 0x3d, 0xcf,
+	0xde, 0x25, 0x06, 0x6e, 0x3b, 0x06, 0x76, 0x5d, 0x8f, 0x61, 0xe6, 0x78, 0x2e, 0x95, 0xbb, 0xf3,
+	0x72, 0x97, 0xaf, 0xb6, 0x3b, 0x1f, 0x1a, 0xcc, 0x69, 0x11, 0xca, 0x70, 0xab, 0x2d, 0x15, 0xe6,
+	0x06, 0x15, 0xac, 0x8e, 0xcf, 0x11, 0xe4, 0xfe, 0xdf, 0xf8, 0x4f, 0x63, 0xc1, 0x26, 0xee, 0x02,
+	0xdd, 0xc7, 0xb6, 0x4d, 0x7c, 0xc3, 0x6b, 0x73, 0x17, 0xc3, 0xee, 0xf4, 0xcf, 0x14, 0x38, 0xbb,
+	0x42, 0xd8, 0x96, 0x8f, 0x1b, 0xc4, 0x24, 0x4f, 0x3b, 0x84, 0x32, 0xf4, 0x3e, 0xa4, 0x58, 0xb0,
+	0xae, 0x3b, 0x96, 0xaa, 0xe4, 0x95, 0x42, 0x76, 0xe9, 0xdf, 0x2f, 0x5e, 0xce, 0x9f, 0xf9, 0xf1,
+	0xe5, 0xfc, 0x82, 0xed, 0xb0, 0x66, 0x67, 0xbb, 0xd8, 0xf0, 0x5a, 0x86, 0xc8, 0x3b, 0x50, 0x74,
+	0x5c, 0x5b, 0xae, 0x0c, 0x91, 0x3d, 0x47, 0x5b, 0xad, 0x1c, 0xbd, 0x9c, 0x4f, 0xca, 0x4f, 0x33,
+	0xc9, 0x11, 0x57, 0x2d, 0x34, 0x0b, 0xe3, 0xd8, 0x7a, 0xd2, 0xa1, 0x4c, 0x8d, 0xe5, 0x95, 0x42,
+	0xca, 0x94, 0x2b, 0xfd, 0x57, 0x05, 0xd0, 0x66, 0x1b, 0xbb, 0xd4, 0x24, 0xb4, 0xed, 0xb9, 0x94,
+	0x2c, 0x37, 0x3b, 0xee, 0x0e, 0x32, 0x20, 0x41, 0x03, 0xa9, 0xaa, 0xe4, 0xe3, 0x85, 0x4c, 0x69,
+	0xaa, 0x18, 0x39, 0xee, 0x62, 0x60, 0xb1, 0x34, 0x16, 0x44, 0x67, 0x0a, 0x3d, 0x74, 0x11, 0xd2,
+	0x22, 0x78, 0xe2, 0x5a, 0xd2, 0x85, 0xc8, 0xa6, 0xea, 0x5a, 0x48, 0x83, 0xd4, 0x3e, 0xf6, 0x5d,
+	0xc7, 0xb5, 0xa9, 0x1a, 0xcf, 0xc7, 0x0b, 0x69, 0xb3, 0xbb, 0x8e, 0x64, 0x3d, 0x76, 0xda, 0x59,
+	0x4f, 0x43, 0x82, 0xf8, 0xbe, 0xe7, 0xab, 0x89, 0xbc, 0x52, 0x48, 0x9b, 0x62, 0xa1, 0x7f, 0xa3,
+	0x40, 0x2e, 0x3c, 0x7c, 0x1a, 0x9e, 0xfe, 0x07, 0x61, 0x02, 0x8e, 0x25, 0xb2, 0xce, 0x2e, 0x95,
+	0xdf, 0x35, 0x90, 0x94, 0xfc, 0xa4, 0xf2, 0x0c, 0x56, 0x2d, 0x8a, 0x2e, 0x03, 0x34, 0x82, 0xa3,
+	0xad, 0x53, 0xe7, 0x19, 0xe1, 0x27, 0x34, 0x61, 0xa6, 0xb9, 0x64, 0xd3, 0x79, 0x46, 0xfa, 0xee,
+	0x27, 0x1e, 0xb9, 0x1f, 0x1f, 0xa6, 0xca, 0x7e, 0xa3, 0xe9, 0xec, 0x91, 0x3f, 0xad, 0x56, 0xf4,
+	0x59, 0x98, 0x8e, 0xfa, 0x14, 0x95, 0xa1, 0x7f, 0x9d, 0x80, 0x69, 0x2e, 0xf9, 0x4f, 0x40, 0xc4,
+	0x1a, 0xf6, 0x71, 0x8b, 0x30, 0xe2, 0x53, 0x74, 0x05, 0xb2, 0x94, 0xf8, 0x7b, 0x4e, 0x83, 0xd4,
+	0x5d, 0xdc, 0x22, 0x3c, 0xa2, 0xb4, 0x99, 0x91, 0xb2, 0x75, 0xdc, 0x22, 0xe8, 0x3a, 0x4c, 0x7a,
+	0x6d, 0x22, 0x18, 0x23, 0x94, 0x62, 0x5c, 0x69, 0xa2, 0x2b, 0xe5, 0x6a, 0x65, 0x18, 0x63, 0x58,
+	0x56, 0x49, 0xa6, 0xb4, 0x30, 0x50, 0x76, 0xa3, 0x9c, 0x17, 0xb7, 0xb0, 0x4d, 0xab, 0x2e, 0xf3,
+	0x0f, 0x4d, 0x6e, 0x8a, 0x1e, 0xc1, 0x24, 0x65, 0xd8, 0x67, 0xf5, 0x80, 0xc1, 0xf5, 0x96, 0xe3,
+	0xf2, 0xb2, 0xca, 0x94, 0xb4, 0xa2, 0x60, 0x70, 0x31, 0x64, 0x70, 0x71, 0x2b, 0xa4, 0xf8, 0x52,
+	0x2a, 0x38, 0xbc, 0xcf, 0x5f, 0xcd, 0x2b, 0x66, 0x96, 0xdb, 0x06, 0x3b, 0x8f, 0x1d, 0x77, 0x10,
+	0x0b, 0x1f, 0xa8, 0x89, 0x77, 0xc3, 0xc2, 0x07, 0xe8, 0x01, 0x64, 0xc3, 0x96, 0xc1, 0xa3, 0x1a,
+	0xe7, 0x48, 0x17, 0x86, 0x90, 0x2a, 0x52, 0x49, 0x00, 0x7d, 0x19, 0x00, 0x65, 0x42, 0xc3, 0x20,
+	0xa6, 0x08, 0x0e, 0x3e, 0x50, 0x93, 0xef, 0x82, 0x83, 0x0f, 0xc4, 0xa5, 0x61, 0xbf, 0xd1, 0xac,
+	0x5b, 0xa4, 0xcd, 0x9a, 0x6a, 0x2a, 0xaf, 0x14, 0x12, 0x66, 0x46, 0xc8, 0x2a, 0x81, 0x08, 0xdd,
+	0x87, 0xb4, 0xe3, 0x32, 0x62, 0xfb, 0x0e, 0x3b, 0x54, 0xd3, 0xf9, 0x78, 0x61, 0xb2, 0x74, 0x79,
+	0xd4, 0x95, 0xac, 0x86, 0x4a, 0x66, 0x4f, 0x1f, 0xa9, 0x90, 0x6c, 0x63, 0xc6, 0x88, 0xef, 0xaa,
+	0xc0, 0xaf, 0x3a, 0x5c, 0x06, 0xb5, 0x40, 0x99, 0xdf, 0x69, 0xb0, 0x8e, 0x4f, 0xea, 0x4d, 0x4c,
+	0x9b, 0x6a, 0x46, 0xd4, 0x42, 0x57, 0xfa, 0x10, 0xd3, 0xa6, 0x76, 0x07, 0xd2, 0xdd, 0xbb, 0x45,
+	0x39, 0x88, 0xef, 0x90, 0x43, 0x59, 0x59, 0xc1, 0x67, 0xc0, 0xed, 0x3d, 0xbc, 0xdb, 0x09, 0x0b,
+	0x49, 0x2c, 0xee, 0xc5, 0xfe, 0xa1, 0xe8, 0xdf, 0x2a, 0x70, 0xee, 0x81, 0xe3, 0x5a, 0x51, 0x82,
+	0xdf, 0x85, 0x04, 0x1f, 0x20, 0x1c, 0x23, 0x53, 0xba, 0x7a, 0x8c, 0xda, 0x32, 0x85, 0xc5, 0xdb,
+	0xb8, 0x7b, 0x03, 0xce, 0x0a, 0x32, 0x5a, 0x64, 0xd7, 0x69, 0x39, 0x8c, 0x58, 0x92, 0xc4, 0x93,
+	0x5c, 0x5c, 0x09, 0xa5, 0x7d, 0x24, 0x1f, 0x8b, 0x90, 0xbc, 0x06, 0x53, 0xdd, 0x78, 0x57, 0x2b,
+	0xa7, 0x10, 0xb1, 0xbe, 0x0f, 0x33, 0x3d, 0xb4, 0xfe, 0xc6, 0xfe, 0x07, 0xb7, 0x39, 0xfd, 0xb7,
+	0x18, 0x64, 0xd7, 0x30, 0x23, 0x6e, 0xe3, 0x70, 0x93, 0x61, 0x46, 0x83, 0x6b, 0x6a, 0x78, 0x1d,
+	0x97, 0xf1, 0x24, 0xe2, 0xa6, 0x58, 0xa0, 0x3b, 0x30, 0xd6, 0x22, 0xd8, 0x55, 0x63, 0xc7, 0x2f,
+	0x5e, 0x6e, 0x80, 0xfe, 0x09, 0x49, 0xca, 0xac, 0xba, 0x45, 0xf6, 0xd4, 0xf8, 0xf1, 0x6d, 0xc7,
+	0x29, 0xb3, 0x2a, 0x64, 0x0f, 0xdd, 0x86, 0x78, 0xaf, 0x21, 0x1c, 0xcb, 0x32, 0xd0, 0xe7, 0x66,
+	0x5d, 0xee, 0x1f, 0xd3, 0x0c, 0x1f, 0x04, 0x66, 0xed, 0xdb, 0x8b, 0x27, 0x21, 0x7a, 0xa0, 0xcf,
+	0xcd, 0xee, 0xde, 0x3d, 0x09, 0xaf, 0x03, 0x7d, 0xfd, 0x95, 0x02, 0x93, 0x9b, 0x21, 0x81, 0x56,
+	0x7c, 0xaf, 0xd3, 0x46, 0x97, 0x20, 0x4d, 0x1d, 0xdb, 0xc5, 0x81, 0x44, 0x52, 0xa7, 0x27, 0x40,
+	0x08, 0xc6, 0x38, 0xf9, 0x04, 0x7f, 0xf8, 0x77, 0xb4, 0x3c, 0xe2, 0xa7, 0x3f, 0x05, 0xef, 0x43,
+	0x72, 0x57, 0x54, 0x87, 0xbc, 0x84, 0x8b, 0x03, 0x45, 0xdd, 0x5f, 0x3b, 0xf2, 0x85, 0x11, 0x5a,
+	0xe8, 0xff, 0x83, 0x0b, 0x2b, 0x84, 0x45, 0x73, 0x3c, 0x0d, 0xb2, 0xfc, 0x1f, 0xb4, 0x51, 0xb8,
+	0x82, 0x36, 0xe8, 0x3e, 0x8c, 0xdb, 0x5c, 0x22, 0xdf, 0x42, 0x83, 0x1d, 0x30, 0x6a, 0x27, 0x63,
+	0x96, 0x26, 0xfa, 0x34, 0xa0, 0x00, 0x5a, 0x0c, 0xc2, 0x30, 0x56, 0xfd, 0x16, 0x4c, 0x45, 0xa4,
+	0xd2, 0x93, 0x06, 0x29, 0x39, 0x32, 0x85, 0xaf, 0xb4, 0xd9, 0x5d, 0xeb, 0x8b, 0x30, 0xbd, 0x42,
+	0xd8, 0x46, 0x38, 0x2c, 0xbb, 0x69, 0xab, 0x90, 0x94, 0x3a, 0xf2, 0x82, 0xc3, 0xa5, 0x7e, 0x07,
+	0x66, 0x06, 0x2c, 0xa4, 0x9b, 0x39, 0x80, 0xee, 0xd0, 0x0d, 0x1d, 0xf5, 0x49, 0xf4, 0xaf, 0x14,
+	0x98, 0x5d, 0x21, 0xac, 0x42, 0xda, 0xc4, 0xb5, 0x88, 0xdb, 0x70, 0x7a, 0x3d, 0x74, 0x19, 0xa0,
+	0x37, 0x0f, 0x55, 0xe5, 0x04, 0xb3, 0x30, 0xdd, 0x9d, 0x85, 0xe8, 0x5f, 0x90, 0x22, 0xae, 0x25,
+	0x20, 0x62, 0x27, 0x80, 0x48, 0x12, 0xd7, 0x0a, 0xe4, 0xfa, 0x36, 0x9c, 0x1f, 0x8a, 0x4f, 0xe6,
+	0xb6, 0x02, 0x59, 0xab, 0x4f, 0xfe, 0x9a, 0x2b, 0xeb, 0x9a, 0x1e, 0xae, 0x39, 0xee, 0x8e, 0xbc,
+	0xb2, 0x88, 0xe1, 0xcd, 0x1d, 0x98, 0x8c, 0x8e, 0x36, 0x94, 0x85, 0xd4, 0xf2, 0xc6, 0xe3, 0xda,
+	0x5a, 0x75, 0xab, 0x9a, 0x3b, 0x83, 0x72, 0x90, 0xad, 0x98, 0x1b, 0xb5, 0x5a, 0xb5, 0x52, 0xdf,
+	0xac, 0x95, 0xd7, 0x73, 0x4a, 0xb0, 0xbf, 0x61, 0xd6, 0x1e, 0x96, 0xd7, 0xab, 0x95, 0x5c, 0x2c,
+	0xd8, 0x7f, 0xfc, 0xdf, 0xb5, 0xad, 0xd5, 0x7a, 0xad, 0x6c, 0x56, 0xd7, 0xb7, 0x72, 0x71, 0x74,
+	0x01, 0x66, 0x96, 0xcd, 0x8d, 0xcd, 0xcd, 0xfa, 0x96, 0x59, 0x5e, 0xae, 0xd6, 0xcd, 0xea, 0x83,
+	0xaa, 0x59, 0x5d, 0x5f, 0xae, 0xe6, 0xc6, 0x4a, 0xcf, 0x53, 0x90, 0xe5, 0xb5, 0x29, 0x4b, 0x02,
+	0xed, 0x40, 0x2a, 0x7c, 0xa0, 0xa2, 0xb9, 0x81, 0xe0, 0x07, 0xfe, 0x36, 0x68, 0x57, 0x46, 0xbc,
+	0xcd, 0xa3, 0x4d, 0x5f, 0xd7, 0x3e, 0xfd, 0xe1, 0x97, 0x2f, 0x62, 0xd3, 0x08, 0x19, 0x9c, 0x88,
+	0xd4, 0xf8, 0x28, 0x24, 0xf9, 0xc7, 0x8b, 0x0a, 0x22, 0x90, 0x0e, 0x31, 0x29, 0x9a, 0x7f, 0x8d,
+	0x37, 0x7a, 0x02, 0x77, 0x88, 0xbb, 0xcb, 0xea, 0x49, 0xe9, 0xee, 0x9e, 0x72, 0x73, 0x51, 0x41,
+	0x0c, 0xb2, 0xfd, 0xaf, 0x4a, 0xa4, 0x0f, 0x00, 0x8d, 0x78, 0xe6, 0x6a, 0x57, 0xdf, 0xa8, 0x23,
+	0x9f, 0xa5, 0x17, 0xb9, 0xbb, 0x19, 0x7d, 0xca, 0xc0, 0x62, 0xbb, 0x2f, 0x3d, 0x64, 0x03, 0xf4,
+	0x9e, 0x02, 0x28, 0x3f, 0x80, 0x37, 0xf4, 0x4a, 0x38, 0x59, 0x7a, 0xe2, 0xad, 0x24, 0xd2, 0x3b,
+	0x80, 0x6c, 0xff, 0x0c, 0x1f, 0x4a, 0x6f, 0xc4, 0x80, 0xd7, 0xae, 0x8d, 0x7c, 0x4c, 0x0d, 0x8c,
+	0x6c, 0xfd, 0x12, 0xf7, 0x37, 0xab, 0x9f, 0x93, 0xfe, 0xc4, 0xa9, 0x2e, 0x38, 0x96, 0x3c, 0xd8,
+	0x4f, 0x14, 0xd1, 0x64, 0xa2, 0xfd, 0x0b, 0x15, 0x86, 0x6f, 0x72, 0x74, 0xeb, 0xd4, 0xfe, 0x7a,
+	0x0c, 0x4d, 0x79, 0xd6, 0xb3, 0x3c, 0x96, 0x9c, 0x9e, 0x31, 0xba, 0x6f, 0xb5, 0x20, 0x0a, 0x64,
+	0x43, 0xa6, 0xaf, 0xa3, 0xa1, 0x2b, 0x23, 0x10, 0xa3, 0x3d, 0x50, 0xd3, 0xdf, 0xa4, 0x22, 0xbd,
+	0x9d, 0xe3, 0xde, 0x32, 0x28, 0x6d, 0x84, 0x7d, 0x10, 0x79, 0x30, 0x11, 0xe9, 0x6a, 0xe8, 0xea,
+	0x30, 0xce, 0x50, 0x97, 0xd4, 0xae, 0xbd, 0x59, 0x49, 0xba, 0x9b, 0xe2, 0xee, 0x26, 0x50, 0xc6,
+	0xe8, 0x75, 0x43, 0xb4, 0xcf, 0xff, 0xa8, 0xf7, 0x37, 0x1b, 0x74, 0x7d, 0x18, 0x6d, 0x44, 0xb3,
+	0xd4, 0xfe, 0xf2, 0x36, 0x35, 0xe9, 0x76, 0x86, 0xbb, 0x3d, 0x8b, 0x26, 0x8c, 0xfe, 0x0e, 0xb4,
+	0xb4, 0xf7, 0xbc, 0xbc, 0x84, 0x12, 0xa5, 0xf8, 0xad, 0xe2, 0xe2, 0xcd, 0x98, 0x12, 0xf3, 0x6f,
+	0x03, 0x3c, 0xe2, 0x78, 0xf9, 0x72, 0x6d, 0x15, 0xdd, 0x68, 0x32, 0xd6, 0xa6, 0xf7, 0x0c, 0xe3,
+	0x2d, 0x53, 0xf9, 0xc5, 0xd1, 0x9c, 0xf2, 0xfd, 0xd1, 0x9c, 0xf2, 0xd3, 0xd1, 0x9c, 0xf2, 0xdd,
+	0xcf, 0x73, 0x0a, 0x9c, 0x77, 0xbc, 0x62, 0x44, 0x51, 0x86, 0xf7, 0xde, 0xb8, 0xf8, 0xdd, 0x1e,
+	0xe7, 0x4d, 0xf8, 0xef, 0xbf, 0x0f, 0x00, 0x7f, 0x16, 0x16, 0xe7, 0x76, 0x11, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -742,8 +1328,14 @@ const _ = grpc.SupportPackageIsVersion4
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
 type QueryServiceClient interface {
 	GetTrace(ctx context.Context, in *GetTraceRequest, opts ...grpc.CallOption) (QueryService_GetTraceClient, error)
+	// GetTraces fetches several traces by ID. The stream is trace-delimited, and the traces
+	// are sent in the order of the request. A trace that cannot be found is reported by a
+	// single chunk carrying its ID and an error.
+	GetTraces(ctx context.Context, in *GetTracesRequest, opts ...grpc.CallOption) (QueryService_GetTracesClient, error)
 	ArchiveTrace(ctx context.Context, in *ArchiveTraceRequest, opts ...grpc.CallOption) (*ArchiveTraceResponse, error)
 	FindTraces(ctx context.Context, in *FindTracesRequest, opts ...grpc.CallOption) (QueryService_FindTracesClient, error)
+	FindTraceIDs(ctx context.Context, in *FindTraceIDsRequest, opts ...grpc.CallOption) (QueryService_FindTraceIDsClient, error)
+	GetStructureGroups(ctx context.Context, in *GetStructureGroupsRequest, opts ...grpc.CallOption) (*GetStructureGroupsResponse, error)
 	GetServices(ctx context.Context, in *GetServicesRequest, opts ...grpc.CallOption) (*GetServicesResponse, error)
 	GetOperations(ctx context.Context, in *GetOperationsRequest, opts ...grpc.CallOption) (*GetOperationsResponse, error)
 	GetDependencies(ctx context.Context, in *GetDependenciesRequest, opts ...grpc.CallOption) (*GetDependenciesResponse, error)
@@ -789,18 +1381,50 @@ func (x *queryServiceGetTraceClient) Recv() (*SpansResponseChunk, error) {
 	return m, nil
 }
 
-func (c *queryServiceClient) ArchiveTrace(ctx context.Context, in *ArchiveTraceRequest, opts ...grpc.CallOption) (*ArchiveTraceResponse, error) {
-	out := new(ArchiveTraceResponse)
-	err := c.cc.Invoke(ctx, "/jaeger.api_v2.QueryService/ArchiveTrace", in, out, opts...)
+func (c *queryServiceClient) GetTraces(ctx context.Context, in *GetTracesRequest, opts ...grpc.CallOption) (QueryService_GetTracesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_QueryService_serviceDesc.Streams[1], "/jaeger.api_v2.QueryService/GetTraces", opts...)
 	if err != nil {
 		return nil, err
 	}
-	return out, nil
-}
-
-func (c *queryServiceClient) FindTraces(ctx context.Context, in *FindTracesRequest, opts ...grpc.CallOption) (QueryService_FindTracesClient, error) {
-	stream, err := c.cc.NewStream(ctx, &_QueryService_serviceDesc.Streams[1], "/jaeger.api_v2.QueryService/FindTraces", opts...)
-	if err != nil {
+	x := &queryServiceGetTracesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type QueryService_GetTracesClient interface {
+	Recv() (*SpansResponseChunk, error)
+	grpc.ClientStream
+}
+
+type queryServiceGetTracesClient struct {
+	grpc.ClientStream
+}
+
+func (x *queryServiceGetTracesClient) Recv() (*SpansResponseChunk, error) {
+	m := new(SpansResponseChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *queryServiceClient) ArchiveTrace(ctx context.Context, in *ArchiveTraceRequest, opts ...grpc.CallOption) (*ArchiveTraceResponse, error) {
+	out := new(ArchiveTraceResponse)
+	err := c.cc.Invoke(ctx, "/jaeger.api_v2.QueryService/ArchiveTrace", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *queryServiceClient) FindTraces(ctx context.Context, in *FindTracesRequest, opts ...grpc.CallOption) (QueryService_FindTracesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_QueryService_serviceDesc.Streams[2], "/jaeger.api_v2.QueryService/FindTraces", opts...)
+	if err != nil {
 		return nil, err
 	}
 	x := &queryServiceFindTracesClient{stream}
@@ -830,6 +1454,47 @@ func (x *queryServiceFindTracesClient) Recv() (*SpansResponseChunk, error) {
 	return m, nil
 }
 
+func (c *queryServiceClient) FindTraceIDs(ctx context.Context, in *FindTraceIDsRequest, opts ...grpc.CallOption) (QueryService_FindTraceIDsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_QueryService_serviceDesc.Streams[3], "/jaeger.api_v2.QueryService/FindTraceIDs", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &queryServiceFindTraceIDsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type QueryService_FindTraceIDsClient interface {
+	Recv() (*TraceIDsResponseChunk, error)
+	grpc.ClientStream
+}
+
+type queryServiceFindTraceIDsClient struct {
+	grpc.ClientStream
+}
+
+func (x *queryServiceFindTraceIDsClient) Recv() (*TraceIDsResponseChunk, error) {
+	m := new(TraceIDsResponseChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *queryServiceClient) GetStructureGroups(ctx context.Context, in *GetStructureGroupsRequest, opts ...grpc.CallOption) (*GetStructureGroupsResponse, error) {
+	out := new(GetStructureGroupsResponse)
+	err := c.cc.Invoke(ctx, "/jaeger.api_v2.QueryService/GetStructureGroups", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *queryServiceClient) GetServices(ctx context.Context, in *GetServicesRequest, opts ...grpc.CallOption) (*GetServicesResponse, error) {
 	out := new(GetServicesResponse)
 	err := c.cc.Invoke(ctx, "/jaeger.api_v2.QueryService/GetServices", in, out, opts...)
@@ -860,8 +1525,14 @@ func (c *queryServiceClient) GetDependencies(ctx context.Context, in *GetDepende
 // QueryServiceServer is the server API for QueryService service.
 type QueryServiceServer interface {
 	GetTrace(*GetTraceRequest, QueryService_GetTraceServer) error
+	// GetTraces fetches several traces by ID. The stream is trace-delimited, and the traces
+	// are sent in the order of the request. A trace that cannot be found is reported by a
+	// single chunk carrying its ID and an error.
+	GetTraces(*GetTracesRequest, QueryService_GetTracesServer) error
 	ArchiveTrace(context.Context, *ArchiveTraceRequest) (*ArchiveTraceResponse, error)
 	FindTraces(*FindTracesRequest, QueryService_FindTracesServer) error
+	FindTraceIDs(*FindTraceIDsRequest, QueryService_FindTraceIDsServer) error
+	GetStructureGroups(context.Context, *GetStructureGroupsRequest) (*GetStructureGroupsResponse, error)
 	GetServices(context.Context, *GetServicesRequest) (*GetServicesResponse, error)
 	GetOperations(context.Context, *GetOperationsRequest) (*GetOperationsResponse, error)
 	GetDependencies(context.Context, *GetDependenciesRequest) (*GetDependenciesResponse, error)
@@ -892,6 +1563,27 @@ func (x *queryServiceGetTraceServer) Send(m *SpansResponseChunk) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _QueryService_GetTraces_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetTracesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(QueryServiceServer).GetTraces(m, &queryServiceGetTracesServer{stream})
+}
+
+type QueryService_GetTracesServer interface {
+	Send(*SpansResponseChunk) error
+	grpc.ServerStream
+}
+
+type queryServiceGetTracesServer struct {
+	grpc.ServerStream
+}
+
+func (x *queryServiceGetTracesServer) Send(m *SpansResponseChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 func _QueryService_ArchiveTrace_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ArchiveTraceRequest)
 	if err := dec(in); err != nil {
@@ -931,6 +1623,45 @@ func (x *queryServiceFindTracesServer) Send(m *SpansResponseChunk) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _QueryService_FindTraceIDs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(FindTraceIDsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(QueryServiceServer).FindTraceIDs(m, &queryServiceFindTraceIDsServer{stream})
+}
+
+type QueryService_FindTraceIDsServer interface {
+	Send(*TraceIDsResponseChunk) error
+	grpc.ServerStream
+}
+
+type queryServiceFindTraceIDsServer struct {
+	grpc.ServerStream
+}
+
+func (x *queryServiceFindTraceIDsServer) Send(m *TraceIDsResponseChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _QueryService_GetStructureGroups_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStructureGroupsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueryServiceServer).GetStructureGroups(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/jaeger.api_v2.QueryService/GetStructureGroups",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueryServiceServer).GetStructureGroups(ctx, req.(*GetStructureGroupsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _QueryService_GetServices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetServicesRequest)
 	if err := dec(in); err != nil {
@@ -993,6 +1724,10 @@ var _QueryService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "ArchiveTrace",
 			Handler:    _QueryService_ArchiveTrace_Handler,
 		},
+		{
+			MethodName: "GetStructureGroups",
+			Handler:    _QueryService_GetStructureGroups_Handler,
+		},
 		{
 			MethodName: "GetServices",
 			Handler:    _QueryService_GetServices_Handler,
@@ -1012,11 +1747,21 @@ var _QueryService_serviceDesc = grpc.ServiceDesc{
 			Handler:       _QueryService_GetTrace_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "GetTraces",
+			Handler:       _QueryService_GetTraces_Handler,
+			ServerStreams: true,
+		},
 		{
 			StreamName:    "FindTraces",
 			Handler:       _QueryService_FindTraces_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "FindTraceIDs",
+			Handler:       _QueryService_FindTraceIDs_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "api_v2/query.proto",
 }
@@ -1044,6 +1789,16 @@ func (m *GetTraceRequest) MarshalTo(dAtA []byte) (int, error) {
 		return 0, err
 	}
 	i += n1
+	if m.Adjust {
+		dAtA[i] = 0x10
+		i++
+		if m.Adjust {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
 	if m.XXX_unrecognized != nil {
 		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
@@ -1077,6 +1832,93 @@ func (m *SpansResponseChunk) MarshalTo(dAtA []byte) (int, error) {
 			i += n
 		}
 	}
+	if m.TraceEnd {
+		dAtA[i] = 0x10
+		i++
+		if m.TraceEnd {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if len(m.Warnings) > 0 {
+		for _, s := range m.Warnings {
+			dAtA[i] = 0x1a
+			i++
+			l = len(s)
+			for l >= 1<<7 {
+				dAtA[i] = uint8(uint64(l)&0x7f | 0x80)
+				l >>= 7
+				i++
+			}
+			dAtA[i] = uint8(l)
+			i++
+			i += copy(dAtA[i:], s)
+		}
+	}
+	dAtA[i] = 0x22
+	i++
+	i = encodeVarintQuery(dAtA, i, uint64(m.TraceID.Size()))
+	n2, err := m.TraceID.MarshalTo(dAtA[i:])
+	if err != nil {
+		return 0, err
+	}
+	i += n2
+	if len(m.Error) > 0 {
+		dAtA[i] = 0x2a
+		i++
+		i = encodeVarintQuery(dAtA, i, uint64(len(m.Error)))
+		i += copy(dAtA[i:], m.Error)
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *GetTracesRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *GetTracesRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.TraceIDs) > 0 {
+		for _, msg := range m.TraceIDs {
+			dAtA[i] = 0xa
+			i++
+			i = encodeVarintQuery(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if m.ChunkSize != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintQuery(dAtA, i, uint64(m.ChunkSize))
+	}
+	if m.Adjust {
+		dAtA[i] = 0x18
+		i++
+		if m.Adjust {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
 	if m.XXX_unrecognized != nil {
 		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
@@ -1101,11 +1943,11 @@ func (m *ArchiveTraceRequest) MarshalTo(dAtA []byte) (int, error) {
 	dAtA[i] = 0xa
 	i++
 	i = encodeVarintQuery(dAtA, i, uint64(m.TraceID.Size()))
-	n2, err := m.TraceID.MarshalTo(dAtA[i:])
+	n3, err := m.TraceID.MarshalTo(dAtA[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n2
+	i += n3
 	if m.XXX_unrecognized != nil {
 		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
@@ -1180,40 +2022,69 @@ func (m *TraceQueryParameters) MarshalTo(dAtA []byte) (int, error) {
 	dAtA[i] = 0x22
 	i++
 	i = encodeVarintQuery(dAtA, i, uint64(github_com_gogo_protobuf_types.SizeOfStdTime(m.StartTimeMin)))
-	n3, err := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.StartTimeMin, dAtA[i:])
+	n4, err := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.StartTimeMin, dAtA[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n3
+	i += n4
 	dAtA[i] = 0x2a
 	i++
 	i = encodeVarintQuery(dAtA, i, uint64(github_com_gogo_protobuf_types.SizeOfStdTime(m.StartTimeMax)))
-	n4, err := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.StartTimeMax, dAtA[i:])
+	n5, err := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.StartTimeMax, dAtA[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n4
+	i += n5
 	dAtA[i] = 0x32
 	i++
 	i = encodeVarintQuery(dAtA, i, uint64(github_com_gogo_protobuf_types.SizeOfStdDuration(m.DurationMin)))
-	n5, err := github_com_gogo_protobuf_types.StdDurationMarshalTo(m.DurationMin, dAtA[i:])
+	n6, err := github_com_gogo_protobuf_types.StdDurationMarshalTo(m.DurationMin, dAtA[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n5
+	i += n6
 	dAtA[i] = 0x3a
 	i++
 	i = encodeVarintQuery(dAtA, i, uint64(github_com_gogo_protobuf_types.SizeOfStdDuration(m.DurationMax)))
-	n6, err := github_com_gogo_protobuf_types.StdDurationMarshalTo(m.DurationMax, dAtA[i:])
+	n7, err := github_com_gogo_protobuf_types.StdDurationMarshalTo(m.DurationMax, dAtA[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n6
+	i += n7
 	if m.SearchDepth != 0 {
 		dAtA[i] = 0x40
 		i++
 		i = encodeVarintQuery(dAtA, i, uint64(m.SearchDepth))
 	}
+	if len(m.Integrity) > 0 {
+		dAtA9 := make([]byte, len(m.Integrity)*10)
+		var j8 int
+		for _, num := range m.Integrity {
+			for num >= 1<<7 {
+				dAtA9[j8] = uint8(uint64(num)&0x7f | 0x80)
+				num >>= 7
+				j8++
+			}
+			dAtA9[j8] = uint8(num)
+			j8++
+		}
+		dAtA[i] = 0x4a
+		i++
+		i = encodeVarintQuery(dAtA, i, uint64(j8))
+		i += copy(dAtA[i:], dAtA9[:j8])
+	}
+	if len(m.Pattern) > 0 {
+		dAtA[i] = 0x52
+		i++
+		i = encodeVarintQuery(dAtA, i, uint64(len(m.Pattern)))
+		i += copy(dAtA[i:], m.Pattern)
+	}
+	if len(m.StructureHash) > 0 {
+		dAtA[i] = 0x5a
+		i++
+		i = encodeVarintQuery(dAtA, i, uint64(len(m.StructureHash)))
+		i += copy(dAtA[i:], m.StructureHash)
+	}
 	if m.XXX_unrecognized != nil {
 		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
@@ -1239,11 +2110,36 @@ func (m *FindTracesRequest) MarshalTo(dAtA []byte) (int, error) {
 		dAtA[i] = 0xa
 		i++
 		i = encodeVarintQuery(dAtA, i, uint64(m.Query.Size()))
-		n7, err := m.Query.MarshalTo(dAtA[i:])
+		n10, err := m.Query.MarshalTo(dAtA[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n7
+		i += n10
+	}
+	if m.ChunkSize != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintQuery(dAtA, i, uint64(m.ChunkSize))
+	}
+	if m.TraceDelimited {
+		dAtA[i] = 0x18
+		i++
+		if m.TraceDelimited {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if m.Adjust {
+		dAtA[i] = 0x20
+		i++
+		if m.Adjust {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
 	}
 	if m.XXX_unrecognized != nil {
 		i += copy(dAtA[i:], m.XXX_unrecognized)
@@ -1251,7 +2147,7 @@ func (m *FindTracesRequest) MarshalTo(dAtA []byte) (int, error) {
 	return i, nil
 }
 
-func (m *GetServicesRequest) Marshal() (dAtA []byte, err error) {
+func (m *FindTraceIDsRequest) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalTo(dAtA)
@@ -1261,18 +2157,28 @@ func (m *GetServicesRequest) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *GetServicesRequest) MarshalTo(dAtA []byte) (int, error) {
+func (m *FindTraceIDsRequest) MarshalTo(dAtA []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
 	_ = l
+	if m.Query != nil {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintQuery(dAtA, i, uint64(m.Query.Size()))
+		n11, err := m.Query.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n11
+	}
 	if m.XXX_unrecognized != nil {
 		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
 	return i, nil
 }
 
-func (m *GetServicesResponse) Marshal() (dAtA []byte, err error) {
+func (m *TraceIDsResponseChunk) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalTo(dAtA)
@@ -1282,24 +2188,21 @@ func (m *GetServicesResponse) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *GetServicesResponse) MarshalTo(dAtA []byte) (int, error) {
+func (m *TraceIDsResponseChunk) MarshalTo(dAtA []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
 	_ = l
-	if len(m.Services) > 0 {
-		for _, s := range m.Services {
+	if len(m.TraceIDs) > 0 {
+		for _, msg := range m.TraceIDs {
 			dAtA[i] = 0xa
 			i++
-			l = len(s)
-			for l >= 1<<7 {
-				dAtA[i] = uint8(uint64(l)&0x7f | 0x80)
-				l >>= 7
-				i++
+			i = encodeVarintQuery(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
 			}
-			dAtA[i] = uint8(l)
-			i++
-			i += copy(dAtA[i:], s)
+			i += n
 		}
 	}
 	if m.XXX_unrecognized != nil {
@@ -1308,7 +2211,7 @@ func (m *GetServicesResponse) MarshalTo(dAtA []byte) (int, error) {
 	return i, nil
 }
 
-func (m *GetOperationsRequest) Marshal() (dAtA []byte, err error) {
+func (m *LatencyStats) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalTo(dAtA)
@@ -1318,29 +2221,277 @@ func (m *GetOperationsRequest) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *GetOperationsRequest) MarshalTo(dAtA []byte) (int, error) {
+func (m *LatencyStats) MarshalTo(dAtA []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
 	_ = l
-	if len(m.Service) > 0 {
-		dAtA[i] = 0xa
+	if m.Count != 0 {
+		dAtA[i] = 0x8
 		i++
-		i = encodeVarintQuery(dAtA, i, uint64(len(m.Service)))
-		i += copy(dAtA[i:], m.Service)
+		i = encodeVarintQuery(dAtA, i, uint64(m.Count))
 	}
-	if m.XXX_unrecognized != nil {
-		i += copy(dAtA[i:], m.XXX_unrecognized)
+	dAtA[i] = 0x12
+	i++
+	i = encodeVarintQuery(dAtA, i, uint64(github_com_gogo_protobuf_types.SizeOfStdDuration(m.Mean)))
+	n12, err := github_com_gogo_protobuf_types.StdDurationMarshalTo(m.Mean, dAtA[i:])
+	if err != nil {
+		return 0, err
 	}
-	return i, nil
-}
-
-func (m *GetOperationsResponse) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
+	i += n12
+	dAtA[i] = 0x1a
+	i++
+	i = encodeVarintQuery(dAtA, i, uint64(github_com_gogo_protobuf_types.SizeOfStdDuration(m.StdDev)))
+	n13, err := github_com_gogo_protobuf_types.StdDurationMarshalTo(m.StdDev, dAtA[i:])
 	if err != nil {
-		return nil, err
+		return 0, err
+	}
+	i += n13
+	dAtA[i] = 0x22
+	i++
+	i = encodeVarintQuery(dAtA, i, uint64(github_com_gogo_protobuf_types.SizeOfStdDuration(m.Min)))
+	n14, err := github_com_gogo_protobuf_types.StdDurationMarshalTo(m.Min, dAtA[i:])
+	if err != nil {
+		return 0, err
+	}
+	i += n14
+	dAtA[i] = 0x2a
+	i++
+	i = encodeVarintQuery(dAtA, i, uint64(github_com_gogo_protobuf_types.SizeOfStdDuration(m.Max)))
+	n15, err := github_com_gogo_protobuf_types.StdDurationMarshalTo(m.Max, dAtA[i:])
+	if err != nil {
+		return 0, err
+	}
+	i += n15
+	dAtA[i] = 0x32
+	i++
+	i = encodeVarintQuery(dAtA, i, uint64(github_com_gogo_protobuf_types.SizeOfStdDuration(m.P50)))
+	n16, err := github_com_gogo_protobuf_types.StdDurationMarshalTo(m.P50, dAtA[i:])
+	if err != nil {
+		return 0, err
+	}
+	i += n16
+	dAtA[i] = 0x3a
+	i++
+	i = encodeVarintQuery(dAtA, i, uint64(github_com_gogo_protobuf_types.SizeOfStdDuration(m.P99)))
+	n17, err := github_com_gogo_protobuf_types.StdDurationMarshalTo(m.P99, dAtA[i:])
+	if err != nil {
+		return 0, err
+	}
+	i += n17
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *StructureGroup) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *StructureGroup) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Signature) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintQuery(dAtA, i, uint64(len(m.Signature)))
+		i += copy(dAtA[i:], m.Signature)
+	}
+	if len(m.Hash) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintQuery(dAtA, i, uint64(len(m.Hash)))
+		i += copy(dAtA[i:], m.Hash)
+	}
+	if len(m.TraceIDs) > 0 {
+		for _, msg := range m.TraceIDs {
+			dAtA[i] = 0x1a
+			i++
+			i = encodeVarintQuery(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	dAtA[i] = 0x22
+	i++
+	i = encodeVarintQuery(dAtA, i, uint64(m.Latency.Size()))
+	n18, err := m.Latency.MarshalTo(dAtA[i:])
+	if err != nil {
+		return 0, err
+	}
+	i += n18
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *GetStructureGroupsRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *GetStructureGroupsRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Query != nil {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintQuery(dAtA, i, uint64(m.Query.Size()))
+		n19, err := m.Query.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n19
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *GetStructureGroupsResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *GetStructureGroupsResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Groups) > 0 {
+		for _, msg := range m.Groups {
+			dAtA[i] = 0xa
+			i++
+			i = encodeVarintQuery(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *GetServicesRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *GetServicesRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *GetServicesResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *GetServicesResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Services) > 0 {
+		for _, s := range m.Services {
+			dAtA[i] = 0xa
+			i++
+			l = len(s)
+			for l >= 1<<7 {
+				dAtA[i] = uint8(uint64(l)&0x7f | 0x80)
+				l >>= 7
+				i++
+			}
+			dAtA[i] = uint8(l)
+			i++
+			i += copy(dAtA[i:], s)
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *GetOperationsRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *GetOperationsRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Service) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintQuery(dAtA, i, uint64(len(m.Service)))
+		i += copy(dAtA[i:], m.Service)
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *GetOperationsResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
 	return dAtA[:n], nil
 }
@@ -1389,19 +2540,19 @@ func (m *GetDependenciesRequest) MarshalTo(dAtA []byte) (int, error) {
 	dAtA[i] = 0xa
 	i++
 	i = encodeVarintQuery(dAtA, i, uint64(github_com_gogo_protobuf_types.SizeOfStdTime(m.StartTime)))
-	n8, err := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.StartTime, dAtA[i:])
+	n20, err := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.StartTime, dAtA[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n8
+	i += n20
 	dAtA[i] = 0x12
 	i++
 	i = encodeVarintQuery(dAtA, i, uint64(github_com_gogo_protobuf_types.SizeOfStdTime(m.EndTime)))
-	n9, err := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.EndTime, dAtA[i:])
+	n21, err := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.EndTime, dAtA[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n9
+	i += n21
 	if m.XXX_unrecognized != nil {
 		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
@@ -1458,6 +2609,9 @@ func (m *GetTraceRequest) Size() (n int) {
 	_ = l
 	l = m.TraceID.Size()
 	n += 1 + l + sovQuery(uint64(l))
+	if m.Adjust {
+		n += 2
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -1476,6 +2630,45 @@ func (m *SpansResponseChunk) Size() (n int) {
 			n += 1 + l + sovQuery(uint64(l))
 		}
 	}
+	if m.TraceEnd {
+		n += 2
+	}
+	if len(m.Warnings) > 0 {
+		for _, s := range m.Warnings {
+			l = len(s)
+			n += 1 + l + sovQuery(uint64(l))
+		}
+	}
+	l = m.TraceID.Size()
+	n += 1 + l + sovQuery(uint64(l))
+	l = len(m.Error)
+	if l > 0 {
+		n += 1 + l + sovQuery(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *GetTracesRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.TraceIDs) > 0 {
+		for _, e := range m.TraceIDs {
+			l = e.Size()
+			n += 1 + l + sovQuery(uint64(l))
+		}
+	}
+	if m.ChunkSize != 0 {
+		n += 1 + sovQuery(uint64(m.ChunkSize))
+	}
+	if m.Adjust {
+		n += 2
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -1541,6 +2734,21 @@ func (m *TraceQueryParameters) Size() (n int) {
 	if m.SearchDepth != 0 {
 		n += 1 + sovQuery(uint64(m.SearchDepth))
 	}
+	if len(m.Integrity) > 0 {
+		l = 0
+		for _, e := range m.Integrity {
+			l += sovQuery(uint64(e))
+		}
+		n += 1 + sovQuery(uint64(l)) + l
+	}
+	l = len(m.Pattern)
+	if l > 0 {
+		n += 1 + l + sovQuery(uint64(l))
+	}
+	l = len(m.StructureHash)
+	if l > 0 {
+		n += 1 + l + sovQuery(uint64(l))
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -1557,21 +2765,153 @@ func (m *FindTracesRequest) Size() (n int) {
 		l = m.Query.Size()
 		n += 1 + l + sovQuery(uint64(l))
 	}
+	if m.ChunkSize != 0 {
+		n += 1 + sovQuery(uint64(m.ChunkSize))
+	}
+	if m.TraceDelimited {
+		n += 2
+	}
+	if m.Adjust {
+		n += 2
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
 	return n
 }
 
-func (m *GetServicesRequest) Size() (n int) {
+func (m *FindTraceIDsRequest) Size() (n int) {
 	if m == nil {
 		return 0
 	}
 	var l int
 	_ = l
-	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
-	}
+	if m.Query != nil {
+		l = m.Query.Size()
+		n += 1 + l + sovQuery(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *TraceIDsResponseChunk) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.TraceIDs) > 0 {
+		for _, e := range m.TraceIDs {
+			l = e.Size()
+			n += 1 + l + sovQuery(uint64(l))
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *LatencyStats) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Count != 0 {
+		n += 1 + sovQuery(uint64(m.Count))
+	}
+	l = github_com_gogo_protobuf_types.SizeOfStdDuration(m.Mean)
+	n += 1 + l + sovQuery(uint64(l))
+	l = github_com_gogo_protobuf_types.SizeOfStdDuration(m.StdDev)
+	n += 1 + l + sovQuery(uint64(l))
+	l = github_com_gogo_protobuf_types.SizeOfStdDuration(m.Min)
+	n += 1 + l + sovQuery(uint64(l))
+	l = github_com_gogo_protobuf_types.SizeOfStdDuration(m.Max)
+	n += 1 + l + sovQuery(uint64(l))
+	l = github_com_gogo_protobuf_types.SizeOfStdDuration(m.P50)
+	n += 1 + l + sovQuery(uint64(l))
+	l = github_com_gogo_protobuf_types.SizeOfStdDuration(m.P99)
+	n += 1 + l + sovQuery(uint64(l))
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *StructureGroup) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Signature)
+	if l > 0 {
+		n += 1 + l + sovQuery(uint64(l))
+	}
+	l = len(m.Hash)
+	if l > 0 {
+		n += 1 + l + sovQuery(uint64(l))
+	}
+	if len(m.TraceIDs) > 0 {
+		for _, e := range m.TraceIDs {
+			l = e.Size()
+			n += 1 + l + sovQuery(uint64(l))
+		}
+	}
+	l = m.Latency.Size()
+	n += 1 + l + sovQuery(uint64(l))
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *GetStructureGroupsRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Query != nil {
+		l = m.Query.Size()
+		n += 1 + l + sovQuery(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *GetStructureGroupsResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Groups) > 0 {
+		for _, e := range m.Groups {
+			l = e.Size()
+			n += 1 + l + sovQuery(uint64(l))
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *GetServicesRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
 	return n
 }
 
@@ -1707,7 +3047,1153 @@ func (m *GetTraceRequest) Unmarshal(dAtA []byte) error {
 			if wireType != 2 {
 				return fmt.Errorf("proto: wrong wireType = %d for field TraceID", wireType)
 			}
-			var byteLen int
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.TraceID.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Adjust", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Adjust = bool(v != 0)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipQuery(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *SpansResponseChunk) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowQuery
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: SpansResponseChunk: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: SpansResponseChunk: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Spans", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Spans = append(m.Spans, model.Span{})
+			if err := m.Spans[len(m.Spans)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TraceEnd", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.TraceEnd = bool(v != 0)
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Warnings", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Warnings = append(m.Warnings, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TraceID", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.TraceID.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Error", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Error = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipQuery(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *GetTracesRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowQuery
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: GetTracesRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: GetTracesRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TraceIDs", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			var v github_com_jaegertracing_jaeger_model.TraceID
+			m.TraceIDs = append(m.TraceIDs, v)
+			if err := m.TraceIDs[len(m.TraceIDs)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ChunkSize", wireType)
+			}
+			m.ChunkSize = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ChunkSize |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Adjust", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Adjust = bool(v != 0)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipQuery(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ArchiveTraceRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowQuery
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ArchiveTraceRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ArchiveTraceRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TraceID", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.TraceID.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipQuery(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ArchiveTraceResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowQuery
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ArchiveTraceResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ArchiveTraceResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipQuery(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *TraceQueryParameters) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowQuery
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: TraceQueryParameters: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: TraceQueryParameters: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ServiceName", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ServiceName = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field OperationName", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.OperationName = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Tags", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Tags == nil {
+				m.Tags = make(map[string]string)
+			}
+			var mapkey string
+			var mapvalue string
+			for iNdEx < postIndex {
+				entryPreIndex := iNdEx
+				var wire uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowQuery
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					wire |= uint64(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				fieldNum := int32(wire >> 3)
+				if fieldNum == 1 {
+					var stringLenmapkey uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowQuery
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapkey |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapkey := int(stringLenmapkey)
+					if intStringLenmapkey < 0 {
+						return ErrInvalidLengthQuery
+					}
+					postStringIndexmapkey := iNdEx + intStringLenmapkey
+					if postStringIndexmapkey < 0 {
+						return ErrInvalidLengthQuery
+					}
+					if postStringIndexmapkey > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapkey = string(dAtA[iNdEx:postStringIndexmapkey])
+					iNdEx = postStringIndexmapkey
+				} else if fieldNum == 2 {
+					var stringLenmapvalue uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowQuery
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapvalue |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapvalue := int(stringLenmapvalue)
+					if intStringLenmapvalue < 0 {
+						return ErrInvalidLengthQuery
+					}
+					postStringIndexmapvalue := iNdEx + intStringLenmapvalue
+					if postStringIndexmapvalue < 0 {
+						return ErrInvalidLengthQuery
+					}
+					if postStringIndexmapvalue > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapvalue = string(dAtA[iNdEx:postStringIndexmapvalue])
+					iNdEx = postStringIndexmapvalue
+				} else {
+					iNdEx = entryPreIndex
+					skippy, err := skipQuery(dAtA[iNdEx:])
+					if err != nil {
+						return err
+					}
+					if skippy < 0 {
+						return ErrInvalidLengthQuery
+					}
+					if (iNdEx + skippy) > postIndex {
+						return io.ErrUnexpectedEOF
+					}
+					iNdEx += skippy
+				}
+			}
+			m.Tags[mapkey] = mapvalue
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field StartTimeMin", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := github_com_gogo_protobuf_types.StdTimeUnmarshal(&m.StartTimeMin, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field StartTimeMax", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := github_com_gogo_protobuf_types.StdTimeUnmarshal(&m.StartTimeMax, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DurationMin", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := github_com_gogo_protobuf_types.StdDurationUnmarshal(&m.DurationMin, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DurationMax", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := github_com_gogo_protobuf_types.StdDurationUnmarshal(&m.DurationMax, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 8:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SearchDepth", wireType)
+			}
+			m.SearchDepth = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.SearchDepth |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 9:
+			if wireType == 0 {
+				var v TraceIntegrity
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowQuery
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					v |= TraceIntegrity(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				m.Integrity = append(m.Integrity, v)
+			} else if wireType == 2 {
+				var packedLen int
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowQuery
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					packedLen |= int(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				if packedLen < 0 {
+					return ErrInvalidLengthQuery
+				}
+				postIndex := iNdEx + packedLen
+				if postIndex < 0 {
+					return ErrInvalidLengthQuery
+				}
+				if postIndex > l {
+					return io.ErrUnexpectedEOF
+				}
+				var elementCount int
+				if elementCount != 0 && len(m.Integrity) == 0 {
+					m.Integrity = make([]TraceIntegrity, 0, elementCount)
+				}
+				for iNdEx < postIndex {
+					var v TraceIntegrity
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowQuery
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						v |= TraceIntegrity(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					m.Integrity = append(m.Integrity, v)
+				}
+			} else {
+				return fmt.Errorf("proto: wrong wireType = %d for field Integrity", wireType)
+			}
+		case 10:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Pattern", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Pattern = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 11:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field StructureHash", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.StructureHash = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipQuery(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *FindTracesRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowQuery
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: FindTracesRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: FindTracesRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Query", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Query == nil {
+				m.Query = &TraceQueryParameters{}
+			}
+			if err := m.Query.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ChunkSize", wireType)
+			}
+			m.ChunkSize = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowQuery
@@ -1717,25 +4203,51 @@ func (m *GetTraceRequest) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				byteLen |= int(b&0x7F) << shift
+				m.ChunkSize |= uint32(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if byteLen < 0 {
-				return ErrInvalidLengthQuery
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TraceDelimited", wireType)
 			}
-			postIndex := iNdEx + byteLen
-			if postIndex < 0 {
-				return ErrInvalidLengthQuery
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
 			}
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
+			m.TraceDelimited = bool(v != 0)
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Adjust", wireType)
 			}
-			if err := m.TraceID.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
 			}
-			iNdEx = postIndex
+			m.Adjust = bool(v != 0)
 		default:
 			iNdEx = preIndex
 			skippy, err := skipQuery(dAtA[iNdEx:])
@@ -1761,7 +4273,7 @@ func (m *GetTraceRequest) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *SpansResponseChunk) Unmarshal(dAtA []byte) error {
+func (m *FindTraceIDsRequest) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1784,15 +4296,15 @@ func (m *SpansResponseChunk) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: SpansResponseChunk: wiretype end group for non-group")
+			return fmt.Errorf("proto: FindTraceIDsRequest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: SpansResponseChunk: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: FindTraceIDsRequest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Spans", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Query", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -1819,8 +4331,10 @@ func (m *SpansResponseChunk) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Spans = append(m.Spans, model.Span{})
-			if err := m.Spans[len(m.Spans)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			if m.Query == nil {
+				m.Query = &TraceQueryParameters{}
+			}
+			if err := m.Query.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
@@ -1849,7 +4363,7 @@ func (m *SpansResponseChunk) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *ArchiveTraceRequest) Unmarshal(dAtA []byte) error {
+func (m *TraceIDsResponseChunk) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1872,15 +4386,15 @@ func (m *ArchiveTraceRequest) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: ArchiveTraceRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: TraceIDsResponseChunk: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: ArchiveTraceRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: TraceIDsResponseChunk: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field TraceID", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field TraceIDs", wireType)
 			}
 			var byteLen int
 			for shift := uint(0); ; shift += 7 {
@@ -1907,7 +4421,9 @@ func (m *ArchiveTraceRequest) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if err := m.TraceID.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			var v github_com_jaegertracing_jaeger_model.TraceID
+			m.TraceIDs = append(m.TraceIDs, v)
+			if err := m.TraceIDs[len(m.TraceIDs)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
@@ -1936,7 +4452,7 @@ func (m *ArchiveTraceRequest) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *ArchiveTraceResponse) Unmarshal(dAtA []byte) error {
+func (m *LatencyStats) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1959,12 +4475,229 @@ func (m *ArchiveTraceResponse) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: ArchiveTraceResponse: wiretype end group for non-group")
+			return fmt.Errorf("proto: LatencyStats: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: ArchiveTraceResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: LatencyStats: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Count", wireType)
+			}
+			m.Count = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Count |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Mean", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := github_com_gogo_protobuf_types.StdDurationUnmarshal(&m.Mean, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field StdDev", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := github_com_gogo_protobuf_types.StdDurationUnmarshal(&m.StdDev, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Min", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := github_com_gogo_protobuf_types.StdDurationUnmarshal(&m.Min, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Max", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := github_com_gogo_protobuf_types.StdDurationUnmarshal(&m.Max, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field P50", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := github_com_gogo_protobuf_types.StdDurationUnmarshal(&m.P50, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field P99", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := github_com_gogo_protobuf_types.StdDurationUnmarshal(&m.P99, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipQuery(dAtA[iNdEx:])
@@ -1990,7 +4723,7 @@ func (m *ArchiveTraceResponse) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *TraceQueryParameters) Unmarshal(dAtA []byte) error {
+func (m *StructureGroup) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -2013,15 +4746,15 @@ func (m *TraceQueryParameters) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: TraceQueryParameters: wiretype end group for non-group")
+			return fmt.Errorf("proto: StructureGroup: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: TraceQueryParameters: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: StructureGroup: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ServiceName", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Signature", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -2049,11 +4782,11 @@ func (m *TraceQueryParameters) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.ServiceName = string(dAtA[iNdEx:postIndex])
+			m.Signature = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field OperationName", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Hash", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -2081,13 +4814,13 @@ func (m *TraceQueryParameters) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.OperationName = string(dAtA[iNdEx:postIndex])
+			m.Hash = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		case 3:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Tags", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field TraceIDs", wireType)
 			}
-			var msglen int
+			var byteLen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowQuery
@@ -2097,122 +4830,30 @@ func (m *TraceQueryParameters) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= int(b&0x7F) << shift
+				byteLen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
+			if byteLen < 0 {
 				return ErrInvalidLengthQuery
 			}
-			postIndex := iNdEx + msglen
+			postIndex := iNdEx + byteLen
 			if postIndex < 0 {
 				return ErrInvalidLengthQuery
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.Tags == nil {
-				m.Tags = make(map[string]string)
-			}
-			var mapkey string
-			var mapvalue string
-			for iNdEx < postIndex {
-				entryPreIndex := iNdEx
-				var wire uint64
-				for shift := uint(0); ; shift += 7 {
-					if shift >= 64 {
-						return ErrIntOverflowQuery
-					}
-					if iNdEx >= l {
-						return io.ErrUnexpectedEOF
-					}
-					b := dAtA[iNdEx]
-					iNdEx++
-					wire |= uint64(b&0x7F) << shift
-					if b < 0x80 {
-						break
-					}
-				}
-				fieldNum := int32(wire >> 3)
-				if fieldNum == 1 {
-					var stringLenmapkey uint64
-					for shift := uint(0); ; shift += 7 {
-						if shift >= 64 {
-							return ErrIntOverflowQuery
-						}
-						if iNdEx >= l {
-							return io.ErrUnexpectedEOF
-						}
-						b := dAtA[iNdEx]
-						iNdEx++
-						stringLenmapkey |= uint64(b&0x7F) << shift
-						if b < 0x80 {
-							break
-						}
-					}
-					intStringLenmapkey := int(stringLenmapkey)
-					if intStringLenmapkey < 0 {
-						return ErrInvalidLengthQuery
-					}
-					postStringIndexmapkey := iNdEx + intStringLenmapkey
-					if postStringIndexmapkey < 0 {
-						return ErrInvalidLengthQuery
-					}
-					if postStringIndexmapkey > l {
-						return io.ErrUnexpectedEOF
-					}
-					mapkey = string(dAtA[iNdEx:postStringIndexmapkey])
-					iNdEx = postStringIndexmapkey
-				} else if fieldNum == 2 {
-					var stringLenmapvalue uint64
-					for shift := uint(0); ; shift += 7 {
-						if shift >= 64 {
-							return ErrIntOverflowQuery
-						}
-						if iNdEx >= l {
-							return io.ErrUnexpectedEOF
-						}
-						b := dAtA[iNdEx]
-						iNdEx++
-						stringLenmapvalue |= uint64(b&0x7F) << shift
-						if b < 0x80 {
-							break
-						}
-					}
-					intStringLenmapvalue := int(stringLenmapvalue)
-					if intStringLenmapvalue < 0 {
-						return ErrInvalidLengthQuery
-					}
-					postStringIndexmapvalue := iNdEx + intStringLenmapvalue
-					if postStringIndexmapvalue < 0 {
-						return ErrInvalidLengthQuery
-					}
-					if postStringIndexmapvalue > l {
-						return io.ErrUnexpectedEOF
-					}
-					mapvalue = string(dAtA[iNdEx:postStringIndexmapvalue])
-					iNdEx = postStringIndexmapvalue
-				} else {
-					iNdEx = entryPreIndex
-					skippy, err := skipQuery(dAtA[iNdEx:])
-					if err != nil {
-						return err
-					}
-					if skippy < 0 {
-						return ErrInvalidLengthQuery
-					}
-					if (iNdEx + skippy) > postIndex {
-						return io.ErrUnexpectedEOF
-					}
-					iNdEx += skippy
-				}
+			var v github_com_jaegertracing_jaeger_model.TraceID
+			m.TraceIDs = append(m.TraceIDs, v)
+			if err := m.TraceIDs[len(m.TraceIDs)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
 			}
-			m.Tags[mapkey] = mapvalue
 			iNdEx = postIndex
 		case 4:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field StartTimeMin", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Latency", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -2239,79 +4880,67 @@ func (m *TraceQueryParameters) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if err := github_com_gogo_protobuf_types.StdTimeUnmarshal(&m.StartTimeMin, dAtA[iNdEx:postIndex]); err != nil {
+			if err := m.Latency.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
-		case 5:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field StartTimeMax", wireType)
-			}
-			var msglen int
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowQuery
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				msglen |= int(b&0x7F) << shift
-				if b < 0x80 {
-					break
-				}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipQuery(dAtA[iNdEx:])
+			if err != nil {
+				return err
 			}
-			if msglen < 0 {
+			if skippy < 0 {
 				return ErrInvalidLengthQuery
 			}
-			postIndex := iNdEx + msglen
-			if postIndex < 0 {
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthQuery
 			}
-			if postIndex > l {
+			if (iNdEx + skippy) > l {
 				return io.ErrUnexpectedEOF
 			}
-			if err := github_com_gogo_protobuf_types.StdTimeUnmarshal(&m.StartTimeMax, dAtA[iNdEx:postIndex]); err != nil {
-				return err
-			}
-			iNdEx = postIndex
-		case 6:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field DurationMin", wireType)
-			}
-			var msglen int
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowQuery
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				msglen |= int(b&0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
-			if msglen < 0 {
-				return ErrInvalidLengthQuery
-			}
-			postIndex := iNdEx + msglen
-			if postIndex < 0 {
-				return ErrInvalidLengthQuery
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *GetStructureGroupsRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowQuery
 			}
-			if postIndex > l {
+			if iNdEx >= l {
 				return io.ErrUnexpectedEOF
 			}
-			if err := github_com_gogo_protobuf_types.StdDurationUnmarshal(&m.DurationMin, dAtA[iNdEx:postIndex]); err != nil {
-				return err
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
 			}
-			iNdEx = postIndex
-		case 7:
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: GetStructureGroupsRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: GetStructureGroupsRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field DurationMax", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Query", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -2338,29 +4967,13 @@ func (m *TraceQueryParameters) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if err := github_com_gogo_protobuf_types.StdDurationUnmarshal(&m.DurationMax, dAtA[iNdEx:postIndex]); err != nil {
+			if m.Query == nil {
+				m.Query = &TraceQueryParameters{}
+			}
+			if err := m.Query.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
-		case 8:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field SearchDepth", wireType)
-			}
-			m.SearchDepth = 0
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowQuery
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				m.SearchDepth |= int32(b&0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipQuery(dAtA[iNdEx:])
@@ -2386,7 +4999,7 @@ func (m *TraceQueryParameters) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *FindTracesRequest) Unmarshal(dAtA []byte) error {
+func (m *GetStructureGroupsResponse) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -2409,15 +5022,15 @@ func (m *FindTracesRequest) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: FindTracesRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: GetStructureGroupsResponse: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: FindTracesRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: GetStructureGroupsResponse: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Query", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Groups", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -2444,10 +5057,8 @@ func (m *FindTracesRequest) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.Query == nil {
-				m.Query = &TraceQueryParameters{}
-			}
-			if err := m.Query.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			m.Groups = append(m.Groups, StructureGroup{})
+			if err := m.Groups[len(m.Groups)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
@@ -12,7 +12,7 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package dependencystore
+package spanreader
 
 import (
 	"context"
@@ -22,7 +22,8 @@ import (
 	"github.com/jaegertracing/jaeger/storage/spanstore"
 )
 
-// DependencyStore handles all queries and insertions to Cassandra dependencies
+// DependencyStore computes the dependencies from the traces of a span reader, for the storage
+// backends which do not store them
 type DependencyStore struct {
 	reader spanstore.Reader
 }
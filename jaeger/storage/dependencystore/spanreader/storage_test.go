@@ -0,0 +1,67 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spanreader
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+	spanstoremocks "github.com/jaegertracing/jaeger/storage/spanstore/mocks"
+)
+
+func TestGetDependencies(t *testing.T) {
+	traceID := model.NewTraceID(0, 1)
+	span := func(spanID uint64, service string, parentID uint64) *model.Span {
+		s := &model.Span{
+			TraceID: traceID,
+			SpanID:  model.NewSpanID(spanID),
+			Process: &model.Process{ServiceName: service},
+		}
+		if parentID != 0 {
+			s.References = []model.SpanRef{model.NewChildOfRef(traceID, model.NewSpanID(parentID))}
+		}
+		return s
+	}
+	trace := &model.Trace{Spans: []*model.Span{
+		span(1, "frontend", 0),
+		span(2, "frontend", 1),
+		span(3, "backend", 2),
+		span(4, "backend", 2),
+	}}
+	endTs := time.Date(2020, time.January, 1, 12, 0, 0, 0, time.UTC)
+	reader := &spanstoremocks.Reader{}
+	reader.On("FindTraces", mock.Anything, &spanstore.TraceQueryParameters{
+		StartTimeMin: endTs.Add(-time.Hour),
+		StartTimeMax: endTs,
+	}).Return([]*model.Trace{trace}, nil)
+
+	links, err := NewDependencyStore(reader).GetDependencies(endTs, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, []model.DependencyLink{{Parent: "frontend", Child: "backend", CallCount: 2}}, links)
+}
+
+func TestGetDependenciesError(t *testing.T) {
+	reader := &spanstoremocks.Reader{}
+	reader.On("FindTraces", mock.Anything, mock.Anything).Return(nil, errors.New("storage error"))
+	_, err := NewDependencyStore(reader).GetDependencies(time.Now(), time.Hour)
+	assert.EqualError(t, err, "storage error")
+}
@@ -52,6 +52,9 @@ var (
 
 	// ErrArchiveStorageNotSupported can be returned by the ArchiveFactory when the archive storage is not supported by the backend.
 	ErrArchiveStorageNotSupported = errors.New("archive storage not supported")
+
+	// ErrStructureIndexNotSupported can be returned by the StructureIndexFactory when the structure index is not supported by the backend.
+	ErrStructureIndexNotSupported = errors.New("structure index not supported")
 )
 
 // ArchiveFactory is an additional interface that can be implemented by a factory to support trace archiving.
@@ -62,3 +65,14 @@ type ArchiveFactory interface {
 	// CreateArchiveSpanWriter creates a spanstore.Writer.
 	CreateArchiveSpanWriter() (spanstore.Writer, error)
 }
+
+// StructureIndexFactory is an additional interface that can be implemented by a factory to
+// index traces by structural hash. The span readers of such a factory filter their results by
+// spanstore.TraceQueryParameters.StructureHash.
+type StructureIndexFactory interface {
+	// CreateStructureReader creates a spanstore.StructureReader.
+	CreateStructureReader() (spanstore.StructureReader, error)
+
+	// CreateStructureWriter creates a spanstore.StructureWriter.
+	CreateStructureWriter() (spanstore.StructureWriter, error)
+}
@@ -52,4 +52,29 @@ type TraceQueryParameters struct {
 	DurationMin   time.Duration
 	DurationMax   time.Duration
 	NumTraces     int
+	// Integrity restricts the results to the traces matching any of the statuses, see
+	// model.IntegrityStatus.Matches. This filter is applied by the query service, which
+	// reads more than NumTraces traces from storage until NumTraces of them match.
+	Integrity []model.IntegrityStatus
+	// Pattern restricts the results to the traces whose span tree matches the pattern, see
+	// the model/structure/pattern package. Like Integrity, it is applied by the query service.
+	Pattern string
+	// StructureHash restricts the results to the traces with the structural hash, see
+	// structure.Hash. It is applied by the readers of the backends implementing
+	// StructureReader, which only know the hash of the traces indexed by a StructureWriter,
+	// and by the query service when the structures are not indexed.
+	StructureHash string
+}
+
+// StructureWriter persists the structural hash of complete traces, see structure.Hash.
+type StructureWriter interface {
+	// WriteStructure records the hash of the trace, which started at startTime.
+	WriteStructure(traceID model.TraceID, startTime time.Time, hash string) error
+}
+
+// StructureReader reads the structural hashes persisted by a StructureWriter.
+type StructureReader interface {
+	// CountStructures counts the indexed traces by structural hash, among the traces which
+	// started between startTimeMin and startTimeMax, without loading their spans.
+	CountStructures(ctx context.Context, startTimeMin, startTimeMax time.Time) (map[string]int, error)
 }
@@ -0,0 +1,47 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mocks
+
+import context "context"
+import mock "github.com/stretchr/testify/mock"
+import time "time"
+
+// StructureReader is an autogenerated mock type for the StructureReader type
+type StructureReader struct {
+	mock.Mock
+}
+
+// CountStructures provides a mock function with given fields: ctx, startTimeMin, startTimeMax
+func (_m *StructureReader) CountStructures(ctx context.Context, startTimeMin time.Time, startTimeMax time.Time) (map[string]int, error) {
+	ret := _m.Called(ctx, startTimeMin, startTimeMax)
+
+	var r0 map[string]int
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, time.Time) map[string]int); ok {
+		r0 = rf(ctx, startTimeMin, startTimeMax)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]int)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, time.Time) error); ok {
+		r1 = rf(ctx, startTimeMin, startTimeMax)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
@@ -0,0 +1,38 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mocks
+
+import mock "github.com/stretchr/testify/mock"
+import model "github.com/jaegertracing/jaeger/model"
+import time "time"
+
+// StructureWriter is an autogenerated mock type for the StructureWriter type
+type StructureWriter struct {
+	mock.Mock
+}
+
+// WriteStructure provides a mock function with given fields: traceID, startTime, hash
+func (_m *StructureWriter) WriteStructure(traceID model.TraceID, startTime time.Time, hash string) error {
+	ret := _m.Called(traceID, startTime, hash)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(model.TraceID, time.Time, string) error); ok {
+		r0 = rf(traceID, startTime, hash)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}